@@ -51,6 +51,7 @@ func (src *Cluster) ConvertTo(dstRaw conversion.Hub) error {
 	dst.Status.ControlPlaneReady = restored.Status.ControlPlaneReady
 	dst.Status.FailureDomains = restored.Status.FailureDomains
 	dst.Spec.Paused = restored.Spec.Paused
+	dst.Spec.TargetVersion = restored.Spec.TargetVersion
 	dst.Status.Conditions = restored.Status.Conditions
 	dst.Status.ObservedGeneration = restored.Status.ObservedGeneration
 
@@ -191,6 +192,7 @@ func (src *MachineSet) ConvertTo(dstRaw conversion.Hub) error {
 		dst.Spec.ClusterName = restored.Spec.ClusterName
 	}
 	restoreMachineSpec(&restored.Spec.Template.Spec, &dst.Spec.Template.Spec)
+	dst.Status.Conditions = restored.Status.Conditions
 
 	return nil
 }
@@ -255,6 +257,7 @@ func (src *MachineDeployment) ConvertTo(dstRaw conversion.Hub) error {
 	}
 	dst.Spec.Paused = restored.Spec.Paused
 	dst.Status.Phase = restored.Status.Phase
+	dst.Status.Conditions = restored.Status.Conditions
 	restoreMachineSpec(&restored.Spec.Template.Spec, &dst.Spec.Template.Spec)
 
 	return nil
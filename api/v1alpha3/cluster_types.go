@@ -56,6 +56,13 @@ type ClusterSpec struct {
 	// for provisioning infrastructure for a cluster in said provider.
 	// +optional
 	InfrastructureRef *corev1.ObjectReference `json:"infrastructureRef,omitempty"`
+
+	// TargetVersion is the Kubernetes version the Cluster should be upgraded to. When set, the control
+	// plane is upgraded one minor version at a time, going through the intermediate minor versions
+	// (e.g. v1.17.0 when upgrading from v1.16.x to v1.18.x), then the MachineDeployments belonging
+	// to the Cluster are upgraded. Requires a control plane provider supporting spec.version.
+	// +optional
+	TargetVersion *string `json:"targetVersion,omitempty"`
 }
 
 // ANCHOR_END: ClusterSpec
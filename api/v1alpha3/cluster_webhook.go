@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha3
 
 import (
+	"strings"
+
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -44,6 +46,11 @@ func (c *Cluster) Default() {
 	if c.Spec.ControlPlaneRef != nil && len(c.Spec.ControlPlaneRef.Namespace) == 0 {
 		c.Spec.ControlPlaneRef.Namespace = c.Namespace
 	}
+
+	if c.Spec.TargetVersion != nil && !strings.HasPrefix(*c.Spec.TargetVersion, "v") {
+		normalizedVersion := "v" + *c.Spec.TargetVersion
+		c.Spec.TargetVersion = &normalizedVersion
+	}
 }
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
@@ -87,6 +94,10 @@ func (c *Cluster) validate() error {
 
 	}
 
+	if c.Spec.TargetVersion != nil && !kubeSemver.MatchString(*c.Spec.TargetVersion) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "targetVersion"), *c.Spec.TargetVersion, "must be a valid semantic version"))
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -23,6 +23,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 )
 
 func TestClusterDefault(t *testing.T) {
@@ -35,12 +36,14 @@ func TestClusterDefault(t *testing.T) {
 		Spec: ClusterSpec{
 			InfrastructureRef: &corev1.ObjectReference{},
 			ControlPlaneRef:   &corev1.ObjectReference{},
+			TargetVersion:     pointer.StringPtr("1.18.2"),
 		},
 	}
 	c.Default()
 
 	g.Expect(c.Spec.InfrastructureRef.Namespace).To(Equal(c.Namespace))
 	g.Expect(c.Spec.ControlPlaneRef.Namespace).To(Equal(c.Namespace))
+	g.Expect(*c.Spec.TargetVersion).To(Equal("v1.18.2"))
 }
 
 func TestClusterValidation(t *testing.T) {
@@ -63,6 +66,12 @@ func TestClusterValidation(t *testing.T) {
 	invalidCPNamespace := valid.DeepCopy()
 	invalidCPNamespace.Spec.InfrastructureRef.Namespace = "baz"
 
+	validTargetVersion := valid.DeepCopy()
+	validTargetVersion.Spec.TargetVersion = pointer.StringPtr("v1.18.2")
+
+	invalidTargetVersion := valid.DeepCopy()
+	invalidTargetVersion.Spec.TargetVersion = pointer.StringPtr("v1.18")
+
 	tests := []struct {
 		name      string
 		expectErr bool
@@ -83,6 +92,16 @@ func TestClusterValidation(t *testing.T) {
 			expectErr: false,
 			c:         valid,
 		},
+		{
+			name:      "should succeed when target version is a valid semantic version",
+			expectErr: false,
+			c:         validTargetVersion,
+		},
+		{
+			name:      "should return error when target version is not a valid semantic version",
+			expectErr: true,
+			c:         invalidTargetVersion,
+		},
 	}
 
 	for _, tt := range tests {
@@ -38,6 +38,12 @@ const (
 	// on the reconciled object.
 	PausedAnnotation = "cluster.x-k8s.io/paused"
 
+	// TunnelAnnotation is an annotation that can be applied to a Cluster whose API server can't be reached
+	// from the management cluster, so the Cluster API controllers reach it through the reverse tunnel agent
+	// running in the workload cluster; the Cluster controller generates the token of the agent in the
+	// <cluster-name>-tunnel Secret.
+	TunnelAnnotation = "cluster.x-k8s.io/tunnel"
+
 	// ClusterSecretType defines the type of secret created by core components
 	ClusterSecretType corev1.SecretType = "cluster.x-k8s.io/secret" //nolint:gosec
 )
@@ -52,6 +52,24 @@ const (
 	// to be available.
 	// NOTE: This reason is used only as a fallback when the control plane object is not reporting its own ready condition.
 	WaitingForControlPlaneFallbackReason = "WaitingForControlPlane"
+
+	// WorkersReadyCondition reports if all the replicas of the MachineDeployments and of the MachinePools belonging to
+	// this cluster are ready and available; the absence of this condition means the cluster has no worker pools.
+	WorkersReadyCondition ConditionType = "WorkersReady"
+
+	// WaitingForWorkersReason (Severity=Info) documents a cluster waiting for all the replicas of its
+	// MachineDeployments and MachinePools to be ready and available.
+	WaitingForWorkersReason = "WaitingForWorkers"
+
+	// RemoteConnectionProbeCondition reports the result of the periodic probes of the connection from the management
+	// cluster to the API server of the workload cluster; the absence of this condition means the connection was never
+	// probed, e.g. because no controller needed to reach the workload cluster yet.
+	RemoteConnectionProbeCondition ConditionType = "RemoteConnectionProbe"
+
+	// RemoteConnectionFailedReason (Severity=Warning) documents a cluster whose API server failed to answer the last
+	// connection probe; the severity is raised to Error once the failures reach the unhealthy threshold and the
+	// connection to the cluster is reset.
+	RemoteConnectionFailedReason = "RemoteConnectionFailed"
 )
 
 // Conditions and condition Reasons for the Machine object
@@ -95,3 +113,89 @@ const (
 	// WaitingForRemediation is the reason used when a machine fails a health check and remediation is needed.
 	WaitingForRemediation = "WaitingForRemediation"
 )
+
+const (
+	// DrainingSucceededCondition provide evidence of the status of the node drain operation which happens during the machine
+	// deletion process; the LastTransitionTime of the condition records when the drain operation started.
+	DrainingSucceededCondition ConditionType = "DrainingSucceeded"
+
+	// DrainingReason (Severity=Info) documents a machine node being drained; failures of the drain operation are
+	// reported as events, so the condition is not changed until the drain operation succeeds.
+	DrainingReason = "Draining"
+)
+
+// Conditions and condition Reasons for the MachineSet object
+
+const (
+	// MachinesCreatedCondition documents that the machines controlled by the MachineSet are created.
+	// When this condition is false, it indicates that there was an error when cloning the infrastructure/bootstrap template or
+	// when generating the machine object.
+	MachinesCreatedCondition ConditionType = "MachinesCreated"
+
+	// InfrastructureTemplateCloningFailedReason (Severity=Error) documents a MachineSet failing to
+	// clone the infrastructure template.
+	InfrastructureTemplateCloningFailedReason = "InfrastructureTemplateCloningFailed"
+
+	// BootstrapTemplateCloningFailedReason (Severity=Error) documents a MachineSet failing to
+	// clone the bootstrap template.
+	BootstrapTemplateCloningFailedReason = "BootstrapTemplateCloningFailed"
+
+	// MachineCreationFailedReason (Severity=Error) documents a MachineSet failing to
+	// generate a machine object.
+	MachineCreationFailedReason = "MachineCreationFailed"
+
+	// MachinesReadyCondition reports an aggregate of current status of the machines controlled by the MachineSet,
+	// or of the MachineSets controlled by the MachineDeployment.
+	MachinesReadyCondition ConditionType = "MachinesReady"
+)
+
+// Conditions and condition Reasons for the MachineDeployment object
+
+const (
+	// MachineDeploymentAvailableCondition means the MachineDeployment is available, that is, at least the minimum available
+	// machines required (i.e. Spec.Replicas-MaxUnavailable when MachineDeploymentStrategyType = RollingUpdate) are up and running for at least minReadySeconds.
+	MachineDeploymentAvailableCondition ConditionType = "Available"
+
+	// WaitingForAvailableMachinesReason (Severity=Warning) reflects the fact that the required minimum number of machines for a MachineDeployment are not available.
+	WaitingForAvailableMachinesReason = "WaitingForAvailableMachines"
+)
+
+// Conditions and condition Reasons for the MachineHealthCheck object
+
+const (
+	// RemediationAllowedCondition is set on MachineHealthChecks to show the status of whether the MachineHealthCheck is
+	// allowed to remediate any Machines or whether it is blocked from remediating any further.
+	RemediationAllowedCondition ConditionType = "RemediationAllowed"
+
+	// TooManyUnhealthyReason is the reason used when too many Machines are unhealthy and the MachineHealthCheck is blocked
+	// from making any further remediations.
+	TooManyUnhealthyReason = "TooManyUnhealthy"
+)
+
+// Conditions and condition Reasons for the Cluster TargetVersion.
+
+const (
+	// TargetVersionReachedCondition reports on the progress of the upgrade of a Cluster to Cluster.Spec.TargetVersion;
+	// it is True when the control plane and all the MachineDeployments belonging to the Cluster are at the target version.
+	TargetVersionReachedCondition ConditionType = "TargetVersionReached"
+
+	// ControlPlaneUpgradingReason (Severity=Info) documents a Cluster waiting for the control plane to be upgraded
+	// to the target version, or to one of the intermediate minor versions.
+	ControlPlaneUpgradingReason = "ControlPlaneUpgrading"
+
+	// WorkersUpgradingReason (Severity=Info) documents a Cluster waiting for the MachineDeployments to be upgraded
+	// to the target version.
+	WorkersUpgradingReason = "WorkersUpgrading"
+
+	// InvalidTargetVersionReason (Severity=Error) documents a Cluster whose target version cannot be reached, e.g.
+	// because it is older than the control plane version.
+	InvalidTargetVersionReason = "InvalidTargetVersion"
+)
+
+// Conditions and condition Reasons for the MachineDeployment and MachinePool version skew.
+
+const (
+	// VersionSkewNotSupportedReason (Severity=Warning) documents a MachineDeployment or MachinePool whose version is
+	// newer than the control plane version, which is not supported by the Kubernetes version skew policy.
+	VersionSkewNotSupportedReason = "VersionSkewNotSupported"
+)
@@ -0,0 +1,61 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterIdentityReference is a reference to a cluster-scoped infrastructure provider identity, which
+// defines the credentials an infrastructure provider should use for provisioning the infrastructure of a cluster.
+//
+// Infrastructure providers supporting multi-tenancy are expected to expose this reference
+// in the spec of their infrastructure cluster objects, under the `identityRef` field.
+type ClusterIdentityReference struct {
+	// APIVersion of the identity.
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the identity.
+	Kind string `json:"kind"`
+
+	// Name of the identity.
+	Name string `json:"name"`
+}
+
+// ClusterIdentitySpec defines the fields shared by all the infrastructure provider identities.
+//
+// Infrastructure providers are expected to embed this type inline in the spec of their identity objects,
+// so the `spec.allowedNamespaces` field can be used for checking if an infrastructure cluster is allowed
+// to use an identity.
+type ClusterIdentitySpec struct {
+	// AllowedNamespaces defines the namespaces of the infrastructure clusters allowed to use the identity.
+	// If nil, no namespaces are allowed to use the identity; if empty, all the namespaces are allowed to use the identity.
+	// +optional
+	AllowedNamespaces *AllowedNamespaces `json:"allowedNamespaces,omitempty"`
+}
+
+// AllowedNamespaces defines the namespaces allowed to use an identity, either by name or with a label selector;
+// a namespace is allowed if it is included in the list of names or if it matches the selector.
+type AllowedNamespaces struct {
+	// NamespaceList is a list of the names of the namespaces allowed to use the identity.
+	// +optional
+	NamespaceList []string `json:"list,omitempty"`
+
+	// Selector is a label selector of the namespaces allowed to use the identity.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
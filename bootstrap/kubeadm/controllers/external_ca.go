@@ -0,0 +1,252 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha3"
+	kubeadmv1beta1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/cluster-api/util/kubeconfig"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/cluster-api/util/signer"
+)
+
+const (
+	defaultServiceSubnet = "10.96.0.0/12"
+	defaultDNSDomain     = "cluster.local"
+	defaultAPIServerPort = "6443"
+	kubernetesDir        = "/etc/kubernetes"
+)
+
+// externalCAFiles returns the certificates and kubeconfig files of a control plane machine which are signed by the
+// certificate authorities whose private key is kept by an external signer. As kubeadm cannot sign them, they are
+// issued with the external signer, so kubeadm uses them as-is (a.k.a. kubeadm external CA mode).
+//
+// The ClusterConfiguration and the InitConfiguration are nil for joining control plane machines, in which case the
+// Cluster settings are used. The kubelet kubeconfig is issued only for the init control plane machine, as the joining
+// machines use the kubelet TLS bootstrap; it requires the node name to be set in the InitConfiguration.
+func (r *KubeadmConfigReconciler) externalCAFiles(ctx context.Context, cluster *clusterv1.Cluster, clusterConfig *kubeadmv1beta1.ClusterConfiguration, initConfig *kubeadmv1beta1.InitConfiguration, certificates secret.Certificates) ([]bootstrapv1.File, error) {
+	if etcdCA := certificates.GetByPurpose(secret.EtcdCA); etcdCA != nil && etcdCA.ExternalSigner {
+		return nil, errors.New("the etcd certificate authority cannot use an external signer, the etcd certificates are specific to each machine")
+	}
+	clusterCA := certificates.GetByPurpose(secret.ClusterCA)
+	frontProxyCA := certificates.GetByPurpose(secret.FrontProxyCA)
+	if (clusterCA == nil || !clusterCA.ExternalSigner) && (frontProxyCA == nil || !frontProxyCA.ExternalSigner) {
+		return nil, nil
+	}
+
+	if clusterConfig == nil {
+		clusterConfig = &kubeadmv1beta1.ClusterConfiguration{}
+	}
+	certificatesDir := clusterConfig.CertificatesDir
+	if certificatesDir == "" {
+		certificatesDir = secret.DefaultCertificatesDir
+	}
+
+	var files []bootstrapv1.File
+	if frontProxyCA != nil && frontProxyCA.ExternalSigner {
+		s, err := signer.Get(ctx, r.Client, util.ObjectKey(cluster), secret.FrontProxyCA)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get the signer of the front proxy certificate authority")
+		}
+		certFiles, err := signedCertFiles(ctx, s, filepath.Join(certificatesDir, "front-proxy-client"), &certs.Config{
+			CommonName: "front-proxy-client",
+			Usages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		})
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, certFiles...)
+	}
+
+	if clusterCA == nil || !clusterCA.ExternalSigner {
+		return files, nil
+	}
+	s, err := signer.Get(ctx, r.Client, util.ObjectKey(cluster), secret.ClusterCA)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the signer of the cluster certificate authority")
+	}
+
+	endpoint := clusterConfig.ControlPlaneEndpoint
+	if endpoint == "" {
+		endpoint = cluster.Spec.ControlPlaneEndpoint.String()
+	}
+	host, _, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		host = endpoint
+		endpoint = net.JoinHostPort(host, defaultAPIServerPort)
+	}
+	if host == "" {
+		return nil, errors.New("the control plane endpoint is required to issue the certificates with an external signer")
+	}
+
+	altNames, err := apiServerAltNames(cluster, clusterConfig, host)
+	if err != nil {
+		return nil, err
+	}
+	for name, cfg := range map[string]*certs.Config{
+		"apiserver": {
+			CommonName: "kube-apiserver",
+			AltNames:   *altNames,
+			Usages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		},
+		"apiserver-kubelet-client": {
+			CommonName:   "kube-apiserver-kubelet-client",
+			Organization: []string{"system:masters"},
+			Usages:       []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		},
+	} {
+		certFiles, err := signedCertFiles(ctx, s, filepath.Join(certificatesDir, name), cfg)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, certFiles...)
+	}
+
+	kubeconfigs := map[string]*certs.Config{
+		"admin.conf": {
+			CommonName:   "kubernetes-admin",
+			Organization: []string{"system:masters"},
+		},
+		"controller-manager.conf": {
+			CommonName: "system:kube-controller-manager",
+		},
+		"scheduler.conf": {
+			CommonName: "system:kube-scheduler",
+		},
+	}
+	if initConfig != nil {
+		nodeName := initConfig.NodeRegistration.Name
+		if nodeName == "" || strings.Contains(nodeName, "{{") {
+			return nil, errors.New("the node name must be set in the init configuration to issue the kubelet kubeconfig with an external signer")
+		}
+		kubeconfigs["kubelet.conf"] = &certs.Config{
+			CommonName:   fmt.Sprintf("system:node:%s", nodeName),
+			Organization: []string{"system:nodes"},
+		}
+	}
+	clusterName := clusterConfig.ClusterName
+	if clusterName == "" {
+		clusterName = cluster.Name
+	}
+	for name, cfg := range kubeconfigs {
+		cfg.Usages = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+		config, err := kubeconfig.NewWithSigner(ctx, clusterName, "https://"+endpoint, cfg.CommonName, cfg, s, clusterCA.KeyPair.Cert)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to issue the %s kubeconfig", name)
+		}
+		out, err := clientcmd.Write(*config)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to serialize the %s kubeconfig", name)
+		}
+		files = append(files, bootstrapv1.File{
+			Path:        filepath.Join(kubernetesDir, name),
+			Owner:       "root:root",
+			Permissions: "0600",
+			Content:     string(out),
+		})
+	}
+
+	return files, nil
+}
+
+// signedCertFiles returns the certificate and key files of a new key pair issued by the given Signer.
+func signedCertFiles(ctx context.Context, s signer.Signer, pathWithoutExtension string, cfg *certs.Config) ([]bootstrapv1.File, error) {
+	key, err := certs.NewPrivateKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create private key")
+	}
+	cert, err := s.Sign(ctx, key, cfg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to issue the %s certificate", cfg.CommonName)
+	}
+	return []bootstrapv1.File{
+		{
+			Path:        pathWithoutExtension + ".crt",
+			Owner:       "root:root",
+			Permissions: "0640",
+			Content:     string(certs.EncodeCertPEM(cert)),
+		},
+		{
+			Path:        pathWithoutExtension + ".key",
+			Owner:       "root:root",
+			Permissions: "0600",
+			Content:     string(certs.EncodePrivateKeyPEM(key)),
+		},
+	}, nil
+}
+
+// apiServerAltNames returns the SANs of the API server certificate, as kubeadm would, but for the node specific ones.
+func apiServerAltNames(cluster *clusterv1.Cluster, clusterConfig *kubeadmv1beta1.ClusterConfiguration, host string) (*certs.AltNames, error) {
+	serviceSubnet := clusterConfig.Networking.ServiceSubnet
+	if serviceSubnet == "" && cluster.Spec.ClusterNetwork != nil && cluster.Spec.ClusterNetwork.Services != nil && len(cluster.Spec.ClusterNetwork.Services.CIDRBlocks) > 0 {
+		serviceSubnet = cluster.Spec.ClusterNetwork.Services.CIDRBlocks[0]
+	}
+	if serviceSubnet == "" {
+		serviceSubnet = defaultServiceSubnet
+	}
+	dnsDomain := clusterConfig.Networking.DNSDomain
+	if dnsDomain == "" && cluster.Spec.ClusterNetwork != nil {
+		dnsDomain = cluster.Spec.ClusterNetwork.ServiceDomain
+	}
+	if dnsDomain == "" {
+		dnsDomain = defaultDNSDomain
+	}
+
+	// The kubernetes Service uses the first IP of the (first) service subnet.
+	_, serviceNet, err := net.ParseCIDR(strings.Split(serviceSubnet, ",")[0])
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid service subnet %q", serviceSubnet)
+	}
+	serviceIP := make(net.IP, len(serviceNet.IP))
+	copy(serviceIP, serviceNet.IP)
+	for i := len(serviceIP) - 1; i >= 0; i-- {
+		serviceIP[i]++
+		if serviceIP[i] != 0 {
+			break
+		}
+	}
+
+	altNames := &certs.AltNames{
+		DNSNames: []string{
+			"kubernetes",
+			"kubernetes.default",
+			"kubernetes.default.svc",
+			fmt.Sprintf("kubernetes.default.svc.%s", dnsDomain),
+		},
+		IPs: []net.IP{serviceIP},
+	}
+	for _, name := range append([]string{host}, clusterConfig.APIServer.CertSANs...) {
+		if ip := net.ParseIP(name); ip != nil {
+			altNames.IPs = append(altNames.IPs, ip)
+		} else {
+			altNames.DNSNames = append(altNames.DNSNames, name)
+		}
+	}
+	return altNames, nil
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha3"
+	kubeadmv1beta1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
+	"sigs.k8s.io/cluster-api/test/helpers"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/cluster-api/util/signer"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestKubeadmConfigReconciler_externalCAFiles(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	cluster := newCluster("my-cluster")
+	cluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{Host: "api.example.com", Port: 6443}
+
+	// The cluster CA is kept by the external signer, the other CAs are stored in the management cluster.
+	generated := secret.NewCertificatesForInitialControlPlane(&kubeadmv1beta1.ClusterConfiguration{})
+	g.Expect(generated.Generate()).To(Succeed())
+	objs := []runtime.Object{}
+	for _, c := range generated {
+		objs = append(objs, c.AsSecret(util.ObjectKey(cluster), metav1.OwnerReference{}))
+	}
+	clusterCA := generated.GetByPurpose(secret.ClusterCA)
+	caCert, err := certs.DecodeCertPEM(clusterCA.KeyPair.Cert)
+	g.Expect(err).NotTo(HaveOccurred())
+	caKey, err := certs.DecodePrivateKeyPEM(clusterCA.KeyPair.Key)
+	g.Expect(err).NotTo(HaveOccurred())
+	server := signer.NewFakeServer(caCert, caKey, "token")
+	defer server.Close()
+
+	clusterCASecret := objs[0].(*corev1.Secret)
+	g.Expect(clusterCASecret.Name).To(Equal(secret.Name(cluster.Name, secret.ClusterCA)))
+	clusterCASecret.Annotations = map[string]string{secret.ExternalSignerURLAnnotation: server.URL + "/v1/pki/sign-verbatim/cluster-api"}
+	delete(clusterCASecret.Data, secret.TLSKeyDataName)
+	clusterCASecret.Data[secret.ExternalSignerTokenDataName] = []byte("token")
+	clusterCASecret.Data[secret.ExternalSignerCADataName] = certs.EncodeCertPEM(server.Certificate())
+
+	r := &KubeadmConfigReconciler{
+		Log:    log.Log,
+		Client: helpers.NewFakeClientWithScheme(setupScheme(), objs...),
+	}
+
+	filesByPath := func(files []bootstrapv1.File) map[string]string {
+		out := map[string]string{}
+		for _, f := range files {
+			out[f.Path] = f.Content
+		}
+		return out
+	}
+	expectKubeconfig := func(content, commonName string) {
+		config, err := clientcmd.Load([]byte(content))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(config.Clusters).To(HaveLen(1))
+		for _, c := range config.Clusters {
+			g.Expect(c.Server).To(Equal("https://api.example.com:6443"))
+			g.Expect(c.CertificateAuthorityData).To(Equal(clusterCA.KeyPair.Cert))
+		}
+		clientCert, err := certs.DecodeCertPEM(config.AuthInfos[commonName].ClientCertificateData)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(clientCert.Subject.CommonName).To(Equal(commonName))
+		g.Expect(clientCert.CheckSignatureFrom(caCert)).To(Succeed())
+	}
+
+	// Init control plane machine.
+	certificates := secret.NewCertificatesForInitialControlPlane(&kubeadmv1beta1.ClusterConfiguration{})
+	g.Expect(certificates.Lookup(ctx, r.Client, util.ObjectKey(cluster))).To(Succeed())
+	g.Expect(certificates.EnsureAllExist()).To(Succeed())
+	g.Expect(certificates.GetByPurpose(secret.ClusterCA).ExternalSigner).To(BeTrue())
+	g.Expect(certificates.GetByPurpose(secret.FrontProxyCA).ExternalSigner).To(BeFalse())
+
+	clusterConfig := &kubeadmv1beta1.ClusterConfiguration{
+		ControlPlaneEndpoint: "api.example.com:6443",
+		APIServer: kubeadmv1beta1.APIServer{
+			CertSANs: []string{"10.0.0.10", "api.internal"},
+		},
+	}
+	initConfig := &kubeadmv1beta1.InitConfiguration{
+		NodeRegistration: kubeadmv1beta1.NodeRegistrationOptions{Name: "control-plane-0"},
+	}
+	files, err := r.externalCAFiles(ctx, cluster, clusterConfig, initConfig, certificates)
+	g.Expect(err).NotTo(HaveOccurred())
+	contents := filesByPath(files)
+	g.Expect(contents).To(HaveLen(8))
+
+	apiServerCert, err := certs.DecodeCertPEM([]byte(contents["/etc/kubernetes/pki/apiserver.crt"]))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(apiServerCert.CheckSignatureFrom(caCert)).To(Succeed())
+	g.Expect(apiServerCert.DNSNames).To(ConsistOf("kubernetes", "kubernetes.default", "kubernetes.default.svc", "kubernetes.default.svc.cluster.local", "api.example.com", "api.internal"))
+	g.Expect(apiServerCert.IPAddresses).To(HaveLen(2))
+	g.Expect(apiServerCert.IPAddresses[0].Equal(net.ParseIP("10.96.0.1"))).To(BeTrue())
+	g.Expect(apiServerCert.IPAddresses[1].Equal(net.ParseIP("10.0.0.10"))).To(BeTrue())
+	g.Expect(contents).To(HaveKey("/etc/kubernetes/pki/apiserver.key"))
+	g.Expect(contents).To(HaveKey("/etc/kubernetes/pki/apiserver-kubelet-client.crt"))
+	g.Expect(contents).To(HaveKey("/etc/kubernetes/pki/apiserver-kubelet-client.key"))
+	g.Expect(contents).NotTo(HaveKey("/etc/kubernetes/pki/front-proxy-client.crt"))
+	expectKubeconfig(contents["/etc/kubernetes/admin.conf"], "kubernetes-admin")
+	expectKubeconfig(contents["/etc/kubernetes/controller-manager.conf"], "system:kube-controller-manager")
+	expectKubeconfig(contents["/etc/kubernetes/scheduler.conf"], "system:kube-scheduler")
+	expectKubeconfig(contents["/etc/kubernetes/kubelet.conf"], "system:node:control-plane-0")
+
+	// The kubelet kubeconfig of the init control plane machine requires a node name.
+	initConfig.NodeRegistration.Name = "{{ ds.meta_data.local_hostname }}"
+	_, err = r.externalCAFiles(ctx, cluster, clusterConfig, initConfig, certificates)
+	g.Expect(err).To(HaveOccurred())
+
+	// Joining control plane machine.
+	certificates = secret.NewCertificatesForJoiningControlPlane()
+	g.Expect(certificates.Lookup(ctx, r.Client, util.ObjectKey(cluster))).To(Succeed())
+	g.Expect(certificates.EnsureAllExist()).To(Succeed())
+	files, err = r.externalCAFiles(ctx, cluster, nil, nil, certificates)
+	g.Expect(err).NotTo(HaveOccurred())
+	contents = filesByPath(files)
+	g.Expect(contents).To(HaveLen(7))
+	g.Expect(contents).NotTo(HaveKey("/etc/kubernetes/kubelet.conf"))
+	expectKubeconfig(contents["/etc/kubernetes/admin.conf"], "kubernetes-admin")
+
+	// Nothing is issued when the CAs are stored in the management cluster.
+	certificates = secret.NewCertificatesForJoiningControlPlane()
+	g.Expect(r.externalCAFiles(ctx, cluster, nil, nil, certificates)).To(BeEmpty())
+}
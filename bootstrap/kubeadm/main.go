@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"math/rand"
 	"net/http"
@@ -35,8 +36,13 @@ import (
 	kubeadmbootstrapv1alpha3 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha3"
 	kubeadmbootstrapcontrollers "sigs.k8s.io/cluster-api/bootstrap/kubeadm/controllers"
 	"sigs.k8s.io/cluster-api/cmd/version"
+	"sigs.k8s.io/cluster-api/controllers/remote"
 	expv1alpha3 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/feature"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/cluster-api/util/tracing"
+	"sigs.k8s.io/cluster-api/util/tunnel"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -68,6 +74,11 @@ var (
 	leaderElectionRetryPeriod   time.Duration
 	watchNamespace              string
 	profilerAddress             string
+	tracingExporter             string
+	tracingOTLPEndpoint         string
+	tunnelBindAddress           string
+	tunnelCertFile              string
+	tunnelKeyFile               string
 	kubeadmConfigConcurrency    int
 	syncPeriod                  time.Duration
 	webhookPort                 int
@@ -95,6 +106,21 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&profilerAddress, "profiler-address", "",
 		"Bind address to expose the pprof profiler (e.g. localhost:6060)")
 
+	fs.StringVar(&tracingExporter, "tracing-exporter", tracing.ExporterNone,
+		"Exporter for the OpenTelemetry traces of the reconciles, one of 'stdout' or 'otlp'. If unspecified, tracing is disabled.")
+
+	fs.StringVar(&tracingOTLPEndpoint, "tracing-otlp-endpoint", tracing.DefaultOTLPEndpoint,
+		"The OTLP/HTTP endpoint traces are sent to when using the 'otlp' tracing exporter.")
+
+	fs.StringVar(&tunnelBindAddress, "tunnel-bind-address", "",
+		"The address the reverse tunnel server binds to, for reaching workload clusters through the tunnel agent running in them (e.g. :8443). If unspecified, the tunnel server is disabled.")
+
+	fs.StringVar(&tunnelCertFile, "tunnel-tls-cert-file", "",
+		"The TLS certificate presented by the reverse tunnel server to the tunnel agents.")
+
+	fs.StringVar(&tunnelKeyFile, "tunnel-tls-private-key-file", "",
+		"The TLS private key of the reverse tunnel server.")
+
 	fs.IntVar(&kubeadmConfigConcurrency, "kubeadmconfig-concurrency", 10,
 		"Number of kubeadm configs to process simultaneously")
 
@@ -126,6 +152,16 @@ func main() {
 		}()
 	}
 
+	shutdownTracing, err := tracing.Setup(tracing.Options{
+		ServiceName:  "capi-kubeadm-bootstrap-controller-manager",
+		Exporter:     tracingExporter,
+		OTLPEndpoint: tracingOTLPEndpoint,
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:             scheme,
 		MetricsBindAddress: metricsAddr,
@@ -146,6 +182,7 @@ func main() {
 
 	setupWebhooks(mgr)
 	setupReconcilers(mgr)
+	setupTunnel(mgr)
 
 	// +kubebuilder:scaffold:builder
 	setupLog.Info("starting manager", "version", version.Get().String())
@@ -153,6 +190,10 @@ func main() {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
+
+	if err := shutdownTracing(context.Background()); err != nil {
+		setupLog.Error(err, "problem flushing traces")
+	}
 }
 
 func setupReconcilers(mgr ctrl.Manager) {
@@ -160,6 +201,11 @@ func setupReconcilers(mgr ctrl.Manager) {
 		return
 	}
 
+	// Emit an Event for each condition transition of the objects reconciled by this manager, once patched.
+	conditions.AddChangeHandler(record.ConditionChangeHandler(
+		record.NewRateLimitedRecorder(mgr.GetEventRecorderFor("kubeadm-bootstrap-manager"), record.DefaultDuplicateEventsInterval),
+	))
+
 	if err := (&kubeadmbootstrapcontrollers.KubeadmConfigReconciler{
 		Client: mgr.GetClient(),
 		Log:    ctrl.Log.WithName("controllers").WithName("KubeadmConfig"),
@@ -169,6 +215,25 @@ func setupReconcilers(mgr ctrl.Manager) {
 	}
 }
 
+func setupTunnel(mgr ctrl.Manager) {
+	if webhookPort != 0 || tunnelBindAddress == "" {
+		return
+	}
+
+	server := &tunnel.Server{
+		Log:         ctrl.Log.WithName("tunnel"),
+		Client:      mgr.GetClient(),
+		BindAddress: tunnelBindAddress,
+		CertFile:    tunnelCertFile,
+		KeyFile:     tunnelKeyFile,
+	}
+	if err := mgr.Add(server); err != nil {
+		setupLog.Error(err, "unable to add tunnel server")
+		os.Exit(1)
+	}
+	remote.SetClusterDialer(server.DialContextFor)
+}
+
 func setupWebhooks(mgr ctrl.Manager) {
 	if webhookPort == 0 {
 		return
@@ -34,6 +34,10 @@ type Components repository.Components
 // ComponentsOptions wraps inputs to get provider's components
 type ComponentsOptions repository.ComponentsOptions
 
+// Variable defines a variable used in a YAML file, including info about required variables and default values.
+// NB. This is a type alias, so slices returned by the low-level libraries can be used without conversion.
+type Variable = repository.Variable
+
 // Template wraps a YAML file that defines the cluster objects (Cluster, Machines etc.).
 type Template repository.Template
 
@@ -42,3 +46,11 @@ type UpgradePlan cluster.UpgradePlan
 
 // Kubeconfig is a type that specifies inputs related to the actual kubeconfig.
 type Kubeconfig cluster.Kubeconfig
+
+// WorkloadClusterInfo summarizes the status of a workload cluster.
+// NB. This is a type alias, so slices returned by the low-level libraries can be used without conversion.
+type WorkloadClusterInfo = cluster.WorkloadClusterInfo
+
+// RolloutRevision describes a revision of a MachineDeployment.
+// NB. This is a type alias, so slices returned by the low-level libraries can be used without conversion.
+type RolloutRevision = cluster.RolloutRevision
@@ -0,0 +1,33 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"time"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+)
+
+// PruneCacheOptions carries the options supported by PruneCache.
+type PruneCacheOptions struct {
+	// OlderThan defines the minimum age of the cached files to be removed; if zero, all the cached files are removed.
+	OlderThan time.Duration
+}
+
+func (c *clusterctlClient) PruneCache(options PruneCacheOptions) error {
+	return repository.PruneCache(c.configClient.Variables(), options.OlderThan)
+}
@@ -40,6 +40,9 @@ type Client interface {
 	// GetClusterTemplate returns a workload cluster template.
 	GetClusterTemplate(options GetClusterTemplateOptions) (Template, error)
 
+	// ProcessYAML processes an arbitrary template, e.g. the manifest of an addon, replacing the variables it contains.
+	ProcessYAML(options ProcessYAMLOptions) (Template, error)
+
 	// Delete deletes providers from a management cluster.
 	Delete(options DeleteOptions) error
 
@@ -55,6 +58,30 @@ type Client interface {
 
 	// ApplyUpgrade executes an upgrade plan.
 	ApplyUpgrade(options ApplyUpgradeOptions) error
+
+	// GetKubeconfig returns the kubeconfig of a workload cluster.
+	GetKubeconfig(options GetKubeconfigOptions) (string, error)
+
+	// GetClusters returns the workload clusters hosted in a management cluster.
+	GetClusters(options GetClustersOptions) ([]WorkloadClusterInfo, error)
+
+	// RolloutRestart triggers a rollout of the Machines of MachineDeployments or KubeadmControlPlanes.
+	RolloutRestart(options RolloutOptions) error
+
+	// RolloutPause pauses the rollout of MachineDeployments or KubeadmControlPlanes.
+	RolloutPause(options RolloutOptions) error
+
+	// RolloutResume resumes the paused rollout of MachineDeployments or KubeadmControlPlanes.
+	RolloutResume(options RolloutOptions) error
+
+	// RolloutUndo rolls back MachineDeployments to a previous revision.
+	RolloutUndo(options RolloutUndoOptions) error
+
+	// RolloutHistory returns the revisions of a MachineDeployment.
+	RolloutHistory(options RolloutOptions) ([]RolloutRevision, error)
+
+	// PruneCache removes files from the local cache of the provider repositories.
+	PruneCache(options PruneCacheOptions) error
 }
 
 // clusterctlClient implements Client.
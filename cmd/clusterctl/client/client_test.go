@@ -80,6 +80,10 @@ func (f fakeClient) GetClusterTemplate(options GetClusterTemplateOptions) (Templ
 	return f.internalClient.GetClusterTemplate(options)
 }
 
+func (f fakeClient) ProcessYAML(options ProcessYAMLOptions) (Template, error) {
+	return f.internalClient.ProcessYAML(options)
+}
+
 func (f fakeClient) Init(options InitOptions) ([]Components, error) {
 	return f.internalClient.Init(options)
 }
@@ -104,6 +108,38 @@ func (f fakeClient) ApplyUpgrade(options ApplyUpgradeOptions) error {
 	return f.internalClient.ApplyUpgrade(options)
 }
 
+func (f fakeClient) GetKubeconfig(options GetKubeconfigOptions) (string, error) {
+	return f.internalClient.GetKubeconfig(options)
+}
+
+func (f fakeClient) GetClusters(options GetClustersOptions) ([]WorkloadClusterInfo, error) {
+	return f.internalClient.GetClusters(options)
+}
+
+func (f fakeClient) RolloutRestart(options RolloutOptions) error {
+	return f.internalClient.RolloutRestart(options)
+}
+
+func (f fakeClient) RolloutPause(options RolloutOptions) error {
+	return f.internalClient.RolloutPause(options)
+}
+
+func (f fakeClient) RolloutResume(options RolloutOptions) error {
+	return f.internalClient.RolloutResume(options)
+}
+
+func (f fakeClient) RolloutUndo(options RolloutUndoOptions) error {
+	return f.internalClient.RolloutUndo(options)
+}
+
+func (f fakeClient) RolloutHistory(options RolloutOptions) ([]RolloutRevision, error) {
+	return f.internalClient.RolloutHistory(options)
+}
+
+func (f fakeClient) PruneCache(options PruneCacheOptions) error {
+	return f.internalClient.PruneCache(options)
+}
+
 // newFakeClient returns a clusterctl client that allows to execute tests on a set of fake config, fake repositories and fake clusters.
 // you can use WithCluster and WithRepository to prepare for the test case.
 func newFakeClient(configClient config.Client) *fakeClient {
@@ -247,6 +283,18 @@ func (f *fakeClusterClient) Template() cluster.TemplateClient {
 	return f.internalclient.Template()
 }
 
+func (f *fakeClusterClient) TemplateValidator() cluster.TemplateValidator {
+	return f.internalclient.TemplateValidator()
+}
+
+func (f *fakeClusterClient) WorkloadCluster() cluster.WorkloadCluster {
+	return f.internalclient.WorkloadCluster()
+}
+
+func (f *fakeClusterClient) Rollout() cluster.RolloutClient {
+	return f.internalclient.Rollout()
+}
+
 func (f *fakeClusterClient) WithObjs(objs ...runtime.Object) *fakeClusterClient {
 	f.fakeProxy.WithObjs(objs...)
 	return f
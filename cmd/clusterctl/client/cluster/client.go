@@ -85,6 +85,18 @@ type Client interface {
 
 	// Template has methods to work with templates stored in the cluster.
 	Template() TemplateClient
+
+	// TemplateValidator returns a TemplateValidator that checks workload cluster templates against the providers
+	// installed in the management cluster.
+	TemplateValidator() TemplateValidator
+
+	// WorkloadCluster has methods for getting information about the workload clusters hosted in the management cluster,
+	// e.g. their kubeconfig.
+	WorkloadCluster() WorkloadCluster
+
+	// Rollout returns a RolloutClient that supports managing the rollout of the Machines of a MachineDeployment
+	// or of a KubeadmControlPlane.
+	Rollout() RolloutClient
 }
 
 // PollImmediateWaiter tries a condition func until it returns true, an error, or the timeout is reached.
@@ -140,6 +152,18 @@ func (c *clusterClient) Template() TemplateClient {
 	return newTemplateClient(c.proxy, c.configClient)
 }
 
+func (c *clusterClient) TemplateValidator() TemplateValidator {
+	return newTemplateValidator(c.proxy, c.ProviderInventory())
+}
+
+func (c *clusterClient) WorkloadCluster() WorkloadCluster {
+	return newWorkloadCluster(c.proxy)
+}
+
+func (c *clusterClient) Rollout() RolloutClient {
+	return newRolloutClient(c.proxy)
+}
+
 // Option is a configuration option supplied to New
 type Option func(*clusterClient)
 
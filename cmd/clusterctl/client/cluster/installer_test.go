@@ -231,6 +231,10 @@ func (c *fakeComponents) Variables() []string {
 	panic("not implemented")
 }
 
+func (c *fakeComponents) VariableDetails() []repository.Variable {
+	panic("not implemented")
+}
+
 func (c *fakeComponents) Images() []string {
 	panic("not implemented")
 }
@@ -0,0 +1,407 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// MachineDeploymentKind is the kind of the MachineDeployment objects supported by rollout.
+	MachineDeploymentKind = "MachineDeployment"
+
+	// KubeadmControlPlaneKind is the kind of the KubeadmControlPlane objects supported by rollout.
+	KubeadmControlPlaneKind = "KubeadmControlPlane"
+
+	// RestartedAtAnnotation is set on the machine template of a MachineDeployment for triggering a rollout.
+	RestartedAtAnnotation = "cluster.x-k8s.io/restartedAt"
+)
+
+// RolloutRevision describes a revision of a MachineDeployment, i.e. one of its MachineSets.
+type RolloutRevision struct {
+	// Revision number of the MachineSet.
+	Revision int64
+
+	// MachineSet is the name of the MachineSet.
+	MachineSet string
+
+	// CreationTimestamp of the MachineSet.
+	CreationTimestamp metav1.Time
+
+	// Changes summarizes the differences between the machine template of the revision and the one of
+	// the previous revision.
+	Changes []string
+}
+
+// RolloutClient has methods for managing the rollout of the Machines of a MachineDeployment or of a KubeadmControlPlane.
+type RolloutClient interface {
+	// Restart triggers a rollout of all the Machines.
+	// For a MachineDeployment, the RestartedAtAnnotation is set on the machine template; for a KubeadmControlPlane
+	// the UpgradeAfter field is set to now.
+	Restart(ref corev1.ObjectReference) error
+
+	// Pause pauses the rollout. For a MachineDeployment the Paused field is set, for a KubeadmControlPlane the
+	// cluster.x-k8s.io/paused annotation is set.
+	Pause(ref corev1.ObjectReference) error
+
+	// Resume resumes a paused rollout.
+	Resume(ref corev1.ObjectReference) error
+
+	// Undo rolls back a MachineDeployment to the machine template of a previous revision; if toRevision is zero,
+	// the revision before the current one is used.
+	Undo(ref corev1.ObjectReference, toRevision int64) error
+
+	// History returns the revisions of a MachineDeployment, sorted by revision number.
+	History(ref corev1.ObjectReference) ([]RolloutRevision, error)
+}
+
+// rolloutClient implements RolloutClient.
+type rolloutClient struct {
+	proxy Proxy
+}
+
+// ensure rolloutClient implements RolloutClient.
+var _ RolloutClient = &rolloutClient{}
+
+// newRolloutClient returns a rolloutClient.
+func newRolloutClient(proxy Proxy) *rolloutClient {
+	return &rolloutClient{
+		proxy: proxy,
+	}
+}
+
+func (r *rolloutClient) Restart(ref corev1.ObjectReference) error {
+	log := logf.Log
+	log.Info("Restarting", "Kind", ref.Kind, "Namespace", ref.Namespace, "Name", ref.Name)
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	switch ref.Kind {
+	case MachineDeploymentKind:
+		return r.patchMachineDeployment(ref, func(md *clusterv1.MachineDeployment) error {
+			if md.Spec.Paused {
+				return errors.Errorf("can't restart paused MachineDeployment %s/%s (run rollout resume first)", ref.Namespace, ref.Name)
+			}
+			if md.Spec.Template.Annotations == nil {
+				md.Spec.Template.Annotations = map[string]string{}
+			}
+			md.Spec.Template.Annotations[RestartedAtAnnotation] = now
+			return nil
+		})
+	case KubeadmControlPlaneKind:
+		return r.patchKubeadmControlPlane(ref, fmt.Sprintf("{\"spec\":{\"upgradeAfter\":%q}}", now))
+	}
+	return errors.Errorf("rollout restart is not supported for %s", ref.Kind)
+}
+
+func (r *rolloutClient) Pause(ref corev1.ObjectReference) error {
+	return r.setPaused(ref, true)
+}
+
+func (r *rolloutClient) Resume(ref corev1.ObjectReference) error {
+	return r.setPaused(ref, false)
+}
+
+// setPaused sets or removes the pause on a MachineDeployment or on a KubeadmControlPlane.
+func (r *rolloutClient) setPaused(ref corev1.ObjectReference, value bool) error {
+	log := logf.Log
+	log.Info("Setting pause", "Paused", value, "Kind", ref.Kind, "Namespace", ref.Namespace, "Name", ref.Name)
+
+	switch ref.Kind {
+	case MachineDeploymentKind:
+		c, err := r.proxy.NewClient()
+		if err != nil {
+			return err
+		}
+
+		md := &clusterv1.MachineDeployment{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, md); err != nil {
+			return errors.Wrapf(err, "failed to get MachineDeployment %s/%s", ref.Namespace, ref.Name)
+		}
+		if md.Spec.Paused == value {
+			return errors.Errorf("MachineDeployment %s/%s is already %s", ref.Namespace, ref.Name, pausedString(value))
+		}
+
+		// Nb. The paused field is set explicitly, so it is not dropped when false.
+		patch := client.RawPatch(types.MergePatchType, []byte(fmt.Sprintf("{\"spec\":{\"paused\":%t}}", value)))
+		if err := c.Patch(ctx, md, patch); err != nil {
+			return errors.Wrapf(err, "failed to patch MachineDeployment %s/%s", ref.Namespace, ref.Name)
+		}
+		return nil
+	case KubeadmControlPlaneKind:
+		// Nb. A null value removes the annotation.
+		annotation := "null"
+		if value {
+			annotation = "\"true\""
+		}
+		return r.patchKubeadmControlPlane(ref, fmt.Sprintf("{\"metadata\":{\"annotations\":{%q:%s}}}", clusterv1.PausedAnnotation, annotation))
+	}
+	return errors.Errorf("rollout pause and resume are not supported for %s", ref.Kind)
+}
+
+func (r *rolloutClient) Undo(ref corev1.ObjectReference, toRevision int64) error {
+	log := logf.Log
+
+	if ref.Kind != MachineDeploymentKind {
+		return errors.Errorf("rollout undo is not supported for %s", ref.Kind)
+	}
+
+	return r.patchMachineDeployment(ref, func(md *clusterv1.MachineDeployment) error {
+		if md.Spec.Paused {
+			return errors.Errorf("can't undo paused MachineDeployment %s/%s (run rollout resume first)", ref.Namespace, ref.Name)
+		}
+
+		machineSets, err := r.getMachineSets(md)
+		if err != nil {
+			return err
+		}
+
+		ms, err := findMachineSetForRevision(md, machineSets, toRevision)
+		if err != nil {
+			return err
+		}
+		log.Info("Rolling back", "Kind", ref.Kind, "Namespace", ref.Namespace, "Name", ref.Name, "MachineSet", ms.Name)
+
+		template := ms.Spec.Template.DeepCopy()
+		delete(template.Labels, mdutil.DefaultMachineDeploymentUniqueLabelKey)
+		md.Spec.Template = *template
+		return nil
+	})
+}
+
+func (r *rolloutClient) History(ref corev1.ObjectReference) ([]RolloutRevision, error) {
+	if ref.Kind != MachineDeploymentKind {
+		return nil, errors.Errorf("rollout history is not supported for %s", ref.Kind)
+	}
+
+	c, err := r.proxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	md := &clusterv1.MachineDeployment{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, md); err != nil {
+		return nil, errors.Wrapf(err, "failed to get MachineDeployment %s/%s", ref.Namespace, ref.Name)
+	}
+
+	machineSets, err := r.getMachineSets(md)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]RolloutRevision, 0, len(machineSets))
+	var previous *clusterv1.MachineSet
+	for _, ms := range machineSets {
+		revision, err := mdutil.Revision(ms)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the revision of MachineSet %s/%s", ms.Namespace, ms.Name)
+		}
+		changes := []string{"initial revision"}
+		if previous != nil {
+			changes = diffMachineTemplates(&previous.Spec.Template, &ms.Spec.Template)
+		}
+		ret = append(ret, RolloutRevision{
+			Revision:          revision,
+			MachineSet:        ms.Name,
+			CreationTimestamp: ms.CreationTimestamp,
+			Changes:           changes,
+		})
+		previous = ms
+	}
+	return ret, nil
+}
+
+// patchMachineDeployment gets a MachineDeployment, applies the mutate func and patches the changes.
+func (r *rolloutClient) patchMachineDeployment(ref corev1.ObjectReference, mutate func(md *clusterv1.MachineDeployment) error) error {
+	c, err := r.proxy.NewClient()
+	if err != nil {
+		return err
+	}
+
+	md := &clusterv1.MachineDeployment{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, md); err != nil {
+		return errors.Wrapf(err, "failed to get MachineDeployment %s/%s", ref.Namespace, ref.Name)
+	}
+
+	patch := client.MergeFrom(md.DeepCopy())
+	if err := mutate(md); err != nil {
+		return err
+	}
+
+	if err := c.Patch(ctx, md, patch); err != nil {
+		return errors.Wrapf(err, "failed to patch MachineDeployment %s/%s", ref.Namespace, ref.Name)
+	}
+	return nil
+}
+
+// patchKubeadmControlPlane applies a merge patch to a KubeadmControlPlane.
+// Nb. The KubeadmControlPlane is read as unstructured, so clusterctl is not bound to a specific version of the provider.
+func (r *rolloutClient) patchKubeadmControlPlane(ref corev1.ObjectReference, patch string) error {
+	c, err := r.proxy.NewClient()
+	if err != nil {
+		return err
+	}
+
+	kcp := &unstructured.Unstructured{}
+	kcp.SetAPIVersion(controlplanev1.GroupVersion.String())
+	kcp.SetKind(KubeadmControlPlaneKind)
+	if err := c.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, kcp); err != nil {
+		return errors.Wrapf(err, "failed to get KubeadmControlPlane %s/%s", ref.Namespace, ref.Name)
+	}
+
+	if err := c.Patch(ctx, kcp, client.RawPatch(types.MergePatchType, []byte(patch))); err != nil {
+		return errors.Wrapf(err, "failed to patch KubeadmControlPlane %s/%s", ref.Namespace, ref.Name)
+	}
+	return nil
+}
+
+// getMachineSets returns the MachineSets controlled by a MachineDeployment, sorted by revision.
+func (r *rolloutClient) getMachineSets(md *clusterv1.MachineDeployment) ([]*clusterv1.MachineSet, error) {
+	c, err := r.proxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	machineSetList := &clusterv1.MachineSetList{}
+	if err := c.List(ctx, machineSetList, client.InNamespace(md.Namespace), client.MatchingLabels{clusterv1.ClusterLabelName: md.Spec.ClusterName}); err != nil {
+		return nil, errors.Wrapf(err, "failed to list MachineSets for MachineDeployment %s/%s", md.Namespace, md.Name)
+	}
+
+	machineSets := []*clusterv1.MachineSet{}
+	for i := range machineSetList.Items {
+		ms := &machineSetList.Items[i]
+		if metav1.IsControlledBy(ms, md) {
+			machineSets = append(machineSets, ms)
+		}
+	}
+
+	sort.SliceStable(machineSets, func(i, j int) bool {
+		// Nb. Revision errors are ignored here, MachineSets with an invalid revision are sorted first.
+		ri, _ := mdutil.Revision(machineSets[i])
+		rj, _ := mdutil.Revision(machineSets[j])
+		return ri < rj
+	})
+	return machineSets, nil
+}
+
+// findMachineSetForRevision returns the MachineSet with the given revision; if toRevision is zero, the MachineSet
+// with the highest revision before the current one is returned.
+func findMachineSetForRevision(md *clusterv1.MachineDeployment, machineSets []*clusterv1.MachineSet, toRevision int64) (*clusterv1.MachineSet, error) {
+	currentRevision, err := mdutil.Revision(md)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the revision of MachineDeployment %s/%s", md.Namespace, md.Name)
+	}
+
+	// If the MachineDeployment has no revision yet, the MachineSet with the highest revision is the current one.
+	if currentRevision == 0 && len(machineSets) > 0 {
+		if currentRevision, err = mdutil.Revision(machineSets[len(machineSets)-1]); err != nil {
+			return nil, errors.Wrapf(err, "failed to get the revision of MachineSet %s/%s", machineSets[len(machineSets)-1].Namespace, machineSets[len(machineSets)-1].Name)
+		}
+	}
+
+	var found *clusterv1.MachineSet
+	for _, ms := range machineSets {
+		revision, err := mdutil.Revision(ms)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the revision of MachineSet %s/%s", ms.Namespace, ms.Name)
+		}
+		// Nb. MachineSets are sorted by revision, so the last one before the current revision is the previous one.
+		if (toRevision == 0 && revision < currentRevision) || (toRevision != 0 && revision == toRevision) {
+			found = ms
+		}
+	}
+
+	if found == nil {
+		if toRevision == 0 {
+			return nil, errors.Errorf("no previous revision found for MachineDeployment %s/%s", md.Namespace, md.Name)
+		}
+		return nil, errors.Errorf("revision %d not found for MachineDeployment %s/%s", toRevision, md.Namespace, md.Name)
+	}
+	return found, nil
+}
+
+// diffMachineTemplates summarizes the differences between two machine templates.
+func diffMachineTemplates(from, to *clusterv1.MachineTemplateSpec) []string {
+	from = from.DeepCopy()
+	to = to.DeepCopy()
+	delete(from.Labels, mdutil.DefaultMachineDeploymentUniqueLabelKey)
+	delete(to.Labels, mdutil.DefaultMachineDeploymentUniqueLabelKey)
+
+	changes := []string{}
+	if v1, v2 := stringValue(from.Spec.Version), stringValue(to.Spec.Version); v1 != v2 {
+		changes = append(changes, fmt.Sprintf("version %s -> %s", v1, v2))
+	}
+	if ref1, ref2 := from.Spec.InfrastructureRef, to.Spec.InfrastructureRef; ref1.Kind != ref2.Kind || ref1.Name != ref2.Name {
+		changes = append(changes, fmt.Sprintf("infrastructureRef %s/%s -> %s/%s", ref1.Kind, ref1.Name, ref2.Kind, ref2.Name))
+	}
+	if b1, b2 := bootstrapString(from.Spec.Bootstrap), bootstrapString(to.Spec.Bootstrap); b1 != b2 {
+		changes = append(changes, fmt.Sprintf("bootstrap %s -> %s", b1, b2))
+	}
+	if f1, f2 := stringValue(from.Spec.FailureDomain), stringValue(to.Spec.FailureDomain); f1 != f2 {
+		changes = append(changes, fmt.Sprintf("failureDomain %s -> %s", f1, f2))
+	}
+	if r1, r2 := from.Annotations[RestartedAtAnnotation], to.Annotations[RestartedAtAnnotation]; r1 != r2 {
+		changes = append(changes, fmt.Sprintf("restarted at %s", r2))
+	}
+	delete(from.Annotations, RestartedAtAnnotation)
+	delete(to.Annotations, RestartedAtAnnotation)
+	if !apiequality.Semantic.DeepEqual(from.ObjectMeta, to.ObjectMeta) {
+		changes = append(changes, "labels or annotations")
+	}
+
+	if len(changes) == 0 && !apiequality.Semantic.DeepEqual(from.Spec, to.Spec) {
+		changes = append(changes, "machine spec")
+	}
+	return changes
+}
+
+func bootstrapString(bootstrap clusterv1.Bootstrap) string {
+	if bootstrap.ConfigRef != nil {
+		return fmt.Sprintf("%s/%s", bootstrap.ConfigRef.Kind, bootstrap.ConfigRef.Name)
+	}
+	if bootstrap.DataSecretName != nil {
+		return fmt.Sprintf("Secret/%s", *bootstrap.DataSecretName)
+	}
+	return "<none>"
+}
+
+func stringValue(s *string) string {
+	if s == nil || *s == "" {
+		return "<none>"
+	}
+	return *s
+}
+
+func pausedString(paused bool) string {
+	if paused {
+		return "paused"
+	}
+	return "not paused"
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func fakeMachineDeployment(revision, version string) *clusterv1.MachineDeployment {
+	return &clusterv1.MachineDeployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "MachineDeployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns1",
+			Name:        "md",
+			UID:         types.UID("md-uid"),
+			Annotations: map[string]string{clusterv1.RevisionAnnotation: revision},
+		},
+		Spec: clusterv1.MachineDeploymentSpec{
+			ClusterName: "cluster",
+			Template:    fakeMachineTemplate(version, "infra-"+version, nil),
+		},
+	}
+}
+
+func fakeMachineTemplate(version, infraName string, labels map[string]string) clusterv1.MachineTemplateSpec {
+	return clusterv1.MachineTemplateSpec{
+		ObjectMeta: clusterv1.ObjectMeta{
+			Labels: labels,
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: "cluster",
+			Version:     &version,
+			InfrastructureRef: corev1.ObjectReference{
+				Kind: "InfrastructureMachineTemplate",
+				Name: infraName,
+			},
+		},
+	}
+}
+
+func fakeMachineDeploymentMachineSet(md *clusterv1.MachineDeployment, name, revision, version string) *clusterv1.MachineSet {
+	return &clusterv1.MachineSet{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "MachineSet",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "ns1",
+			Name:            name,
+			Labels:          map[string]string{clusterv1.ClusterLabelName: "cluster"},
+			Annotations:     map[string]string{clusterv1.RevisionAnnotation: revision},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(md, md.GroupVersionKind())},
+		},
+		Spec: clusterv1.MachineSetSpec{
+			ClusterName: "cluster",
+			Template:    fakeMachineTemplate(version, "infra-"+version, map[string]string{mdutil.DefaultMachineDeploymentUniqueLabelKey: name}),
+		},
+	}
+}
+
+func fakeKubeadmControlPlane() *unstructured.Unstructured {
+	kcp := &unstructured.Unstructured{}
+	kcp.SetAPIVersion(controlplanev1.GroupVersion.String())
+	kcp.SetKind("KubeadmControlPlane")
+	kcp.SetNamespace("ns1")
+	kcp.SetName("kcp")
+	return kcp
+}
+
+var (
+	mdRef  = corev1.ObjectReference{Kind: MachineDeploymentKind, Namespace: "ns1", Name: "md"}
+	kcpRef = corev1.ObjectReference{Kind: KubeadmControlPlaneKind, Namespace: "ns1", Name: "kcp"}
+)
+
+func newRolloutTestClient(objs ...runtime.Object) (*rolloutClient, client.Client) {
+	proxy := test.NewFakeProxy().WithObjs(objs...)
+	c, _ := proxy.NewClient()
+	return newRolloutClient(proxy), c
+}
+
+func Test_rolloutClient_Restart(t *testing.T) {
+	g := NewWithT(t)
+
+	r, c := newRolloutTestClient(fakeMachineDeployment("1", "v1.17.3"), fakeKubeadmControlPlane())
+
+	g.Expect(r.Restart(mdRef)).To(Succeed())
+	md := &clusterv1.MachineDeployment{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "md"}, md)).To(Succeed())
+	g.Expect(md.Spec.Template.Annotations).To(HaveKey(RestartedAtAnnotation))
+
+	g.Expect(r.Restart(kcpRef)).To(Succeed())
+	kcp := fakeKubeadmControlPlane()
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "kcp"}, kcp)).To(Succeed())
+	upgradeAfter, found, err := unstructured.NestedString(kcp.Object, "spec", "upgradeAfter")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(found).To(BeTrue())
+	g.Expect(upgradeAfter).NotTo(BeEmpty())
+
+	g.Expect(r.Restart(corev1.ObjectReference{Kind: "MachineSet", Namespace: "ns1", Name: "ms"})).NotTo(Succeed())
+	g.Expect(r.Restart(corev1.ObjectReference{Kind: MachineDeploymentKind, Namespace: "ns1", Name: "does-not-exist"})).NotTo(Succeed())
+}
+
+func Test_rolloutClient_PauseResume(t *testing.T) {
+	g := NewWithT(t)
+
+	r, c := newRolloutTestClient(fakeMachineDeployment("1", "v1.17.3"), fakeKubeadmControlPlane())
+
+	md := &clusterv1.MachineDeployment{}
+	g.Expect(r.Resume(mdRef)).NotTo(Succeed())
+	g.Expect(r.Pause(mdRef)).To(Succeed())
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "md"}, md)).To(Succeed())
+	g.Expect(md.Spec.Paused).To(BeTrue())
+	g.Expect(r.Pause(mdRef)).NotTo(Succeed())
+	g.Expect(r.Restart(mdRef)).NotTo(Succeed())
+	g.Expect(r.Undo(mdRef, 0)).NotTo(Succeed())
+	g.Expect(r.Resume(mdRef)).To(Succeed())
+	md = &clusterv1.MachineDeployment{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "md"}, md)).To(Succeed())
+	g.Expect(md.Spec.Paused).To(BeFalse())
+
+	kcp := fakeKubeadmControlPlane()
+	g.Expect(r.Pause(kcpRef)).To(Succeed())
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "kcp"}, kcp)).To(Succeed())
+	g.Expect(kcp.GetAnnotations()).To(HaveKeyWithValue(clusterv1.PausedAnnotation, "true"))
+	g.Expect(r.Resume(kcpRef)).To(Succeed())
+	kcp = fakeKubeadmControlPlane()
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "kcp"}, kcp)).To(Succeed())
+	g.Expect(kcp.GetAnnotations()).NotTo(HaveKey(clusterv1.PausedAnnotation))
+}
+
+func Test_rolloutClient_UndoAndHistory(t *testing.T) {
+	md := fakeMachineDeployment("3", "v1.18.0")
+	objs := []runtime.Object{
+		md,
+		fakeMachineDeploymentMachineSet(md, "md-3", "3", "v1.18.0"),
+		fakeMachineDeploymentMachineSet(md, "md-1", "1", "v1.16.2"),
+		fakeMachineDeploymentMachineSet(md, "md-2", "2", "v1.17.3"),
+	}
+	other := fakeMachineDeploymentMachineSet(md, "other", "4", "v1.19.0")
+	other.OwnerReferences = nil
+	objs = append(objs, other)
+
+	t.Run("history returns the revisions of the MachineDeployment", func(t *testing.T) {
+		g := NewWithT(t)
+
+		r, _ := newRolloutTestClient(objs...)
+		history, err := r.History(mdRef)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(history).To(HaveLen(3))
+		g.Expect(history[0].Revision).To(Equal(int64(1)))
+		g.Expect(history[0].MachineSet).To(Equal("md-1"))
+		g.Expect(history[0].Changes).To(ConsistOf("initial revision"))
+		g.Expect(history[2].Revision).To(Equal(int64(3)))
+		g.Expect(history[2].Changes).To(ConsistOf(
+			"version v1.17.3 -> v1.18.0",
+			"infrastructureRef InfrastructureMachineTemplate/infra-v1.17.3 -> InfrastructureMachineTemplate/infra-v1.18.0",
+		))
+
+		_, err = r.History(kcpRef)
+		g.Expect(err).To(HaveOccurred())
+	})
+
+	tests := []struct {
+		name        string
+		toRevision  int64
+		wantVersion string
+		wantErr     bool
+	}{
+		{
+			name:        "undo to the previous revision",
+			toRevision:  0,
+			wantVersion: "v1.17.3",
+		},
+		{
+			name:        "undo to a given revision",
+			toRevision:  1,
+			wantVersion: "v1.16.2",
+		},
+		{
+			name:       "undo to a revision that does not exist",
+			toRevision: 4,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			r, c := newRolloutTestClient(objs...)
+			err := r.Undo(mdRef, tt.toRevision)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			got := &clusterv1.MachineDeployment{}
+			g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "md"}, got)).To(Succeed())
+			g.Expect(*got.Spec.Template.Spec.Version).To(Equal(tt.wantVersion))
+			g.Expect(got.Spec.Template.Spec.InfrastructureRef.Name).To(Equal("infra-" + tt.wantVersion))
+			g.Expect(got.Spec.Template.Labels).NotTo(HaveKey(mdutil.DefaultMachineDeploymentUniqueLabelKey))
+		})
+	}
+}
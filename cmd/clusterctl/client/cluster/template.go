@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"strings"
 
 	"github.com/google/go-github/github"
@@ -37,10 +38,14 @@ import (
 // TemplateClient has methods to work with templates stored in the cluster/out of the provider repository.
 type TemplateClient interface {
 	// GetFromConfigMap returns a workload cluster template from the given ConfigMap.
-	GetFromConfigMap(namespace, name, dataKey, targetNamespace string, listVariablesOnly bool) (repository.Template, error)
+	// If one or more flavors are specified, the corresponding overlays are read from the <dataKey>-overlay-<flavor> data keys
+	// and applied on top of the template.
+	GetFromConfigMap(namespace, name, dataKey, flavor, targetNamespace string, listVariablesOnly bool) (repository.Template, error)
 
 	// GetFromURL returns a workload cluster template from the given URL.
-	GetFromURL(templateURL, targetNamespace string, listVariablesOnly bool) (repository.Template, error)
+	// If one or more flavors are specified, the corresponding overlays are read from the <template>-overlay-<flavor>.yaml
+	// files located next to the template and applied on top of it.
+	GetFromURL(templateURL, flavor, targetNamespace string, listVariablesOnly bool) (repository.Template, error)
 }
 
 // templateClient implements TemplateClient.
@@ -62,7 +67,7 @@ func newTemplateClient(proxy Proxy, configClient config.Client) *templateClient
 	}
 }
 
-func (t *templateClient) GetFromConfigMap(configMapNamespace, configMapName, configMapDataKey, targetNamespace string, listVariablesOnly bool) (repository.Template, error) {
+func (t *templateClient) GetFromConfigMap(configMapNamespace, configMapName, configMapDataKey, flavor, targetNamespace string, listVariablesOnly bool) (repository.Template, error) {
 	if configMapNamespace == "" {
 		return nil, errors.New("invalid GetFromConfigMap operation: missing configMapNamespace value")
 	}
@@ -90,10 +95,20 @@ func (t *templateClient) GetFromConfigMap(configMapNamespace, configMapName, con
 		return nil, errors.Errorf("the ConfigMap %s/%s does not have the %q data key", configMapNamespace, configMapName, configMapDataKey)
 	}
 
-	return repository.NewTemplate([]byte(data), t.configClient.Variables(), targetNamespace, listVariablesOnly)
+	var overlays []repository.TemplateOverlay
+	for _, f := range repository.ParseFlavors(flavor) {
+		overlayKey := repository.OverlayName(configMapDataKey, f)
+		overlay, ok := configMap.Data[overlayKey]
+		if !ok {
+			return nil, errors.Errorf("the ConfigMap %s/%s does not have the %q data key for flavor %q", configMapNamespace, configMapName, overlayKey, f)
+		}
+		overlays = append(overlays, repository.TemplateOverlay{Flavor: f, RawYaml: []byte(overlay)})
+	}
+
+	return repository.NewTemplateWithOverlays([]byte(data), overlays, t.configClient.Variables(), targetNamespace, listVariablesOnly)
 }
 
-func (t *templateClient) GetFromURL(templateURL, targetNamespace string, listVariablesOnly bool) (repository.Template, error) {
+func (t *templateClient) GetFromURL(templateURL, flavor, targetNamespace string, listVariablesOnly bool) (repository.Template, error) {
 	if templateURL == "" {
 		return nil, errors.New("invalid GetFromURL operation: missing templateURL value")
 	}
@@ -103,7 +118,31 @@ func (t *templateClient) GetFromURL(templateURL, targetNamespace string, listVar
 		return nil, errors.Wrapf(err, "invalid GetFromURL operation")
 	}
 
-	return repository.NewTemplate(content, t.configClient.Variables(), targetNamespace, listVariablesOnly)
+	var overlays []repository.TemplateOverlay
+	for _, f := range repository.ParseFlavors(flavor) {
+		overlayURL, err := overlayURL(templateURL, f)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid GetFromURL operation")
+		}
+		overlay, err := t.getURLContent(overlayURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid GetFromURL operation: failed to read the overlay for flavor %q", f)
+		}
+		overlays = append(overlays, repository.TemplateOverlay{Flavor: f, RawYaml: overlay})
+	}
+
+	return repository.NewTemplateWithOverlays(content, overlays, t.configClient.Variables(), targetNamespace, listVariablesOnly)
+}
+
+// overlayURL returns the URL of the overlay implementing a flavor for the template at the given URL;
+// overlays are expected to be located next to the template, e.g. cluster-template-overlay-<flavor>.yaml for cluster-template.yaml.
+func overlayURL(templateURL, flavor string) (string, error) {
+	rURL, err := url.Parse(templateURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse %q", templateURL)
+	}
+	rURL.Path = path.Join(path.Dir(rURL.Path), repository.OverlayName(path.Base(rURL.Path), flavor))
+	return rURL.String(), nil
 }
 
 func (t *templateClient) getURLContent(templateURL string) ([]byte, error) {
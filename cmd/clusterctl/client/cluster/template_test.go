@@ -42,6 +42,11 @@ var template = "apiVersion: cluster.x-k8s.io/v1alpha3\n" +
 	"apiVersion: cluster.x-k8s.io/v1alpha3\n" +
 	"kind: Machine"
 
+var templateOverlay = "apiVersion: v1\n" +
+	"kind: ConfigMap\n" +
+	"metadata:\n" +
+	"  name: extra"
+
 func Test_templateClient_GetFromConfigMap(t *testing.T) {
 	g := NewWithT(t)
 
@@ -58,7 +63,8 @@ func Test_templateClient_GetFromConfigMap(t *testing.T) {
 			Name:      "my-template",
 		},
 		Data: map[string]string{
-			"prod": template,
+			"prod":            template,
+			"prod-overlay-ha": templateOverlay,
 		},
 	}
 
@@ -70,6 +76,7 @@ func Test_templateClient_GetFromConfigMap(t *testing.T) {
 		configMapNamespace string
 		configMapName      string
 		configMapDataKey   string
+		flavor             string
 		targetNamespace    string
 		listVariablesOnly  bool
 	}
@@ -96,6 +103,40 @@ func Test_templateClient_GetFromConfigMap(t *testing.T) {
 			want:    template,
 			wantErr: false,
 		},
+		{
+			name: "Return template with overlays",
+			fields: fields{
+				proxy:        test.NewFakeProxy().WithObjs(configMap),
+				configClient: configClient,
+			},
+			args: args{
+				configMapNamespace: "ns1",
+				configMapName:      "my-template",
+				configMapDataKey:   "prod",
+				flavor:             "ha",
+				targetNamespace:    "",
+				listVariablesOnly:  false,
+			},
+			want:    template + "\n---\n" + templateOverlay,
+			wantErr: false,
+		},
+		{
+			name: "Config map key for the overlay does not exists",
+			fields: fields{
+				proxy:        test.NewFakeProxy().WithObjs(configMap),
+				configClient: configClient,
+			},
+			args: args{
+				configMapNamespace: "ns1",
+				configMapName:      "my-template",
+				configMapDataKey:   "prod",
+				flavor:             "something-else",
+				targetNamespace:    "",
+				listVariablesOnly:  false,
+			},
+			want:    "",
+			wantErr: true,
+		},
 		{
 			name: "Config map does not exists",
 			fields: fields{
@@ -137,7 +178,7 @@ func Test_templateClient_GetFromConfigMap(t *testing.T) {
 				proxy:        tt.fields.proxy,
 				configClient: tt.fields.configClient,
 			}
-			got, err := tc.GetFromConfigMap(tt.args.configMapNamespace, tt.args.configMapName, tt.args.configMapDataKey, tt.args.targetNamespace, tt.args.listVariablesOnly)
+			got, err := tc.GetFromConfigMap(tt.args.configMapNamespace, tt.args.configMapName, tt.args.configMapDataKey, tt.args.flavor, tt.args.targetNamespace, tt.args.listVariablesOnly)
 			if tt.wantErr {
 				g.Expect(err).To(HaveOccurred())
 				return
@@ -303,9 +344,11 @@ func Test_templateClient_GetFromURL(t *testing.T) {
 
 	path := filepath.Join(tmpDir, "cluster-template.yaml")
 	g.Expect(ioutil.WriteFile(path, []byte(template), 0600)).To(Succeed())
+	g.Expect(ioutil.WriteFile(filepath.Join(tmpDir, "cluster-template-overlay-ha.yaml"), []byte(templateOverlay), 0600)).To(Succeed())
 
 	type args struct {
 		templateURL       string
+		flavor            string
 		targetNamespace   string
 		listVariablesOnly bool
 	}
@@ -325,6 +368,27 @@ func Test_templateClient_GetFromURL(t *testing.T) {
 			want:    template,
 			wantErr: false,
 		},
+		{
+			name: "Get from local file system with overlays",
+			args: args{
+				templateURL:       path,
+				flavor:            "ha",
+				targetNamespace:   "",
+				listVariablesOnly: false,
+			},
+			want:    template + "\n---\n" + templateOverlay,
+			wantErr: false,
+		},
+		{
+			name: "Fails if the overlay does not exist",
+			args: args{
+				templateURL:       path,
+				flavor:            "something-else",
+				targetNamespace:   "",
+				listVariablesOnly: false,
+			},
+			wantErr: true,
+		},
 		{
 			name: "Get from GitHub",
 			args: args{
@@ -346,7 +410,7 @@ func Test_templateClient_GetFromURL(t *testing.T) {
 					return client, nil
 				},
 			}
-			got, err := c.GetFromURL(tt.args.templateURL, tt.args.targetNamespace, tt.args.listVariablesOnly)
+			got, err := c.GetFromURL(tt.args.templateURL, tt.args.flavor, tt.args.targetNamespace, tt.args.listVariablesOnly)
 			if tt.wantErr {
 				g.Expect(err).To(HaveOccurred())
 				return
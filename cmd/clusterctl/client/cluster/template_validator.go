@@ -0,0 +1,347 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// templateValidatorFieldOwner defines the field manager used for the server-side dry-run apply of the template objects.
+	templateValidatorFieldOwner = "clusterctl"
+)
+
+// TemplateValidator validates workload cluster templates against the providers installed in a management cluster.
+type TemplateValidator interface {
+	// Validate checks that the objects in a workload cluster template are going to be accepted by the management cluster.
+	// Validation includes:
+	// - checking that each object is valid according to the OpenAPI schema of the CRDs of the installed providers;
+	// - checking that every object reference (e.g. spec.infrastructureRef) refers to a kind served by an installed provider;
+	// - a server-side dry-run apply of each object, so validation webhooks and other server-side checks are executed.
+	Validate(objs []unstructured.Unstructured) error
+}
+
+// templateValidator implements TemplateValidator.
+type templateValidator struct {
+	proxy             Proxy
+	providerInventory InventoryClient
+}
+
+// ensure templateValidator implements TemplateValidator.
+var _ TemplateValidator = &templateValidator{}
+
+// newTemplateValidator returns a templateValidator.
+func newTemplateValidator(proxy Proxy, providerInventory InventoryClient) *templateValidator {
+	return &templateValidator{
+		proxy:             proxy,
+		providerInventory: providerInventory,
+	}
+}
+
+func (v *templateValidator) Validate(objs []unstructured.Unstructured) error {
+	log := logf.Log
+
+	crds, err := v.getProviderCRDs()
+	if err != nil {
+		return err
+	}
+
+	// Validate all the objects against the CRDs of the installed providers, and check all the object references.
+	errList := []error{}
+	for i := range objs {
+		obj := objs[i]
+		log.V(5).Info("Validating", "Kind", obj.GetKind(), "Name", obj.GetName())
+
+		allErrs := validateObjectSchema(obj, crds)
+		allErrs = append(allErrs, validateObjectReferences(obj, crds)...)
+		if len(allErrs) > 0 {
+			errList = append(errList, errors.Errorf("%s %q is not valid: %v", obj.GetKind(), obj.GetName(), allErrs.ToAggregate()))
+		}
+	}
+	if len(errList) > 0 {
+		return kerrors.NewAggregate(errList)
+	}
+
+	// Run a server-side dry-run apply for all the objects, so all the server-side checks (e.g. webhooks) are executed.
+	c, err := v.proxy.NewClient()
+	if err != nil {
+		return err
+	}
+	for i := range objs {
+		obj := objs[i].DeepCopy()
+		if err := c.Patch(ctx, obj, client.Apply, client.DryRunAll, client.ForceOwnership, client.FieldOwner(templateValidatorFieldOwner)); err != nil {
+			errList = append(errList, errors.Wrapf(err, "%s %q failed the server-side dry-run apply", objs[i].GetKind(), objs[i].GetName()))
+		}
+	}
+	return kerrors.NewAggregate(errList)
+}
+
+// getProviderCRDs returns the CRDs installed by the providers in the management cluster, indexed by GroupKind.
+func (v *templateValidator) getProviderCRDs() (map[schema.GroupKind]apiextensionsv1.CustomResourceDefinition, error) {
+	providerList, err := v.providerInventory.List()
+	if err != nil {
+		return nil, err
+	}
+	providers := map[string]bool{}
+	for _, p := range providerList.Items {
+		providers[p.ManifestLabel()] = true
+	}
+
+	crdList := &apiextensionsv1.CustomResourceDefinitionList{}
+	if err := retryWithExponentialBackoff(newReadBackoff(), func() error {
+		return getCRDList(v.proxy, crdList)
+	}); err != nil {
+		return nil, err
+	}
+
+	crds := map[schema.GroupKind]apiextensionsv1.CustomResourceDefinition{}
+	for _, crd := range crdList.Items {
+		if !providers[crd.Labels[clusterv1.ProviderLabelName]] {
+			continue
+		}
+		crds[schema.GroupKind{Group: crd.Spec.Group, Kind: crd.Spec.Names.Kind}] = crd
+	}
+	return crds, nil
+}
+
+// validateObjectSchema validates an object against the OpenAPI schema defined in the corresponding CRD, if any.
+func validateObjectSchema(obj unstructured.Unstructured, crds map[schema.GroupKind]apiextensionsv1.CustomResourceDefinition) field.ErrorList {
+	gvk := obj.GroupVersionKind()
+	crd, ok := crds[gvk.GroupKind()]
+	if !ok {
+		// Objects of a kind not defined by a provider CRD (e.g. Secrets) are validated only by the server-side dry-run;
+		// however, objects in a group served by an installed provider must be of a known kind.
+		for gk := range crds {
+			if gk.Group == gvk.Group {
+				return field.ErrorList{field.Invalid(field.NewPath("kind"), gvk.Kind, fmt.Sprintf("kind is not served by any installed provider for group %q", gvk.Group))}
+			}
+		}
+		return nil
+	}
+
+	for _, version := range crd.Spec.Versions {
+		if version.Name != gvk.Version {
+			continue
+		}
+		if !version.Served {
+			break
+		}
+		if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+			return nil
+		}
+		return validateSchema(nil, obj.Object, version.Schema.OpenAPIV3Schema, true)
+	}
+	return field.ErrorList{field.Invalid(field.NewPath("apiVersion"), obj.GetAPIVersion(), fmt.Sprintf("version is not served by the installed provider for %s", crd.Name))}
+}
+
+// validateSchema validates a value against an OpenAPI schema, checking types, required and unknown fields, enums.
+// NB. This implements a subset of the OpenAPI validation implemented by the API server, which is enough to detect
+// common errors like typos in field names or values of the wrong type; the server-side dry-run apply covers the rest.
+func validateSchema(fldPath *field.Path, value interface{}, s *apiextensionsv1.JSONSchemaProps, isRoot bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if value == nil {
+		if !s.Nullable {
+			allErrs = append(allErrs, field.Required(fldPath, "must not be null"))
+		}
+		return allErrs
+	}
+
+	if s.XIntOrString {
+		switch value.(type) {
+		case int64, float64, string:
+			return allErrs
+		}
+		return append(allErrs, field.Invalid(fldPath, value, "must be an integer or a string"))
+	}
+
+	switch s.Type {
+	case "object":
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return append(allErrs, field.Invalid(fldPath, value, "must be an object"))
+		}
+		for _, r := range s.Required {
+			if _, ok := m[r]; !ok {
+				allErrs = append(allErrs, field.Required(fldPath.Child(r), ""))
+			}
+		}
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if p, ok := s.Properties[k]; ok {
+				allErrs = append(allErrs, validateSchema(fldPath.Child(k), m[k], &p, false)...)
+				continue
+			}
+			if (isRoot || s.XEmbeddedResource) && (k == "apiVersion" || k == "kind" || k == "metadata") {
+				continue
+			}
+			if s.AdditionalProperties != nil {
+				if s.AdditionalProperties.Schema != nil {
+					allErrs = append(allErrs, validateSchema(fldPath.Child(k), m[k], s.AdditionalProperties.Schema, false)...)
+					continue
+				}
+				if s.AdditionalProperties.Allows {
+					continue
+				}
+			}
+			if (s.XPreserveUnknownFields != nil && *s.XPreserveUnknownFields) || (len(s.Properties) == 0 && s.AdditionalProperties == nil) {
+				continue
+			}
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child(k), k, knownProperties(s)))
+		}
+	case "array":
+		a, ok := value.([]interface{})
+		if !ok {
+			return append(allErrs, field.Invalid(fldPath, value, "must be an array"))
+		}
+		if s.Items != nil && s.Items.Schema != nil {
+			for i := range a {
+				allErrs = append(allErrs, validateSchema(fldPath.Index(i), a[i], s.Items.Schema, false)...)
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return append(allErrs, field.Invalid(fldPath, value, "must be a string"))
+		}
+	case "integer":
+		switch v := value.(type) {
+		case int64:
+		case float64:
+			if v != math.Trunc(v) {
+				return append(allErrs, field.Invalid(fldPath, value, "must be an integer"))
+			}
+		default:
+			return append(allErrs, field.Invalid(fldPath, value, "must be an integer"))
+		}
+	case "number":
+		switch value.(type) {
+		case int64, float64:
+		default:
+			return append(allErrs, field.Invalid(fldPath, value, "must be a number"))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return append(allErrs, field.Invalid(fldPath, value, "must be a boolean"))
+		}
+	}
+
+	if len(s.Enum) > 0 && !inEnum(value, s.Enum) {
+		enum := make([]string, 0, len(s.Enum))
+		for _, e := range s.Enum {
+			enum = append(enum, string(e.Raw))
+		}
+		allErrs = append(allErrs, field.NotSupported(fldPath, value, enum))
+	}
+	return allErrs
+}
+
+// knownProperties returns the sorted list of the properties defined in a schema.
+func knownProperties(s *apiextensionsv1.JSONSchemaProps) []string {
+	ret := make([]string, 0, len(s.Properties))
+	for k := range s.Properties {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// inEnum returns true if the value is one of the values defined in an enum.
+func inEnum(value interface{}, enum []apiextensionsv1.JSON) bool {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	for _, e := range enum {
+		if string(e.Raw) == string(raw) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateObjectReferences checks that all the object references in an object (e.g. spec.infrastructureRef) refer
+// to a kind served by an installed provider.
+// NB. references to kinds in the core API group (e.g. Secrets) are not checked.
+func validateObjectReferences(obj unstructured.Unstructured, crds map[schema.GroupKind]apiextensionsv1.CustomResourceDefinition) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for k, v := range obj.Object {
+		if k == "apiVersion" || k == "kind" || k == "metadata" {
+			continue
+		}
+		allErrs = append(allErrs, validateReferences(field.NewPath(k), v, crds)...)
+	}
+	return allErrs
+}
+
+func validateReferences(fldPath *field.Path, value interface{}, crds map[schema.GroupKind]apiextensionsv1.CustomResourceDefinition) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if isObjectReference(v) {
+			gv, err := schema.ParseGroupVersion(v["apiVersion"].(string))
+			if err != nil {
+				return append(allErrs, field.Invalid(fldPath.Child("apiVersion"), v["apiVersion"], err.Error()))
+			}
+			if gv.Group == "" {
+				return allErrs
+			}
+			if _, ok := crds[gv.WithKind(v["kind"].(string)).GroupKind()]; !ok {
+				return append(allErrs, field.Invalid(fldPath.Child("kind"), v["kind"], fmt.Sprintf("kind is not served by any installed provider for group %q", gv.Group)))
+			}
+			return allErrs
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			allErrs = append(allErrs, validateReferences(fldPath.Child(k), v[k], crds)...)
+		}
+	case []interface{}:
+		for i := range v {
+			allErrs = append(allErrs, validateReferences(fldPath.Index(i), v[i], crds)...)
+		}
+	}
+	return allErrs
+}
+
+// isObjectReference returns true if a value looks like an ObjectReference, that is it has apiVersion, kind and name.
+func isObjectReference(v map[string]interface{}) bool {
+	for _, k := range []string{"apiVersion", "kind", "name"} {
+		if _, ok := v[k].(string); !ok {
+			return false
+		}
+	}
+	return true
+}
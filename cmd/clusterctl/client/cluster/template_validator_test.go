@@ -0,0 +1,233 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+)
+
+// fakeProviderCRD returns a CRD for a kind served by the infra provider, with a schema defining spec.replicas,
+// spec.mode and spec.infrastructureRef.
+func fakeProviderCRD() *apiextensionsv1.CustomResourceDefinition {
+	return &apiextensionsv1.CustomResourceDefinition{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: apiextensionsv1.SchemeGroupVersion.String(),
+			Kind:       "CustomResourceDefinition",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "foos.infrastructure.example.com",
+			Labels: map[string]string{
+				clusterctlv1.ClusterctlLabelName: "",
+				clusterv1.ProviderLabelName:      clusterctlv1.ManifestLabel("infra", clusterctlv1.InfrastructureProviderType),
+			},
+		},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: "infrastructure.example.com",
+			Names: apiextensionsv1.CustomResourceDefinitionNames{Kind: "Foo"},
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{
+					Name:    "v1",
+					Served:  true,
+					Storage: true,
+					Schema: &apiextensionsv1.CustomResourceValidation{
+						OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+							Type: "object",
+							Properties: map[string]apiextensionsv1.JSONSchemaProps{
+								"spec": {
+									Type:     "object",
+									Required: []string{"replicas"},
+									Properties: map[string]apiextensionsv1.JSONSchemaProps{
+										"replicas": {Type: "integer"},
+										"mode": {
+											Type: "string",
+											Enum: []apiextensionsv1.JSON{{Raw: []byte(`"a"`)}, {Raw: []byte(`"b"`)}},
+										},
+										"infrastructureRef": {
+											Type: "object",
+											Properties: map[string]apiextensionsv1.JSONSchemaProps{
+												"apiVersion": {Type: "string"},
+												"kind":       {Type: "string"},
+												"name":       {Type: "string"},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				{
+					Name:   "v0",
+					Served: false,
+				},
+			},
+		},
+	}
+}
+
+func Test_templateValidator_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{
+			name: "pass for valid objects",
+			yaml: "apiVersion: infrastructure.example.com/v1\n" +
+				"kind: Foo\n" +
+				"metadata:\n" +
+				"  name: foo\n" +
+				"spec:\n" +
+				"  replicas: 3\n" +
+				"  mode: a\n" +
+				"  infrastructureRef:\n" +
+				"    apiVersion: infrastructure.example.com/v1\n" +
+				"    kind: Foo\n" +
+				"    name: bar\n" +
+				"---\n" +
+				"apiVersion: v1\n" +
+				"kind: Secret\n" +
+				"metadata:\n" +
+				"  name: foo\n",
+			wantErr: false,
+		},
+		{
+			name: "fails for unknown fields",
+			yaml: "apiVersion: infrastructure.example.com/v1\n" +
+				"kind: Foo\n" +
+				"metadata:\n" +
+				"  name: foo\n" +
+				"spec:\n" +
+				"  replicas: 3\n" +
+				"  replcas: 3\n",
+			wantErr: true,
+		},
+		{
+			name: "fails for missing required fields",
+			yaml: "apiVersion: infrastructure.example.com/v1\n" +
+				"kind: Foo\n" +
+				"metadata:\n" +
+				"  name: foo\n" +
+				"spec:\n" +
+				"  mode: a\n",
+			wantErr: true,
+		},
+		{
+			name: "fails for values of the wrong type",
+			yaml: "apiVersion: infrastructure.example.com/v1\n" +
+				"kind: Foo\n" +
+				"metadata:\n" +
+				"  name: foo\n" +
+				"spec:\n" +
+				"  replicas: \"3\"\n",
+			wantErr: true,
+		},
+		{
+			name: "fails for values not in enum",
+			yaml: "apiVersion: infrastructure.example.com/v1\n" +
+				"kind: Foo\n" +
+				"metadata:\n" +
+				"  name: foo\n" +
+				"spec:\n" +
+				"  replicas: 3\n" +
+				"  mode: c\n",
+			wantErr: true,
+		},
+		{
+			name: "fails for versions not served",
+			yaml: "apiVersion: infrastructure.example.com/v0\n" +
+				"kind: Foo\n" +
+				"metadata:\n" +
+				"  name: foo\n",
+			wantErr: true,
+		},
+		{
+			name: "fails for kinds not served by the provider",
+			yaml: "apiVersion: infrastructure.example.com/v1\n" +
+				"kind: Bar\n" +
+				"metadata:\n" +
+				"  name: foo\n",
+			wantErr: true,
+		},
+		{
+			name: "fails for object references to kinds not served by any provider",
+			yaml: "apiVersion: infrastructure.example.com/v1\n" +
+				"kind: Foo\n" +
+				"metadata:\n" +
+				"  name: foo\n" +
+				"spec:\n" +
+				"  replicas: 3\n" +
+				"  infrastructureRef:\n" +
+				"    apiVersion: infrastructure.example.com/v1\n" +
+				"    kind: Fooo\n" +
+				"    name: bar\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			proxy := test.NewFakeProxy().
+				WithProviderInventory("infra", clusterctlv1.InfrastructureProviderType, "v1.0.0", "infra-system", "").
+				WithObjs(fakeProviderCRD())
+
+			objs, err := utilyaml.ToUnstructured([]byte(tt.yaml))
+			g.Expect(err).NotTo(HaveOccurred())
+
+			v := newTemplateValidator(proxy, newInventoryClient(proxy, nil))
+			err = v.Validate(objs)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
+
+func Test_templateValidator_Validate_ignoresProvidersNotInstalled(t *testing.T) {
+	g := NewWithT(t)
+
+	// the CRD exists, but the corresponding provider is not in the inventory, so the kind is not considered served.
+	proxy := test.NewFakeProxy().
+		WithProviderInventory("other", clusterctlv1.InfrastructureProviderType, "v1.0.0", "other-system", "").
+		WithObjs(fakeProviderCRD())
+
+	obj := unstructured.Unstructured{}
+	obj.SetAPIVersion("cluster.x-k8s.io/v1alpha3")
+	obj.SetKind("Cluster")
+	obj.SetName("foo")
+	g.Expect(unstructured.SetNestedMap(obj.Object, map[string]interface{}{
+		"apiVersion": "infrastructure.example.com/v1",
+		"kind":       "Foo",
+		"name":       "foo",
+	}, "spec", "infrastructureRef")).To(Succeed())
+
+	v := newTemplateValidator(proxy, newInventoryClient(proxy, nil))
+	g.Expect(v.Validate([]unstructured.Unstructured{obj})).NotTo(Succeed())
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	utilkubeconfig "sigs.k8s.io/cluster-api/util/kubeconfig"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WorkloadClusterInfo summarizes the status of a workload cluster.
+type WorkloadClusterInfo struct {
+	// Namespace of the Cluster object.
+	Namespace string
+
+	// Name of the Cluster object.
+	Name string
+
+	// Phase of the Cluster, e.g. Provisioned.
+	Phase string
+
+	// ControlPlaneReady is true when the control plane of the Cluster is ready.
+	ControlPlaneReady bool
+
+	// Version is the Kubernetes version of the control plane, if defined by the control plane object,
+	// otherwise the most recent Kubernetes version of the Machines.
+	Version string
+
+	// Machines is the number of Machines belonging to the Cluster.
+	Machines int
+
+	// ReadyMachines is the number of Machines belonging to the Cluster with a Node.
+	ReadyMachines int
+}
+
+// WorkloadCluster has methods for getting information about the workload clusters hosted in a management cluster.
+type WorkloadCluster interface {
+	// GetKubeconfig returns the kubeconfig of a workload cluster, as stored in the management cluster.
+	GetKubeconfig(workloadClusterName string, namespace string) ([]byte, error)
+
+	// List returns the workload clusters existing in a namespace, or in all the namespaces if empty,
+	// sorted by namespace and name.
+	List(namespace string) ([]WorkloadClusterInfo, error)
+}
+
+// workloadCluster implements WorkloadCluster.
+type workloadCluster struct {
+	proxy Proxy
+}
+
+// ensure workloadCluster implements WorkloadCluster.
+var _ WorkloadCluster = &workloadCluster{}
+
+// newWorkloadCluster returns a workloadCluster.
+func newWorkloadCluster(proxy Proxy) *workloadCluster {
+	return &workloadCluster{
+		proxy: proxy,
+	}
+}
+
+func (w *workloadCluster) GetKubeconfig(workloadClusterName string, namespace string) ([]byte, error) {
+	cs, err := w.proxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	cluster := &clusterv1.Cluster{}
+	if err := cs.Get(ctx, client.ObjectKey{Namespace: namespace, Name: workloadClusterName}, cluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, errors.Errorf("cluster %s/%s does not exist", namespace, workloadClusterName)
+		}
+		return nil, errors.Wrapf(err, "failed to get cluster %s/%s", namespace, workloadClusterName)
+	}
+
+	data, err := utilkubeconfig.FromSecret(ctx, cs, client.ObjectKey{Namespace: namespace, Name: workloadClusterName})
+	if err != nil {
+		if apierrors.IsNotFound(errors.Cause(err)) {
+			return nil, errors.Errorf("the kubeconfig of cluster %s/%s is not available yet", namespace, workloadClusterName)
+		}
+		return nil, errors.Wrapf(err, "failed to get the kubeconfig of cluster %s/%s", namespace, workloadClusterName)
+	}
+	return data, nil
+}
+
+func (w *workloadCluster) List(namespace string) ([]WorkloadClusterInfo, error) {
+	cs, err := w.proxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	clusterList := &clusterv1.ClusterList{}
+	if err := cs.List(ctx, clusterList, client.InNamespace(namespace)); err != nil {
+		return nil, errors.Wrap(err, "failed to list clusters")
+	}
+
+	machineList := &clusterv1.MachineList{}
+	if err := cs.List(ctx, machineList, client.InNamespace(namespace)); err != nil {
+		return nil, errors.Wrap(err, "failed to list machines")
+	}
+
+	ret := make([]WorkloadClusterInfo, 0, len(clusterList.Items))
+	for i := range clusterList.Items {
+		cluster := &clusterList.Items[i]
+		info := WorkloadClusterInfo{
+			Namespace:         cluster.Namespace,
+			Name:              cluster.Name,
+			Phase:             cluster.Status.Phase,
+			ControlPlaneReady: cluster.Status.ControlPlaneReady,
+		}
+
+		for j := range machineList.Items {
+			machine := &machineList.Items[j]
+			if machine.Namespace != cluster.Namespace || machine.Spec.ClusterName != cluster.Name {
+				continue
+			}
+			info.Machines++
+			if machine.Status.NodeRef != nil {
+				info.ReadyMachines++
+			}
+			if machine.Spec.Version != nil && isNewerVersion(*machine.Spec.Version, info.Version) {
+				info.Version = *machine.Spec.Version
+			}
+		}
+
+		version, err := w.getControlPlaneVersion(cs, cluster)
+		if err != nil {
+			return nil, err
+		}
+		if version != "" {
+			info.Version = version
+		}
+
+		ret = append(ret, info)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Namespace != ret[j].Namespace {
+			return ret[i].Namespace < ret[j].Namespace
+		}
+		return ret[i].Name < ret[j].Name
+	})
+	return ret, nil
+}
+
+// getControlPlaneVersion returns the Kubernetes version defined in the control plane object of a Cluster, if any.
+func (w *workloadCluster) getControlPlaneVersion(cs client.Client, cluster *clusterv1.Cluster) (string, error) {
+	ref := cluster.Spec.ControlPlaneRef
+	if ref == nil {
+		return "", nil
+	}
+
+	controlPlane := &unstructured.Unstructured{}
+	controlPlane.SetAPIVersion(ref.APIVersion)
+	controlPlane.SetKind(ref.Kind)
+	if err := cs.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, controlPlane); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "failed to get the control plane of cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+
+	version, _, err := unstructured.NestedString(controlPlane.Object, "spec", "version")
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get the version of the control plane of cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+	return version, nil
+}
+
+// isNewerVersion returns true if version is more recent than current, or if current is not a valid version.
+func isNewerVersion(version, current string) bool {
+	v, err := utilversion.ParseGeneric(version)
+	if err != nil {
+		return false
+	}
+	c, err := utilversion.ParseGeneric(current)
+	if err != nil {
+		return true
+	}
+	return c.LessThan(v)
+}
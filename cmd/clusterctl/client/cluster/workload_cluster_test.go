@@ -0,0 +1,167 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	"sigs.k8s.io/cluster-api/util/secret"
+)
+
+func fakeWorkloadCluster(namespace, name, phase string, controlPlaneReady bool) *clusterv1.Cluster {
+	return &clusterv1.Cluster{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "Cluster",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Status: clusterv1.ClusterStatus{
+			Phase:             phase,
+			ControlPlaneReady: controlPlaneReady,
+		},
+	}
+}
+
+func fakeWorkloadMachine(namespace, name, clusterName, version string, ready bool) *clusterv1.Machine {
+	m := &clusterv1.Machine{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "Machine",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: clusterName,
+			Version:     &version,
+		},
+	}
+	if ready {
+		m.Status.NodeRef = &corev1.ObjectReference{Kind: "Node", Name: name}
+	}
+	return m
+}
+
+func Test_workloadCluster_GetKubeconfig(t *testing.T) {
+	kubeconfigSecret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns1",
+			Name:      secret.Name("foo", secret.Kubeconfig),
+		},
+		Data: map[string][]byte{
+			secret.KubeconfigDataName: []byte("kubeconfig"),
+		},
+	}
+
+	tests := []struct {
+		name    string
+		objs    []runtime.Object
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "returns the kubeconfig of the workload cluster",
+			objs: []runtime.Object{fakeWorkloadCluster("ns1", "foo", "Provisioned", true), kubeconfigSecret},
+			want: "kubeconfig",
+		},
+		{
+			name:    "returns an error if the cluster does not exist",
+			objs:    []runtime.Object{kubeconfigSecret},
+			wantErr: true,
+		},
+		{
+			name:    "returns an error if the kubeconfig does not exist yet",
+			objs:    []runtime.Object{fakeWorkloadCluster("ns1", "foo", "Provisioning", false)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			w := newWorkloadCluster(test.NewFakeProxy().WithObjs(tt.objs...))
+			got, err := w.GetKubeconfig("foo", "ns1")
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(string(got)).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_workloadCluster_List(t *testing.T) {
+	objs := []runtime.Object{
+		fakeWorkloadCluster("ns2", "bar", "Provisioning", false),
+		fakeWorkloadCluster("ns1", "foo", "Provisioned", true),
+		fakeWorkloadMachine("ns1", "foo-1", "foo", "v1.17.3", true),
+		fakeWorkloadMachine("ns1", "foo-2", "foo", "v1.9.11", false),
+		fakeWorkloadMachine("ns1", "other-1", "other", "v1.18.0", true),
+	}
+
+	tests := []struct {
+		name      string
+		namespace string
+		want      []WorkloadClusterInfo
+	}{
+		{
+			name:      "lists the clusters in all the namespaces",
+			namespace: "",
+			want: []WorkloadClusterInfo{
+				{Namespace: "ns1", Name: "foo", Phase: "Provisioned", ControlPlaneReady: true, Version: "v1.17.3", Machines: 2, ReadyMachines: 1},
+				{Namespace: "ns2", Name: "bar", Phase: "Provisioning"},
+			},
+		},
+		{
+			name:      "lists the clusters in a namespace",
+			namespace: "ns2",
+			want: []WorkloadClusterInfo{
+				{Namespace: "ns2", Name: "bar", Phase: "Provisioning"},
+			},
+		},
+		{
+			name:      "returns an empty list if there are no clusters in the namespace",
+			namespace: "ns3",
+			want:      []WorkloadClusterInfo{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			w := newWorkloadCluster(test.NewFakeProxy().WithObjs(objs...))
+			got, err := w.List(tt.namespace)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
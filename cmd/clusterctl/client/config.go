@@ -25,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/version"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
 )
 
@@ -94,9 +95,18 @@ type GetClusterTemplateOptions struct {
 	// It can be set through the cli flag, WORKER_MACHINE_COUNT environment variable or will default to 0
 	WorkerMachineCount *int64
 
+	// ValuesFile is the path of a YAML file defining a map of variables to be used for the workload cluster template;
+	// values from this file take precedence over environment variables and the .cluster-api/clusterctl.yaml config file,
+	// while the other options, e.g. KubernetesVersion, take precedence over the values from this file.
+	ValuesFile string
+
 	// ListVariablesOnly sets the GetClusterTemplate method to return the list of variables expected by the template
 	// without executing any further processing.
 	ListVariablesOnly bool
+
+	// Validate sets the GetClusterTemplate method to check the workload cluster template against the providers installed
+	// in the management cluster, including a server-side dry-run apply of all the objects in the template.
+	Validate bool
 }
 
 // numSources return the number of template sources currently set on a GetClusterTemplateOptions.
@@ -123,6 +133,8 @@ type ProviderRepositorySourceOptions struct {
 
 	// Flavor defines The workload cluster template variant to be used when reading from the infrastructure
 	// provider repository. If unspecified, the default cluster template will be used.
+	// Many flavors can be applied at once using a comma separated list, e.g. "a,b"; in this case the
+	// corresponding overlays are applied in order on top of the default cluster template.
 	Flavor string
 }
 
@@ -130,6 +142,10 @@ type ProviderRepositorySourceOptions struct {
 type URLSourceOptions struct {
 	// URL to read the workload cluster template from.
 	URL string
+
+	// Flavor defines a comma separated list of overlays to be applied on top of the workload cluster template;
+	// overlays are read from files located next to the template, e.g. cluster-template-overlay-<flavor>.yaml.
+	Flavor string
 }
 
 // DefaultCustomTemplateConfigMapKey  where the workload cluster template is hosted.
@@ -146,6 +162,10 @@ type ConfigMapSourceOptions struct {
 	// DataKey where the workload cluster template is hosted. If unspecified, the
 	// DefaultCustomTemplateConfigMapKey will be used.
 	DataKey string
+
+	// Flavor defines a comma separated list of overlays to be applied on top of the workload cluster template;
+	// overlays are read from the <DataKey>-overlay-<flavor> data keys of the same ConfigMap.
+	Flavor string
 }
 
 func (c *clusterctlClient) GetClusterTemplate(options GetClusterTemplateOptions) (Template, error) {
@@ -179,12 +199,35 @@ func (c *clusterctlClient) GetClusterTemplate(options GetClusterTemplateOptions)
 		options.TargetNamespace = currentNamespace
 	}
 
+	// If a values file is specified, inject its values into the configClient, before the templateOptions so they can override them.
+	if options.ValuesFile != "" {
+		if err := config.SetValuesFromFile(c.configClient.Variables(), options.ValuesFile); err != nil {
+			return nil, err
+		}
+	}
+
 	// Inject some of the templateOptions into the configClient so they can be consumed as a variables from the template.
 	if err := c.templateOptionsToVariables(options); err != nil {
 		return nil, err
 	}
 
 	// Gets the workload cluster template from the selected source
+	template, err := c.getTemplate(cluster, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// If required, validate the workload cluster template against the providers installed in the management cluster.
+	if options.Validate && !options.ListVariablesOnly {
+		if err := cluster.TemplateValidator().Validate(template.Objs()); err != nil {
+			return nil, errors.Wrap(err, "the workload cluster template is not valid")
+		}
+	}
+	return template, nil
+}
+
+// getTemplate returns a workload cluster template from the selected source.
+func (c *clusterctlClient) getTemplate(cluster cluster.Client, options GetClusterTemplateOptions) (Template, error) {
 	if options.ProviderRepositorySource != nil {
 		return c.getTemplateFromRepository(cluster, *options.ProviderRepositorySource, options.TargetNamespace, options.ListVariablesOnly)
 	}
@@ -281,12 +324,12 @@ func (c *clusterctlClient) getTemplateFromConfigMap(cluster cluster.Client, sour
 		source.DataKey = DefaultCustomTemplateConfigMapKey
 	}
 
-	return cluster.Template().GetFromConfigMap(source.Namespace, source.Name, source.DataKey, targetNamespace, listVariablesOnly)
+	return cluster.Template().GetFromConfigMap(source.Namespace, source.Name, source.DataKey, source.Flavor, targetNamespace, listVariablesOnly)
 }
 
 // getTemplateFromURL returns a workload cluster template from an URL.
 func (c *clusterctlClient) getTemplateFromURL(cluster cluster.Client, source URLSourceOptions, targetNamespace string, listVariablesOnly bool) (Template, error) {
-	return cluster.Template().GetFromURL(source.URL, targetNamespace, listVariablesOnly)
+	return cluster.Template().GetFromURL(source.URL, source.Flavor, targetNamespace, listVariablesOnly)
 }
 
 // templateOptionsToVariables injects some of the templateOptions to the configClient so they can be consumed as a variables from the template.
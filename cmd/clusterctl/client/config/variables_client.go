@@ -16,11 +16,41 @@ limitations under the License.
 
 package config
 
-import "sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+import (
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
 
 const (
 	// GitHubTokenVariable defines a variable hosting the GitHub access token
 	GitHubTokenVariable = "github-token"
+
+	// HTTPRepositoryUsernameVariable defines a variable hosting the username used for basic authentication against HTTP repositories
+	HTTPRepositoryUsernameVariable = "http-repository-username"
+
+	// HTTPRepositoryPasswordVariable defines a variable hosting the password used for basic authentication against HTTP repositories
+	HTTPRepositoryPasswordVariable = "http-repository-password"
+
+	// HTTPRepositoryTokenVariable defines a variable hosting the bearer token used for authentication against HTTP repositories
+	HTTPRepositoryTokenVariable = "http-repository-token"
+
+	// OCIRepositoryUsernameVariable defines a variable hosting the username used for authentication against OCI registries
+	OCIRepositoryUsernameVariable = "oci-repository-username"
+
+	// OCIRepositoryPasswordVariable defines a variable hosting the password used for authentication against OCI registries
+	OCIRepositoryPasswordVariable = "oci-repository-password"
+
+	// OCIRepositoryTokenVariable defines a variable hosting the bearer token used for authentication against OCI registries
+	OCIRepositoryTokenVariable = "oci-repository-token"
+
+	// OfflineVariable defines a variable that, if set to true, forces clusterctl to read provider repositories only from the cache
+	OfflineVariable = "offline"
+
+	// CacheTTLVariable defines a variable hosting the duration after which version listings stored in the cache are refreshed
+	CacheTTLVariable = "cache-ttl"
 )
 
 // VariablesClient has methods to work with environment variables and with variables defined in the clusterctl configuration file.
@@ -59,3 +89,25 @@ func (p *variablesClient) Get(key string) (string, error) {
 func (p *variablesClient) Set(key, value string) {
 	p.reader.Set(key, value)
 }
+
+// SetValuesFromFile reads a YAML file defining a map of variables, e.g. "KUBERNETES_VERSION: v1.18.2", and sets them as
+// overrides in the VariablesClient, so they take precedence over environment variables and the clusterctl configuration file.
+// Values must be scalars, and they are set exactly as written in the file, e.g. "1.20" is not turned into "1.2".
+func SetValuesFromFile(variablesClient VariablesClient, path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read values file %q", path)
+	}
+
+	// NB. values are decoded with yaml.v2 into strings, which preserves the literal text of scalars, while
+	// sigs.k8s.io/yaml would convert them to JSON first, reformatting numbers.
+	values := map[string]string{}
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return errors.Wrapf(err, "failed to parse values file %q, a YAML map of scalar values is expected", path)
+	}
+
+	for key, value := range values {
+		variablesClient.Set(key, value)
+	}
+	return nil
+}
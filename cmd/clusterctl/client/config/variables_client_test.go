@@ -17,6 +17,9 @@ limitations under the License.
 package config
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -70,3 +73,73 @@ func Test_variables_Get(t *testing.T) {
 		})
 	}
 }
+
+func Test_SetValuesFromFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:   "Sets the values defined in the file",
+			values: "KUBERNETES_VERSION: v1.18.2\nWORKER_MACHINE_COUNT: 3\nENABLE_FOO: true\nEMPTY:\n",
+			want: map[string]string{
+				"KUBERNETES_VERSION":   "v1.18.2",
+				"WORKER_MACHINE_COUNT": "3",
+				"ENABLE_FOO":           "true",
+				"EMPTY":                "",
+			},
+		},
+		{
+			name:   "Preserves the literal text of numbers",
+			values: "UBUNTU_VERSION: 19.10\nKUBERNETES_MINOR: 1.20\nBIG_NUMBER: 12345678901234567890\n",
+			want: map[string]string{
+				"UBUNTU_VERSION":   "19.10",
+				"KUBERNETES_MINOR": "1.20",
+				"BIG_NUMBER":       "12345678901234567890",
+			},
+		},
+		{
+			name:    "Returns error if a value is not a scalar",
+			values:  "FOO:\n  bar: baz\n",
+			wantErr: true,
+		},
+		{
+			name:    "Returns error if the file is not a YAML map",
+			values:  "- foo\n- bar\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			tmpDir, err := ioutil.TempDir("", "cc")
+			g.Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(tmpDir)
+
+			path := filepath.Join(tmpDir, "values.yaml")
+			g.Expect(ioutil.WriteFile(path, []byte(tt.values), 0600)).To(Succeed())
+
+			variables := newVariablesClient(test.NewFakeReader().WithVar("KUBERNETES_VERSION", "v1.17.3"))
+			err = SetValuesFromFile(variables, path)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			for key, value := range tt.want {
+				got, err := variables.Get(key)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(got).To(Equal(value))
+			}
+		})
+	}
+
+	t.Run("Returns error if the file does not exist", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(SetValuesFromFile(newVariablesClient(test.NewFakeReader()), "does-not-exist")).NotTo(Succeed())
+	})
+}
@@ -413,6 +413,50 @@ func Test_clusterctlClient_templateOptionsToVariables_withExistingMachineCountVa
 	}
 }
 
+func Test_clusterctlClient_GetClusterTemplate_withValuesFile(t *testing.T) {
+	g := NewWithT(t)
+
+	tmpDir, err := ioutil.TempDir("", "cc")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(tmpDir)
+
+	templatePath := filepath.Join(tmpDir, "cluster-template.yaml")
+	g.Expect(ioutil.WriteFile(templatePath, templateYAML("ns3", "${ CLUSTER_NAME }-${ KUBERNETES_VERSION }-${ WORKER_MACHINE_COUNT }"), 0600)).To(Succeed())
+
+	valuesPath := filepath.Join(tmpDir, "values.yaml")
+	g.Expect(ioutil.WriteFile(valuesPath, []byte("KUBERNETES_VERSION: v1.17.3\nWORKER_MACHINE_COUNT: 3\n"), 0600)).To(Succeed())
+
+	config1 := newFakeConfig().
+		WithVar("KUBERNETES_VERSION", "v1.16.0").
+		WithVar("WORKER_MACHINE_COUNT", "1")
+	cluster1 := newFakeCluster(cluster.Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"}, config1)
+	client := newFakeClient(config1).
+		WithCluster(cluster1)
+
+	options := GetClusterTemplateOptions{
+		Kubeconfig:      Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+		URLSource:       &URLSourceOptions{URL: templatePath},
+		ClusterName:     "test",
+		TargetNamespace: "ns1",
+		ValuesFile:      valuesPath,
+	}
+
+	// Values from the values file take precedence over the environment variables.
+	template, err := client.GetClusterTemplate(options)
+	g.Expect(err).NotTo(HaveOccurred())
+	yaml, err := template.Yaml()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(yaml)).To(ContainSubstring("name: test-v1.17.3-3"))
+
+	// Options take precedence over the values file.
+	options.KubernetesVersion = "v1.18.2"
+	template, err = client.GetClusterTemplate(options)
+	g.Expect(err).NotTo(HaveOccurred())
+	yaml, err = template.Yaml()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(yaml)).To(ContainSubstring("name: test-v1.18.2-3"))
+}
+
 func Test_clusterctlClient_GetClusterTemplate(t *testing.T) {
 	g := NewWithT(t)
 
@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+// ProcessYAMLOptions carries the options supported by ProcessYAML.
+type ProcessYAMLOptions struct {
+	// URL of the template to process; it can be a path on the local file system or a GitHub URL.
+	URL string
+
+	// ValuesFile is the path of a YAML file defining a map of variables to be used for the template; values from this
+	// file take precedence over environment variables and the .cluster-api/clusterctl.yaml config file.
+	ValuesFile string
+
+	// ListVariablesOnly sets the ProcessYAML method to return the list of variables expected by the template
+	// without executing any further processing.
+	ListVariablesOnly bool
+}
+
+// ProcessYAML processes an arbitrary template, e.g. the manifest of an addon, replacing the variables with the same
+// variable engine used for workload cluster templates. Unlike GetClusterTemplate, the namespaces of the objects in the
+// template are preserved.
+func (c *clusterctlClient) ProcessYAML(options ProcessYAMLOptions) (Template, error) {
+	if options.URL == "" {
+		return nil, errors.New("invalid ProcessYAML operation: missing URL value")
+	}
+
+	if options.ValuesFile != "" {
+		if err := config.SetValuesFromFile(c.configClient.Variables(), options.ValuesFile); err != nil {
+			return nil, err
+		}
+	}
+
+	// Nb. The cluster client is used only for reading the template, so it never connects to a management cluster.
+	cluster, err := c.clusterClientFactory(Kubeconfig{})
+	if err != nil {
+		return nil, err
+	}
+
+	return cluster.Template().GetFromURL(options.URL, "", "", options.ListVariablesOnly)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+)
+
+func Test_clusterctlClient_ProcessYAML(t *testing.T) {
+	g := NewWithT(t)
+
+	tmpDir, err := ioutil.TempDir("", "cc")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(tmpDir)
+
+	templatePath := filepath.Join(tmpDir, "addon.yaml")
+	g.Expect(ioutil.WriteFile(templatePath, []byte("apiVersion: v1\n"+
+		"kind: ConfigMap\n"+
+		"metadata:\n"+
+		"  name: ${ NAME }\n"+
+		"  namespace: kube-system\n"+
+		"data:\n"+
+		"  replicas: \"${ REPLICAS }\"\n"), 0600)).To(Succeed())
+
+	valuesPath := filepath.Join(tmpDir, "values.yaml")
+	g.Expect(ioutil.WriteFile(valuesPath, []byte("NAME: bar\nREPLICAS: 3\n"), 0600)).To(Succeed())
+
+	tests := []struct {
+		name          string
+		options       ProcessYAMLOptions
+		wantVariables []string
+		wantYaml      string
+		wantErr       bool
+	}{
+		{
+			name: "replaces the variables with values from the values file, preserving namespaces",
+			options: ProcessYAMLOptions{
+				URL:        templatePath,
+				ValuesFile: valuesPath,
+			},
+			wantVariables: []string{"NAME", "REPLICAS"},
+			wantYaml: "apiVersion: v1\n" +
+				"data:\n" +
+				"  replicas: \"3\"\n" +
+				"kind: ConfigMap\n" +
+				"metadata:\n" +
+				"  name: bar\n" +
+				"  namespace: kube-system",
+		},
+		{
+			name: "lists the variables",
+			options: ProcessYAMLOptions{
+				URL:               templatePath,
+				ListVariablesOnly: true,
+			},
+			wantVariables: []string{"NAME", "REPLICAS"},
+		},
+		{
+			name: "returns an error if variables are not set",
+			options: ProcessYAMLOptions{
+				URL: templatePath,
+			},
+			wantErr: true,
+		},
+		{
+			name: "returns an error if the values file does not exist",
+			options: ProcessYAMLOptions{
+				URL:        templatePath,
+				ValuesFile: filepath.Join(tmpDir, "does-not-exist.yaml"),
+			},
+			wantErr: true,
+		},
+		{
+			name:    "returns an error if the URL is missing",
+			options: ProcessYAMLOptions{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			config1 := newFakeConfig()
+			client := newFakeClient(config1).
+				WithCluster(newFakeCluster(cluster.Kubeconfig{}, config1))
+
+			got, err := client.ProcessYAML(tt.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got.Variables()).To(Equal(tt.wantVariables))
+			if tt.options.ListVariablesOnly {
+				return
+			}
+
+			yaml, err := got.Yaml()
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(string(yaml)).To(Equal(tt.wantYaml))
+		})
+	}
+}
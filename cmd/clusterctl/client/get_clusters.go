@@ -0,0 +1,52 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+// GetClustersOptions carries the options supported by GetClusters.
+type GetClustersOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// Namespace where the workload clusters exist. If unspecified, the current namespace will be used.
+	Namespace string
+
+	// AllNamespaces lists the workload clusters existing in all the namespaces; Namespace is ignored.
+	AllNamespaces bool
+}
+
+func (c *clusterctlClient) GetClusters(options GetClustersOptions) ([]WorkloadClusterInfo, error) {
+	// Get the client for interacting with the management cluster.
+	clusterClient, err := c.clusterClientFactory(options.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := options.Namespace
+	if options.AllNamespaces {
+		namespace = ""
+	} else if namespace == "" {
+		// If the option specifying the Namespace is empty, try to detect it.
+		currentNamespace, err := clusterClient.Proxy().CurrentNamespace()
+		if err != nil {
+			return nil, err
+		}
+		namespace = currentNamespace
+	}
+
+	return clusterClient.WorkloadCluster().List(namespace)
+}
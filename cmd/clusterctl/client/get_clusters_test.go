@@ -0,0 +1,116 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+)
+
+func Test_clusterctlClient_GetClusters(t *testing.T) {
+	type args struct {
+		options GetClustersOptions
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "lists the clusters in the current namespace",
+			args: args{
+				options: GetClustersOptions{
+					Kubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+				},
+			},
+			want: []string{"default/foo"},
+		},
+		{
+			name: "lists the clusters in a namespace",
+			args: args{
+				options: GetClustersOptions{
+					Kubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					Namespace:  "ns1",
+				},
+			},
+			want: []string{"ns1/bar"},
+		},
+		{
+			name: "lists the clusters in all the namespaces",
+			args: args{
+				options: GetClustersOptions{
+					Kubeconfig:    Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+					Namespace:     "ns1",
+					AllNamespaces: true,
+				},
+			},
+			want: []string{"default/foo", "ns1/bar"},
+		},
+		{
+			name: "returns an error if the cluster client is not found",
+			args: args{
+				options: GetClustersOptions{
+					Kubeconfig: Kubeconfig{Path: "kubeconfig", Context: "does-not-exist"},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := fakeClientForGet().GetClusters(tt.args.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			names := []string{}
+			for _, c := range got {
+				names = append(names, c.Namespace+"/"+c.Name)
+			}
+			g.Expect(names).To(Equal(tt.want))
+		})
+	}
+}
+
+func fakeClientForGet() *fakeClient {
+	config1 := newFakeConfig()
+
+	cluster1 := newFakeCluster(cluster.Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"}, config1).
+		WithObjs(
+			&clusterv1.Cluster{
+				TypeMeta:   metav1.TypeMeta{APIVersion: clusterv1.GroupVersion.String(), Kind: "Cluster"},
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"},
+			},
+			&clusterv1.Cluster{
+				TypeMeta:   metav1.TypeMeta{APIVersion: clusterv1.GroupVersion.String(), Kind: "Cluster"},
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "bar"},
+			},
+		)
+
+	return newFakeClient(config1).
+		WithCluster(cluster1)
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+// GetKubeconfigOptions carries the options supported by GetKubeconfig.
+type GetKubeconfigOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// WorkloadClusterName is the name of the workload cluster.
+	WorkloadClusterName string
+
+	// Namespace where the workload cluster exists. If unspecified, the current namespace will be used.
+	Namespace string
+}
+
+func (c *clusterctlClient) GetKubeconfig(options GetKubeconfigOptions) (string, error) {
+	// Get the client for interacting with the management cluster.
+	clusterClient, err := c.clusterClientFactory(options.Kubeconfig)
+	if err != nil {
+		return "", err
+	}
+
+	// If the option specifying the Namespace is empty, try to detect it.
+	if options.Namespace == "" {
+		currentNamespace, err := clusterClient.Proxy().CurrentNamespace()
+		if err != nil {
+			return "", err
+		}
+		options.Namespace = currentNamespace
+	}
+
+	data, err := clusterClient.WorkloadCluster().GetKubeconfig(options.WorkloadClusterName, options.Namespace)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -137,6 +137,8 @@ type Repository interface {
 var _ Repository = &test.FakeRepository{}
 
 //repositoryFactory returns the repository implementation corresponding to the provider URL.
+// Remote repositories are wrapped by a cache, so the files read from the provider repository are stored on disk and
+// re-used by following clusterctl invocations.
 func repositoryFactory(providerConfig config.Provider, configVariablesClient config.VariablesClient) (Repository, error) {
 	// parse the repository url
 	rURL, err := url.Parse(providerConfig.URL())
@@ -146,13 +148,37 @@ func repositoryFactory(providerConfig config.Provider, configVariablesClient con
 
 	// if the url is a github repository
 	if rURL.Scheme == httpsScheme && rURL.Host == githubDomain {
-		repo, err := newGitHubRepository(providerConfig, configVariablesClient)
+		repo, err := newCachedRepository(providerConfig, configVariablesClient, func() (Repository, error) {
+			return newGitHubRepository(providerConfig, configVariablesClient)
+		})
 		if err != nil {
 			return nil, errors.Wrap(err, "error creating the GitHub repository client")
 		}
 		return repo, err
 	}
 
+	// if the url is a generic HTTP(S) repository
+	if rURL.Scheme == httpsScheme || rURL.Scheme == httpScheme {
+		repo, err := newCachedRepository(providerConfig, configVariablesClient, func() (Repository, error) {
+			return newHTTPRepository(providerConfig, configVariablesClient)
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating the HTTP repository client")
+		}
+		return repo, err
+	}
+
+	// if the url is an OCI registry repository
+	if rURL.Scheme == ociScheme {
+		repo, err := newCachedRepository(providerConfig, configVariablesClient, func() (Repository, error) {
+			return newOCIRepository(providerConfig, configVariablesClient)
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating the OCI repository client")
+		}
+		return repo, err
+	}
+
 	// if the url is a local filesystem repository
 	if rURL.Scheme == "file" || rURL.Scheme == "" {
 		repo, err := newLocalRepository(providerConfig, configVariablesClient)
@@ -71,3 +71,48 @@ func Test_newRepositoryClient_LocalFileSystemRepository(t *testing.T) {
 		})
 	}
 }
+
+func Test_newRepositoryClient_RemoteRepository(t *testing.T) {
+	tmpDir := createTempDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	configClient, err := config.New("", config.InjectReader(test.NewFakeReader().WithVar(cacheFolderKey, tmpDir)))
+	NewWithT(t).Expect(err).NotTo(HaveOccurred())
+
+	tests := []struct {
+		name     string
+		provider config.Provider
+		expected Repository
+	}{
+		{
+			name:     "successfully creates repository client with GitHub backend",
+			provider: config.NewProvider("foo", "https://github.com/o/r/releases/v1.0.0/bootstrap-components.yaml", clusterctlv1.BootstrapProviderType),
+			expected: &gitHubRepository{},
+		},
+		{
+			name:     "successfully creates repository client with HTTP backend",
+			provider: config.NewProvider("foo", "https://artifacts.example.com/bootstrap-foo/v1.0.0/bootstrap-components.yaml", clusterctlv1.BootstrapProviderType),
+			expected: &httpRepository{},
+		},
+		{
+			name:     "successfully creates repository client with OCI backend",
+			provider: config.NewProvider("foo", "oci://registry.example.com/bootstrap-foo:v1.0.0/bootstrap-components.yaml", clusterctlv1.BootstrapProviderType),
+			expected: &ociRepository{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			repoClient, err := newRepositoryClient(tt.provider, configClient)
+			g.Expect(err).NotTo(HaveOccurred())
+			// remote repositories are wrapped by the cache.
+			var cached *cachedRepository
+			g.Expect(repoClient.repository).To(BeAssignableToTypeOf(cached))
+
+			repository, err := repoClient.repository.(*cachedRepository).getRepository()
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(repository).To(BeAssignableToTypeOf(tt.expected))
+		})
+	}
+}
@@ -18,8 +18,6 @@ package repository
 
 import (
 	"fmt"
-	"regexp"
-	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -27,7 +25,6 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/util/sets"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
@@ -52,9 +49,6 @@ const (
 	namespaceArgPrefix      = "--namespace="
 )
 
-// variableRegEx defines the regexp used for searching variables inside a YAML
-var variableRegEx = regexp.MustCompile(`\${\s*([A-Z0-9_]+)\s*}`)
-
 // Components wraps a YAML file that defines the provider components
 // to be installed in a management cluster (CRD, Controller, RBAC etc.)
 // It is important to notice that clusterctl applies a set of processing steps to the “raw” component YAML read
@@ -75,6 +69,11 @@ type Components interface {
 	// This value is derived by the component YAML.
 	Variables() []string
 
+	// VariableDetails returns the variables used by the provider components, including info about
+	// required variables and default values.
+	// This value is derived by the component YAML.
+	VariableDetails() []Variable
+
 	// Images required to install the provider components.
 	// This value is derived by the component YAML.
 	Images() []string
@@ -108,6 +107,7 @@ type components struct {
 	config.Provider
 	version           string
 	variables         []string
+	variableDetails   []Variable
 	images            []string
 	targetNamespace   string
 	watchingNamespace string
@@ -126,6 +126,10 @@ func (c *components) Variables() []string {
 	return c.variables
 }
 
+func (c *components) VariableDetails() []Variable {
+	return c.variableDetails
+}
+
 func (c *components) Images() []string {
 	return c.images
 }
@@ -197,6 +201,7 @@ type ComponentsOptions struct {
 func NewComponents(provider config.Provider, configClient config.Client, rawyaml []byte, options ComponentsOptions) (*components, error) {
 	// Inspect the yaml read from the repository for variables.
 	variables := inspectVariables(rawyaml)
+	variableDetails := inspectVariableDetails(rawyaml)
 
 	// Replace variables with corresponding values read from the config
 	yaml, err := replaceVariables(rawyaml, variables, configClient.Variables(), options.SkipVariables)
@@ -293,6 +298,7 @@ func NewComponents(provider config.Provider, configClient config.Client, rawyaml
 		Provider:          provider,
 		version:           options.Version,
 		variables:         variables,
+		variableDetails:   variableDetails,
 		images:            images,
 		targetNamespace:   options.TargetNamespace,
 		watchingNamespace: options.WatchingNamespace,
@@ -333,41 +339,6 @@ func splitInstanceAndSharedResources(objs []unstructured.Unstructured) (instance
 	return
 }
 
-func inspectVariables(data []byte) []string {
-	variables := sets.NewString()
-	match := variableRegEx.FindAllStringSubmatch(string(data), -1)
-
-	for _, m := range match {
-		submatch := m[1]
-		if !variables.Has(submatch) {
-			variables.Insert(submatch)
-		}
-	}
-
-	ret := variables.List()
-	sort.Strings(ret)
-	return ret
-}
-
-func replaceVariables(yaml []byte, variables []string, configVariablesClient config.VariablesClient, skipVariables bool) ([]byte, error) {
-	tmp := string(yaml)
-	var missingVariables []string
-	for _, key := range variables {
-		val, err := configVariablesClient.Get(key)
-		if err != nil {
-			missingVariables = append(missingVariables, key)
-			continue
-		}
-		exp := regexp.MustCompile(`\$\{\s*` + regexp.QuoteMeta(key) + `\s*\}`)
-		tmp = exp.ReplaceAllLiteralString(tmp, val)
-	}
-	if !skipVariables && len(missingVariables) > 0 {
-		return nil, errors.Errorf("value for variables [%s] is not set. Please set the value using os environment variables or the clusterctl config file", strings.Join(missingVariables, ", "))
-	}
-
-	return []byte(tmp), nil
-}
-
 // inspectTargetNamespace identifies the name of the namespace object contained in the components YAML, if any.
 // In case more than one Namespace object is identified, an error is returned.
 func inspectTargetNamespace(objs []unstructured.Unstructured) (string, error) {
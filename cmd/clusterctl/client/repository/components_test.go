@@ -26,138 +26,8 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
-	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
 )
 
-func Test_inspectVariables(t *testing.T) {
-	type args struct {
-		data string
-	}
-	tests := []struct {
-		name string
-		args args
-		want []string
-	}{
-		{
-			name: "variable with different spacing around the name",
-			args: args{
-				data: "yaml with ${A} ${ B} ${ C} ${ D }",
-			},
-			want: []string{"A", "B", "C", "D"},
-		},
-		{
-			name: "variables used in many places are grouped",
-			args: args{
-				data: "yaml with ${A} ${A} ${A}",
-			},
-			want: []string{"A"},
-		},
-		{
-			name: "variables in multiline texts are processed",
-			args: args{
-				data: "yaml with ${A}\n${B}\n${C}",
-			},
-			want: []string{"A", "B", "C"},
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			g := NewWithT(t)
-
-			g.Expect(inspectVariables([]byte(tt.args.data))).To(Equal(tt.want))
-		})
-	}
-}
-
-func Test_replaceVariables(t *testing.T) {
-	type args struct {
-		yaml                  []byte
-		variables             []string
-		configVariablesClient config.VariablesClient
-		skipVariables         bool
-	}
-	tests := []struct {
-		name    string
-		args    args
-		want    []byte
-		wantErr bool
-	}{
-		{
-			name: "pass and replaces variables",
-			args: args{
-				yaml:      []byte("foo ${ BAR }"),
-				variables: []string{"BAR"},
-				configVariablesClient: test.NewFakeVariableClient().
-					WithVar("BAR", "bar"),
-				skipVariables: false,
-			},
-			want:    []byte("foo bar"),
-			wantErr: false,
-		},
-		{
-			name: "pass and replaces variables when variable name contains regex metacharacters",
-			args: args{
-				yaml:      []byte("foo ${ BA$R }"),
-				variables: []string{"BA$R"},
-				configVariablesClient: test.NewFakeVariableClient().
-					WithVar("BA$R", "bar"),
-				skipVariables: false,
-			},
-			want:    []byte("foo bar"),
-			wantErr: false,
-		},
-		{
-			name: "pass and replaces variables when variable value contains regex metacharacters",
-			args: args{
-				yaml:      []byte("foo ${ BAR }"),
-				variables: []string{"BAR"},
-				configVariablesClient: test.NewFakeVariableClient().
-					WithVar("BAR", "ba$r"),
-				skipVariables: false,
-			},
-			want:    []byte("foo ba$r"),
-			wantErr: false,
-		},
-		{
-			name: "fails for missing variables and not skip variables",
-			args: args{
-				yaml:                  []byte("foo ${ BAR } ${ BAZ }"),
-				variables:             []string{"BAR", "BAZ"},
-				configVariablesClient: test.NewFakeVariableClient(),
-				skipVariables:         false,
-			},
-			want:    nil,
-			wantErr: true,
-		},
-		{
-			name: "pass when missing variables and skip variables",
-			args: args{
-				yaml:      []byte("foo ${ BAR } ${ BAZ }"),
-				variables: []string{"BAR", "BAZ"},
-				configVariablesClient: test.NewFakeVariableClient().
-					WithVar("BAR", "bar"),
-				skipVariables: true,
-			},
-			want:    []byte("foo bar ${ BAZ }"),
-			wantErr: false,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			g := NewWithT(t)
-
-			got, err := replaceVariables(tt.args.yaml, tt.args.variables, tt.args.configVariablesClient, tt.args.skipVariables)
-			if tt.wantErr {
-				g.Expect(err).To(HaveOccurred())
-				return
-			}
-			g.Expect(err).NotTo(HaveOccurred())
-
-			g.Expect(got).To(Equal(tt.want))
-		})
-	}
-}
-
 func Test_inspectTargetNamespace(t *testing.T) {
 	type args struct {
 		objs []unstructured.Unstructured
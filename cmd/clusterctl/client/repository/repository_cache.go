@@ -0,0 +1,356 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/util/homedir"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	cacheFolder    = "cache"
+	cacheFolderKey = "cacheFolder"
+	cacheIndexFile = "index.yaml"
+
+	// defaultCacheTTL defines how long the version listings stored in the cache are considered valid.
+	defaultCacheTTL = 1 * time.Hour
+)
+
+// cacheIndex stores the information about a provider repository that can change over time (e.g. the list of versions),
+// and thus should be refreshed when the cache TTL expires.
+type cacheIndex struct {
+	// URL of the provider repository the index refers to; if the provider URL changes, the index is considered invalid.
+	URL string `json:"url"`
+
+	// DefaultVersion, RootPath and ComponentsPath are the values returned by the provider repository.
+	DefaultVersion string `json:"defaultVersion"`
+	RootPath       string `json:"rootPath"`
+	ComponentsPath string `json:"componentsPath"`
+
+	// Timestamp is the time when the values above were read from the provider repository.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Versions is the list of versions available in the provider repository, if already read.
+	Versions []string `json:"versions,omitempty"`
+
+	// VersionsTimestamp is the time when the list of versions was read from the provider repository.
+	VersionsTimestamp time.Time `json:"versionsTimestamp,omitempty"`
+}
+
+// cachedRepository implements Repository by storing the files read from a provider repository on disk, under the
+// clusterctl config folder; this allows to limit the number of calls to the provider repository, and to work offline.
+//
+// Files are stored using the following layout:
+// {cache-folder}/{provider-label}/{version}/{file}
+//
+// Files for a given version are assumed to be immutable, while the list of versions and the default version
+// (e.g. latest) are refreshed after the cache TTL expires.
+type cachedRepository struct {
+	providerConfig    config.Provider
+	repositoryFactory func() (Repository, error)
+	repository        Repository
+	path              string
+	ttl               time.Duration
+	offline           bool
+	index             *cacheIndex
+}
+
+var _ Repository = &cachedRepository{}
+
+// DefaultVersion returns the default version for the provider repository.
+func (c *cachedRepository) DefaultVersion() string {
+	return c.index.DefaultVersion
+}
+
+// RootPath returns the root path for the provider repository.
+func (c *cachedRepository) RootPath() string {
+	return c.index.RootPath
+}
+
+// ComponentsPath returns the path to the components file for the provider repository.
+func (c *cachedRepository) ComponentsPath() string {
+	return c.index.ComponentsPath
+}
+
+// GetFile returns a file for a given provider version, reading from the cache if the file was already downloaded.
+func (c *cachedRepository) GetFile(version, path string) ([]byte, error) {
+	log := logf.Log
+
+	var err error
+	if version == latestLabel {
+		version, err = c.getLatestRelease()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the latest release")
+		}
+	} else if version == "" {
+		version = c.DefaultVersion()
+	}
+
+	cachePath := filepath.Join(c.path, version, c.RootPath(), path)
+	if content, err := ioutil.ReadFile(cachePath); err == nil {
+		log.V(5).Info("Using", "Cache", path, "Provider", c.providerConfig.ManifestLabel(), "Version", version)
+		return content, nil
+	}
+
+	if c.offline {
+		return nil, errors.Errorf("failed to read file %q for version %s from the cache: the file is not available in offline mode", path, version)
+	}
+
+	repository, err := c.getRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := repository.GetFile(version, path)
+	if err != nil {
+		return nil, err
+	}
+
+	// NB. the cache is best-effort, so errors while writing to the cache should not block the operation.
+	if err := writeCacheFile(cachePath, content); err != nil {
+		log.V(5).Info("Failed to write file to the cache", "File", path, "Provider", c.providerConfig.ManifestLabel(), "Version", version, "Error", err.Error())
+	}
+	return content, nil
+}
+
+// GetVersions returns the list of versions that are available in the provider repository, reading from the
+// cache if the list was read less than the cache TTL ago.
+func (c *cachedRepository) GetVersions() ([]string, error) {
+	if c.index.Versions != nil && (c.offline || time.Since(c.index.VersionsTimestamp) < c.ttl) {
+		return c.index.Versions, nil
+	}
+
+	if c.offline {
+		return nil, errors.Errorf("failed to get the list of versions for provider %q from the cache: the list is not available in offline mode", c.providerConfig.ManifestLabel())
+	}
+
+	repository, err := c.getRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := repository.GetVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	c.index.Versions = versions
+	c.index.VersionsTimestamp = time.Now()
+	c.writeIndex()
+
+	return versions, nil
+}
+
+// newCachedRepository returns a cachedRepository wrapping the repository returned by the repositoryFactory function.
+// The wrapped repository is created only when it is necessary to read from the provider repository.
+func newCachedRepository(providerConfig config.Provider, configVariablesClient config.VariablesClient, repositoryFactory func() (Repository, error)) (*cachedRepository, error) {
+	if configVariablesClient == nil {
+		return nil, errors.New("invalid arguments: configVariablesClient can't be nil")
+	}
+
+	ttl, err := getCacheTTL(configVariablesClient)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &cachedRepository{
+		providerConfig:    providerConfig,
+		repositoryFactory: repositoryFactory,
+		path:              filepath.Join(CachePath(configVariablesClient), providerConfig.ManifestLabel()),
+		ttl:               ttl,
+		offline:           IsOffline(configVariablesClient),
+	}
+
+	// if there is a valid index, use it, otherwise read the required info from the provider repository.
+	c.index = c.readIndex()
+	if c.index != nil && (c.offline || time.Since(c.index.Timestamp) < c.ttl) {
+		return c, nil
+	}
+
+	if c.offline {
+		return nil, errors.Errorf("failed to get the repository for provider %q from the cache: the provider repository was never read, so it is not available in offline mode", providerConfig.ManifestLabel())
+	}
+
+	repository, err := c.getRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	// NB. the list of versions is preserved, and it is refreshed according to the TTL when required.
+	index := &cacheIndex{}
+	if c.index != nil {
+		index = c.index
+	}
+	index.URL = providerConfig.URL()
+	index.DefaultVersion = repository.DefaultVersion()
+	index.RootPath = repository.RootPath()
+	index.ComponentsPath = repository.ComponentsPath()
+	index.Timestamp = time.Now()
+	c.index = index
+	c.writeIndex()
+
+	return c, nil
+}
+
+// getRepository returns the wrapped repository, creating it if necessary.
+func (c *cachedRepository) getRepository() (Repository, error) {
+	if c.repository == nil {
+		repository, err := c.repositoryFactory()
+		if err != nil {
+			return nil, err
+		}
+		c.repository = repository
+	}
+	return c.repository, nil
+}
+
+// getLatestRelease returns the latest release for the provider repository, according to
+// semantic version order.
+func (c *cachedRepository) getLatestRelease() (string, error) {
+	versions, err := c.GetVersions()
+	if err != nil {
+		return "", err
+	}
+	return latestSemanticVersion(versions)
+}
+
+// readIndex returns the cache index for the provider, if it exists and it refers to the current provider URL.
+func (c *cachedRepository) readIndex() *cacheIndex {
+	content, err := ioutil.ReadFile(filepath.Join(c.path, cacheIndexFile))
+	if err != nil {
+		return nil
+	}
+
+	index := &cacheIndex{}
+	if err := yaml.Unmarshal(content, index); err != nil {
+		return nil
+	}
+	if index.URL != c.providerConfig.URL() {
+		return nil
+	}
+	return index
+}
+
+// writeIndex writes the cache index for the provider.
+func (c *cachedRepository) writeIndex() {
+	log := logf.Log
+
+	content, err := yaml.Marshal(c.index)
+	if err == nil {
+		err = writeCacheFile(filepath.Join(c.path, cacheIndexFile), content)
+	}
+	if err != nil {
+		log.V(5).Info("Failed to write the cache index", "Provider", c.providerConfig.ManifestLabel(), "Error", err.Error())
+	}
+}
+
+// writeCacheFile writes a file to the cache, creating the parent folders if required.
+func writeCacheFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrapf(err, "failed to create cache folder %q", filepath.Dir(path))
+	}
+	if err := ioutil.WriteFile(path, content, 0600); err != nil {
+		return errors.Wrapf(err, "failed to write cache file %q", path)
+	}
+	return nil
+}
+
+// getCacheTTL returns the cache TTL defined in the clusterctl config, if any, or the default cache TTL.
+func getCacheTTL(configVariablesClient config.VariablesClient) (time.Duration, error) {
+	ttl, err := configVariablesClient.Get(config.CacheTTLVariable)
+	if err != nil || strings.TrimSpace(ttl) == "" {
+		return defaultCacheTTL, nil
+	}
+	d, err := time.ParseDuration(ttl)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid %s value %q", config.CacheTTLVariable, ttl)
+	}
+	return d, nil
+}
+
+// CachePath returns the path to the folder where clusterctl caches the files read from the provider repositories.
+func CachePath(configVariablesClient config.VariablesClient) string {
+	f, err := configVariablesClient.Get(cacheFolderKey)
+	if err == nil && len(strings.TrimSpace(f)) != 0 {
+		return f
+	}
+	return filepath.Join(homedir.HomeDir(), config.ConfigFolder, cacheFolder)
+}
+
+// IsOffline returns true if clusterctl should read only from the cache.
+func IsOffline(configVariablesClient config.VariablesClient) bool {
+	v, err := configVariablesClient.Get(config.OfflineVariable)
+	if err != nil {
+		return false
+	}
+	offline, err := strconv.ParseBool(v)
+	return err == nil && offline
+}
+
+// PruneCache removes the files in the cache older than the given age; if age is zero, all the files are removed.
+func PruneCache(configVariablesClient config.VariablesClient, age time.Duration) error {
+	path := CachePath(configVariablesClient)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+
+	if age == 0 {
+		if err := os.RemoveAll(path); err != nil {
+			return errors.Wrapf(err, "failed to remove cache folder %q", path)
+		}
+		return nil
+	}
+
+	threshold := time.Now().Add(-age)
+	var dirs []string
+	err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			dirs = append(dirs, p)
+			return nil
+		}
+		if info.ModTime().Before(threshold) {
+			return os.Remove(p)
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to prune cache folder %q", path)
+	}
+
+	// remove empty folders, starting from the deepest ones; the cache folder itself is preserved.
+	for i := len(dirs) - 1; i > 0; i-- {
+		if files, err := ioutil.ReadDir(dirs[i]); err == nil && len(files) == 0 {
+			if err := os.Remove(dirs[i]); err != nil {
+				return errors.Wrapf(err, "failed to remove cache folder %q", dirs[i])
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+// countingRepository wraps a FakeRepository counting the number of calls to the repository.
+type countingRepository struct {
+	*test.FakeRepository
+	getFileCalls     int
+	getVersionsCalls int
+}
+
+func (r *countingRepository) GetFile(version, path string) ([]byte, error) {
+	r.getFileCalls++
+	return r.FakeRepository.GetFile(version, path)
+}
+
+func (r *countingRepository) GetVersions() ([]string, error) {
+	r.getVersionsCalls++
+	return r.FakeRepository.GetVersions()
+}
+
+func Test_cachedRepository(t *testing.T) {
+	g := NewWithT(t)
+
+	tmpDir := createTempDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	providerConfig := config.NewProvider("foo", "https://github.com/o/r/releases/latest/infrastructure-components.yaml", clusterctlv1.InfrastructureProviderType)
+
+	repository := &countingRepository{
+		FakeRepository: test.NewFakeRepository().
+			WithPaths(".", "infrastructure-components.yaml").
+			WithDefaultVersion("v1.0.0").
+			WithFile("v1.0.0", "infrastructure-components.yaml", []byte("content")).
+			WithVersions("v1.0.0", "v0.9.0"),
+	}
+	factoryCalls := 0
+	factory := func() (Repository, error) {
+		factoryCalls++
+		return repository, nil
+	}
+
+	online := test.NewFakeVariableClient().WithVar(cacheFolderKey, tmpDir)
+	offline := test.NewFakeVariableClient().WithVar(cacheFolderKey, tmpDir).WithVar(config.OfflineVariable, "true")
+
+	// in offline mode, it fails if the provider repository was never read.
+	_, err := newCachedRepository(providerConfig, offline, factory)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(factoryCalls).To(Equal(0))
+
+	// reads from the provider repository, and stores the files into the cache.
+	cached, err := newCachedRepository(providerConfig, online, factory)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cached.DefaultVersion()).To(Equal("v1.0.0"))
+	g.Expect(cached.ComponentsPath()).To(Equal("infrastructure-components.yaml"))
+
+	content, err := cached.GetFile("", "infrastructure-components.yaml")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(content)).To(Equal("content"))
+	g.Expect(repository.getFileCalls).To(Equal(1))
+	g.Expect(filepath.Join(tmpDir, "infrastructure-foo", "v1.0.0", "infrastructure-components.yaml")).To(BeARegularFile())
+
+	versions, err := cached.GetVersions()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(versions).To(ConsistOf("v1.0.0", "v0.9.0"))
+	g.Expect(repository.getVersionsCalls).To(Equal(1))
+
+	// reads from the cache, without accessing the provider repository.
+	cached, err = newCachedRepository(providerConfig, online, factory)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cached.DefaultVersion()).To(Equal("v1.0.0"))
+
+	content, err = cached.GetFile("v1.0.0", "infrastructure-components.yaml")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(content)).To(Equal("content"))
+
+	versions, err = cached.GetVersions()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(versions).To(ConsistOf("v1.0.0", "v0.9.0"))
+
+	g.Expect(factoryCalls).To(Equal(1))
+	g.Expect(repository.getFileCalls).To(Equal(1))
+	g.Expect(repository.getVersionsCalls).To(Equal(1))
+
+	// in offline mode, reads from the cache and fails for files not in the cache.
+	cached, err = newCachedRepository(providerConfig, offline, factory)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	content, err = cached.GetFile("latest", "infrastructure-components.yaml")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(content)).To(Equal("content"))
+
+	_, err = cached.GetFile("v0.9.0", "infrastructure-components.yaml")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(factoryCalls).To(Equal(1))
+
+	// when the TTL expires, the version listing is read again from the provider repository.
+	expired := test.NewFakeVariableClient().WithVar(cacheFolderKey, tmpDir).WithVar(config.CacheTTLVariable, "0s")
+	cached, err = newCachedRepository(providerConfig, expired, factory)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	_, err = cached.GetVersions()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(factoryCalls).To(Equal(2))
+	g.Expect(repository.getVersionsCalls).To(Equal(2))
+
+	// when the provider URL changes, the cache index is invalidated.
+	changedProviderConfig := config.NewProvider("foo", "https://github.com/o/r2/releases/latest/infrastructure-components.yaml", clusterctlv1.InfrastructureProviderType)
+	_, err = newCachedRepository(changedProviderConfig, offline, factory)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func Test_PruneCache(t *testing.T) {
+	g := NewWithT(t)
+
+	tmpDir := createTempDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	oldFile := createLocalTestProviderFile(t, tmpDir, "infrastructure-foo/v1.0.0/infrastructure-components.yaml", "")
+	newFile := createLocalTestProviderFile(t, tmpDir, "infrastructure-foo/v2.0.0/infrastructure-components.yaml", "")
+	oldTime := time.Now().Add(-48 * time.Hour)
+	g.Expect(os.Chtimes(oldFile, oldTime, oldTime)).To(Succeed())
+
+	variableClient := test.NewFakeVariableClient().WithVar(cacheFolderKey, tmpDir)
+
+	// removes only the old files, and the folders left empty.
+	g.Expect(PruneCache(variableClient, 24*time.Hour)).To(Succeed())
+	g.Expect(oldFile).NotTo(BeAnExistingFile())
+	g.Expect(filepath.Dir(oldFile)).NotTo(BeADirectory())
+	g.Expect(newFile).To(BeARegularFile())
+
+	// removes all the files.
+	g.Expect(PruneCache(variableClient, 0)).To(Succeed())
+	g.Expect(tmpDir).NotTo(BeADirectory())
+
+	// does not fail if the cache does not exist.
+	g.Expect(PruneCache(variableClient, 0)).To(Succeed())
+}
@@ -0,0 +1,313 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+const (
+	httpScheme   = "http"
+	latestLabel  = "latest"
+	versionIndex = "versions"
+
+	// httpClientTimeout is the timeout of the requests to HTTP servers and OCI registries, so clusterctl
+	// does not hang when a server stalls.
+	httpClientTimeout = 30 * time.Second
+)
+
+var (
+	// hrefRegEx matches the links contained in a directory listing page, as generated by most
+	// HTTP file servers (e.g. nginx, Apache or Go http.FileServer).
+	hrefRegEx = regexp.MustCompile(`(?i)href="([^"]+)"`)
+
+	// defaultHTTPClient is the client used for reading from HTTP servers and OCI registries.
+	defaultHTTPClient = &http.Client{Timeout: httpClientTimeout}
+)
+
+// httpRepository provides support for providers hosted on a generic HTTP(S) server, e.g. an internal artifact server.
+// As part of the provider object, the URL is expected to contain the URL of the components yaml on the server.
+// To support different versions, the files must adhere to the following layout:
+// {scheme}://{host}/{basepath}/{version}/{components.yaml}
+//
+// (1): {version} must obey the syntax and semantics of the "Semantic Versioning"
+// specification (http://semver.org/); however, "latest" is also an acceptable value.
+//
+// The list of available versions is read from a plain text file named "versions" stored under {basepath}, with one
+// version per line; if such file does not exists, the list of versions is read from the directory listing of {basepath}.
+//
+// Concrete example:
+// https://artifacts.example.com/capi/infrastructure-aws/v0.5.2/infrastructure-components.yaml
+// basepath: capi/infrastructure-aws
+// version: v0.5.2
+// components.yaml: infrastructure-components.yaml
+type httpRepository struct {
+	providerConfig        config.Provider
+	configVariablesClient config.VariablesClient
+	baseURL               *url.URL
+	defaultVersion        string
+	componentsPath        string
+	username              string
+	password              string
+	token                 string
+	injectClient          *http.Client
+}
+
+var _ Repository = &httpRepository{}
+
+// DefaultVersion returns the default version for the HTTP repository.
+func (h *httpRepository) DefaultVersion() string {
+	return h.defaultVersion
+}
+
+// RootPath returns the empty string as it is not applicable to HTTP repositories.
+func (h *httpRepository) RootPath() string {
+	return ""
+}
+
+// ComponentsPath returns the path to the components file for the HTTP repository.
+func (h *httpRepository) ComponentsPath() string {
+	return h.componentsPath
+}
+
+// GetFile returns a file for a given provider version.
+func (h *httpRepository) GetFile(version, fileName string) ([]byte, error) {
+	var err error
+
+	if version == latestLabel {
+		version, err = h.getLatestRelease()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the latest release")
+		}
+	} else if version == "" {
+		version = h.defaultVersion
+	}
+
+	content, err := h.get(h.resolve(version, fileName))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read file %q from release %s", fileName, version)
+	}
+	return content, nil
+}
+
+// GetVersions returns the list of versions that are available for a HTTP repository.
+func (h *httpRepository) GetVersions() ([]string, error) {
+	candidates, err := h.listVersions()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get repository versions")
+	}
+
+	versions := []string{}
+	for _, c := range candidates {
+		if _, err := version.ParseSemantic(c); err != nil {
+			// discard releases with tags that are not a valid semantic versions (the user can point explicitly to such releases)
+			continue
+		}
+		versions = append(versions, c)
+	}
+	return versions, nil
+}
+
+// newHTTPRepository returns a new httpRepository.
+func newHTTPRepository(providerConfig config.Provider, configVariablesClient config.VariablesClient) (*httpRepository, error) {
+	if configVariablesClient == nil {
+		return nil, errors.New("invalid arguments: configVariablesClient can't be nil")
+	}
+
+	rURL, err := url.Parse(providerConfig.URL())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+
+	if rURL.Scheme != httpsScheme && rURL.Scheme != httpScheme {
+		return nil, errors.New("invalid url: a HTTP repository url should start with http:// or https://")
+	}
+
+	// Extracts version and componentsPath from the url
+	// NB. format is {basepath}/{version}/{components.yaml}
+	urlSplit := strings.Split(strings.Trim(rURL.Path, "/"), "/")
+	if len(urlSplit) < 2 {
+		return nil, errors.New("invalid url: a HTTP repository url should be in the form {scheme}://{host}/{basepath}/{version}/{components.yaml}")
+	}
+
+	componentsPath := urlSplit[len(urlSplit)-1]
+	defaultVersion := urlSplit[len(urlSplit)-2]
+	if defaultVersion != latestLabel {
+		if _, err := version.ParseSemantic(defaultVersion); err != nil {
+			return nil, errors.Errorf("invalid version: %q. Version must obey the syntax and semantics of the \"Semantic Versioning\" specification (http://semver.org/) and path format {basepath}/{version}/{components.yaml}", defaultVersion)
+		}
+	}
+
+	baseURL := *rURL
+	baseURL.Path = "/" + strings.Join(urlSplit[:len(urlSplit)-2], "/")
+	baseURL.RawPath = ""
+	baseURL.RawQuery = ""
+	baseURL.Fragment = ""
+
+	repo := &httpRepository{
+		providerConfig:        providerConfig,
+		configVariablesClient: configVariablesClient,
+		baseURL:               &baseURL,
+		defaultVersion:        defaultVersion,
+		componentsPath:        componentsPath,
+	}
+
+	// Credentials embedded in the url takes precedence over the ones defined in the clusterctl config.
+	if rURL.User != nil {
+		repo.username = rURL.User.Username()
+		repo.password, _ = rURL.User.Password()
+		repo.baseURL.User = nil
+	} else {
+		if username, err := configVariablesClient.Get(config.HTTPRepositoryUsernameVariable); err == nil {
+			repo.username = username
+		}
+		if password, err := configVariablesClient.Get(config.HTTPRepositoryPasswordVariable); err == nil {
+			repo.password = password
+		}
+	}
+	if token, err := configVariablesClient.Get(config.HTTPRepositoryTokenVariable); err == nil {
+		repo.token = token
+	}
+
+	if defaultVersion == latestLabel {
+		repo.defaultVersion, err = repo.getLatestRelease()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get latest version")
+		}
+	}
+	return repo, nil
+}
+
+// getClient returns the HTTP client to be used for reading from the repository.
+func (h *httpRepository) getClient() *http.Client {
+	if h.injectClient != nil {
+		return h.injectClient
+	}
+	return defaultHTTPClient
+}
+
+// resolve returns the URL of a path relative to the repository base path.
+func (h *httpRepository) resolve(elem ...string) string {
+	u := *h.baseURL
+	u.Path = path.Join(append([]string{u.Path}, elem...)...)
+	return u.String()
+}
+
+// get reads the content at the given URL, applying credentials if defined.
+func (h *httpRepository) get(rawURL string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create request for %q", rawURL)
+	}
+	switch {
+	case h.token != "":
+		req.Header.Set("Authorization", "Bearer "+h.token)
+	case h.username != "":
+		req.SetBasicAuth(h.username, h.password)
+	}
+
+	resp, err := h.getClient().Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get %q", rawURL)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to get %q: server returned %s", rawURL, resp.Status)
+	}
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read response from %q", rawURL)
+	}
+	return content, nil
+}
+
+// listVersions returns the list of candidate versions, reading them from the versions index file if available, or
+// from the directory listing of the repository base path otherwise.
+func (h *httpRepository) listVersions() ([]string, error) {
+	if content, err := h.get(h.resolve(versionIndex)); err == nil {
+		versions := []string{}
+		for _, l := range strings.Split(string(content), "\n") {
+			if l = strings.TrimSpace(l); l != "" && !strings.HasPrefix(l, "#") {
+				versions = append(versions, l)
+			}
+		}
+		return versions, nil
+	}
+
+	content, err := h.get(strings.TrimSuffix(h.resolve(), "/") + "/")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list release directories")
+	}
+
+	versions := []string{}
+	for _, m := range hrefRegEx.FindAllStringSubmatch(string(content), -1) {
+		href := m[1]
+		// only consider links to direct sub-folders.
+		if !strings.HasSuffix(href, "/") {
+			continue
+		}
+		href = strings.TrimSuffix(href, "/")
+		if u, err := url.Parse(href); err == nil {
+			href = u.Path
+		}
+		versions = append(versions, path.Base(href))
+	}
+	return versions, nil
+}
+
+// getLatestRelease returns the latest release for the HTTP repository, according to
+// semantic version order of the version folders.
+func (h *httpRepository) getLatestRelease() (string, error) {
+	versions, err := h.GetVersions()
+	if err != nil {
+		return "", err
+	}
+	return latestSemanticVersion(versions)
+}
+
+// latestSemanticVersion returns the latest version in a list, according to semantic version ordering.
+// Versions that are not in semver format are ignored.
+func latestSemanticVersion(versions []string) (string, error) {
+	var latestTag string
+	var latestReleaseVersion *version.Version
+	for _, v := range versions {
+		sv, err := version.ParseSemantic(v)
+		if err != nil {
+			continue
+		}
+		if latestReleaseVersion == nil || latestReleaseVersion.LessThan(sv) {
+			latestTag = v
+			latestReleaseVersion = sv
+		}
+	}
+	if latestTag == "" {
+		return "", errors.New("failed to find releases tagged with a valid semantic version number")
+	}
+	return latestTag, nil
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_httpRepository_newHTTPRepository(t *testing.T) {
+	tests := []struct {
+		name               string
+		url                string
+		wantBasePath       string
+		wantVersion        string
+		wantComponentsPath string
+		wantErr            bool
+	}{
+		{
+			name:               "can create a new HTTP repository",
+			url:                "https://artifacts.example.com/capi/infrastructure-aws/v0.5.2/infrastructure-components.yaml",
+			wantBasePath:       "https://artifacts.example.com/capi/infrastructure-aws",
+			wantVersion:        "v0.5.2",
+			wantComponentsPath: "infrastructure-components.yaml",
+			wantErr:            false,
+		},
+		{
+			name:               "can create a new HTTP repository with an empty base path",
+			url:                "http://artifacts.example.com/v0.5.2/infrastructure-components.yaml",
+			wantBasePath:       "http://artifacts.example.com/",
+			wantVersion:        "v0.5.2",
+			wantComponentsPath: "infrastructure-components.yaml",
+			wantErr:            false,
+		},
+		{
+			name:    "fails if the version is not a semantic version",
+			url:     "https://artifacts.example.com/capi/infrastructure-aws/foo/infrastructure-components.yaml",
+			wantErr: true,
+		},
+		{
+			name:    "fails if the url does not contain a version",
+			url:     "https://artifacts.example.com/infrastructure-components.yaml",
+			wantErr: true,
+		},
+		{
+			name:    "fails if the url is not http",
+			url:     "ftp://artifacts.example.com/capi/v0.5.2/infrastructure-components.yaml",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			providerConfig := config.NewProvider("test", tt.url, clusterctlv1.CoreProviderType)
+			repo, err := newHTTPRepository(providerConfig, test.NewFakeVariableClient())
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(repo.baseURL.String()).To(Equal(tt.wantBasePath))
+			g.Expect(repo.DefaultVersion()).To(Equal(tt.wantVersion))
+			g.Expect(repo.ComponentsPath()).To(Equal(tt.wantComponentsPath))
+		})
+	}
+}
+
+func Test_httpRepository_GetFile(t *testing.T) {
+	tmpDir := createTempDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	createLocalTestProviderFile(t, tmpDir, "infrastructure-foo/v1.0.0/infrastructure-components.yaml", "content")
+
+	// serves the local folder as a protected HTTP repository
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.FileServer(http.Dir(tmpDir)).ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	providerConfig := config.NewProvider("foo", server.URL+"/infrastructure-foo/v1.0.0/infrastructure-components.yaml", clusterctlv1.InfrastructureProviderType)
+
+	tests := []struct {
+		name           string
+		variableClient config.VariablesClient
+		version        string
+		fileName       string
+		want           string
+		wantErr        bool
+	}{
+		{
+			name:           "Get file",
+			variableClient: test.NewFakeVariableClient().WithVar(config.HTTPRepositoryUsernameVariable, "user").WithVar(config.HTTPRepositoryPasswordVariable, "pass"),
+			version:        "v1.0.0",
+			fileName:       "infrastructure-components.yaml",
+			want:           "content",
+			wantErr:        false,
+		},
+		{
+			name:           "Get file from the default version",
+			variableClient: test.NewFakeVariableClient().WithVar(config.HTTPRepositoryUsernameVariable, "user").WithVar(config.HTTPRepositoryPasswordVariable, "pass"),
+			version:        "",
+			fileName:       "infrastructure-components.yaml",
+			want:           "content",
+			wantErr:        false,
+		},
+		{
+			name:           "Fails if file does not exist",
+			variableClient: test.NewFakeVariableClient().WithVar(config.HTTPRepositoryUsernameVariable, "user").WithVar(config.HTTPRepositoryPasswordVariable, "pass"),
+			version:        "v1.0.0",
+			fileName:       "404.yaml",
+			wantErr:        true,
+		},
+		{
+			name:           "Fails if credentials are not valid",
+			variableClient: test.NewFakeVariableClient().WithVar(config.HTTPRepositoryUsernameVariable, "user").WithVar(config.HTTPRepositoryPasswordVariable, "wrong"),
+			version:        "v1.0.0",
+			fileName:       "infrastructure-components.yaml",
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			repo, err := newHTTPRepository(providerConfig, tt.variableClient)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			got, err := repo.GetFile(tt.version, tt.fileName)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(string(got)).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_httpRepository_GetVersions(t *testing.T) {
+	tmpDir := createTempDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	createLocalTestProviderFile(t, tmpDir, "infrastructure-foo/v1.0.0/infrastructure-components.yaml", "")
+	createLocalTestProviderFile(t, tmpDir, "infrastructure-foo/v1.1.0-alpha.0/infrastructure-components.yaml", "")
+	createLocalTestProviderFile(t, tmpDir, "infrastructure-foo/foo/infrastructure-components.yaml", "")
+	createLocalTestProviderFile(t, tmpDir, "infrastructure-foo/v2.0.0.yaml", "")
+
+	// serves the local folder as an HTTP repository with directory listing
+	dirListing := httptest.NewServer(http.FileServer(http.Dir(tmpDir)))
+	defer dirListing.Close()
+
+	// serves an HTTP repository with a versions index file
+	versionIndex := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/infrastructure-foo/versions" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, "# versions\nv1.0.0\nv1.2.0\n\nfoo\n")
+	}))
+	defer versionIndex.Close()
+
+	tests := []struct {
+		name           string
+		url            string
+		variableClient config.VariablesClient
+		want           []string
+		wantLatest     string
+		wantErr        bool
+	}{
+		{
+			name:           "Get versions from the directory listing",
+			url:            dirListing.URL + "/infrastructure-foo/latest/infrastructure-components.yaml",
+			variableClient: test.NewFakeVariableClient(),
+			want:           []string{"v1.0.0", "v1.1.0-alpha.0"},
+			wantLatest:     "v1.1.0-alpha.0",
+			wantErr:        false,
+		},
+		{
+			name:           "Get versions from the versions index",
+			url:            versionIndex.URL + "/infrastructure-foo/latest/infrastructure-components.yaml",
+			variableClient: test.NewFakeVariableClient().WithVar(config.HTTPRepositoryTokenVariable, "token"),
+			want:           []string{"v1.0.0", "v1.2.0"},
+			wantLatest:     "v1.2.0",
+			wantErr:        false,
+		},
+		{
+			name:           "Fails if the versions can not be read",
+			url:            versionIndex.URL + "/infrastructure-foo/latest/infrastructure-components.yaml",
+			variableClient: test.NewFakeVariableClient(),
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			providerConfig := config.NewProvider("foo", tt.url, clusterctlv1.InfrastructureProviderType)
+			repo, err := newHTTPRepository(providerConfig, tt.variableClient)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(repo.DefaultVersion()).To(Equal(tt.wantLatest))
+
+			got, err := repo.GetVersions()
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(ConsistOf(tt.want))
+		})
+	}
+}
@@ -0,0 +1,480 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+const (
+	ociScheme = "oci"
+
+	// ociTitleAnnotation is the annotation used for storing the file name of an artifact layer.
+	ociTitleAnnotation = "org.opencontainers.image.title"
+
+	ociManifestMediaType       = "application/vnd.oci.image.manifest.v1+json"
+	dockerManifestMediaType    = "application/vnd.docker.distribution.manifest.v2+json"
+	ociAuthenticateHeader      = "Www-Authenticate"
+	ociLinkHeader              = "Link"
+	ociBearerChallengePrefix   = "bearer "
+	ociRepositoryPathSeparator = ":"
+)
+
+var (
+	// Caches used to limit the number of calls to the registry
+
+	cacheOCIVersions  = map[string][]string{}
+	cacheOCIManifests = map[string]*ociManifest{}
+	cacheOCIFiles     = map[string][]byte{}
+
+	// challengeParamRegEx matches the key="value" pairs of an authentication challenge; values can contain commas
+	// e.g. scope="repository:capi/infrastructure-aws:pull,push".
+	challengeParamRegEx = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+	// nextLinkRegEx matches the URL of the next page in a Link header returned by a paginated registry API,
+	// e.g. </v2/capi/infrastructure-aws/tags/list?last=v0.5.2&n=100>; rel="next".
+	nextLinkRegEx = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="?next"?`)
+)
+
+// ociRepository provides support for providers hosted in an OCI registry.
+//
+// Each provider version is expected to be published as an OCI artifact tagged with the version number,
+// having one layer for each file; the layer must be annotated with the file name using the
+// "org.opencontainers.image.title" annotation, as done by tools like oras (https://github.com/deislabs/oras).
+// The URL is expected to be in the following format:
+// oci://{registry}/{repository}:{version}/{components.yaml}
+//
+// (1): {version} must obey the syntax and semantics of the "Semantic Versioning"
+// specification (http://semver.org/); however, "latest" is also an acceptable value.
+//
+// Concrete example:
+// oci://registry.example.com/capi/infrastructure-aws:v0.5.2/infrastructure-components.yaml
+// registry: registry.example.com
+// repository: capi/infrastructure-aws
+// version: v0.5.2
+// components.yaml: infrastructure-components.yaml
+type ociRepository struct {
+	providerConfig        config.Provider
+	configVariablesClient config.VariablesClient
+	registryURL           *url.URL
+	repository            string
+	defaultVersion        string
+	componentsPath        string
+	username              string
+	password              string
+	token                 string
+	injectClient          *http.Client
+}
+
+var _ Repository = &ociRepository{}
+
+// ociManifest defines the subset of an OCI image manifest used by clusterctl.
+type ociManifest struct {
+	Layers []ociDescriptor `json:"layers"`
+}
+
+// ociDescriptor defines the subset of an OCI content descriptor used by clusterctl.
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ociTagList defines the response of the registry tag list API.
+type ociTagList struct {
+	Tags []string `json:"tags"`
+}
+
+// ociToken defines the response of a registry token server.
+type ociToken struct {
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
+}
+
+// DefaultVersion returns the default version for the OCI repository.
+func (o *ociRepository) DefaultVersion() string {
+	return o.defaultVersion
+}
+
+// RootPath returns the empty string as it is not applicable to OCI repositories.
+func (o *ociRepository) RootPath() string {
+	return ""
+}
+
+// ComponentsPath returns the path to the components file for the OCI repository.
+func (o *ociRepository) ComponentsPath() string {
+	return o.componentsPath
+}
+
+// GetFile returns a file for a given provider version.
+func (o *ociRepository) GetFile(version, fileName string) ([]byte, error) {
+	var err error
+
+	if version == latestLabel {
+		version, err = o.getLatestRelease()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the latest release")
+		}
+	} else if version == "" {
+		version = o.defaultVersion
+	}
+
+	cacheID := fmt.Sprintf("%s/%s:%s:%s", o.registryURL.Host, o.repository, version, fileName)
+	if content, ok := cacheOCIFiles[cacheID]; ok {
+		return content, nil
+	}
+
+	manifest, err := o.getManifest(version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get OCI artifact %s", version)
+	}
+
+	// search for the file into the artifact layers, retrieving the layer digest
+	var digest string
+	for _, l := range manifest.Layers {
+		if l.Annotations[ociTitleAnnotation] == fileName {
+			digest = l.Digest
+			break
+		}
+	}
+	if digest == "" {
+		return nil, errors.Errorf("failed to get file %q from %q OCI artifact", fileName, version)
+	}
+
+	content, err := o.get(o.resolve("blobs", digest), "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download file %q from %q OCI artifact", fileName, version)
+	}
+
+	cacheOCIFiles[cacheID] = content
+	return content, nil
+}
+
+// GetVersions returns the list of versions that are available for an OCI repository.
+func (o *ociRepository) GetVersions() ([]string, error) {
+	cacheID := fmt.Sprintf("%s/%s", o.registryURL.Host, o.repository)
+	if versions, ok := cacheOCIVersions[cacheID]; ok {
+		return versions, nil
+	}
+
+	// Read all the pages of the tag list, following the Link headers returned by the registry.
+	tags := []string{}
+	visited := map[string]bool{}
+	for next := o.resolve("tags", "list"); next != "" && !visited[next]; {
+		visited[next] = true
+
+		content, header, err := o.getWithHeader(next, "")
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get the list of tags")
+		}
+
+		tagList := &ociTagList{}
+		if err := json.Unmarshal(content, tagList); err != nil {
+			return nil, errors.Wrap(err, "failed to parse the list of tags")
+		}
+		tags = append(tags, tagList.Tags...)
+
+		next, err = o.nextPage(header)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	versions := []string{}
+	for _, t := range tags {
+		if _, err := version.ParseSemantic(t); err != nil {
+			// Discard tags that are not a valid semantic versions (the user can point explicitly to such tags).
+			continue
+		}
+		versions = append(versions, t)
+	}
+
+	cacheOCIVersions[cacheID] = versions
+	return versions, nil
+}
+
+// newOCIRepository returns a new ociRepository.
+func newOCIRepository(providerConfig config.Provider, configVariablesClient config.VariablesClient) (*ociRepository, error) {
+	if configVariablesClient == nil {
+		return nil, errors.New("invalid arguments: configVariablesClient can't be nil")
+	}
+
+	rURL, err := url.Parse(providerConfig.URL())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid url")
+	}
+
+	if rURL.Scheme != ociScheme || rURL.Host == "" {
+		return nil, errors.New("invalid url: an OCI repository url should start with oci://{registry}")
+	}
+
+	// Extracts repository, version and componentsPath from the url
+	// NB. format is {repository}:{version}/{components.yaml}
+	repoAndVersion, componentsPath := splitLastPathElement(strings.Trim(rURL.Path, "/"))
+	i := strings.LastIndex(repoAndVersion, ociRepositoryPathSeparator)
+	if componentsPath == "" || i <= 0 || i == len(repoAndVersion)-1 {
+		return nil, errors.New("invalid url: an OCI repository url should be in the form oci://{registry}/{repository}:{version}/{components.yaml}")
+	}
+	repository := repoAndVersion[:i]
+	defaultVersion := repoAndVersion[i+1:]
+	if defaultVersion != latestLabel {
+		if _, err := version.ParseSemantic(defaultVersion); err != nil {
+			return nil, errors.Errorf("invalid version: %q. Version must obey the syntax and semantics of the \"Semantic Versioning\" specification (http://semver.org/) and path format oci://{registry}/{repository}:{version}/{components.yaml}", defaultVersion)
+		}
+	}
+
+	// Registries running on the local host are accessed using plain HTTP, all the other registries using HTTPS.
+	registryURL := &url.URL{Scheme: httpsScheme, Host: rURL.Host}
+	if isLocalHost(rURL.Hostname()) {
+		registryURL.Scheme = httpScheme
+	}
+
+	repo := &ociRepository{
+		providerConfig:        providerConfig,
+		configVariablesClient: configVariablesClient,
+		registryURL:           registryURL,
+		repository:            repository,
+		defaultVersion:        defaultVersion,
+		componentsPath:        componentsPath,
+	}
+
+	if username, err := configVariablesClient.Get(config.OCIRepositoryUsernameVariable); err == nil {
+		repo.username = username
+	}
+	if password, err := configVariablesClient.Get(config.OCIRepositoryPasswordVariable); err == nil {
+		repo.password = password
+	}
+	if token, err := configVariablesClient.Get(config.OCIRepositoryTokenVariable); err == nil {
+		repo.token = token
+	}
+
+	if defaultVersion == latestLabel {
+		repo.defaultVersion, err = repo.getLatestRelease()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get latest version")
+		}
+	}
+	return repo, nil
+}
+
+// splitLastPathElement splits a path into everything before the last "/" and the last element.
+func splitLastPathElement(p string) (string, string) {
+	i := strings.LastIndex(p, "/")
+	if i < 0 {
+		return p, ""
+	}
+	return p[:i], p[i+1:]
+}
+
+// isLocalHost returns true if the host is the local host.
+func isLocalHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// getClient returns the HTTP client to be used for reading from the registry.
+func (o *ociRepository) getClient() *http.Client {
+	if o.injectClient != nil {
+		return o.injectClient
+	}
+	return defaultHTTPClient
+}
+
+// resolve returns the URL of a registry API endpoint for the repository.
+func (o *ociRepository) resolve(elem ...string) string {
+	u := *o.registryURL
+	u.Path = "/v2/" + o.repository + "/" + strings.Join(elem, "/")
+	return u.String()
+}
+
+// getManifest returns the manifest of the OCI artifact with a specific tag.
+func (o *ociRepository) getManifest(tag string) (*ociManifest, error) {
+	cacheID := fmt.Sprintf("%s/%s:%s", o.registryURL.Host, o.repository, tag)
+	if manifest, ok := cacheOCIManifests[cacheID]; ok {
+		return manifest, nil
+	}
+
+	content, err := o.get(o.resolve("manifests", tag), strings.Join([]string{ociManifestMediaType, dockerManifestMediaType}, ", "))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read manifest %q", tag)
+	}
+
+	manifest := &ociManifest{}
+	if err := json.Unmarshal(content, manifest); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse manifest %q", tag)
+	}
+
+	cacheOCIManifests[cacheID] = manifest
+	return manifest, nil
+}
+
+// nextPage returns the URL of the next page of a paginated registry API, as defined by the Link header
+// of the response, or an empty string if this is the last page.
+func (o *ociRepository) nextPage(header http.Header) (string, error) {
+	m := nextLinkRegEx.FindStringSubmatch(header.Get(ociLinkHeader))
+	if m == nil {
+		return "", nil
+	}
+	next, err := url.Parse(m[1])
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid link to the next page %q", m[1])
+	}
+	return o.registryURL.ResolveReference(next).String(), nil
+}
+
+// get reads the content at the given URL, authenticating against the registry if required.
+func (o *ociRepository) get(rawURL, accept string) ([]byte, error) {
+	content, _, err := o.getWithHeader(rawURL, accept)
+	return content, err
+}
+
+// getWithHeader reads the content and the response header at the given URL, authenticating against the registry if required.
+func (o *ociRepository) getWithHeader(rawURL, accept string) ([]byte, http.Header, error) {
+	resp, err := o.do(rawURL, accept, o.token)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	// In case the registry requires a token, get one from the token server and try again.
+	if resp.StatusCode == http.StatusUnauthorized && o.token == "" {
+		challenge := resp.Header.Get(ociAuthenticateHeader)
+		if strings.HasPrefix(strings.ToLower(challenge), ociBearerChallengePrefix) {
+			token, err := o.getToken(challenge)
+			if err != nil {
+				return nil, nil, err
+			}
+			resp.Body.Close()
+			resp, err = o.do(rawURL, accept, token)
+			if err != nil {
+				return nil, nil, err
+			}
+			defer resp.Body.Close()
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, errors.Errorf("failed to get %q: registry returned %s", rawURL, resp.Status)
+	}
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to read response from %q", rawURL)
+	}
+	return content, resp.Header, nil
+}
+
+// do executes a GET request, using the token or the basic auth credentials if defined.
+func (o *ociRepository) do(rawURL, accept, token string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create request for %q", rawURL)
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	switch {
+	case token != "":
+		req.Header.Set("Authorization", "Bearer "+token)
+	case o.username != "":
+		req.SetBasicAuth(o.username, o.password)
+	}
+
+	resp, err := o.getClient().Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get %q", rawURL)
+	}
+	return resp, nil
+}
+
+// getToken gets a bearer token from the token server defined in the authentication challenge returned by the registry
+// (see https://docs.docker.com/registry/spec/auth/token/).
+func (o *ociRepository) getToken(challenge string) (string, error) {
+	params := parseChallengeParams(challenge[len(ociBearerChallengePrefix):])
+	realm, ok := params["realm"]
+	if !ok {
+		return "", errors.Errorf("invalid authentication challenge %q: realm is missing", challenge)
+	}
+
+	tokenURL, err := url.Parse(realm)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid authentication realm %q", realm)
+	}
+	query := tokenURL.Query()
+	for _, k := range []string{"service", "scope"} {
+		if v, ok := params[k]; ok {
+			query.Set(k, v)
+		}
+	}
+	tokenURL.RawQuery = query.Encode()
+
+	resp, err := o.do(tokenURL.String(), "", "")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("failed to get a token from %q: server returned %s", realm, resp.Status)
+	}
+
+	token := &ociToken{}
+	if err := json.NewDecoder(resp.Body).Decode(token); err != nil {
+		return "", errors.Wrapf(err, "failed to parse the token returned by %q", realm)
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	if token.AccessToken != "" {
+		return token.AccessToken, nil
+	}
+	return "", errors.Errorf("failed to get a token from %q: empty token", realm)
+}
+
+// parseChallengeParams parses the key="value" pairs of an authentication challenge.
+func parseChallengeParams(s string) map[string]string {
+	params := map[string]string{}
+	for _, m := range challengeParamRegEx.FindAllStringSubmatch(s, -1) {
+		params[strings.ToLower(m[1])] = m[2]
+	}
+	return params
+}
+
+// getLatestRelease returns the latest release for the OCI repository, according to
+// semantic version order of the artifact tags.
+func (o *ociRepository) getLatestRelease() (string, error) {
+	versions, err := o.GetVersions()
+	if err != nil {
+		return "", err
+	}
+	return latestSemanticVersion(versions)
+}
@@ -0,0 +1,252 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+// newFakeRegistry returns a fake OCI registry hosting the infrastructure-foo repository, with a token server
+// protecting the registry API.
+func newFakeRegistry(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+
+	// handler for returning a token, only if the client uses the expected credentials and scope
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("scope") != "repository:capi/infrastructure-foo:pull,push" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, `{"token": "token"}`)
+	})
+
+	// handler for the registry API, requiring the token
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.Header().Set(ociAuthenticateHeader, fmt.Sprintf(`Bearer realm="%s/token",service="fake",scope="repository:capi/infrastructure-foo:pull,push"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v2/capi/infrastructure-foo/tags/list":
+			// the tag list is paginated, so clients are required to follow the Link header for getting all the tags
+			if r.URL.Query().Get("last") == "" {
+				w.Header().Set(ociLinkHeader, `</v2/capi/infrastructure-foo/tags/list?last=foo&n=2>; rel="next"`)
+				fmt.Fprint(w, `{"name": "capi/infrastructure-foo", "tags": ["v1.0.0", "foo"]}`)
+				return
+			}
+			fmt.Fprint(w, `{"name": "capi/infrastructure-foo", "tags": ["v1.1.0"]}`)
+		case "/v2/capi/infrastructure-foo/manifests/v1.0.0":
+			if !strings.Contains(r.Header.Get("Accept"), ociManifestMediaType) {
+				w.WriteHeader(http.StatusNotAcceptable)
+				return
+			}
+			w.Header().Set("Content-Type", ociManifestMediaType)
+			fmt.Fprint(w, `{"schemaVersion": 2, "layers": [`)
+			fmt.Fprint(w, `{"mediaType": "application/yaml", "digest": "sha256:1", "annotations": {"org.opencontainers.image.title": "infrastructure-components.yaml"}},`)
+			fmt.Fprint(w, `{"mediaType": "application/yaml", "digest": "sha256:2", "annotations": {"org.opencontainers.image.title": "metadata.yaml"}}`)
+			fmt.Fprint(w, `]}`)
+		case "/v2/capi/infrastructure-foo/blobs/sha256:1":
+			fmt.Fprint(w, "components")
+		case "/v2/capi/infrastructure-foo/blobs/sha256:2":
+			fmt.Fprint(w, "metadata")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	return server
+}
+
+func Test_ociRepository_newOCIRepository(t *testing.T) {
+	tests := []struct {
+		name               string
+		url                string
+		wantRegistry       string
+		wantRepository     string
+		wantVersion        string
+		wantComponentsPath string
+		wantErr            bool
+	}{
+		{
+			name:               "can create a new OCI repository",
+			url:                "oci://registry.example.com/capi/infrastructure-aws:v0.5.2/infrastructure-components.yaml",
+			wantRegistry:       "https://registry.example.com",
+			wantRepository:     "capi/infrastructure-aws",
+			wantVersion:        "v0.5.2",
+			wantComponentsPath: "infrastructure-components.yaml",
+			wantErr:            false,
+		},
+		{
+			name:               "registries on localhost are accessed using http",
+			url:                "oci://localhost:5000/infrastructure-aws:v0.5.2/infrastructure-components.yaml",
+			wantRegistry:       "http://localhost:5000",
+			wantRepository:     "infrastructure-aws",
+			wantVersion:        "v0.5.2",
+			wantComponentsPath: "infrastructure-components.yaml",
+			wantErr:            false,
+		},
+		{
+			name:    "fails if the version is missing",
+			url:     "oci://registry.example.com/capi/infrastructure-aws/infrastructure-components.yaml",
+			wantErr: true,
+		},
+		{
+			name:    "fails if the version is not a semantic version",
+			url:     "oci://registry.example.com/capi/infrastructure-aws:foo/infrastructure-components.yaml",
+			wantErr: true,
+		},
+		{
+			name:    "fails if the components path is missing",
+			url:     "oci://registry.example.com/capi/infrastructure-aws:v0.5.2",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			providerConfig := config.NewProvider("test", tt.url, clusterctlv1.InfrastructureProviderType)
+			repo, err := newOCIRepository(providerConfig, test.NewFakeVariableClient())
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(repo.registryURL.String()).To(Equal(tt.wantRegistry))
+			g.Expect(repo.repository).To(Equal(tt.wantRepository))
+			g.Expect(repo.DefaultVersion()).To(Equal(tt.wantVersion))
+			g.Expect(repo.ComponentsPath()).To(Equal(tt.wantComponentsPath))
+		})
+	}
+}
+
+func Test_ociRepository_GetFile(t *testing.T) {
+	server := newFakeRegistry(t)
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	providerConfig := config.NewProvider("foo", fmt.Sprintf("oci://%s/capi/infrastructure-foo:v1.0.0/infrastructure-components.yaml", host), clusterctlv1.InfrastructureProviderType)
+
+	tests := []struct {
+		name           string
+		variableClient config.VariablesClient
+		version        string
+		fileName       string
+		want           string
+		wantErr        bool
+	}{
+		{
+			name:           "Get file",
+			variableClient: test.NewFakeVariableClient().WithVar(config.OCIRepositoryUsernameVariable, "user").WithVar(config.OCIRepositoryPasswordVariable, "pass"),
+			version:        "v1.0.0",
+			fileName:       "metadata.yaml",
+			want:           "metadata",
+			wantErr:        false,
+		},
+		{
+			name:           "Get file using a static token",
+			variableClient: test.NewFakeVariableClient().WithVar(config.OCIRepositoryTokenVariable, "token"),
+			version:        "",
+			fileName:       "infrastructure-components.yaml",
+			want:           "components",
+			wantErr:        false,
+		},
+		{
+			name:           "Fails if the file does not exist",
+			variableClient: test.NewFakeVariableClient().WithVar(config.OCIRepositoryTokenVariable, "token"),
+			version:        "v1.0.0",
+			fileName:       "404.yaml",
+			wantErr:        true,
+		},
+		{
+			name:           "Fails if the version does not exist",
+			variableClient: test.NewFakeVariableClient().WithVar(config.OCIRepositoryTokenVariable, "token"),
+			version:        "v9.9.9",
+			fileName:       "infrastructure-components.yaml",
+			wantErr:        true,
+		},
+		{
+			name:           "Fails if credentials are not valid",
+			variableClient: test.NewFakeVariableClient().WithVar(config.OCIRepositoryUsernameVariable, "user").WithVar(config.OCIRepositoryPasswordVariable, "wrong"),
+			version:        "v1.0.0",
+			fileName:       "metadata.yaml",
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			// reset the caches
+			cacheOCIManifests = map[string]*ociManifest{}
+			cacheOCIFiles = map[string][]byte{}
+
+			repo, err := newOCIRepository(providerConfig, tt.variableClient)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			got, err := repo.GetFile(tt.version, tt.fileName)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(string(got)).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_ociRepository_GetVersions(t *testing.T) {
+	server := newFakeRegistry(t)
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	providerConfig := config.NewProvider("foo", fmt.Sprintf("oci://%s/capi/infrastructure-foo:latest/infrastructure-components.yaml", host), clusterctlv1.InfrastructureProviderType)
+
+	g := NewWithT(t)
+
+	variableClient := test.NewFakeVariableClient().WithVar(config.OCIRepositoryUsernameVariable, "user").WithVar(config.OCIRepositoryPasswordVariable, "pass")
+	repo, err := newOCIRepository(providerConfig, variableClient)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(repo.DefaultVersion()).To(Equal("v1.1.0"))
+
+	got, err := repo.GetVersions()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(ConsistOf("v1.0.0", "v1.1.0"))
+}
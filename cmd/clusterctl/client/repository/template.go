@@ -33,6 +33,11 @@ type Template interface {
 	// This value is derived by the template YAML.
 	Variables() []string
 
+	// VariableDetails returns the variables used by the template, including info about required variables
+	// and default values.
+	// This value is derived by the template YAML.
+	VariableDetails() []Variable
+
 	// TargetNamespace where the template objects will be installed.
 	TargetNamespace() string
 
@@ -46,6 +51,7 @@ type Template interface {
 // template implements Template.
 type template struct {
 	variables       []string
+	variableDetails []Variable
 	targetNamespace string
 	objs            []unstructured.Unstructured
 }
@@ -57,6 +63,10 @@ func (t *template) Variables() []string {
 	return t.variables
 }
 
+func (t *template) VariableDetails() []Variable {
+	return t.variableDetails
+}
+
 func (t *template) TargetNamespace() string {
 	return t.targetNamespace
 }
@@ -71,11 +81,27 @@ func (t *template) Yaml() ([]byte, error) {
 
 // NewTemplate returns a new objects embedding a cluster template YAML file.
 func NewTemplate(rawYaml []byte, configVariablesClient config.VariablesClient, targetNamespace string, listVariablesOnly bool) (*template, error) {
-	// Inspect variables and replace with values from the configuration.
-	variables := inspectVariables(rawYaml)
+	return NewTemplateWithOverlays(rawYaml, nil, configVariablesClient, targetNamespace, listVariablesOnly)
+}
+
+// NewTemplateWithOverlays returns a new objects embedding a cluster template YAML file, with a list of flavor overlays applied in order
+// on top of it.
+// Variables are inspected across the base template and all the overlays, and variable substitution is applied to each layer before
+// applying the overlays, so patches can be expressed using the same variables used in the base template (e.g. ${ CLUSTER_NAME }).
+func NewTemplateWithOverlays(rawYaml []byte, overlays []TemplateOverlay, configVariablesClient config.VariablesClient, targetNamespace string, listVariablesOnly bool) (*template, error) {
+	// Inspect variables in all the layers and replace with values from the configuration.
+	layers := [][]byte{rawYaml}
+	for _, o := range overlays {
+		layers = append(layers, o.RawYaml)
+	}
+	allLayers := utilyaml.JoinYaml(layers...)
+
+	variables := inspectVariables(allLayers)
+	variableDetails := inspectVariableDetails(allLayers)
 	if listVariablesOnly {
 		return &template{
 			variables:       variables,
+			variableDetails: variableDetails,
 			targetNamespace: targetNamespace,
 		}, nil
 	}
@@ -91,13 +117,29 @@ func NewTemplate(rawYaml []byte, configVariablesClient config.VariablesClient, t
 		return nil, errors.Wrap(err, "failed to parse yaml")
 	}
 
+	// Apply the overlays in order.
+	for _, o := range overlays {
+		overlayYaml, err := replaceVariables(o.RawYaml, variables, configVariablesClient, false)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to perform variable substitution in the overlay for flavor %q", o.Flavor)
+		}
+		objs, err = applyOverlay(objs, o.Flavor, overlayYaml)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Ensures all the template components are deployed in the target namespace (applies only to namespaced objects)
 	// This is required in order to ensure a cluster and all the related objects are in a single namespace, that is a requirement for
 	// the clusterctl move operation (and also for many controller reconciliation loops).
-	objs = fixTargetNamespace(objs, targetNamespace)
+	// If the target namespace is empty, e.g. for templates not defining a cluster, the namespaces in the template are preserved.
+	if targetNamespace != "" {
+		objs = fixTargetNamespace(objs, targetNamespace)
+	}
 
 	return &template{
 		variables:       variables,
+		variableDetails: variableDetails,
 		targetNamespace: targetNamespace,
 		objs:            objs,
 	}, nil
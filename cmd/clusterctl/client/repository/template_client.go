@@ -54,24 +54,71 @@ func newTemplateClient(provider config.Provider, version string, repository Repo
 // Get return the template for the flavor specified.
 // In case the template does not exists, an error is returned.
 // Get assumes the following naming convention for templates: cluster-template[-<flavor_name>].yaml
+//
+// Flavors can also be implemented as overlays on top of the base cluster-template.yaml, using the following naming convention
+// cluster-template-overlay-<flavor_name>.yaml; in this case it is possible to apply many flavors at once, e.g. "a,b", and the
+// corresponding overlays are applied in order.
 func (c *templateClient) Get(flavor, targetNamespace string, listVariablesOnly bool) (Template, error) {
-	log := logf.Log
-
 	if targetNamespace == "" {
 		return nil, errors.New("invalid arguments: please provide a targetNamespace")
 	}
 
+	baseName := "cluster-template.yaml"
+	flavors := ParseFlavors(flavor)
+
+	// if there is a single flavor, check first for a template implementing the flavor as a whole, e.g. cluster-template-<flavor_name>.yaml
+	if len(flavors) <= 1 {
+		name := baseName
+		if len(flavors) == 1 {
+			name = fmt.Sprintf("cluster-template-%s.yaml", flavors[0])
+		}
+
+		rawYaml, err := c.getFile(name)
+		if err == nil {
+			return NewTemplate(rawYaml, c.configVariablesClient, targetNamespace, listVariablesOnly)
+		}
+		if len(flavors) == 0 {
+			return nil, err
+		}
+
+		// if the template for the flavor does not exists, fall back to the base template + overlay
+		template, overlayErr := c.getWithOverlays(baseName, flavors, targetNamespace, listVariablesOnly)
+		if overlayErr != nil {
+			return nil, errors.Wrapf(err, "failed to read the template for flavor %q, either as a template or as an overlay (%v)", flavors[0], overlayErr)
+		}
+		return template, nil
+	}
+
+	return c.getWithOverlays(baseName, flavors, targetNamespace, listVariablesOnly)
+}
+
+// getWithOverlays returns the base template with the overlays corresponding to the given flavors applied on top.
+func (c *templateClient) getWithOverlays(baseName string, flavors []string, targetNamespace string, listVariablesOnly bool) (Template, error) {
+	rawYaml, err := c.getFile(baseName)
+	if err != nil {
+		return nil, err
+	}
+
+	overlays := make([]TemplateOverlay, 0, len(flavors))
+	for _, f := range flavors {
+		overlay, err := c.getFile(OverlayName(baseName, f))
+		if err != nil {
+			return nil, err
+		}
+		overlays = append(overlays, TemplateOverlay{Flavor: f, RawYaml: overlay})
+	}
+
+	return NewTemplateWithOverlays(rawYaml, overlays, c.configVariablesClient, targetNamespace, listVariablesOnly)
+}
+
+// getFile reads a file, reading the local override file if it exists, otherwise read from the provider repository.
+func (c *templateClient) getFile(name string) ([]byte, error) {
+	log := logf.Log
+
 	// we are always reading templateClient for a well know version, that usually is
 	// the version of the provider installed in the management cluster.
 	version := c.version
 
-	// building template name according with the naming convention
-	name := "cluster-template"
-	if flavor != "" {
-		name = fmt.Sprintf("%s-%s", name, flavor)
-	}
-	name = fmt.Sprintf("%s.yaml", name)
-
 	// read the component YAML, reading the local override file if it exists, otherwise read from the provider repository
 	rawYaml, err := getLocalOverride(&newOverrideInput{
 		configVariablesClient: c.configVariablesClient,
@@ -92,6 +139,5 @@ func (c *templateClient) Get(flavor, targetNamespace string, listVariablesOnly b
 	} else {
 		log.V(1).Info("Using", "Override", name, "Provider", c.provider.ManifestLabel(), "Version", version)
 	}
-
-	return NewTemplate(rawYaml, c.configVariablesClient, targetNamespace, listVariablesOnly)
+	return rawYaml, nil
 }
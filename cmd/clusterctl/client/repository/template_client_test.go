@@ -27,6 +27,13 @@ import (
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
 )
 
+var overlayMapYaml = []byte("apiVersion: v1\n" +
+	"kind: ConfigMap\n" +
+	"metadata:\n" +
+	"  name: manager\n" +
+	"data:\n" +
+	"  overlay: ${FOO}\n")
+
 func Test_templates_Get(t *testing.T) {
 	p1 := config.NewProvider("p1", "", clusterctlv1.BootstrapProviderType)
 
@@ -96,6 +103,72 @@ func Test_templates_Get(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "pass if overlay for a flavor exists",
+			fields: fields{
+				version:  "v1.0",
+				provider: p1,
+				repository: test.NewFakeRepository().
+					WithPaths("root", "").
+					WithDefaultVersion("v1.0").
+					WithFile("v1.0", "cluster-template.yaml", templateMapYaml).
+					WithFile("v1.0", "cluster-template-overlay-prod.yaml", overlayMapYaml),
+				configVariablesClient: test.NewFakeVariableClient().WithVar(variableName, variableValue),
+			},
+			args: args{
+				flavor:            "prod",
+				targetNamespace:   "ns1",
+				listVariablesOnly: false,
+			},
+			want: want{
+				variables:       []string{variableName},
+				targetNamespace: "ns1",
+			},
+			wantErr: false,
+		},
+		{
+			name: "pass if overlays for many flavors exist",
+			fields: fields{
+				version:  "v1.0",
+				provider: p1,
+				repository: test.NewFakeRepository().
+					WithPaths("root", "").
+					WithDefaultVersion("v1.0").
+					WithFile("v1.0", "cluster-template.yaml", templateMapYaml).
+					WithFile("v1.0", "cluster-template-overlay-prod.yaml", overlayMapYaml).
+					WithFile("v1.0", "cluster-template-overlay-ha.yaml", overlayMapYaml),
+				configVariablesClient: test.NewFakeVariableClient().WithVar(variableName, variableValue),
+			},
+			args: args{
+				flavor:            "prod,ha",
+				targetNamespace:   "ns1",
+				listVariablesOnly: false,
+			},
+			want: want{
+				variables:       []string{variableName},
+				targetNamespace: "ns1",
+			},
+			wantErr: false,
+		},
+		{
+			name: "fails if the overlay for one of many flavors does not exists",
+			fields: fields{
+				version:  "v1.0",
+				provider: p1,
+				repository: test.NewFakeRepository().
+					WithPaths("root", "").
+					WithDefaultVersion("v1.0").
+					WithFile("v1.0", "cluster-template.yaml", templateMapYaml).
+					WithFile("v1.0", "cluster-template-overlay-prod.yaml", overlayMapYaml),
+				configVariablesClient: test.NewFakeVariableClient().WithVar(variableName, variableValue),
+			},
+			args: args{
+				flavor:            "prod,ha",
+				targetNamespace:   "ns1",
+				listVariablesOnly: false,
+			},
+			wantErr: true,
+		},
 		{
 			name: "fails if template does not exists",
 			fields: fields{
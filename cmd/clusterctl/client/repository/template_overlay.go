@@ -0,0 +1,255 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+)
+
+const (
+	// overlayInfix defines the infix used for naming flavor overlays, e.g. cluster-template-overlay-<flavor>.yaml.
+	overlayInfix = "overlay"
+
+	// FlavorSeparator defines the separator to be used when applying many flavors at once, e.g. --flavor a,b.
+	FlavorSeparator = ","
+
+	jsonPatchTargetField = "target"
+	jsonPatchField       = "patch"
+)
+
+// TemplateOverlay defines a set of patches to be applied on top of a base cluster template.
+//
+// An overlay is a YAML file with one or more documents. Each document can be a merge patch, that is a (partial) object
+// identified by kind and metadata.name; the patch is merged into the object with the same kind and name in the base
+// template using JSON merge patch semantic (https://tools.ietf.org/html/rfc7386), or, in case there is no such object,
+// the patch is added to the template as a new object. Given that a merge patch replaces lists as a whole, and that
+// strategic merge patches can't be used for provider types, merge patches setting list fields of an existing object
+// are rejected; JSON patches should be used instead.
+//
+// A document can also be a JSON patch (https://tools.ietf.org/html/rfc6902), that is a document with a target selecting
+// one or more objects by group, kind and name, and a list of patch operations, e.g.
+//
+//	target:
+//	  kind: KubeadmControlPlane
+//	  name: ${ CLUSTER_NAME }-control-plane
+//	patch:
+//	- op: replace
+//	  path: /spec/replicas
+//	  value: 3
+type TemplateOverlay struct {
+	// Flavor is the name of the flavor the overlay implements.
+	Flavor string
+
+	// RawYaml is the overlay content, before variable substitution.
+	RawYaml []byte
+}
+
+// jsonPatchTarget defines the objects a JSON patch applies to.
+type jsonPatchTarget struct {
+	Group string `json:"group,omitempty"`
+	Kind  string `json:"kind"`
+	Name  string `json:"name,omitempty"`
+}
+
+// matches returns true if the object is selected by the target.
+func (t jsonPatchTarget) matches(o unstructured.Unstructured) bool {
+	gvk := o.GroupVersionKind()
+	if t.Group != "" && t.Group != gvk.Group {
+		return false
+	}
+	if t.Kind != gvk.Kind {
+		return false
+	}
+	return t.Name == "" || t.Name == o.GetName()
+}
+
+// jsonPatchDocument defines a JSON patch document in an overlay.
+type jsonPatchDocument struct {
+	Target jsonPatchTarget   `json:"target"`
+	Patch  []json.RawMessage `json:"patch"`
+}
+
+// ParseFlavors splits a list of comma separated flavor names.
+func ParseFlavors(flavor string) []string {
+	var flavors []string
+	for _, f := range strings.Split(flavor, FlavorSeparator) {
+		if f = strings.TrimSpace(f); f != "" {
+			flavors = append(flavors, f)
+		}
+	}
+	return flavors
+}
+
+// OverlayName returns the name of the overlay implementing a flavor for a base template, e.g.
+// cluster-template-overlay-<flavor>.yaml for cluster-template.yaml.
+func OverlayName(baseName, flavor string) string {
+	ext := ""
+	if i := strings.LastIndex(baseName, "."); i > 0 && !strings.Contains(baseName[i:], "/") {
+		baseName, ext = baseName[:i], baseName[i:]
+	}
+	return fmt.Sprintf("%s-%s-%s%s", baseName, overlayInfix, flavor, ext)
+}
+
+// applyOverlay applies the patches defined in an overlay on top of a list of objects.
+// NB. the overlay is expected to be already processed for variable substitution.
+func applyOverlay(objs []unstructured.Unstructured, flavor string, overlay []byte) ([]unstructured.Unstructured, error) {
+	patches, err := utilyaml.ToUnstructured(overlay)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse overlay for flavor %q", flavor)
+	}
+
+	for i, p := range patches {
+		if isJSONPatch(p) {
+			objs, err = applyJSONPatch(objs, p)
+		} else {
+			objs, err = applyMergePatch(objs, p)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to apply patch #%d of the overlay for flavor %q", i+1, flavor)
+		}
+	}
+	return objs, nil
+}
+
+// isJSONPatch returns true if an overlay document is a JSON patch.
+func isJSONPatch(p unstructured.Unstructured) bool {
+	_, hasTarget := p.Object[jsonPatchTargetField]
+	_, hasPatch := p.Object[jsonPatchField]
+	return hasTarget && hasPatch && p.GetKind() == ""
+}
+
+// applyJSONPatch applies a JSON patch to all the objects selected by the patch target.
+func applyJSONPatch(objs []unstructured.Unstructured, p unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+	raw, err := json.Marshal(p.Object)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal JSON patch")
+	}
+	doc := &jsonPatchDocument{}
+	if err := json.Unmarshal(raw, doc); err != nil {
+		return nil, errors.Wrap(err, "failed to parse JSON patch")
+	}
+	if doc.Target.Kind == "" {
+		return nil, errors.New("invalid JSON patch: target.kind must be set")
+	}
+
+	ops, err := json.Marshal(doc.Patch)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal JSON patch operations")
+	}
+	patch, err := jsonpatch.DecodePatch(ops)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode JSON patch operations")
+	}
+
+	found := false
+	for i := range objs {
+		if !doc.Target.matches(objs[i]) {
+			continue
+		}
+		found = true
+
+		original, err := json.Marshal(objs[i].Object)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal %s %s", objs[i].GetKind(), objs[i].GetName())
+		}
+		patched, err := patch.Apply(original)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to apply JSON patch to %s %s", objs[i].GetKind(), objs[i].GetName())
+		}
+		if err := objs[i].UnmarshalJSON(patched); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal patched %s %s", objs[i].GetKind(), objs[i].GetName())
+		}
+	}
+	if !found {
+		return nil, errors.Errorf("failed to find objects matching the JSON patch target %s %q", doc.Target.Kind, doc.Target.Name)
+	}
+	return objs, nil
+}
+
+// applyMergePatch merges a patch into the object with the same group, kind and name or, in case
+// such object does not exists, adds the patch as a new object.
+func applyMergePatch(objs []unstructured.Unstructured, p unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+	if p.GetKind() == "" || p.GetName() == "" {
+		return nil, errors.New("invalid merge patch: kind and metadata.name must be set")
+	}
+
+	patchGK := p.GroupVersionKind().GroupKind()
+	for i := range objs {
+		if !sameGroupKind(objs[i].GroupVersionKind().GroupKind(), patchGK) || objs[i].GetName() != p.GetName() {
+			continue
+		}
+
+		if path := findListField(p.Object); path != "" {
+			return nil, errors.Errorf("invalid merge patch for %s %s: lists are replaced as a whole, use a JSON patch to change the list field %s", p.GetKind(), p.GetName(), path)
+		}
+
+		original, err := json.Marshal(objs[i].Object)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal %s %s", objs[i].GetKind(), objs[i].GetName())
+		}
+		patch, err := json.Marshal(p.Object)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal merge patch for %s %s", p.GetKind(), p.GetName())
+		}
+		patched, err := jsonpatch.MergePatch(original, patch)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to apply merge patch to %s %s", objs[i].GetKind(), objs[i].GetName())
+		}
+		if err := objs[i].UnmarshalJSON(patched); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal patched %s %s", objs[i].GetKind(), objs[i].GetName())
+		}
+		return objs, nil
+	}
+
+	return append(objs, p), nil
+}
+
+// findListField returns the path of the first list field, in alphabetical order, found in an object,
+// or an empty string if the object has no list fields.
+func findListField(obj map[string]interface{}) string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		switch v := obj[k].(type) {
+		case []interface{}:
+			return "." + k
+		case map[string]interface{}:
+			if path := findListField(v); path != "" {
+				return "." + k + path
+			}
+		}
+	}
+	return ""
+}
+
+// sameGroupKind returns true if two GroupKinds are equal; an empty group in the patch matches any group.
+func sameGroupKind(obj, patch schema.GroupKind) bool {
+	return obj.Kind == patch.Kind && (patch.Group == "" || obj.Group == patch.Group)
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+var overlayBaseYaml = []byte("apiVersion: controlplane.cluster.x-k8s.io/v1alpha3\n" +
+	"kind: KubeadmControlPlane\n" +
+	"metadata:\n" +
+	"  name: ${CLUSTER_NAME}-control-plane\n" +
+	"spec:\n" +
+	"  replicas: 1\n" +
+	"  version: v1.18.2\n" +
+	"---\n" +
+	"apiVersion: v1\n" +
+	"kind: ConfigMap\n" +
+	"metadata:\n" +
+	"  name: manager\n" +
+	"data:\n" +
+	"  foo: bar\n")
+
+func Test_ParseFlavors(t *testing.T) {
+	tests := []struct {
+		name   string
+		flavor string
+		want   []string
+	}{
+		{
+			name:   "no flavor",
+			flavor: "",
+			want:   nil,
+		},
+		{
+			name:   "one flavor",
+			flavor: "prod",
+			want:   []string{"prod"},
+		},
+		{
+			name:   "many flavors, ignoring spaces and empty values",
+			flavor: "prod, ha,,",
+			want:   []string{"prod", "ha"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(ParseFlavors(tt.flavor)).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_OverlayName(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(OverlayName("cluster-template.yaml", "prod")).To(Equal("cluster-template-overlay-prod.yaml"))
+	g.Expect(OverlayName("template", "prod")).To(Equal("template-overlay-prod"))
+}
+
+func Test_NewTemplateWithOverlays(t *testing.T) {
+	tests := []struct {
+		name      string
+		overlays  []TemplateOverlay
+		want      []string
+		wantObjs  int
+		variables []string
+		wantErr   bool
+	}{
+		{
+			name: "merge patch",
+			overlays: []TemplateOverlay{
+				{
+					Flavor: "ha",
+					RawYaml: []byte("kind: KubeadmControlPlane\n" +
+						"metadata:\n" +
+						"  name: ${CLUSTER_NAME}-control-plane\n" +
+						"spec:\n" +
+						"  replicas: ${CONTROL_PLANE_MACHINE_COUNT}\n"),
+				},
+			},
+			want:      []string{"replicas: 3", "version: v1.18.2"},
+			wantObjs:  2,
+			variables: []string{"CLUSTER_NAME", "CONTROL_PLANE_MACHINE_COUNT"},
+		},
+		{
+			name: "merge patch adding a new object",
+			overlays: []TemplateOverlay{
+				{
+					Flavor: "extra",
+					RawYaml: []byte("apiVersion: v1\n" +
+						"kind: Secret\n" +
+						"metadata:\n" +
+						"  name: extra\n"),
+				},
+			},
+			want:      []string{"kind: Secret", "name: extra"},
+			wantObjs:  3,
+			variables: []string{"CLUSTER_NAME"},
+		},
+		{
+			name: "JSON patch, with overlays applied in order",
+			overlays: []TemplateOverlay{
+				{
+					Flavor: "ha",
+					RawYaml: []byte("target:\n" +
+						"  kind: KubeadmControlPlane\n" +
+						"patch:\n" +
+						"- op: replace\n" +
+						"  path: /spec/replicas\n" +
+						"  value: 3\n"),
+				},
+				{
+					Flavor: "upgrade",
+					RawYaml: []byte("target:\n" +
+						"  group: controlplane.cluster.x-k8s.io\n" +
+						"  kind: KubeadmControlPlane\n" +
+						"  name: test-control-plane\n" +
+						"patch:\n" +
+						"- op: replace\n" +
+						"  path: /spec/version\n" +
+						"  value: v1.19.0\n"),
+				},
+			},
+			want:      []string{"replicas: 3", "version: v1.19.0"},
+			wantObjs:  2,
+			variables: []string{"CLUSTER_NAME"},
+		},
+		{
+			name: "fails if the JSON patch target does not match any object",
+			overlays: []TemplateOverlay{
+				{
+					Flavor: "ha",
+					RawYaml: []byte("target:\n" +
+						"  kind: MachineDeployment\n" +
+						"patch:\n" +
+						"- op: replace\n" +
+						"  path: /spec/replicas\n" +
+						"  value: 3\n"),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "fails if the merge patch sets a list field of an existing object",
+			overlays: []TemplateOverlay{
+				{
+					Flavor: "ha",
+					RawYaml: []byte("kind: KubeadmControlPlane\n" +
+						"metadata:\n" +
+						"  name: ${CLUSTER_NAME}-control-plane\n" +
+						"spec:\n" +
+						"  kubeadmConfigSpec:\n" +
+						"    preKubeadmCommands:\n" +
+						"    - echo ha\n"),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "merge patch adding a new object with list fields",
+			overlays: []TemplateOverlay{
+				{
+					Flavor: "extra",
+					RawYaml: []byte("apiVersion: v1\n" +
+						"kind: ConfigMap\n" +
+						"metadata:\n" +
+						"  name: extra\n" +
+						"  finalizers:\n" +
+						"  - extra\n"),
+				},
+			},
+			want:      []string{"kind: ConfigMap", "- extra"},
+			wantObjs:  3,
+			variables: []string{"CLUSTER_NAME"},
+		},
+		{
+			name: "fails if the merge patch has no name",
+			overlays: []TemplateOverlay{
+				{
+					Flavor:  "ha",
+					RawYaml: []byte("kind: KubeadmControlPlane\n"),
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			variableClient := test.NewFakeVariableClient().
+				WithVar("CLUSTER_NAME", "test").
+				WithVar("CONTROL_PLANE_MACHINE_COUNT", "3")
+
+			got, err := NewTemplateWithOverlays(overlayBaseYaml, tt.overlays, variableClient, "ns1", false)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(got.Variables()).To(Equal(tt.variables))
+			g.Expect(got.Objs()).To(HaveLen(tt.wantObjs))
+
+			yaml, err := got.Yaml()
+			g.Expect(err).NotTo(HaveOccurred())
+			for _, w := range tt.want {
+				g.Expect(string(yaml)).To(ContainSubstring(w))
+			}
+
+			for _, o := range got.Objs() {
+				g.Expect(o.GetNamespace()).To(Equal("ns1"))
+			}
+		})
+	}
+}
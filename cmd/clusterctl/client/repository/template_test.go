@@ -106,3 +106,24 @@ func Test_newTemplate(t *testing.T) {
 		})
 	}
 }
+
+func Test_newTemplate_withoutTargetNamespace(t *testing.T) {
+	g := NewWithT(t)
+
+	rawYaml := []byte("apiVersion: v1\n" +
+		"kind: ConfigMap\n" +
+		"metadata:\n" +
+		"  name: manager\n" +
+		"  namespace: foo\n" +
+		"---\n" +
+		"apiVersion: v1\n" +
+		"kind: Namespace\n" +
+		"metadata:\n" +
+		"  name: foo")
+
+	got, err := NewTemplate(rawYaml, test.NewFakeVariableClient(), "", false)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got.Objs()).To(HaveLen(2))
+	g.Expect(got.Objs()[0].GetNamespace()).To(Equal("foo"))
+	g.Expect(got.Objs()[1].GetName()).To(Equal("foo"))
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+)
+
+const (
+	// defaultOperator defines the modifier for variables with a default value, e.g. ${VAR:=default}.
+	defaultOperator = ":="
+
+	// defaultAltOperator defines an alternative modifier for variables with a default value, e.g. ${VAR:-default}.
+	defaultAltOperator = ":-"
+
+	// requiredOperator defines the modifier for required variables with a custom error message, e.g. ${VAR:?message}.
+	requiredOperator = ":?"
+
+	// replaceOperator defines the modifier for variables with a pattern replacement, e.g. ${VAR/pattern/replacement};
+	// using ${VAR//pattern/replacement} all the occurrences of pattern are replaced.
+	replaceOperator = "/"
+)
+
+// variableModifierExp defines the regexp used for matching the (optional) modifier of a variable.
+const variableModifierExp = `((?::=|:-|:\?|/)[^}]*)?`
+
+// variableRegEx defines the regexp used for searching variables inside a YAML
+var variableRegEx = regexp.MustCompile(`\${\s*([A-Z0-9_]+)\s*` + variableModifierExp + `}`)
+
+// Variable defines a variable used in a YAML file, e.g. a cluster template or a provider components YAML.
+type Variable struct {
+	// Name of the variable.
+	Name string
+
+	// Required is true if the YAML can't be processed without a value for the variable; this happens when
+	// at least one occurrence of the variable does not define a default value.
+	Required bool
+
+	// Default value for the variable, if defined.
+	Default *string
+
+	// Message is the custom error message to be returned when a required variable is not set, if defined.
+	Message string
+}
+
+// variableExpression defines a single occurrence of a variable inside a YAML.
+type variableExpression struct {
+	name     string
+	operator string
+	argument string
+}
+
+// parseVariableExpression parses the name and the modifier of a variable expression.
+func parseVariableExpression(name, modifier string) variableExpression {
+	e := variableExpression{name: name}
+	modifier = strings.TrimRight(modifier, " \t")
+	for _, op := range []string{defaultOperator, defaultAltOperator, requiredOperator, replaceOperator} {
+		if strings.HasPrefix(modifier, op) {
+			e.operator = op
+			e.argument = strings.TrimPrefix(modifier, op)
+			break
+		}
+	}
+	return e
+}
+
+// hasDefault returns true if the variable expression defines a default value.
+func (e variableExpression) hasDefault() bool {
+	return e.operator == defaultOperator || e.operator == defaultAltOperator
+}
+
+// eval returns the value of the variable expression given the value of the variable.
+// If the variable is not set and the expression does not define a default, false is returned.
+func (e variableExpression) eval(value string, isSet bool) (string, bool) {
+	switch e.operator {
+	case defaultOperator, defaultAltOperator:
+		if !isSet || value == "" {
+			return e.argument, true
+		}
+		return value, true
+	case requiredOperator:
+		if !isSet || value == "" {
+			return "", false
+		}
+		return value, true
+	case replaceOperator:
+		if !isSet {
+			return "", false
+		}
+		replaceAll := strings.HasPrefix(e.argument, replaceOperator)
+		args := strings.SplitN(strings.TrimPrefix(e.argument, replaceOperator), replaceOperator, 2)
+		if args[0] == "" {
+			return value, true
+		}
+		replacement := ""
+		if len(args) > 1 {
+			replacement = args[1]
+		}
+		if replaceAll {
+			return strings.Replace(value, args[0], replacement, -1), true
+		}
+		return strings.Replace(value, args[0], replacement, 1), true
+	}
+	return value, isSet
+}
+
+// inspectVariables returns the sorted list of the names of the variables used in a YAML.
+func inspectVariables(data []byte) []string {
+	variables := inspectVariableDetails(data)
+	ret := make([]string, 0, len(variables))
+	for _, v := range variables {
+		ret = append(ret, v.Name)
+	}
+	return ret
+}
+
+// inspectVariableDetails returns the list of the variables used in a YAML, sorted by name, including
+// info about required variables and defaults.
+func inspectVariableDetails(data []byte) []Variable {
+	variables := map[string]*Variable{}
+	for _, m := range variableRegEx.FindAllStringSubmatch(string(data), -1) {
+		e := parseVariableExpression(m[1], m[2])
+
+		v, ok := variables[e.name]
+		if !ok {
+			v = &Variable{Name: e.name}
+			variables[e.name] = v
+		}
+
+		// A variable is required if at least one occurrence does not have a default; the first
+		// default/message is reported.
+		if e.hasDefault() {
+			if v.Default == nil {
+				d := e.argument
+				v.Default = &d
+			}
+		} else {
+			v.Required = true
+		}
+		if e.operator == requiredOperator && v.Message == "" {
+			v.Message = e.argument
+		}
+	}
+
+	ret := make([]Variable, 0, len(variables))
+	for _, v := range variables {
+		ret = append(ret, *v)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+func replaceVariables(yaml []byte, variables []string, configVariablesClient config.VariablesClient, skipVariables bool) ([]byte, error) {
+	tmp := string(yaml)
+	var missingVariables []string
+	var messages []string
+	for _, key := range variables {
+		val, err := configVariablesClient.Get(key)
+		isSet := err == nil
+
+		missing := false
+		exp := regexp.MustCompile(`\$\{\s*` + regexp.QuoteMeta(key) + `\s*` + variableModifierExp + `\}`)
+		tmp = exp.ReplaceAllStringFunc(tmp, func(match string) string {
+			e := parseVariableExpression(key, exp.FindStringSubmatch(match)[1])
+			value, ok := e.eval(val, isSet)
+			if !ok {
+				if !missing && e.operator == requiredOperator && e.argument != "" {
+					messages = append(messages, fmt.Sprintf("%s: %s", key, e.argument))
+				}
+				missing = true
+				// leave the variable untouched, so it is possible to skip variables without a value.
+				return match
+			}
+			return value
+		})
+		if missing {
+			missingVariables = append(missingVariables, key)
+		}
+	}
+	if !skipVariables && len(missingVariables) > 0 {
+		details := ""
+		if len(messages) > 0 {
+			details = fmt.Sprintf(" (%s)", strings.Join(messages, "; "))
+		}
+		return nil, errors.Errorf("value for variables [%s] is not set%s. Please set the value using os environment variables or the clusterctl config file", strings.Join(missingVariables, ", "), details)
+	}
+
+	return []byte(tmp), nil
+}
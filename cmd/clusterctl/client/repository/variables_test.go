@@ -0,0 +1,282 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_inspectVariables(t *testing.T) {
+	type args struct {
+		data string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "variable with different spacing around the name",
+			args: args{
+				data: "yaml with ${A} ${ B} ${ C} ${ D }",
+			},
+			want: []string{"A", "B", "C", "D"},
+		},
+		{
+			name: "variables used in many places are grouped",
+			args: args{
+				data: "yaml with ${A} ${A} ${A}",
+			},
+			want: []string{"A"},
+		},
+		{
+			name: "variables in multiline texts are processed",
+			args: args{
+				data: "yaml with ${A}\n${B}\n${C}",
+			},
+			want: []string{"A", "B", "C"},
+		},
+		{
+			name: "variables with modifiers are processed",
+			args: args{
+				data: "yaml with ${A:=a} ${ B:-b } ${C:?message} ${D/foo/bar}",
+			},
+			want: []string{"A", "B", "C", "D"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(inspectVariables([]byte(tt.args.data))).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_replaceVariables(t *testing.T) {
+	type args struct {
+		yaml                  []byte
+		variables             []string
+		configVariablesClient config.VariablesClient
+		skipVariables         bool
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name: "pass and replaces variables",
+			args: args{
+				yaml:      []byte("foo ${ BAR }"),
+				variables: []string{"BAR"},
+				configVariablesClient: test.NewFakeVariableClient().
+					WithVar("BAR", "bar"),
+				skipVariables: false,
+			},
+			want:    []byte("foo bar"),
+			wantErr: false,
+		},
+		{
+			name: "pass and replaces variables when variable name contains regex metacharacters",
+			args: args{
+				yaml:      []byte("foo ${ BA$R }"),
+				variables: []string{"BA$R"},
+				configVariablesClient: test.NewFakeVariableClient().
+					WithVar("BA$R", "bar"),
+				skipVariables: false,
+			},
+			want:    []byte("foo bar"),
+			wantErr: false,
+		},
+		{
+			name: "pass and replaces variables when variable value contains regex metacharacters",
+			args: args{
+				yaml:      []byte("foo ${ BAR }"),
+				variables: []string{"BAR"},
+				configVariablesClient: test.NewFakeVariableClient().
+					WithVar("BAR", "ba$r"),
+				skipVariables: false,
+			},
+			want:    []byte("foo ba$r"),
+			wantErr: false,
+		},
+		{
+			name: "fails for missing variables and not skip variables",
+			args: args{
+				yaml:                  []byte("foo ${ BAR } ${ BAZ }"),
+				variables:             []string{"BAR", "BAZ"},
+				configVariablesClient: test.NewFakeVariableClient(),
+				skipVariables:         false,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "pass and replaces variables with default values",
+			args: args{
+				yaml:      []byte("foo ${ BAR:=bar } ${BAZ:-baz } ${QUX:=qux}"),
+				variables: []string{"BAR", "BAZ", "QUX"},
+				configVariablesClient: test.NewFakeVariableClient().
+					WithVar("BAZ", "").
+					WithVar("QUX", "value"),
+				skipVariables: false,
+			},
+			want:    []byte("foo bar baz value"),
+			wantErr: false,
+		},
+		{
+			name: "pass and replaces variables with required marker",
+			args: args{
+				yaml:      []byte("foo ${ BAR:?bar must be set }"),
+				variables: []string{"BAR"},
+				configVariablesClient: test.NewFakeVariableClient().
+					WithVar("BAR", "bar"),
+				skipVariables: false,
+			},
+			want:    []byte("foo bar"),
+			wantErr: false,
+		},
+		{
+			name: "fails for empty variables with required marker",
+			args: args{
+				yaml:      []byte("foo ${ BAR:?bar must be set }"),
+				variables: []string{"BAR"},
+				configVariablesClient: test.NewFakeVariableClient().
+					WithVar("BAR", ""),
+				skipVariables: false,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "pass and replaces variables with pattern replacement",
+			args: args{
+				yaml:      []byte("foo ${BAR/./-} ${BAR//./-} ${BAR/.}"),
+				variables: []string{"BAR"},
+				configVariablesClient: test.NewFakeVariableClient().
+					WithVar("BAR", "v1.18.2"),
+				skipVariables: false,
+			},
+			want:    []byte("foo v1-18.2 v1-18-2 v118.2"),
+			wantErr: false,
+		},
+		{
+			name: "fails for missing variables with pattern replacement",
+			args: args{
+				yaml:                  []byte("foo ${BAR/./-}"),
+				variables:             []string{"BAR"},
+				configVariablesClient: test.NewFakeVariableClient(),
+				skipVariables:         false,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "fails for missing variables used both with and without default",
+			args: args{
+				yaml:                  []byte("foo ${BAR:=bar} ${BAR}"),
+				variables:             []string{"BAR"},
+				configVariablesClient: test.NewFakeVariableClient(),
+				skipVariables:         false,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "pass when missing variables and skip variables",
+			args: args{
+				yaml:      []byte("foo ${ BAR } ${ BAZ }"),
+				variables: []string{"BAR", "BAZ"},
+				configVariablesClient: test.NewFakeVariableClient().
+					WithVar("BAR", "bar"),
+				skipVariables: true,
+			},
+			want:    []byte("foo bar ${ BAZ }"),
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := replaceVariables(tt.args.yaml, tt.args.variables, tt.args.configVariablesClient, tt.args.skipVariables)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_inspectVariableDetails(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []Variable
+	}{
+		{
+			name: "variables without modifiers are required",
+			data: "yaml with ${A} ${ B }",
+			want: []Variable{
+				{Name: "A", Required: true},
+				{Name: "B", Required: true},
+			},
+		},
+		{
+			name: "variables with defaults are optional",
+			data: "yaml with ${A:=a} ${ B:-b } ${C:=}",
+			want: []Variable{
+				{Name: "A", Required: false, Default: pointer.StringPtr("a")},
+				{Name: "B", Required: false, Default: pointer.StringPtr("b")},
+				{Name: "C", Required: false, Default: pointer.StringPtr("")},
+			},
+		},
+		{
+			name: "variables with required marker report the error message",
+			data: "yaml with ${A:?a must be set} ${B/foo/bar}",
+			want: []Variable{
+				{Name: "A", Required: true, Message: "a must be set"},
+				{Name: "B", Required: true},
+			},
+		},
+		{
+			name: "variables are required if at least one occurrence does not have a default",
+			data: "yaml with ${A:=a} ${A} ${A:=b}",
+			want: []Variable{
+				{Name: "A", Required: true, Default: pointer.StringPtr("a")},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(inspectVariableDetails([]byte(tt.data))).To(Equal(tt.want))
+		})
+	}
+}
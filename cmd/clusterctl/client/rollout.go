@@ -0,0 +1,153 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+)
+
+// RolloutOptions carries the base set of options supported by the rollout commands.
+type RolloutOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// Resources to be rolled out, in the kind/name format, e.g. machinedeployment/my-md or kcp/my-control-plane.
+	// The supported kinds are MachineDeployment (md) and KubeadmControlPlane (kcp).
+	Resources []string
+
+	// Namespace where the resources exist. If unspecified, the current namespace will be used.
+	Namespace string
+}
+
+// RolloutUndoOptions carries the options supported by RolloutUndo.
+type RolloutUndoOptions struct {
+	RolloutOptions
+
+	// ToRevision is the revision to roll back to; if zero, the previous revision is used.
+	ToRevision int64
+}
+
+// rolloutKinds maps the kinds supported by rollout, including their aliases, to the corresponding Kind.
+var rolloutKinds = map[string]string{
+	"machinedeployment":    cluster.MachineDeploymentKind,
+	"machinedeployments":   cluster.MachineDeploymentKind,
+	"md":                   cluster.MachineDeploymentKind,
+	"kubeadmcontrolplane":  cluster.KubeadmControlPlaneKind,
+	"kubeadmcontrolplanes": cluster.KubeadmControlPlaneKind,
+	"kcp":                  cluster.KubeadmControlPlaneKind,
+}
+
+func (c *clusterctlClient) RolloutRestart(options RolloutOptions) error {
+	return c.rollout(options, func(r cluster.RolloutClient, ref corev1.ObjectReference) error {
+		return r.Restart(ref)
+	})
+}
+
+func (c *clusterctlClient) RolloutPause(options RolloutOptions) error {
+	return c.rollout(options, func(r cluster.RolloutClient, ref corev1.ObjectReference) error {
+		return r.Pause(ref)
+	})
+}
+
+func (c *clusterctlClient) RolloutResume(options RolloutOptions) error {
+	return c.rollout(options, func(r cluster.RolloutClient, ref corev1.ObjectReference) error {
+		return r.Resume(ref)
+	})
+}
+
+func (c *clusterctlClient) RolloutUndo(options RolloutUndoOptions) error {
+	return c.rollout(options.RolloutOptions, func(r cluster.RolloutClient, ref corev1.ObjectReference) error {
+		return r.Undo(ref, options.ToRevision)
+	})
+}
+
+func (c *clusterctlClient) RolloutHistory(options RolloutOptions) ([]RolloutRevision, error) {
+	if len(options.Resources) != 1 {
+		return nil, errors.New("rollout history requires exactly one resource")
+	}
+
+	var revisions []RolloutRevision
+	err := c.rollout(options, func(r cluster.RolloutClient, ref corev1.ObjectReference) error {
+		var err error
+		revisions, err = r.History(ref)
+		return err
+	})
+	return revisions, err
+}
+
+// rollout executes a rollout operation on each one of the resources.
+func (c *clusterctlClient) rollout(options RolloutOptions, operation func(r cluster.RolloutClient, ref corev1.ObjectReference) error) error {
+	if len(options.Resources) == 0 {
+		return errors.New("at least one resource must be specified")
+	}
+
+	// Get the client for interacting with the management cluster.
+	clusterClient, err := c.clusterClientFactory(options.Kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	// If the option specifying the Namespace is empty, try to detect it.
+	if options.Namespace == "" {
+		currentNamespace, err := clusterClient.Proxy().CurrentNamespace()
+		if err != nil {
+			return err
+		}
+		options.Namespace = currentNamespace
+	}
+
+	// Parse all the resources before executing the operation, so no operation is executed if any resource is invalid.
+	refs := make([]corev1.ObjectReference, 0, len(options.Resources))
+	for _, resource := range options.Resources {
+		ref, err := parseRolloutResource(resource, options.Namespace)
+		if err != nil {
+			return err
+		}
+		refs = append(refs, ref)
+	}
+
+	for _, ref := range refs {
+		if err := operation(clusterClient.Rollout(), ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseRolloutResource parses a resource in the kind/name format.
+func parseRolloutResource(resource, namespace string) (corev1.ObjectReference, error) {
+	parts := strings.Split(resource, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return corev1.ObjectReference{}, errors.Errorf("invalid resource %q, the kind/name format is required, e.g. machinedeployment/my-md", resource)
+	}
+
+	kind, ok := rolloutKinds[strings.ToLower(parts[0])]
+	if !ok {
+		return corev1.ObjectReference{}, errors.Errorf("invalid resource kind %q, rollout supports only MachineDeployment (md) and KubeadmControlPlane (kcp)", parts[0])
+	}
+
+	return corev1.ObjectReference{
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      parts[1],
+	}, nil
+}
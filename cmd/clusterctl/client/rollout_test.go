@@ -0,0 +1,116 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+)
+
+func Test_parseRolloutResource(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource string
+		want     corev1.ObjectReference
+		wantErr  bool
+	}{
+		{
+			name:     "machinedeployment",
+			resource: "machinedeployment/foo",
+			want:     corev1.ObjectReference{Kind: cluster.MachineDeploymentKind, Namespace: "ns1", Name: "foo"},
+		},
+		{
+			name:     "kubeadmcontrolplane alias",
+			resource: "KCP/foo",
+			want:     corev1.ObjectReference{Kind: cluster.KubeadmControlPlaneKind, Namespace: "ns1", Name: "foo"},
+		},
+		{
+			name:     "unsupported kind",
+			resource: "machineset/foo",
+			wantErr:  true,
+		},
+		{
+			name:     "missing name",
+			resource: "md",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := parseRolloutResource(tt.resource, "ns1")
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_clusterctlClient_RolloutPause(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources []string
+		wantErr   bool
+	}{
+		{
+			name:      "pauses a MachineDeployment in the current namespace",
+			resources: []string{"md/foo"},
+		},
+		{
+			name:      "returns an error if the MachineDeployment does not exist",
+			resources: []string{"md/bar"},
+			wantErr:   true,
+		},
+		{
+			name:      "returns an error if no resources are specified",
+			resources: nil,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			config1 := newFakeConfig()
+			cluster1 := newFakeCluster(cluster.Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"}, config1).
+				WithObjs(&clusterv1.MachineDeployment{
+					TypeMeta:   metav1.TypeMeta{APIVersion: clusterv1.GroupVersion.String(), Kind: "MachineDeployment"},
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"},
+				})
+			client := newFakeClient(config1).WithCluster(cluster1)
+
+			err := client.RolloutPause(RolloutOptions{
+				Kubeconfig: Kubeconfig{Path: "kubeconfig", Context: "mgmt-context"},
+				Resources:  tt.resources,
+			})
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
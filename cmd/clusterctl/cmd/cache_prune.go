@@ -0,0 +1,68 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type cachePruneOptions struct {
+	olderThan time.Duration
+}
+
+var cp = &cachePruneOptions{}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove files from the local cache of the provider repositories.",
+	Long: LongDesc(`
+		Remove files from the local cache of the provider repositories.
+
+		By default all the cached files are removed; use --older-than for removing only
+		the files that were downloaded before the given duration.`),
+
+	Example: Examples(`
+		# Removes all the files from the cache.
+		clusterctl cache prune
+
+		# Removes the files downloaded more than one week ago.
+		clusterctl cache prune --older-than 168h`),
+
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCachePrune()
+	},
+}
+
+func init() {
+	cachePruneCmd.Flags().DurationVar(&cp.olderThan, "older-than", 0,
+		"Remove only the files downloaded before the given duration, e.g. 24h. If unspecified, all the files are removed.")
+}
+
+func runCachePrune() error {
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	return c.PruneCache(client.PruneCacheOptions{
+		OlderThan: cp.olderThan,
+	})
+}
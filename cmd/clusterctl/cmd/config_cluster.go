@@ -35,6 +35,7 @@ type configClusterOptions struct {
 	kubernetesVersion        string
 	controlPlaneMachineCount int64
 	workerMachineCount       int64
+	valuesFile               string
 
 	url                string
 	configMapNamespace string
@@ -42,6 +43,7 @@ type configClusterOptions struct {
 	configMapDataKey   string
 
 	listVariables bool
+	validate      bool
 }
 
 var cc = &configClusterOptions{}
@@ -77,6 +79,18 @@ var configClusterClusterCmd = &cobra.Command{
 		# custom number of nodes (if supported by the provider's templates).
 		clusterctl config cluster my-cluster --control-plane-machine-count=3 --worker-machine-count=10
 
+		# Generates a configuration file for creating workload clusters reading
+		# the template variables from a YAML file, e.g. from a CI pipeline.
+		clusterctl config cluster my-cluster --values=values.yaml
+
+		# Generates a configuration file for creating workload clusters using a flavor
+		# composed of many overlays applied in order on top of the default cluster template.
+		clusterctl config cluster my-cluster --flavor=ha,ipv6
+
+		# Generates a configuration file for creating workload clusters, checking that all the objects
+		# are going to be accepted by the management cluster.
+		clusterctl config cluster my-cluster --validate
+
 		# Generates a configuration file for creating workload clusters using a template stored in a ConfigMap.
 		clusterctl config cluster my-cluster --from-config-map MyTemplates
 
@@ -107,12 +121,15 @@ func init() {
 		"The number of control plane machines for the workload cluster.")
 	configClusterClusterCmd.Flags().Int64Var(&cc.workerMachineCount, "worker-machine-count", 0,
 		"The number of worker machines for the workload cluster.")
+	configClusterClusterCmd.Flags().StringVar(&cc.valuesFile, "values", "",
+		"Path to a YAML file defining a map of template variables. Values from this file take precedence over OS environment variables and the .cluster-api/clusterctl.yaml config file.")
 
 	// flags for the repository source
 	configClusterClusterCmd.Flags().StringVarP(&cc.infrastructureProvider, "infrastructure", "i", "",
 		"The infrastructure provider to read the workload cluster template from. If unspecified, the default infrastructure provider will be used.")
 	configClusterClusterCmd.Flags().StringVarP(&cc.flavor, "flavor", "f", "",
-		"The workload cluster template variant to be used when reading from the infrastructure provider repository. If unspecified, the default cluster template will be used.")
+		"The workload cluster template variant to be used. If unspecified, the default cluster template will be used. "+
+			"A comma separated list of flavors can be used for applying many overlays on top of the default cluster template, e.g. --flavor=ha,ipv6")
 
 	// flags for the url source
 	configClusterClusterCmd.Flags().StringVar(&cc.url, "from", "",
@@ -128,7 +145,9 @@ func init() {
 
 	// other flags
 	configClusterClusterCmd.Flags().BoolVar(&cc.listVariables, "list-variables", false,
-		"Returns the list of variables expected by the template instead of the template yaml")
+		"Returns the list of variables expected by the template, with their default values, instead of the template yaml")
+	configClusterClusterCmd.Flags().BoolVar(&cc.validate, "validate", false,
+		"Validates the template against the providers installed in the management cluster, including a server-side dry-run apply of all the objects")
 
 	configCmd.AddCommand(configClusterClusterCmd)
 }
@@ -144,7 +163,9 @@ func runGetClusterTemplate(cmd *cobra.Command, name string) error {
 		ClusterName:       name,
 		TargetNamespace:   cc.targetNamespace,
 		KubernetesVersion: cc.kubernetesVersion,
+		ValuesFile:        cc.valuesFile,
 		ListVariablesOnly: cc.listVariables,
+		Validate:          cc.validate,
 	}
 
 	if cmd.Flags().Changed("control-plane-machine-count") {
@@ -156,7 +177,8 @@ func runGetClusterTemplate(cmd *cobra.Command, name string) error {
 
 	if cc.url != "" {
 		templateOptions.URLSource = &client.URLSourceOptions{
-			URL: cc.url,
+			URL:    cc.url,
+			Flavor: cc.flavor,
 		}
 	}
 
@@ -165,10 +187,12 @@ func runGetClusterTemplate(cmd *cobra.Command, name string) error {
 			Namespace: cc.configMapNamespace,
 			Name:      cc.configMapName,
 			DataKey:   cc.configMapDataKey,
+			Flavor:    cc.flavor,
 		}
 	}
 
-	if cc.infrastructureProvider != "" || cc.flavor != "" {
+	// NB. when reading from an URL or from a ConfigMap, flavors are applied as overlays to the given template.
+	if cc.infrastructureProvider != "" || (cc.flavor != "" && templateOptions.URLSource == nil && templateOptions.ConfigMapSource == nil) {
 		templateOptions.ProviderRepositorySource = &client.ProviderRepositorySourceOptions{
 			InfrastructureProvider: cc.infrastructureProvider,
 			Flavor:                 cc.flavor,
@@ -188,16 +212,44 @@ func runGetClusterTemplate(cmd *cobra.Command, name string) error {
 }
 
 func templateListVariablesOutput(template client.Template) error {
-	if len(template.Variables()) > 0 {
-		fmt.Println("Variables:")
-		for _, v := range template.Variables() {
-			fmt.Printf("  - %s\n", v)
-		}
-	}
+	printVariables(template.VariableDetails())
 	fmt.Println()
 	return nil
 }
 
+// printVariables prints the list of required variables, followed by the list of optional variables with their default values.
+func printVariables(variables []client.Variable) {
+	var required, optional []client.Variable
+	for _, v := range variables {
+		if v.Required {
+			required = append(required, v)
+			continue
+		}
+		optional = append(optional, v)
+	}
+
+	if len(required) > 0 {
+		fmt.Println("Required Variables:")
+		for _, v := range required {
+			switch {
+			case v.Message != "":
+				fmt.Printf("  - %s (%s)\n", v.Name, v.Message)
+			case v.Default != nil:
+				fmt.Printf("  - %s (defaults to %q where optional)\n", v.Name, *v.Default)
+			default:
+				fmt.Printf("  - %s\n", v.Name)
+			}
+		}
+	}
+
+	if len(optional) > 0 {
+		fmt.Println("Optional Variables:")
+		for _, v := range optional {
+			fmt.Printf("  - %s (defaults to %q)\n", v.Name, *v.Default)
+		}
+	}
+}
+
 func templateYAMLOutput(template client.Template) error {
 	yaml, err := template.Yaml()
 	if err != nil {
@@ -164,12 +164,7 @@ func printComponents(c client.Components, output string) error {
 		fmt.Printf("File:               %s\n", file)
 		fmt.Printf("TargetNamespace:    %s\n", c.TargetNamespace())
 		fmt.Printf("WatchingNamespace:  %s\n", c.WatchingNamespace())
-		if len(c.Variables()) > 0 {
-			fmt.Println("Variables:")
-			for _, v := range c.Variables() {
-				fmt.Printf("  - %s\n", v)
-			}
-		}
+		printVariables(c.VariableDetails())
 		if len(c.Images()) > 0 {
 			fmt.Println("Images:")
 			for _, v := range c.Images() {
@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type generateYamlOptions struct {
+	url           string
+	valuesFile    string
+	listVariables bool
+}
+
+var gy = &generateYamlOptions{}
+
+var generateYamlCmd = &cobra.Command{
+	Use:   "yaml",
+	Short: "Process yaml using clusterctl's variable substitution.",
+	Long: LongDesc(`
+		Process yaml using clusterctl's variable substitution.
+
+		Unlike clusterctl config cluster, any template can be processed, e.g. the manifest of an addon;
+		variables are replaced with values from the values file, OS environment variables or the
+		.cluster-api/clusterctl.yaml config file, while the namespaces of the objects are preserved.`),
+
+	Example: Examples(`
+		# Generates a configuration file with variable values using a template from a specific URL.
+		clusterctl generate yaml --from https://github.com/foo-org/foo-repository/blob/master/addon.yaml
+
+		# Generates a configuration file with variable values using a template stored locally,
+		# reading the variable values from a YAML file.
+		clusterctl generate yaml --from ~/workspace/addon.yaml --values values.yaml
+
+		# Prints the list of variables required by the template.
+		clusterctl generate yaml --from ~/workspace/addon.yaml --list-variables`),
+
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGenerateYAML()
+	},
+}
+
+func init() {
+	generateYamlCmd.Flags().StringVar(&gy.url, "from", "",
+		"The URL to read the template from, either a path on the local file system or a GitHub URL.")
+	generateYamlCmd.Flags().StringVar(&gy.valuesFile, "values", "",
+		"Path to a YAML file defining a map of template variables. Values from this file take precedence over OS environment variables and the .cluster-api/clusterctl.yaml config file.")
+	generateYamlCmd.Flags().BoolVar(&gy.listVariables, "list-variables", false,
+		"Returns the list of variables expected by the template, with their default values, instead of the processed yaml")
+}
+
+func runGenerateYAML() error {
+	if gy.url == "" {
+		return errors.New("please specify the template to process using the --from flag")
+	}
+
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	template, err := c.ProcessYAML(client.ProcessYAMLOptions{
+		URL:               gy.url,
+		ValuesFile:        gy.valuesFile,
+		ListVariablesOnly: gy.listVariables,
+	})
+	if err != nil {
+		return err
+	}
+
+	if gy.listVariables {
+		return templateListVariablesOutput(template)
+	}
+
+	return templateYAMLOutput(template)
+}
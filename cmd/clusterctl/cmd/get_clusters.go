@@ -0,0 +1,97 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type getClustersOptions struct {
+	kubeconfig        string
+	kubeconfigContext string
+	namespace         string
+	allNamespaces     bool
+}
+
+var gc = &getClustersOptions{}
+
+var getClustersCmd = &cobra.Command{
+	Use:   "clusters",
+	Short: "List the workload clusters hosted in a management cluster.",
+	Long: LongDesc(`
+		List the workload clusters hosted in a management cluster.
+
+		For each workload cluster, the phase, the readiness of the control plane, the Kubernetes version
+		and the number of Machines with a Node over the number of Machines are reported.`),
+
+	Example: Examples(`
+		# List the workload clusters in the current namespace.
+		clusterctl get clusters
+
+		# List the workload clusters in all the namespaces.
+		clusterctl get clusters -A`),
+
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGetClusters(os.Stdout)
+	},
+}
+
+func init() {
+	getClustersCmd.Flags().StringVar(&gc.kubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig file to use for accessing the management cluster. If empty, default discovery rules apply.")
+	getClustersCmd.Flags().StringVar(&gc.kubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	getClustersCmd.Flags().StringVarP(&gc.namespace, "namespace", "n", "",
+		"The namespace where the workload clusters are hosted. If unspecified, the current context's namespace is used.")
+	getClustersCmd.Flags().BoolVarP(&gc.allNamespaces, "all-namespaces", "A", false,
+		"List the workload clusters in all the namespaces.")
+}
+
+func runGetClusters(out io.Writer) error {
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	clusters, err := c.GetClusters(client.GetClustersOptions{
+		Kubeconfig:    client.Kubeconfig{Path: gc.kubeconfig, Context: gc.kubeconfigContext},
+		Namespace:     gc.namespace,
+		AllNamespaces: gc.allNamespaces,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(clusters) == 0 {
+		fmt.Fprintln(out, "No workload clusters found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 10, 4, 3, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tPHASE\tCONTROLPLANE READY\tVERSION\tMACHINES")
+	for _, cluster := range clusters {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\t%d/%d\n", cluster.Namespace, cluster.Name, cluster.Phase, cluster.ControlPlaneReady, cluster.Version, cluster.ReadyMachines, cluster.Machines)
+	}
+	return w.Flush()
+}
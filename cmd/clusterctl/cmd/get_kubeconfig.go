@@ -0,0 +1,144 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type getKubeconfigOptions struct {
+	kubeconfig        string
+	kubeconfigContext string
+	namespace         string
+	merge             bool
+}
+
+var gk = &getKubeconfigOptions{}
+
+var getKubeconfigCmd = &cobra.Command{
+	Use:   "kubeconfig NAME",
+	Short: "Get the kubeconfig of a workload cluster.",
+	Long: LongDesc(`
+		Get the kubeconfig of a workload cluster.
+
+		The kubeconfig is read from the Secret created by Cluster API in the management cluster, and it is
+		printed on stdout, unless --merge is used; in this case the kubeconfig is merged into the kubeconfig
+		file used for accessing the management cluster, replacing the entries with the same name, if any.`),
+
+	Example: Examples(`
+		# Get the kubeconfig of a workload cluster.
+		clusterctl get kubeconfig my-cluster > my-cluster.kubeconfig
+
+		# Get the kubeconfig of a workload cluster in the foo namespace.
+		clusterctl get kubeconfig my-cluster -n foo
+
+		# Merge the kubeconfig of a workload cluster into the kubeconfig file of the management cluster.
+		clusterctl get kubeconfig my-cluster --merge`),
+
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGetKubeconfig(args[0])
+	},
+}
+
+func init() {
+	getKubeconfigCmd.Flags().StringVar(&gk.kubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig file to use for accessing the management cluster. If empty, default discovery rules apply.")
+	getKubeconfigCmd.Flags().StringVar(&gk.kubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	getKubeconfigCmd.Flags().StringVarP(&gk.namespace, "namespace", "n", "",
+		"The namespace where the workload cluster is hosted. If unspecified, the current context's namespace is used.")
+	getKubeconfigCmd.Flags().BoolVar(&gk.merge, "merge", false,
+		"Merge the kubeconfig of the workload cluster into the kubeconfig file used for accessing the management cluster, instead of printing it.")
+}
+
+func runGetKubeconfig(workloadClusterName string) error {
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	kubeconfig, err := c.GetKubeconfig(client.GetKubeconfigOptions{
+		Kubeconfig:          client.Kubeconfig{Path: gk.kubeconfig, Context: gk.kubeconfigContext},
+		WorkloadClusterName: workloadClusterName,
+		Namespace:           gk.namespace,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !gk.merge {
+		fmt.Print(kubeconfig)
+		return nil
+	}
+
+	path := gk.kubeconfig
+	if path == "" {
+		path = clientcmd.NewDefaultClientConfigLoadingRules().GetDefaultFilename()
+	}
+	context, err := mergeKubeconfig([]byte(kubeconfig), path)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("The kubeconfig of cluster %q has been merged into %s; use the %q context for accessing the workload cluster.\n", workloadClusterName, path, context)
+	return nil
+}
+
+// mergeKubeconfig merges a kubeconfig into the kubeconfig file with the given path, creating it if it does not exist.
+// The clusters, users and contexts of the kubeconfig replace the existing ones with the same name. It returns the
+// current context of the kubeconfig.
+func mergeKubeconfig(kubeconfig []byte, path string) (string, error) {
+	config, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse the kubeconfig of the workload cluster")
+	}
+
+	existing := clientcmdapi.NewConfig()
+	if _, err := os.Stat(path); err == nil {
+		existing, err = clientcmd.LoadFromFile(path)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to load kubeconfig file %q", path)
+		}
+	} else if !os.IsNotExist(err) {
+		return "", errors.Wrapf(err, "failed to read kubeconfig file %q", path)
+	}
+
+	for name, cluster := range config.Clusters {
+		existing.Clusters[name] = cluster
+	}
+	for name, authInfo := range config.AuthInfos {
+		existing.AuthInfos[name] = authInfo
+	}
+	for name, context := range config.Contexts {
+		existing.Contexts[name] = context
+	}
+	if existing.CurrentContext == "" {
+		existing.CurrentContext = config.CurrentContext
+	}
+
+	if err := clientcmd.WriteToFile(*existing, path); err != nil {
+		return "", errors.Wrapf(err, "failed to write kubeconfig file %q", path)
+	}
+	return config.CurrentContext, nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func newTestKubeconfig(name string) *clientcmdapi.Config {
+	return &clientcmdapi.Config{
+		Clusters: map[string]*clientcmdapi.Cluster{
+			name: {Server: "https://" + name + ":6443"},
+		},
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{
+			name + "-admin": {Token: name},
+		},
+		Contexts: map[string]*clientcmdapi.Context{
+			name + "-admin@" + name: {Cluster: name, AuthInfo: name + "-admin"},
+		},
+		CurrentContext: name + "-admin@" + name,
+	}
+}
+
+func Test_mergeKubeconfig(t *testing.T) {
+	g := NewWithT(t)
+
+	tmpDir, err := ioutil.TempDir("", "cc")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(tmpDir)
+
+	workload, err := clientcmd.Write(*newTestKubeconfig("workload"))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// The kubeconfig file is created if it does not exist.
+	path := filepath.Join(tmpDir, ".kube", "config")
+	context, err := mergeKubeconfig(workload, path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(context).To(Equal("workload-admin@workload"))
+
+	config, err := clientcmd.LoadFromFile(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(config.CurrentContext).To(Equal("workload-admin@workload"))
+	g.Expect(config.Contexts).To(HaveKey("workload-admin@workload"))
+
+	// The kubeconfig is merged into an existing file, keeping its current context.
+	g.Expect(clientcmd.WriteToFile(*newTestKubeconfig("mgmt"), path)).To(Succeed())
+	_, err = mergeKubeconfig(workload, path)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	config, err = clientcmd.LoadFromFile(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(config.CurrentContext).To(Equal("mgmt-admin@mgmt"))
+	g.Expect(config.Clusters).To(HaveKey("mgmt"))
+	g.Expect(config.Clusters).To(HaveKey("workload"))
+	g.Expect(config.AuthInfos).To(HaveKey("workload-admin"))
+	g.Expect(config.Contexts).To(HaveKey("workload-admin@workload"))
+	g.Expect(config.Contexts).To(HaveKey("mgmt-admin@mgmt"))
+
+	// Invalid kubeconfig are rejected.
+	_, err = mergeKubeconfig([]byte("invalid"), path)
+	g.Expect(err).To(HaveOccurred())
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type rolloutOptions struct {
+	kubeconfig        string
+	kubeconfigContext string
+	namespace         string
+	toRevision        int64
+}
+
+var ro = &rolloutOptions{}
+
+var rolloutCmd = &cobra.Command{
+	Use:   "rollout",
+	Short: "Manage the rollout of a Cluster API resource.",
+	Long: LongDesc(`
+		Manage the rollout of the Machines of a Cluster API resource.
+
+		Valid resource types include:
+		- machinedeployment (md)
+		- kubeadmcontrolplane (kcp)`),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Help()
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{rolloutRestartCmd, rolloutPauseCmd, rolloutResumeCmd, rolloutUndoCmd, rolloutHistoryCmd} {
+		c.Flags().StringVar(&ro.kubeconfig, "kubeconfig", "",
+			"Path to the kubeconfig file to use for accessing the management cluster. If empty, default discovery rules apply.")
+		c.Flags().StringVar(&ro.kubeconfigContext, "kubeconfig-context", "",
+			"Context to be used within the kubeconfig file. If empty, current context will be used.")
+		c.Flags().StringVarP(&ro.namespace, "namespace", "n", "",
+			"The namespace where the resources are hosted. If unspecified, the current context's namespace is used.")
+		rolloutCmd.AddCommand(c)
+	}
+}
+
+// rolloutClientOptions returns the client.RolloutOptions for the given resources.
+func rolloutClientOptions(resources []string) client.RolloutOptions {
+	return client.RolloutOptions{
+		Kubeconfig: client.Kubeconfig{Path: ro.kubeconfig, Context: ro.kubeconfigContext},
+		Resources:  resources,
+		Namespace:  ro.namespace,
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+var rolloutHistoryCmd = &cobra.Command{
+	Use:   "history RESOURCE",
+	Short: "Show the rollout history of a Cluster API resource.",
+	Long: LongDesc(`
+		Show the rollout history of a Cluster API resource.
+
+		Only MachineDeployments are supported; for each revision, the MachineSet, its creation time and
+		a summary of the changes to the machine template from the previous revision are reported.`),
+
+	Example: Examples(`
+		# Show the rollout history of a MachineDeployment.
+		clusterctl alpha rollout history machinedeployment/my-md`),
+
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRolloutHistory(args, os.Stdout)
+	},
+}
+
+func runRolloutHistory(resources []string, out io.Writer) error {
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	revisions, err := c.RolloutHistory(rolloutClientOptions(resources))
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 10, 4, 3, ' ', 0)
+	fmt.Fprintln(w, "REVISION\tMACHINESET\tCREATED\tCHANGES")
+	for _, r := range revisions {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", r.Revision, r.MachineSet, r.CreationTimestamp.UTC().Format("2006-01-02 15:04:05"), strings.Join(r.Changes, ", "))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+var rolloutPauseCmd = &cobra.Command{
+	Use:   "pause RESOURCE",
+	Short: "Pause a Cluster API resource.",
+	Long: LongDesc(`
+		Pause a Cluster API resource, i.e. stop the rollout of its Machines.
+
+		For a MachineDeployment, spec.paused is set; changes to the machine template are not rolled out
+		until the MachineDeployment is resumed. For a KubeadmControlPlane, the cluster.x-k8s.io/paused
+		annotation is set, so the KubeadmControlPlane is not reconciled until it is resumed.`),
+
+	Example: Examples(`
+		# Pause a MachineDeployment.
+		clusterctl alpha rollout pause machinedeployment/my-md`),
+
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRolloutPause(args)
+	},
+}
+
+func runRolloutPause(resources []string) error {
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	return c.RolloutPause(rolloutClientOptions(resources))
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+var rolloutRestartCmd = &cobra.Command{
+	Use:   "restart RESOURCE",
+	Short: "Restart a Cluster API resource.",
+	Long: LongDesc(`
+		Restart a Cluster API resource, i.e. trigger the rollout of all its Machines.
+
+		For a MachineDeployment, the cluster.x-k8s.io/restartedAt annotation is set on the machine template,
+		so a new MachineSet is created. For a KubeadmControlPlane, spec.upgradeAfter is set to the current time,
+		so all the control plane Machines created before are replaced.`),
+
+	Example: Examples(`
+		# Restart a MachineDeployment.
+		clusterctl alpha rollout restart machinedeployment/my-md
+
+		# Restart a KubeadmControlPlane in the foo namespace.
+		clusterctl alpha rollout restart kcp/my-control-plane -n foo`),
+
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRolloutRestart(args)
+	},
+}
+
+func runRolloutRestart(resources []string) error {
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	return c.RolloutRestart(rolloutClientOptions(resources))
+}
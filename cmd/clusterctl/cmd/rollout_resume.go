@@ -0,0 +1,46 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+var rolloutResumeCmd = &cobra.Command{
+	Use:   "resume RESOURCE",
+	Short: "Resume a paused Cluster API resource.",
+	Long: LongDesc(`
+		Resume a paused Cluster API resource, so the changes applied while it was paused are rolled out.`),
+
+	Example: Examples(`
+		# Resume a MachineDeployment.
+		clusterctl alpha rollout resume machinedeployment/my-md`),
+
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRolloutResume(args)
+	},
+}
+
+func runRolloutResume(resources []string) error {
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	return c.RolloutResume(rolloutClientOptions(resources))
+}
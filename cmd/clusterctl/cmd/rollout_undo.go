@@ -0,0 +1,60 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+var rolloutUndoCmd = &cobra.Command{
+	Use:   "undo RESOURCE",
+	Short: "Undo the rollout of a Cluster API resource.",
+	Long: LongDesc(`
+		Undo the rollout of a Cluster API resource, i.e. roll back to the machine template of a previous revision.
+
+		Only MachineDeployments are supported; the machine template of the MachineSet with the given revision
+		is restored into the MachineDeployment. Use clusterctl alpha rollout history for listing the revisions.`),
+
+	Example: Examples(`
+		# Roll back a MachineDeployment to the previous revision.
+		clusterctl alpha rollout undo machinedeployment/my-md
+
+		# Roll back a MachineDeployment to revision 3.
+		clusterctl alpha rollout undo machinedeployment/my-md --to-revision=3`),
+
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRolloutUndo(args)
+	},
+}
+
+func init() {
+	rolloutUndoCmd.Flags().Int64Var(&ro.toRevision, "to-revision", 0,
+		"The revision to roll back to. If unspecified, the previous revision is used.")
+}
+
+func runRolloutUndo(resources []string) error {
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	return c.RolloutUndo(client.RolloutUndoOptions{
+		RolloutOptions: rolloutClientOptions(resources),
+		ToRevision:     ro.toRevision,
+	})
+}
@@ -37,6 +37,7 @@ type stackTracer interface {
 var (
 	cfgFile   string
 	verbosity *int
+	offline   bool
 )
 
 var RootCmd = &cobra.Command{
@@ -70,11 +71,23 @@ func init() {
 	RootCmd.PersistentFlags().AddGoFlagSet(flag.CommandLine)
 	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "",
 		"Path to clusterctl configuration (default is `$HOME/.cluster-api/clusterctl.yaml`)")
+	RootCmd.PersistentFlags().BoolVar(&offline, "offline", false,
+		"Read provider repositories only from the local cache, without accessing the network. This overrides the OFFLINE environment variable.")
 
 	cobra.OnInitialize(initConfig)
 }
 
 func initConfig() {
+	// propagates the offline flag to the clusterctl configuration, so it will be used by all the repository clients
+	if offline {
+		configClient, err := config.New(cfgFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read the clusterctl configuration. err=%s\n", err.Error())
+			os.Exit(1)
+		}
+		configClient.Variables().Set(config.OfflineVariable, "true")
+	}
+
 	// check if the CLUSTERCTL_LOG_LEVEL was set via env var or in the config file
 	if *verbosity == 0 {
 		configClient, err := config.New(cfgFile)
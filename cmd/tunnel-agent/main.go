@@ -0,0 +1,120 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The tunnel agent runs in a workload cluster which can't be reached from the management cluster, and
+// connects out to the reverse tunnel servers of the Cluster API managers to let them reach its API server.
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/pflag"
+	"k8s.io/klog"
+	"k8s.io/klog/klogr"
+	"sigs.k8s.io/cluster-api/util/tunnel"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	serverURLs       []string
+	serverCAFile     string
+	clusterName      string
+	clusterNamespace string
+	tokenFile        string
+	target           string
+)
+
+func main() {
+	klog.InitFlags(nil)
+
+	pflag.StringSliceVar(&serverURLs, "server-url", nil,
+		"The URL of a reverse tunnel server to connect to (e.g. https://tunnel.example.com:8443); can be repeated for connecting to several managers.")
+	pflag.StringVar(&serverCAFile, "server-ca-file", "",
+		"The CA bundle used to verify the certificate of the tunnel servers. If unspecified, the system roots are used.")
+	pflag.StringVar(&clusterName, "cluster-name", "",
+		"The name of the Cluster object of this cluster in the management cluster.")
+	pflag.StringVar(&clusterNamespace, "cluster-namespace", "default",
+		"The namespace of the Cluster object of this cluster in the management cluster.")
+	pflag.StringVar(&tokenFile, "token-file", "",
+		"The file containing the token stored in the <cluster-name>-tunnel Secret in the management cluster.")
+	pflag.StringVar(&target, "target", defaultTarget(),
+		"The address of the API server the tunneled connections are proxied to. Defaults to the in-cluster API server.")
+	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
+	pflag.Parse()
+
+	log := klogr.New().WithName("tunnel-agent")
+
+	if len(serverURLs) == 0 || clusterName == "" || tokenFile == "" || target == "" {
+		log.Error(nil, "--server-url, --cluster-name, --token-file and --target are required")
+		os.Exit(1)
+	}
+
+	token, err := ioutil.ReadFile(tokenFile)
+	if err != nil {
+		log.Error(err, "unable to read token file")
+		os.Exit(1)
+	}
+
+	tlsConfig := &tls.Config{}
+	if serverCAFile != "" {
+		ca, err := ioutil.ReadFile(serverCAFile)
+		if err != nil {
+			log.Error(err, "unable to read server CA file")
+			os.Exit(1)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+			log.Error(nil, "no certificate found in server CA file")
+			os.Exit(1)
+		}
+	}
+
+	stop := ctrl.SetupSignalHandler()
+	var wg sync.WaitGroup
+	for _, serverURL := range serverURLs {
+		agent := &tunnel.Agent{
+			Log:       log.WithValues("server", serverURL),
+			ServerURL: serverURL,
+			Cluster:   client.ObjectKey{Namespace: clusterNamespace, Name: clusterName},
+			Token:     strings.TrimSpace(string(token)),
+			TLSConfig: tlsConfig,
+			Target:    target,
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			agent.Run(stop)
+		}()
+	}
+	wg.Wait()
+}
+
+// defaultTarget returns the address of the in-cluster API server, if running in a Pod.
+func defaultTarget() string {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return ""
+	}
+	return net.JoinHostPort(host, port)
+}
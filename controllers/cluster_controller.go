@@ -33,13 +33,18 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers/external"
 	"sigs.k8s.io/cluster-api/controllers/metrics"
+	"sigs.k8s.io/cluster-api/controllers/remote"
 	capierrors "sigs.k8s.io/cluster-api/errors"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/feature"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/predicates"
+	capirecord "sigs.k8s.io/cluster-api/util/record"
 	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/cluster-api/util/tracing"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -56,7 +61,7 @@ const (
 )
 
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;patch
-// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;patch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;patch;delete
 // +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io;bootstrap.cluster.x-k8s.io;controlplane.cluster.x-k8s.io,resources=*,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch;create;update;patch;delete
@@ -67,9 +72,15 @@ type ClusterReconciler struct {
 	Client client.Client
 	Log    logr.Logger
 
-	scheme          *runtime.Scheme
-	recorder        record.EventRecorder
-	externalTracker external.ObjectTracker
+	// ControllerCredentialsRotationPeriod is how often the token of the ServiceAccount used by the controllers
+	// in the workload clusters is rotated; zero disables the periodic rotation.
+	ControllerCredentialsRotationPeriod time.Duration
+
+	scheme            *runtime.Scheme
+	recorder          record.EventRecorder
+	externalTracker   external.ObjectTracker
+	adminClientGetter remote.ClusterClientGetter
+	credentialsCache  controllerCredentialsCache
 }
 
 func (r *ClusterReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
@@ -79,6 +90,10 @@ func (r *ClusterReconciler) SetupWithManager(mgr ctrl.Manager, options controlle
 			&source.Kind{Type: &clusterv1.Machine{}},
 			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.controlPlaneMachineToCluster)},
 		).
+		Watches(
+			&source.Kind{Type: &clusterv1.MachineDeployment{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.machineDeploymentToCluster)},
+		).
 		WithOptions(options).
 		WithEventFilter(predicates.ResourceNotPaused(r.Log)).
 		Build(r)
@@ -87,8 +102,20 @@ func (r *ClusterReconciler) SetupWithManager(mgr ctrl.Manager, options controlle
 		return errors.Wrap(err, "failed setting up with a controller manager")
 	}
 
-	r.recorder = mgr.GetEventRecorderFor("cluster-controller")
+	if feature.Gates.Enabled(feature.MachinePool) {
+		if err := controller.Watch(
+			&source.Kind{Type: &expv1.MachinePool{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.machinePoolToCluster)},
+		); err != nil {
+			return errors.Wrap(err, "failed adding Watch for MachinePools to controller manager")
+		}
+	}
+
+	r.recorder = capirecord.NewRateLimitedRecorder(mgr.GetEventRecorderFor("cluster-controller"), capirecord.DefaultDuplicateEventsInterval)
 	r.scheme = mgr.GetScheme()
+	if r.adminClientGetter == nil {
+		r.adminClientGetter = remote.NewAdminClusterClient
+	}
 	r.externalTracker = external.ObjectTracker{
 		Controller: controller,
 	}
@@ -96,7 +123,8 @@ func (r *ClusterReconciler) SetupWithManager(mgr ctrl.Manager, options controlle
 }
 
 func (r *ClusterReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr error) {
-	ctx := context.Background()
+	ctx, span := tracing.StartSpan(context.Background(), "ClusterReconciler.Reconcile", tracing.ObjectAttributes("Cluster", req.Namespace, req.Name)...)
+	defer func() { tracing.EndSpan(span, reterr) }()
 	logger := r.Log.WithValues("cluster", req.Name, "namespace", req.Namespace)
 
 	// Fetch the Cluster instance.
@@ -163,8 +191,12 @@ func (r *ClusterReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cl
 	reconciliationErrors := []error{
 		r.reconcileInfrastructure(ctx, cluster),
 		r.reconcileControlPlane(ctx, cluster),
+		r.reconcileTargetVersion(ctx, cluster),
+		r.reconcileWorkersReady(ctx, cluster),
 		r.reconcileKubeconfig(ctx, cluster),
+		r.reconcileTunnel(ctx, cluster),
 		r.reconcileControlPlaneInitialized(ctx, cluster),
+		r.reconcileControllerCredentials(ctx, cluster),
 	}
 
 	// Parse the errors, making sure we record if there is a RequeueAfterError.
@@ -187,6 +219,14 @@ func (r *ClusterReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cl
 }
 
 func (r *ClusterReconciler) reconcileMetrics(_ context.Context, cluster *clusterv1.Cluster) {
+	// Drop the series of a Cluster which is going away, so they don't outlive the object.
+	if !cluster.DeletionTimestamp.IsZero() && !controllerutil.ContainsFinalizer(cluster, clusterv1.ClusterFinalizer) {
+		metrics.ClusterControlPlaneReady.DeleteLabelValues(cluster.Name, cluster.Namespace)
+		metrics.ClusterInfrastructureReady.DeleteLabelValues(cluster.Name, cluster.Namespace)
+		metrics.ClusterKubeconfigReady.DeleteLabelValues(cluster.Name, cluster.Namespace)
+		metrics.ClusterFailureSet.DeleteLabelValues(cluster.Name, cluster.Namespace)
+		return
+	}
 
 	if cluster.Status.ControlPlaneInitialized {
 		metrics.ClusterControlPlaneReady.WithLabelValues(cluster.Name, cluster.Namespace).Set(1)
@@ -317,6 +357,7 @@ func (r *ClusterReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 		}
 	}
 
+	r.credentialsCache.delete(util.ObjectKey(cluster))
 	controllerutil.RemoveFinalizer(cluster, clusterv1.ClusterFinalizer)
 	return ctrl.Result{}, nil
 }
@@ -507,3 +548,17 @@ func (r *ClusterReconciler) controlPlaneMachineToCluster(o handler.MapObject) []
 		NamespacedName: util.ObjectKey(cluster),
 	}}
 }
+
+// machinePoolToCluster maps MachinePools to the Cluster they belong to,
+// so the WorkersReady condition is updated and the upgrade to Cluster.Spec.TargetVersion progresses as MachinePools are rolled out.
+func (r *ClusterReconciler) machinePoolToCluster(o handler.MapObject) []ctrl.Request {
+	mp, ok := o.Object.(*expv1.MachinePool)
+	if !ok {
+		r.Log.Error(nil, fmt.Sprintf("Expected a MachinePool but got a %T", o.Object))
+		return nil
+	}
+
+	return []ctrl.Request{{
+		NamespacedName: client.ObjectKey{Namespace: mp.Namespace, Name: mp.Spec.ClusterName},
+	}}
+}
@@ -0,0 +1,396 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/clientcmd"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/remote"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/feature"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/kubeconfig"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// controllerServiceAccountName is the name of the ServiceAccount used by the Cluster API controllers
+	// in the workload clusters, and of its ClusterRole, Role and bindings.
+	controllerServiceAccountName = "cluster-api-controller"
+
+	// controllerTokenLabel is set on the token Secrets of the controller ServiceAccount.
+	controllerTokenLabel = "cluster.x-k8s.io/controller-token"
+
+	// controllerTokenAnnotation is set on the controller Kubeconfig Secret with the name of the token Secret it uses.
+	controllerTokenAnnotation = "cluster.x-k8s.io/controller-token-secret"
+
+	// controllerTokenIssuedAtAnnotation is set on the controller Kubeconfig Secret with the time its token was issued.
+	controllerTokenIssuedAtAnnotation = "cluster.x-k8s.io/controller-token-issued-at"
+
+	// controllerTokenGracePeriod is how long the previous tokens of the controller ServiceAccount stay valid after
+	// a rotation, for the clients created with them to pick the new one.
+	controllerTokenGracePeriod = 10 * time.Minute
+
+	// controllerTokenTimeout is how long to wait for the token controller of the workload cluster to populate a
+	// new token Secret.
+	controllerTokenTimeout = 10 * time.Second
+
+	// controllerCredentialsVerifyPeriod is how often the ServiceAccount, its roles and its token are verified in the
+	// workload cluster while the controller Kubeconfig Secret is unchanged and not due for rotation.
+	controllerCredentialsVerifyPeriod = 10 * time.Minute
+)
+
+// controllerCredentialsCache stores the admin clients used for managing the controller credentials of the workload
+// clusters, and the controller Kubeconfig Secrets verified in the workload clusters, so the credentials are not
+// reconciled against the workload clusters on every reconcile.
+type controllerCredentialsCache struct {
+	lock     sync.Mutex
+	clients  map[client.ObjectKey]client.Client
+	verified map[client.ObjectKey]verifiedControllerCredentials
+}
+
+// verifiedControllerCredentials is a controller Kubeconfig Secret verified in the workload cluster.
+type verifiedControllerCredentials struct {
+	resourceVersion string
+	verifiedAt      time.Time
+}
+
+// getClient returns the cached admin client of the given Cluster, creating it with getter if missing.
+func (c *controllerCredentialsCache) getClient(ctx context.Context, mgmtClient client.Client, cluster client.ObjectKey, scheme *runtime.Scheme, getter remote.ClusterClientGetter) (client.Client, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if remoteClient, ok := c.clients[cluster]; ok {
+		return remoteClient, nil
+	}
+	remoteClient, err := getter(ctx, mgmtClient, cluster, scheme)
+	if err != nil {
+		return nil, err
+	}
+	if c.clients == nil {
+		c.clients = make(map[client.ObjectKey]client.Client)
+	}
+	c.clients[cluster] = remoteClient
+	return remoteClient, nil
+}
+
+// isVerified returns true if the given controller Kubeconfig Secret was recently verified in the workload cluster.
+func (c *controllerCredentialsCache) isVerified(cluster client.ObjectKey, credentials *corev1.Secret) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	verified, ok := c.verified[cluster]
+	return ok && verified.resourceVersion == credentials.ResourceVersion && time.Since(verified.verifiedAt) < controllerCredentialsVerifyPeriod
+}
+
+// setVerified records the given controller Kubeconfig Secret as verified in the workload cluster.
+func (c *controllerCredentialsCache) setVerified(cluster client.ObjectKey, credentials *corev1.Secret) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.verified == nil {
+		c.verified = make(map[client.ObjectKey]verifiedControllerCredentials)
+	}
+	c.verified[cluster] = verifiedControllerCredentials{resourceVersion: credentials.ResourceVersion, verifiedAt: time.Now()}
+}
+
+// delete forgets the admin client and the verified credentials of the given Cluster, e.g. because the client failed.
+func (c *controllerCredentialsCache) delete(cluster client.ObjectKey) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.clients, cluster)
+	delete(c.verified, cluster)
+}
+
+// controllerClusterRoleRules are the permissions of the Cluster API controllers in the workload clusters.
+var controllerClusterRoleRules = []rbacv1.PolicyRule{
+	// Machine and MachinePool controllers: node references, draining and deletion of the nodes.
+	// MachineHealthCheck controller: health of the nodes.
+	{
+		APIGroups: []string{""},
+		Resources: []string{"nodes"},
+		Verbs:     []string{"get", "list", "watch", "update", "patch", "delete"},
+	},
+	{
+		APIGroups: []string{""},
+		Resources: []string{"pods"},
+		Verbs:     []string{"get", "list", "delete"},
+	},
+	{
+		APIGroups: []string{""},
+		Resources: []string{"pods/eviction"},
+		Verbs:     []string{"create"},
+	},
+	// KubeadmControlPlane controller: kubeadm and kubelet configuration, kube-proxy, CoreDNS and etcd.
+	{
+		APIGroups: []string{""},
+		Resources: []string{"configmaps"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch"},
+	},
+	{
+		APIGroups: []string{""},
+		Resources: []string{"pods/portforward"},
+		Verbs:     []string{"create"},
+	},
+	{
+		APIGroups: []string{"apps"},
+		Resources: []string{"daemonsets", "deployments"},
+		Verbs:     []string{"get", "list", "update", "patch"},
+	},
+	{
+		APIGroups: []string{rbacv1.GroupName},
+		Resources: []string{"roles", "rolebindings", "clusterroles", "clusterrolebindings"},
+		Verbs:     []string{"get", "list", "watch", "create"},
+	},
+	// Health checks of the remote connection.
+	{
+		NonResourceURLs: []string{"/"},
+		Verbs:           []string{"get"},
+	},
+}
+
+// reconcileControllerCredentials creates the ServiceAccount used by the Cluster API controllers in the workload
+// cluster, and stores a Kubeconfig with its token in the controller Kubeconfig Secret, which remote clients
+// prefer over the admin Kubeconfig. The token is rotated when the Secret is deleted, or periodically if
+// ControllerCredentialsRotationPeriod is set.
+//
+// The workload cluster is only checked when the Secret changes, when the token is due for rotation, or once per
+// controllerCredentialsVerifyPeriod, reusing the same admin client.
+func (r *ClusterReconciler) reconcileControllerCredentials(ctx context.Context, cluster *clusterv1.Cluster) (reterr error) {
+	if !feature.Gates.Enabled(feature.ControllerServiceAccount) {
+		return nil
+	}
+	if !cluster.Status.ControlPlaneInitialized || cluster.Spec.ControlPlaneEndpoint.IsZero() || !cluster.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	credentials, err := secret.Get(ctx, r.Client, util.ObjectKey(cluster), secret.ControllerKubeconfig)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to retrieve controller Kubeconfig Secret for Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+		}
+		credentials = nil
+	}
+
+	var currentToken string
+	var issuedAt time.Time
+	if credentials != nil {
+		currentToken = credentials.Annotations[controllerTokenAnnotation]
+		issuedAt, _ = time.Parse(time.RFC3339, credentials.Annotations[controllerTokenIssuedAtAnnotation])
+	}
+	rotationDue := r.ControllerCredentialsRotationPeriod > 0 && time.Since(issuedAt) > r.ControllerCredentialsRotationPeriod
+
+	// Nothing to do if the credentials were recently verified in the workload cluster and are not due for rotation.
+	if credentials != nil && !rotationDue && r.credentialsCache.isVerified(util.ObjectKey(cluster), credentials) {
+		return nil
+	}
+
+	// Creating the ServiceAccount and its roles requires the permissions of the cluster admin.
+	remoteClient, err := r.credentialsCache.getClient(ctx, r.Client, util.ObjectKey(cluster), r.scheme, r.adminClientGetter)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create client to workload cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	}
+	defer func() {
+		// Recreate the client on the next reconcile, e.g. in case the admin credentials were rotated.
+		if reterr != nil {
+			r.credentialsCache.delete(util.ObjectKey(cluster))
+		}
+	}()
+
+	if err := ensureControllerServiceAccount(ctx, remoteClient); err != nil {
+		return errors.Wrapf(err, "failed to create controller ServiceAccount in workload cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	}
+
+	tokenSecrets := &corev1.SecretList{}
+	if err := remoteClient.List(ctx, tokenSecrets, client.InNamespace(metav1.NamespaceSystem), client.HasLabels{controllerTokenLabel}); err != nil {
+		return errors.Wrapf(err, "failed to list controller token Secrets in workload cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	}
+
+	currentTokenExists := false
+	for _, tokenSecret := range tokenSecrets.Items {
+		if tokenSecret.Name == currentToken {
+			currentTokenExists = true
+		}
+	}
+
+	if credentials == nil || !currentTokenExists || rotationDue {
+		return r.rotateControllerCredentials(ctx, cluster, remoteClient, credentials)
+	}
+
+	// Revoke the previous tokens once the clients using them had time to pick the current one.
+	if len(tokenSecrets.Items) > 1 && time.Since(issuedAt) < controllerTokenGracePeriod {
+		return nil
+	}
+	for i := range tokenSecrets.Items {
+		tokenSecret := &tokenSecrets.Items[i]
+		if tokenSecret.Name == currentToken {
+			continue
+		}
+		if err := remoteClient.Delete(ctx, tokenSecret); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete previous controller token Secret %q in workload cluster %q in namespace %q",
+				tokenSecret.Name, cluster.Name, cluster.Namespace)
+		}
+	}
+
+	r.credentialsCache.setVerified(util.ObjectKey(cluster), credentials)
+	return nil
+}
+
+// rotateControllerCredentials creates a new token for the controller ServiceAccount, and stores it in the
+// controller Kubeconfig Secret.
+func (r *ClusterReconciler) rotateControllerCredentials(ctx context.Context, cluster *clusterv1.Cluster, remoteClient client.Client, credentials *corev1.Secret) error {
+	logger := r.Log.WithValues("cluster", cluster.Name, "namespace", cluster.Namespace)
+
+	tokenSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-token-%s", controllerServiceAccountName, utilrand.String(5)),
+			Namespace: metav1.NamespaceSystem,
+			Labels: map[string]string{
+				controllerTokenLabel: "",
+			},
+			Annotations: map[string]string{
+				corev1.ServiceAccountNameKey: controllerServiceAccountName,
+			},
+		},
+		Type: corev1.SecretTypeServiceAccountToken,
+	}
+	if err := remoteClient.Create(ctx, tokenSecret); err != nil {
+		return errors.Wrapf(err, "failed to create controller token Secret in workload cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	}
+
+	// Wait for the token controller of the workload cluster to populate the token.
+	key := client.ObjectKey{Namespace: tokenSecret.Namespace, Name: tokenSecret.Name}
+	if err := wait.PollImmediate(time.Second, controllerTokenTimeout, func() (bool, error) {
+		if err := remoteClient.Get(ctx, key, tokenSecret); err != nil {
+			return false, err
+		}
+		return len(tokenSecret.Data[corev1.ServiceAccountTokenKey]) > 0, nil
+	}); err != nil {
+		if err := remoteClient.Delete(ctx, tokenSecret); err != nil && !apierrors.IsNotFound(err) {
+			logger.Error(err, "Failed to delete controller token Secret", "secret", tokenSecret.Name)
+		}
+		return errors.Wrapf(&capierrors.RequeueAfterError{RequeueAfter: 10 * time.Second},
+			"controller token Secret %q in workload cluster %q in namespace %q not populated, requeuing: %v",
+			tokenSecret.Name, cluster.Name, cluster.Namespace, err)
+	}
+
+	config := kubeconfig.NewForToken(
+		cluster.Name,
+		fmt.Sprintf("https://%s", cluster.Spec.ControlPlaneEndpoint.String()),
+		controllerServiceAccountName,
+		string(tokenSecret.Data[corev1.ServiceAccountTokenKey]),
+		tokenSecret.Data[corev1.ServiceAccountRootCAKey],
+	)
+	out, err := clientcmd.Write(*config)
+	if err != nil {
+		return errors.Wrap(err, "failed to serialize controller Kubeconfig to yaml")
+	}
+
+	create := credentials == nil
+	if create {
+		credentials = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      secret.Name(cluster.Name, secret.ControllerKubeconfig),
+				Namespace: cluster.Namespace,
+				Labels: map[string]string{
+					clusterv1.ClusterLabelName: cluster.Name,
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: clusterv1.GroupVersion.String(),
+						Kind:       "Cluster",
+						Name:       cluster.Name,
+						UID:        cluster.UID,
+					},
+				},
+			},
+		}
+	}
+	credentials.Annotations = map[string]string{
+		controllerTokenAnnotation:         tokenSecret.Name,
+		controllerTokenIssuedAtAnnotation: time.Now().UTC().Format(time.RFC3339),
+	}
+	credentials.Data = map[string][]byte{
+		secret.KubeconfigDataName: out,
+	}
+
+	if create {
+		err = r.Client.Create(ctx, credentials)
+	} else {
+		err = r.Client.Update(ctx, credentials)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to store controller Kubeconfig Secret for Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	}
+
+	logger.Info("Issued controller credentials", "secret", credentials.Name, "token", tokenSecret.Name)
+	return nil
+}
+
+// ensureControllerServiceAccount creates or updates the ServiceAccount used by the Cluster API controllers,
+// and binds it to its roles.
+func ensureControllerServiceAccount(ctx context.Context, remoteClient client.Client) error {
+	serviceAccount := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      controllerServiceAccountName,
+			Namespace: metav1.NamespaceSystem,
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, remoteClient, serviceAccount, func() error { return nil }); err != nil {
+		return err
+	}
+
+	subjects := []rbacv1.Subject{
+		{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      controllerServiceAccountName,
+			Namespace: metav1.NamespaceSystem,
+		},
+	}
+
+	clusterRole := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: controllerServiceAccountName}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, remoteClient, clusterRole, func() error {
+		clusterRole.Rules = controllerClusterRoleRules
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	clusterRoleBinding := &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: controllerServiceAccountName}}
+	_, err := controllerutil.CreateOrUpdate(ctx, remoteClient, clusterRoleBinding, func() error {
+		clusterRoleBinding.Subjects = subjects
+		clusterRoleBinding.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: controllerServiceAccountName}
+		return nil
+	})
+	return err
+}
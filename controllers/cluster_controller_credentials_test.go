@@ -0,0 +1,204 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	fakeremote "sigs.k8s.io/cluster-api/controllers/remote/fake"
+	"sigs.k8s.io/cluster-api/feature"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// tokenControllerClient populates the token of the ServiceAccount token Secrets it creates, like the token
+// controller of a workload cluster.
+type tokenControllerClient struct {
+	client.Client
+}
+
+func (c *tokenControllerClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	if s, ok := obj.(*corev1.Secret); ok && s.Type == corev1.SecretTypeServiceAccountToken {
+		s.Data = map[string][]byte{
+			corev1.ServiceAccountTokenKey:  []byte("token-of-" + s.Name),
+			corev1.ServiceAccountRootCAKey: []byte("ca"),
+		}
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func TestClusterReconciler_reconcileControllerCredentials(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	g.Expect(feature.MutableGates.Set("ControllerServiceAccount=true")).To(Succeed())
+	defer func() {
+		g.Expect(feature.MutableGates.Set("ControllerServiceAccount=false")).To(Succeed())
+	}()
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "test",
+		},
+		Spec: clusterv1.ClusterSpec{
+			ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "1.2.3.4", Port: 6443},
+		},
+		Status: clusterv1.ClusterStatus{
+			ControlPlaneInitialized: true,
+		},
+	}
+
+	c := &tokenControllerClient{Client: fake.NewFakeClientWithScheme(scheme.Scheme, cluster.DeepCopy())}
+	adminClients := 0
+	r := &ClusterReconciler{
+		Client: c,
+		Log:    log.Log,
+		scheme: scheme.Scheme,
+		adminClientGetter: func(ctx context.Context, c client.Client, cluster client.ObjectKey, scheme *runtime.Scheme) (client.Client, error) {
+			adminClients++
+			return fakeremote.NewClusterClient(ctx, c, cluster, scheme)
+		},
+	}
+	ctx := context.Background()
+
+	getCredentials := func() (*corev1.Secret, string) {
+		credentials, err := secret.Get(ctx, c, util.ObjectKey(cluster), secret.ControllerKubeconfig)
+		g.Expect(err).NotTo(HaveOccurred())
+		config, err := clientcmd.Load(credentials.Data[secret.KubeconfigDataName])
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(config.Clusters[cluster.Name].Server).To(Equal("https://1.2.3.4:6443"))
+		return credentials, config.AuthInfos[controllerServiceAccountName].Token
+	}
+	listTokens := func() []corev1.Secret {
+		tokenSecrets := &corev1.SecretList{}
+		g.Expect(c.List(ctx, tokenSecrets, client.InNamespace(metav1.NamespaceSystem), client.HasLabels{controllerTokenLabel})).To(Succeed())
+		return tokenSecrets.Items
+	}
+
+	// The ServiceAccount, its roles and credentials are created.
+	g.Expect(r.reconcileControllerCredentials(ctx, cluster)).To(Succeed())
+
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceSystem, Name: controllerServiceAccountName}, &corev1.ServiceAccount{})).To(Succeed())
+	clusterRole := &rbacv1.ClusterRole{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Name: controllerServiceAccountName}, clusterRole)).To(Succeed())
+	g.Expect(clusterRole.Rules).To(Equal(controllerClusterRoleRules))
+	for _, rule := range clusterRole.Rules {
+		// Reading Secrets, e.g. the ServiceAccount token Secrets, would grant the permissions of the cluster admin.
+		g.Expect(rule.Resources).NotTo(ContainElement("secrets"))
+	}
+	g.Expect(c.Get(ctx, client.ObjectKey{Name: controllerServiceAccountName}, &rbacv1.ClusterRoleBinding{})).To(Succeed())
+
+	credentials, token := getCredentials()
+	tokenSecrets := listTokens()
+	g.Expect(tokenSecrets).To(HaveLen(1))
+	g.Expect(credentials.Annotations[controllerTokenAnnotation]).To(Equal(tokenSecrets[0].Name))
+	g.Expect(token).To(Equal("token-of-" + tokenSecrets[0].Name))
+
+	// The credentials are left as is until they are rotated.
+	g.Expect(r.reconcileControllerCredentials(ctx, cluster)).To(Succeed())
+	_, sameToken := getCredentials()
+	g.Expect(sameToken).To(Equal(token))
+	g.Expect(listTokens()).To(HaveLen(1))
+
+	// The workload cluster is not checked again until the verify period expires.
+	g.Expect(c.Delete(ctx, clusterRole)).To(Succeed())
+	g.Expect(r.reconcileControllerCredentials(ctx, cluster)).To(Succeed())
+	g.Expect(apierrors.IsNotFound(c.Get(ctx, client.ObjectKey{Name: controllerServiceAccountName}, &rbacv1.ClusterRole{}))).To(BeTrue())
+
+	verified := r.credentialsCache.verified[util.ObjectKey(cluster)]
+	verified.verifiedAt = time.Now().Add(-2 * controllerCredentialsVerifyPeriod)
+	r.credentialsCache.verified[util.ObjectKey(cluster)] = verified
+	g.Expect(r.reconcileControllerCredentials(ctx, cluster)).To(Succeed())
+	g.Expect(c.Get(ctx, client.ObjectKey{Name: controllerServiceAccountName}, &rbacv1.ClusterRole{})).To(Succeed())
+
+	// Deleting the credentials rotates the token; the previous token is kept during the grace period.
+	g.Expect(c.Delete(ctx, credentials)).To(Succeed())
+	g.Expect(r.reconcileControllerCredentials(ctx, cluster)).To(Succeed())
+	credentials, rotatedToken := getCredentials()
+	g.Expect(rotatedToken).NotTo(Equal(token))
+	g.Expect(listTokens()).To(HaveLen(2))
+
+	// The previous token is revoked after the grace period.
+	credentials.Annotations[controllerTokenIssuedAtAnnotation] = time.Now().Add(-2 * controllerTokenGracePeriod).UTC().Format(time.RFC3339)
+	g.Expect(c.Update(ctx, credentials)).To(Succeed())
+	g.Expect(r.reconcileControllerCredentials(ctx, cluster)).To(Succeed())
+	tokenSecrets = listTokens()
+	g.Expect(tokenSecrets).To(HaveLen(1))
+	g.Expect(credentials.Annotations[controllerTokenAnnotation]).To(Equal(tokenSecrets[0].Name))
+
+	// The token is rotated periodically.
+	r.ControllerCredentialsRotationPeriod = controllerTokenGracePeriod
+	g.Expect(r.reconcileControllerCredentials(ctx, cluster)).To(Succeed())
+	_, periodicToken := getCredentials()
+	g.Expect(periodicToken).NotTo(Equal(rotatedToken))
+
+	// The token is recreated if it is deleted in the workload cluster.
+	r.ControllerCredentialsRotationPeriod = 0
+	tokenSecrets = listTokens()
+	for i := range tokenSecrets {
+		g.Expect(c.Delete(ctx, &tokenSecrets[i])).To(Succeed())
+	}
+	g.Expect(r.reconcileControllerCredentials(ctx, cluster)).To(Succeed())
+	_, recreatedToken := getCredentials()
+	g.Expect(recreatedToken).NotTo(Equal(periodicToken))
+	g.Expect(listTokens()).To(HaveLen(1))
+
+	// The same admin client is used for all the reconciles.
+	g.Expect(adminClients).To(Equal(1))
+}
+
+func TestClusterReconciler_reconcileControllerCredentialsDisabled(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "test",
+		},
+		Status: clusterv1.ClusterStatus{
+			ControlPlaneInitialized: true,
+		},
+	}
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, cluster.DeepCopy())
+	r := &ClusterReconciler{
+		Client:            c,
+		Log:               log.Log,
+		scheme:            scheme.Scheme,
+		adminClientGetter: fakeremote.NewClusterClient,
+	}
+
+	g.Expect(r.reconcileControllerCredentials(context.Background(), cluster)).To(Succeed())
+	_, err := secret.Get(context.Background(), c, util.ObjectKey(cluster), secret.ControllerKubeconfig)
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+}
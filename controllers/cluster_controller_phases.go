@@ -24,10 +24,13 @@ import (
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers/external"
 	capierrors "sigs.k8s.io/cluster-api/errors"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/feature"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
@@ -35,6 +38,8 @@ import (
 	"sigs.k8s.io/cluster-api/util/kubeconfig"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/cluster-api/util/tunnel"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 )
@@ -240,6 +245,81 @@ func (r *ClusterReconciler) reconcileControlPlane(ctx context.Context, cluster *
 	return nil
 }
 
+// reconcileWorkersReady reports if all the replicas of the worker pools of a Cluster are ready and available, that is
+// of the MachineDeployments and, if the feature is enabled, of the MachinePools belonging to the Cluster.
+// NOTE: the Available condition of the MachineDeployments can't be used, because it is true as soon as
+// the replicas minus maxUnavailable are available.
+func (r *ClusterReconciler) reconcileWorkersReady(ctx context.Context, cluster *clusterv1.Cluster) error {
+	listOptions := []client.ListOption{
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name},
+	}
+
+	machineDeployments := &clusterv1.MachineDeploymentList{}
+	if err := r.Client.List(ctx, machineDeployments, listOptions...); err != nil {
+		return errors.Wrapf(err, "failed to list MachineDeployments for cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+	workers := make([]conditions.Getter, 0, len(machineDeployments.Items))
+	for i := range machineDeployments.Items {
+		md := &machineDeployments.Items[i]
+		workers = append(workers, &workerPool{Object: md, ready: isMachineDeploymentReady(md)})
+	}
+
+	stepCounterMessage := "%d of %d MachineDeployments ready"
+	if feature.Gates.Enabled(feature.MachinePool) {
+		machinePools := &expv1.MachinePoolList{}
+		if err := r.Client.List(ctx, machinePools, listOptions...); err != nil {
+			return errors.Wrapf(err, "failed to list MachinePools for cluster %s/%s", cluster.Namespace, cluster.Name)
+		}
+		for i := range machinePools.Items {
+			mp := &machinePools.Items[i]
+			workers = append(workers, &workerPool{Object: mp, ready: isMachinePoolReady(mp)})
+		}
+		if len(machinePools.Items) > 0 {
+			stepCounterMessage = "%d of %d MachineDeployments and MachinePools ready"
+		}
+	}
+
+	if len(workers) == 0 {
+		conditions.Delete(cluster, clusterv1.WorkersReadyCondition)
+		return nil
+	}
+
+	conditions.SetAggregate(cluster, clusterv1.WorkersReadyCondition, workers, conditions.WithStepCounterMessage(stepCounterMessage))
+	return nil
+}
+
+// workerPool exposes the readiness of all the replicas of a MachineDeployment or of a MachinePool
+// as its Ready condition, so it can be aggregated into the WorkersReady condition of the Cluster.
+type workerPool struct {
+	controllerutil.Object
+	ready bool
+}
+
+// GetConditions returns the Ready condition of the worker pool.
+func (w *workerPool) GetConditions() clusterv1.Conditions {
+	if w.ready {
+		return clusterv1.Conditions{*conditions.TrueCondition(clusterv1.ReadyCondition)}
+	}
+	return clusterv1.Conditions{*conditions.FalseCondition(clusterv1.ReadyCondition, clusterv1.WaitingForWorkersReason, clusterv1.ConditionSeverityInfo, "")}
+}
+
+// isMachineDeploymentReady returns true if all the desired replicas of the MachineDeployment are ready and available.
+func isMachineDeploymentReady(md *clusterv1.MachineDeployment) bool {
+	if md.Spec.Replicas == nil || md.Status.ObservedGeneration < md.Generation {
+		return false
+	}
+	return md.Status.ReadyReplicas == *md.Spec.Replicas && md.Status.AvailableReplicas == *md.Spec.Replicas
+}
+
+// isMachinePoolReady returns true if all the desired replicas of the MachinePool are ready and available.
+func isMachinePoolReady(mp *expv1.MachinePool) bool {
+	if mp.Spec.Replicas == nil {
+		return false
+	}
+	return mp.Status.ReadyReplicas == *mp.Spec.Replicas && mp.Status.AvailableReplicas == *mp.Spec.Replicas
+}
+
 func (r *ClusterReconciler) reconcileKubeconfig(ctx context.Context, cluster *clusterv1.Cluster) error {
 	if cluster.Spec.ControlPlaneEndpoint.IsZero() {
 		return nil
@@ -269,3 +349,38 @@ func (r *ClusterReconciler) reconcileKubeconfig(ctx context.Context, cluster *cl
 
 	return nil
 }
+
+// reconcileTunnel generates the tunnel Secret storing the token of the reverse tunnel agent of Clusters with the
+// TunnelAnnotation, and deletes it once the annotation is removed so the controllers dial the API server directly.
+func (r *ClusterReconciler) reconcileTunnel(ctx context.Context, cluster *clusterv1.Cluster) error {
+	tunnelSecret, err := secret.Get(ctx, r.Client, util.ObjectKey(cluster), secret.Tunnel)
+	switch {
+	case apierrors.IsNotFound(err):
+		tunnelSecret = nil
+	case err != nil:
+		return errors.Wrapf(err, "failed to retrieve tunnel Secret for Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	}
+
+	if _, ok := cluster.Annotations[clusterv1.TunnelAnnotation]; !ok {
+		// Only delete the Secret generated by this controller.
+		if tunnelSecret == nil || !metav1.IsControlledBy(tunnelSecret, cluster) {
+			return nil
+		}
+		if err := r.Client.Delete(ctx, tunnelSecret); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete tunnel Secret for Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+		}
+		return nil
+	}
+
+	if tunnelSecret != nil {
+		return nil
+	}
+	tunnelSecret, err = tunnel.GenerateSecret(cluster)
+	if err != nil {
+		return err
+	}
+	if err := r.Client.Create(ctx, tunnelSecret); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create tunnel Secret for Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	}
+	return nil
+}
@@ -25,12 +25,19 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers/external"
 	capierrors "sigs.k8s.io/cluster-api/errors"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/feature"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/secret"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -229,6 +236,47 @@ func TestClusterReconcilePhases(t *testing.T) {
 	})
 }
 
+func TestClusterReconciler_reconcileTunnel(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-cluster",
+			Namespace:   "default",
+			UID:         "test-cluster-uid",
+			Annotations: map[string]string{clusterv1.TunnelAnnotation: ""},
+		},
+	}
+	key := client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Name}
+
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, cluster)
+	r := &ClusterReconciler{
+		Client: c,
+		scheme: scheme.Scheme,
+	}
+
+	// The tunnel Secret is generated for Clusters with the annotation.
+	g.Expect(r.reconcileTunnel(context.Background(), cluster)).To(Succeed())
+	tunnelSecret, err := secret.Get(context.Background(), c, key, secret.Tunnel)
+	g.Expect(err).NotTo(HaveOccurred())
+	token := tunnelSecret.Data[secret.TokenDataName]
+	g.Expect(token).To(HaveLen(64))
+	g.Expect(metav1.IsControlledBy(tunnelSecret, cluster)).To(BeTrue())
+
+	// The token is not regenerated.
+	g.Expect(r.reconcileTunnel(context.Background(), cluster)).To(Succeed())
+	tunnelSecret, err = secret.Get(context.Background(), c, key, secret.Tunnel)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(tunnelSecret.Data[secret.TokenDataName]).To(Equal(token))
+
+	// The tunnel Secret is deleted once the annotation is removed.
+	delete(cluster.Annotations, clusterv1.TunnelAnnotation)
+	g.Expect(r.reconcileTunnel(context.Background(), cluster)).To(Succeed())
+	_, err = secret.Get(context.Background(), c, key, secret.Tunnel)
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+}
+
 func TestClusterReconciler_reconcilePhase(t *testing.T) {
 	cluster := &clusterv1.Cluster{
 		ObjectMeta: metav1.ObjectMeta{
@@ -369,3 +417,148 @@ func TestClusterReconciler_reconcilePhase(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterReconciler_reconcileWorkersReady(t *testing.T) {
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "test-namespace",
+		},
+	}
+
+	newMachineDeployment := func(name string, replicas, readyReplicas, availableReplicas int32) *clusterv1.MachineDeployment {
+		md := &clusterv1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test-namespace",
+				Labels:    map[string]string{clusterv1.ClusterLabelName: "test-cluster"},
+			},
+			Spec: clusterv1.MachineDeploymentSpec{
+				Replicas: pointer.Int32Ptr(replicas),
+			},
+			Status: clusterv1.MachineDeploymentStatus{
+				Replicas:          replicas,
+				ReadyReplicas:     readyReplicas,
+				AvailableReplicas: availableReplicas,
+			},
+		}
+		// The Available condition is true as soon as replicas - maxUnavailable are available.
+		conditions.MarkTrue(md, clusterv1.MachineDeploymentAvailableCondition)
+		return md
+	}
+
+	newMachinePool := func(name string, replicas, readyReplicas, availableReplicas int32) *expv1.MachinePool {
+		mp := &expv1.MachinePool{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test-namespace",
+				Labels:    map[string]string{clusterv1.ClusterLabelName: "test-cluster"},
+			},
+			Spec: expv1.MachinePoolSpec{
+				Replicas: pointer.Int32Ptr(replicas),
+			},
+			Status: expv1.MachinePoolStatus{
+				Replicas:          replicas,
+				ReadyReplicas:     readyReplicas,
+				AvailableReplicas: availableReplicas,
+			},
+		}
+		conditions.MarkTrue(mp, expv1.ReplicasReadyCondition)
+		return mp
+	}
+
+	tests := []struct {
+		name      string
+		objs      []runtime.Object
+		wantCond  bool
+		wantState corev1.ConditionStatus
+		wantMsg   string
+	}{
+		{
+			name:     "no condition if the cluster has no worker pools",
+			wantCond: false,
+		},
+		{
+			name: "condition is true if all the replicas of the MachineDeployments are ready and available",
+			objs: []runtime.Object{
+				newMachineDeployment("md1", 3, 3, 3),
+				newMachineDeployment("md2", 1, 1, 1),
+			},
+			wantCond:  true,
+			wantState: corev1.ConditionTrue,
+		},
+		{
+			name: "condition is false with a step counter message if a MachineDeployment is not fully available",
+			objs: []runtime.Object{
+				newMachineDeployment("md1", 3, 3, 3),
+				newMachineDeployment("md2", 3, 3, 3),
+				newMachineDeployment("md3", 3, 3, 3),
+				newMachineDeployment("md4", 3, 3, 2),
+			},
+			wantCond:  true,
+			wantState: corev1.ConditionFalse,
+			wantMsg:   "3 of 4 MachineDeployments ready",
+		},
+		{
+			name: "condition is false if a MachineDeployment is not fully ready",
+			objs: []runtime.Object{
+				newMachineDeployment("md1", 3, 2, 3),
+			},
+			wantCond:  true,
+			wantState: corev1.ConditionFalse,
+			wantMsg:   "0 of 1 MachineDeployments ready",
+		},
+		{
+			name: "condition is true if all the replicas of the MachineDeployments and MachinePools are ready and available",
+			objs: []runtime.Object{
+				newMachineDeployment("md1", 3, 3, 3),
+				newMachinePool("mp1", 2, 2, 2),
+			},
+			wantCond:  true,
+			wantState: corev1.ConditionTrue,
+		},
+		{
+			name: "condition is false if a MachinePool is not fully ready",
+			objs: []runtime.Object{
+				newMachineDeployment("md1", 3, 3, 3),
+				newMachinePool("mp1", 2, 1, 1),
+			},
+			wantCond:  true,
+			wantState: corev1.ConditionFalse,
+			wantMsg:   "1 of 2 MachineDeployments and MachinePools ready",
+		},
+	}
+
+	g := NewWithT(t)
+	g.Expect(feature.MutableGates.Set("MachinePool=true")).To(Succeed())
+	defer func() {
+		g.Expect(feature.MutableGates.Set("MachinePool=false")).To(Succeed())
+	}()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+			g.Expect(expv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+			c := fake.NewFakeClientWithScheme(scheme.Scheme, append(tt.objs, cluster.DeepCopy())...)
+			r := &ClusterReconciler{
+				Client: c,
+				scheme: scheme.Scheme,
+			}
+
+			cl := cluster.DeepCopy()
+			g.Expect(r.reconcileWorkersReady(context.TODO(), cl)).To(Succeed())
+
+			cond := conditions.Get(cl, clusterv1.WorkersReadyCondition)
+			if !tt.wantCond {
+				g.Expect(cond).To(BeNil())
+				return
+			}
+			g.Expect(cond).ToNot(BeNil())
+			g.Expect(cond.Status).To(Equal(tt.wantState))
+			g.Expect(cond.Message).To(Equal(tt.wantMsg))
+		})
+	}
+}
@@ -0,0 +1,287 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/blang/semver"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/feature"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	capirecord "sigs.k8s.io/cluster-api/util/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+// reconcileTargetVersion upgrades the Cluster to Cluster.Spec.TargetVersion, if defined.
+// The control plane is upgraded one minor version at a time, and after each step the MachineDeployments and
+// the MachinePools are upgraded to the minor version of the control plane before the control plane moves on,
+// so the version skew between the control plane and the workers is never larger than supported.
+func (r *ClusterReconciler) reconcileTargetVersion(ctx context.Context, cluster *clusterv1.Cluster) error {
+	if cluster.Spec.TargetVersion == nil {
+		conditions.Delete(cluster, clusterv1.TargetVersionReachedCondition)
+		return nil
+	}
+
+	if cluster.Spec.ControlPlaneRef == nil {
+		conditions.MarkFalse(cluster, clusterv1.TargetVersionReachedCondition, clusterv1.InvalidTargetVersionReason, clusterv1.ConditionSeverityError,
+			"TargetVersion requires a control plane provider")
+		return nil
+	}
+
+	target, err := util.ParseMajorMinorPatch(*cluster.Spec.TargetVersion)
+	if err != nil {
+		conditions.MarkFalse(cluster, clusterv1.TargetVersionReachedCondition, clusterv1.InvalidTargetVersionReason, clusterv1.ConditionSeverityError,
+			"TargetVersion %q is not a valid semantic version", *cluster.Spec.TargetVersion)
+		return nil
+	}
+
+	controlPlane, err := external.Get(ctx, r.Client, cluster.Spec.ControlPlaneRef, cluster.Namespace)
+	if err != nil {
+		return err
+	}
+
+	currentVersion, found, err := unstructured.NestedString(controlPlane.Object, "spec", "version")
+	if err != nil {
+		return errors.Wrapf(err, "failed to get spec.version from %s %q", controlPlane.GetKind(), controlPlane.GetName())
+	}
+	if !found {
+		conditions.MarkFalse(cluster, clusterv1.TargetVersionReachedCondition, clusterv1.InvalidTargetVersionReason, clusterv1.ConditionSeverityError,
+			"%s %s does not define spec.version", controlPlane.GetKind(), controlPlane.GetName())
+		return nil
+	}
+	current, err := util.ParseMajorMinorPatch(currentVersion)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse the version of %s %q", controlPlane.GetKind(), controlPlane.GetName())
+	}
+
+	if current.Major != target.Major || util.IsVersionNewer(current, target) {
+		conditions.MarkFalse(cluster, clusterv1.TargetVersionReachedCondition, clusterv1.InvalidTargetVersionReason, clusterv1.ConditionSeverityError,
+			"Cannot upgrade from %s to %s", currentVersion, *cluster.Spec.TargetVersion)
+		return nil
+	}
+
+	// Wait for the previous upgrade of the control plane to be completed.
+	if !isControlPlaneRolledOut(controlPlane) {
+		conditions.MarkFalse(cluster, clusterv1.TargetVersionReachedCondition, clusterv1.ControlPlaneUpgradingReason, clusterv1.ConditionSeverityInfo,
+			"Waiting for the control plane to be rolled out to %s", currentVersion)
+		return nil
+	}
+
+	if !util.IsVersionNewer(target, current) {
+		// The control plane is at the target version, upgrade the workers.
+		rolledOut, err := r.upgradeWorkers(ctx, cluster, *cluster.Spec.TargetVersion, func(version *string) bool {
+			return !isVersion(version, target)
+		})
+		if err != nil || !rolledOut {
+			return err
+		}
+
+		conditions.MarkTrue(cluster, clusterv1.TargetVersionReachedCondition)
+		return nil
+	}
+
+	// Upgrade the workers older than the minor version of the control plane before upgrading the control plane
+	// to the next version, so they don't fall behind the control plane by more than one minor version.
+	rolledOut, err := r.upgradeWorkers(ctx, cluster, currentVersion, func(version *string) bool {
+		return isOlderMinorVersion(version, current)
+	})
+	if err != nil || !rolledOut {
+		return err
+	}
+
+	nextVersion := *cluster.Spec.TargetVersion
+	if target.Minor > current.Minor+1 {
+		nextVersion = fmt.Sprintf("v%d.%d.0", current.Major, current.Minor+1)
+	}
+
+	patchHelper, err := patch.NewHelper(controlPlane, r.Client)
+	if err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedField(controlPlane.Object, nextVersion, "spec", "version"); err != nil {
+		return errors.Wrapf(err, "failed to set spec.version on %s %q", controlPlane.GetKind(), controlPlane.GetName())
+	}
+	if err := patchHelper.Patch(ctx, controlPlane); err != nil {
+		return errors.Wrapf(err, "failed to upgrade %s %q to %s", controlPlane.GetKind(), controlPlane.GetName(), nextVersion)
+	}
+
+	capirecord.UpgradingControlPlane.Emit(r.recorder, cluster, controlPlane.GetKind(), controlPlane.GetName(), currentVersion, nextVersion)
+	conditions.MarkFalse(cluster, clusterv1.TargetVersionReachedCondition, clusterv1.ControlPlaneUpgradingReason, clusterv1.ConditionSeverityInfo,
+		"Upgrading the control plane to %s", nextVersion)
+	return nil
+}
+
+// upgradeWorkers upgrades to the given version the MachineDeployments and then, if the feature is enabled,
+// the MachinePools of the Cluster for which needsUpgrade returns true; it returns true once all of them are rolled out,
+// otherwise the progress is reported in the TargetVersionReached condition.
+func (r *ClusterReconciler) upgradeWorkers(ctx context.Context, cluster *clusterv1.Cluster, version string, needsUpgrade func(version *string) bool) (bool, error) {
+	machineDeployments := &clusterv1.MachineDeploymentList{}
+	if err := r.Client.List(ctx, machineDeployments,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name},
+	); err != nil {
+		return false, errors.Wrapf(err, "failed to list MachineDeployments for cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+
+	upgraded := 0
+	for i := range machineDeployments.Items {
+		md := &machineDeployments.Items[i]
+
+		if needsUpgrade(md.Spec.Template.Spec.Version) {
+			patchHelper, err := patch.NewHelper(md, r.Client)
+			if err != nil {
+				return false, err
+			}
+			md.Spec.Template.Spec.Version = pointer.StringPtr(version)
+			if err := patchHelper.Patch(ctx, md); err != nil {
+				return false, errors.Wrapf(err, "failed to upgrade MachineDeployment %q to %s", md.Name, version)
+			}
+			capirecord.UpgradingMachineDeployment.Emit(r.recorder, cluster, md.Name, version)
+			continue
+		}
+
+		if md.Spec.Replicas != nil && mdutil.DeploymentComplete(md, &md.Status) {
+			upgraded++
+		}
+	}
+
+	if upgraded < len(machineDeployments.Items) {
+		conditions.MarkFalse(cluster, clusterv1.TargetVersionReachedCondition, clusterv1.WorkersUpgradingReason, clusterv1.ConditionSeverityInfo,
+			"%d of %d MachineDeployments upgraded to %s", upgraded, len(machineDeployments.Items), version)
+		return false, nil
+	}
+
+	// The MachineDeployments are upgraded, upgrade the MachinePools.
+	if !feature.Gates.Enabled(feature.MachinePool) {
+		return true, nil
+	}
+
+	machinePools := &expv1.MachinePoolList{}
+	if err := r.Client.List(ctx, machinePools,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name},
+	); err != nil {
+		return false, errors.Wrapf(err, "failed to list MachinePools for cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+
+	upgraded = 0
+	for i := range machinePools.Items {
+		mp := &machinePools.Items[i]
+
+		if needsUpgrade(mp.Spec.Template.Spec.Version) {
+			patchHelper, err := patch.NewHelper(mp, r.Client)
+			if err != nil {
+				return false, err
+			}
+			mp.Spec.Template.Spec.Version = pointer.StringPtr(version)
+			if err := patchHelper.Patch(ctx, mp); err != nil {
+				return false, errors.Wrapf(err, "failed to upgrade MachinePool %q to %s", mp.Name, version)
+			}
+			capirecord.UpgradingMachinePool.Emit(r.recorder, cluster, mp.Name, version)
+			continue
+		}
+
+		if isMachinePoolRolledOut(mp) {
+			upgraded++
+		}
+	}
+
+	if upgraded < len(machinePools.Items) {
+		conditions.MarkFalse(cluster, clusterv1.TargetVersionReachedCondition, clusterv1.WorkersUpgradingReason, clusterv1.ConditionSeverityInfo,
+			"%d of %d MachinePools upgraded to %s", upgraded, len(machinePools.Items), version)
+		return false, nil
+	}
+	return true, nil
+}
+
+// isControlPlaneRolledOut returns true if all the replicas of the control plane are updated and ready;
+// replica counters not reported by the control plane provider are ignored.
+func isControlPlaneRolledOut(controlPlane *unstructured.Unstructured) bool {
+	if observedGeneration, found, _ := unstructured.NestedInt64(controlPlane.Object, "status", "observedGeneration"); found && observedGeneration < controlPlane.GetGeneration() {
+		return false
+	}
+
+	replicas, found, _ := unstructured.NestedInt64(controlPlane.Object, "spec", "replicas")
+	if !found {
+		return true
+	}
+	for _, field := range []string{"replicas", "updatedReplicas", "readyReplicas"} {
+		if value, found, _ := unstructured.NestedInt64(controlPlane.Object, "status", field); found && value != replicas {
+			return false
+		}
+	}
+	return true
+}
+
+// isMachinePoolRolledOut returns true if all the replicas of the MachinePool are ready and available.
+func isMachinePoolRolledOut(mp *expv1.MachinePool) bool {
+	if mp.Spec.Replicas == nil {
+		return false
+	}
+	return mp.Status.ReadyReplicas == *mp.Spec.Replicas && mp.Status.AvailableReplicas == *mp.Spec.Replicas
+}
+
+// isVersion returns true if the given version is equal to v, looking only at major.minor.patch.
+func isVersion(version *string, v semver.Version) bool {
+	if version == nil {
+		return false
+	}
+	parsed, err := util.ParseMajorMinorPatch(*version)
+	if err != nil {
+		return false
+	}
+	return !util.IsVersionNewer(parsed, v) && !util.IsVersionNewer(v, parsed)
+}
+
+// isOlderMinorVersion returns true if the given version is not defined, is invalid or
+// its major.minor is older than the major.minor of v.
+func isOlderMinorVersion(version *string, v semver.Version) bool {
+	if version == nil {
+		return true
+	}
+	parsed, err := util.ParseMajorMinorPatch(*version)
+	if err != nil {
+		return true
+	}
+	return parsed.Major < v.Major || (parsed.Major == v.Major && parsed.Minor < v.Minor)
+}
+
+// machineDeploymentToCluster maps MachineDeployments to the Cluster they belong to,
+// so the upgrade to Cluster.Spec.TargetVersion progresses as MachineDeployments are rolled out.
+func (r *ClusterReconciler) machineDeploymentToCluster(o handler.MapObject) []ctrl.Request {
+	md, ok := o.Object.(*clusterv1.MachineDeployment)
+	if !ok {
+		r.Log.Error(nil, fmt.Sprintf("Expected a MachineDeployment but got a %T", o.Object))
+		return nil
+	}
+
+	return []ctrl.Request{{
+		NamespacedName: client.ObjectKey{Namespace: md.Namespace, Name: md.Spec.ClusterName},
+	}}
+}
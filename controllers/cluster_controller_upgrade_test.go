@@ -0,0 +1,385 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/feature"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestClusterReconcileTargetVersion(t *testing.T) {
+	newControlPlane := func(version string, rolledOut bool) *unstructured.Unstructured {
+		updatedReplicas := int64(3)
+		if !rolledOut {
+			updatedReplicas = 1
+		}
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"kind":       "ControlPlane",
+				"apiVersion": "controlplane.cluster.x-k8s.io/v1alpha3",
+				"metadata": map[string]interface{}{
+					"name":      "test-cluster-control-plane",
+					"namespace": "test-namespace",
+				},
+				"spec": map[string]interface{}{
+					"version":  version,
+					"replicas": int64(3),
+				},
+				"status": map[string]interface{}{
+					"replicas":        int64(3),
+					"updatedReplicas": updatedReplicas,
+					"readyReplicas":   int64(3),
+				},
+			},
+		}
+	}
+
+	newMachineDeployment := func(name, version string, rolledOut bool) *clusterv1.MachineDeployment {
+		md := &clusterv1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test-namespace",
+				Labels:    map[string]string{clusterv1.ClusterLabelName: "test-cluster"},
+			},
+			Spec: clusterv1.MachineDeploymentSpec{
+				ClusterName: "test-cluster",
+				Replicas:    pointer.Int32Ptr(2),
+				Template: clusterv1.MachineTemplateSpec{
+					Spec: clusterv1.MachineSpec{
+						ClusterName: "test-cluster",
+						Version:     pointer.StringPtr(version),
+					},
+				},
+			},
+		}
+		if rolledOut {
+			md.Status = clusterv1.MachineDeploymentStatus{
+				Replicas:          2,
+				UpdatedReplicas:   2,
+				AvailableReplicas: 2,
+			}
+		}
+		return md
+	}
+
+	newMachinePool := func(name, version string, rolledOut bool) *expv1.MachinePool {
+		mp := &expv1.MachinePool{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test-namespace",
+				Labels:    map[string]string{clusterv1.ClusterLabelName: "test-cluster"},
+			},
+			Spec: expv1.MachinePoolSpec{
+				ClusterName: "test-cluster",
+				Replicas:    pointer.Int32Ptr(2),
+				Template: clusterv1.MachineTemplateSpec{
+					Spec: clusterv1.MachineSpec{
+						ClusterName: "test-cluster",
+						Version:     pointer.StringPtr(version),
+					},
+				},
+			},
+		}
+		if rolledOut {
+			mp.Status = expv1.MachinePoolStatus{
+				Replicas:          2,
+				ReadyReplicas:     2,
+				AvailableReplicas: 2,
+			}
+		}
+		return mp
+	}
+
+	tests := []struct {
+		name                       string
+		targetVersion              *string
+		controlPlane               *unstructured.Unstructured
+		machineDeployments         []*clusterv1.MachineDeployment
+		machinePools               []*expv1.MachinePool
+		expectedReason             string
+		expectedControlPlane       string
+		expectedMachineDeployments map[string]string
+		expectedMachinePools       map[string]string
+	}{
+		{
+			name:                 "does not set the condition without a target version",
+			controlPlane:         newControlPlane("v1.16.6", true),
+			expectedControlPlane: "v1.16.6",
+		},
+		{
+			name:                 "rejects a downgrade",
+			targetVersion:        pointer.StringPtr("v1.15.3"),
+			controlPlane:         newControlPlane("v1.16.6", true),
+			expectedReason:       clusterv1.InvalidTargetVersionReason,
+			expectedControlPlane: "v1.16.6",
+		},
+		{
+			name:                 "upgrades the control plane to the next minor version",
+			targetVersion:        pointer.StringPtr("v1.18.2"),
+			controlPlane:         newControlPlane("v1.16.6", true),
+			expectedReason:       clusterv1.ControlPlaneUpgradingReason,
+			expectedControlPlane: "v1.17.0",
+		},
+		{
+			name:                 "upgrades the control plane to the target version",
+			targetVersion:        pointer.StringPtr("v1.18.2"),
+			controlPlane:         newControlPlane("v1.17.0", true),
+			expectedReason:       clusterv1.ControlPlaneUpgradingReason,
+			expectedControlPlane: "v1.18.2",
+		},
+		{
+			name:                       "waits for the control plane to be rolled out",
+			targetVersion:              pointer.StringPtr("v1.18.2"),
+			controlPlane:               newControlPlane("v1.17.0", false),
+			machineDeployments:         []*clusterv1.MachineDeployment{newMachineDeployment("md-1", "v1.16.6", true)},
+			expectedReason:             clusterv1.ControlPlaneUpgradingReason,
+			expectedControlPlane:       "v1.17.0",
+			expectedMachineDeployments: map[string]string{"md-1": "v1.16.6"},
+		},
+		{
+			name:          "upgrades the MachineDeployments to the minor version of the control plane before the next minor version",
+			targetVersion: pointer.StringPtr("v1.18.2"),
+			controlPlane:  newControlPlane("v1.17.0", true),
+			machineDeployments: []*clusterv1.MachineDeployment{
+				newMachineDeployment("md-1", "v1.16.6", true),
+				newMachineDeployment("md-2", "v1.17.3", true),
+			},
+			machinePools: []*expv1.MachinePool{
+				newMachinePool("mp-1", "v1.16.6", true),
+			},
+			expectedReason:             clusterv1.WorkersUpgradingReason,
+			expectedControlPlane:       "v1.17.0",
+			expectedMachineDeployments: map[string]string{"md-1": "v1.17.0", "md-2": "v1.17.3"},
+			expectedMachinePools:       map[string]string{"mp-1": "v1.16.6"},
+		},
+		{
+			name:          "upgrades the MachinePools to the minor version of the control plane before the next minor version",
+			targetVersion: pointer.StringPtr("v1.18.2"),
+			controlPlane:  newControlPlane("v1.17.0", true),
+			machineDeployments: []*clusterv1.MachineDeployment{
+				newMachineDeployment("md-1", "v1.17.0", true),
+			},
+			machinePools: []*expv1.MachinePool{
+				newMachinePool("mp-1", "v1.16.6", true),
+			},
+			expectedReason:             clusterv1.WorkersUpgradingReason,
+			expectedControlPlane:       "v1.17.0",
+			expectedMachineDeployments: map[string]string{"md-1": "v1.17.0"},
+			expectedMachinePools:       map[string]string{"mp-1": "v1.17.0"},
+		},
+		{
+			name:          "waits for the workers to be rolled out before upgrading the control plane to the next minor version",
+			targetVersion: pointer.StringPtr("v1.18.2"),
+			controlPlane:  newControlPlane("v1.17.0", true),
+			machineDeployments: []*clusterv1.MachineDeployment{
+				newMachineDeployment("md-1", "v1.17.0", true),
+			},
+			machinePools: []*expv1.MachinePool{
+				newMachinePool("mp-1", "v1.17.0", false),
+			},
+			expectedReason:             clusterv1.WorkersUpgradingReason,
+			expectedControlPlane:       "v1.17.0",
+			expectedMachineDeployments: map[string]string{"md-1": "v1.17.0"},
+			expectedMachinePools:       map[string]string{"mp-1": "v1.17.0"},
+		},
+		{
+			name:          "upgrades the control plane to the next minor version once the workers are rolled out",
+			targetVersion: pointer.StringPtr("v1.18.2"),
+			controlPlane:  newControlPlane("v1.17.0", true),
+			machineDeployments: []*clusterv1.MachineDeployment{
+				newMachineDeployment("md-1", "v1.17.0", true),
+			},
+			machinePools: []*expv1.MachinePool{
+				newMachinePool("mp-1", "v1.17.0", true),
+			},
+			expectedReason:             clusterv1.ControlPlaneUpgradingReason,
+			expectedControlPlane:       "v1.18.2",
+			expectedMachineDeployments: map[string]string{"md-1": "v1.17.0"},
+			expectedMachinePools:       map[string]string{"mp-1": "v1.17.0"},
+		},
+		{
+			name:          "upgrades the MachineDeployments once the control plane is upgraded",
+			targetVersion: pointer.StringPtr("v1.18.2"),
+			controlPlane:  newControlPlane("v1.18.2", true),
+			machineDeployments: []*clusterv1.MachineDeployment{
+				newMachineDeployment("md-1", "v1.16.6", true),
+				newMachineDeployment("md-2", "v1.18.2", true),
+			},
+			expectedReason:             clusterv1.WorkersUpgradingReason,
+			expectedControlPlane:       "v1.18.2",
+			expectedMachineDeployments: map[string]string{"md-1": "v1.18.2", "md-2": "v1.18.2"},
+		},
+		{
+			name:          "waits for the MachineDeployments to be rolled out",
+			targetVersion: pointer.StringPtr("v1.18.2"),
+			controlPlane:  newControlPlane("v1.18.2", true),
+			machineDeployments: []*clusterv1.MachineDeployment{
+				newMachineDeployment("md-1", "v1.18.2", false),
+			},
+			expectedReason:             clusterv1.WorkersUpgradingReason,
+			expectedControlPlane:       "v1.18.2",
+			expectedMachineDeployments: map[string]string{"md-1": "v1.18.2"},
+		},
+		{
+			name:          "waits for the MachineDeployments to be rolled out before upgrading the MachinePools",
+			targetVersion: pointer.StringPtr("v1.18.2"),
+			controlPlane:  newControlPlane("v1.18.2", true),
+			machineDeployments: []*clusterv1.MachineDeployment{
+				newMachineDeployment("md-1", "v1.18.2", false),
+			},
+			machinePools: []*expv1.MachinePool{
+				newMachinePool("mp-1", "v1.16.6", true),
+			},
+			expectedReason:             clusterv1.WorkersUpgradingReason,
+			expectedControlPlane:       "v1.18.2",
+			expectedMachineDeployments: map[string]string{"md-1": "v1.18.2"},
+			expectedMachinePools:       map[string]string{"mp-1": "v1.16.6"},
+		},
+		{
+			name:          "upgrades the MachinePools once the MachineDeployments are upgraded",
+			targetVersion: pointer.StringPtr("v1.18.2"),
+			controlPlane:  newControlPlane("v1.18.2", true),
+			machineDeployments: []*clusterv1.MachineDeployment{
+				newMachineDeployment("md-1", "v1.18.2", true),
+			},
+			machinePools: []*expv1.MachinePool{
+				newMachinePool("mp-1", "v1.16.6", true),
+				newMachinePool("mp-2", "v1.18.2", true),
+			},
+			expectedReason:             clusterv1.WorkersUpgradingReason,
+			expectedControlPlane:       "v1.18.2",
+			expectedMachineDeployments: map[string]string{"md-1": "v1.18.2"},
+			expectedMachinePools:       map[string]string{"mp-1": "v1.18.2", "mp-2": "v1.18.2"},
+		},
+		{
+			name:          "waits for the MachinePools to be rolled out",
+			targetVersion: pointer.StringPtr("v1.18.2"),
+			controlPlane:  newControlPlane("v1.18.2", true),
+			machinePools: []*expv1.MachinePool{
+				newMachinePool("mp-1", "v1.18.2", false),
+			},
+			expectedReason:       clusterv1.WorkersUpgradingReason,
+			expectedControlPlane: "v1.18.2",
+			expectedMachinePools: map[string]string{"mp-1": "v1.18.2"},
+		},
+		{
+			name:          "reports the target version as reached",
+			targetVersion: pointer.StringPtr("v1.18.2"),
+			controlPlane:  newControlPlane("v1.18.2", true),
+			machineDeployments: []*clusterv1.MachineDeployment{
+				newMachineDeployment("md-1", "v1.18.2", true),
+			},
+			machinePools: []*expv1.MachinePool{
+				newMachinePool("mp-1", "v1.18.2", true),
+			},
+			expectedControlPlane:       "v1.18.2",
+			expectedMachineDeployments: map[string]string{"md-1": "v1.18.2"},
+			expectedMachinePools:       map[string]string{"mp-1": "v1.18.2"},
+		},
+	}
+
+	g := NewWithT(t)
+	g.Expect(feature.MutableGates.Set("MachinePool=true")).To(Succeed())
+	defer func() {
+		g.Expect(feature.MutableGates.Set("MachinePool=false")).To(Succeed())
+	}()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+			g.Expect(expv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-cluster",
+					Namespace: "test-namespace",
+				},
+				Spec: clusterv1.ClusterSpec{
+					ControlPlaneRef: &corev1.ObjectReference{
+						APIVersion: "controlplane.cluster.x-k8s.io/v1alpha3",
+						Kind:       "ControlPlane",
+						Name:       "test-cluster-control-plane",
+					},
+					TargetVersion: tt.targetVersion,
+				},
+			}
+
+			objs := []runtime.Object{cluster.DeepCopy(), tt.controlPlane}
+			for _, md := range tt.machineDeployments {
+				objs = append(objs, md)
+			}
+			for _, mp := range tt.machinePools {
+				objs = append(objs, mp)
+			}
+			c := fake.NewFakeClientWithScheme(scheme.Scheme, objs...)
+			r := &ClusterReconciler{
+				Client:   c,
+				Log:      log.Log,
+				scheme:   scheme.Scheme,
+				recorder: record.NewFakeRecorder(32),
+			}
+
+			g.Expect(r.reconcileTargetVersion(context.Background(), cluster)).To(Succeed())
+
+			switch {
+			case tt.targetVersion == nil:
+				g.Expect(conditions.Has(cluster, clusterv1.TargetVersionReachedCondition)).To(BeFalse())
+			case tt.expectedReason == "":
+				g.Expect(conditions.IsTrue(cluster, clusterv1.TargetVersionReachedCondition)).To(BeTrue())
+			default:
+				g.Expect(conditions.IsFalse(cluster, clusterv1.TargetVersionReachedCondition)).To(BeTrue())
+				g.Expect(conditions.GetReason(cluster, clusterv1.TargetVersionReachedCondition)).To(Equal(tt.expectedReason))
+			}
+
+			controlPlane := tt.controlPlane.DeepCopy()
+			g.Expect(c.Get(context.Background(), client.ObjectKey{Namespace: "test-namespace", Name: "test-cluster-control-plane"}, controlPlane)).To(Succeed())
+			version, _, err := unstructured.NestedString(controlPlane.Object, "spec", "version")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(version).To(Equal(tt.expectedControlPlane))
+
+			for name, expectedVersion := range tt.expectedMachineDeployments {
+				md := &clusterv1.MachineDeployment{}
+				g.Expect(c.Get(context.Background(), client.ObjectKey{Namespace: "test-namespace", Name: name}, md)).To(Succeed())
+				g.Expect(*md.Spec.Template.Spec.Version).To(Equal(expectedVersion))
+			}
+
+			for name, expectedVersion := range tt.expectedMachinePools {
+				mp := &expv1.MachinePool{}
+				g.Expect(c.Get(context.Background(), client.ObjectKey{Namespace: "test-namespace", Name: name}, mp)).To(Succeed())
+				g.Expect(*mp.Spec.Template.Spec.Version).To(Equal(expectedVersion))
+			}
+		})
+	}
+}
@@ -73,6 +73,10 @@ type CloneTemplateInput struct {
 	// Labels is an optional map of labels to be added to the object.
 	// +optional
 	Labels map[string]string
+
+	// Annotations is an optional map of annotations to be added to the object.
+	// +optional
+	Annotations map[string]string
 }
 
 // CloneTemplate uses the client and the reference to create a new object from the template.
@@ -87,6 +91,7 @@ func CloneTemplate(ctx context.Context, in *CloneTemplateInput) (*corev1.ObjectR
 		ClusterName: in.ClusterName,
 		OwnerRef:    in.OwnerRef,
 		Labels:      in.Labels,
+		Annotations: in.Annotations,
 	}
 	to, err := GenerateTemplate(generateTemplateInput)
 	if err != nil {
@@ -122,6 +127,10 @@ type GenerateTemplateInput struct {
 	// Labels is an optional map of labels to be added to the object.
 	// +optional
 	Labels map[string]string
+
+	// Annotations is an optional map of annotations to be added to the object.
+	// +optional
+	Annotations map[string]string
 }
 
 func GenerateTemplate(in *GenerateTemplateInput) (*unstructured.Unstructured, error) {
@@ -152,6 +161,18 @@ func GenerateTemplate(in *GenerateTemplateInput) (*unstructured.Unstructured, er
 	labels[clusterv1.ClusterLabelName] = in.ClusterName
 	to.SetLabels(labels)
 
+	// Set annotations.
+	if len(in.Annotations) > 0 {
+		annotations := to.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		for key, value := range in.Annotations {
+			annotations[key] = value
+		}
+		to.SetAnnotations(annotations)
+	}
+
 	// Set the owner reference.
 	if in.OwnerRef != nil {
 		to.SetOwnerReferences([]metav1.OwnerReference{*in.OwnerRef})
@@ -172,6 +172,9 @@ func TestCloneTemplateResourceFound(t *testing.T) {
 		Labels: map[string]string{
 			"test-label-1": "value-1",
 		},
+		Annotations: map[string]string{
+			"test-annotation-1": "value-1",
+		},
 	})
 	g.Expect(err).NotTo(HaveOccurred())
 	g.Expect(ref).NotTo(BeNil())
@@ -200,6 +203,7 @@ func TestCloneTemplateResourceFound(t *testing.T) {
 
 	cloneAnnotations := clone.GetAnnotations()
 	g.Expect(cloneAnnotations).To(HaveKeyWithValue("test", "annotations"))
+	g.Expect(cloneAnnotations).To(HaveKeyWithValue("test-annotation-1", "value-1"))
 }
 
 func TestCloneTemplateResourceFoundNoOwner(t *testing.T) {
@@ -46,6 +46,8 @@ import (
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/predicates"
+	capirecord "sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/cluster-api/util/tracing"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -108,7 +110,7 @@ func (r *MachineReconciler) SetupWithManager(mgr ctrl.Manager, options controlle
 		return errors.Wrap(err, "failed to add Watch for Clusters to controller manager")
 	}
 
-	r.recorder = mgr.GetEventRecorderFor("machine-controller")
+	r.recorder = capirecord.NewRateLimitedRecorder(mgr.GetEventRecorderFor("machine-controller"), capirecord.DefaultDuplicateEventsInterval)
 	r.config = mgr.GetConfig()
 	r.scheme = mgr.GetScheme()
 	r.externalTracker = external.ObjectTracker{
@@ -133,7 +135,8 @@ func (r *MachineReconciler) clusterToActiveMachines(a handler.MapObject) []recon
 }
 
 func (r *MachineReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr error) {
-	ctx := context.Background()
+	ctx, span := tracing.StartSpan(context.Background(), "MachineReconciler.Reconcile", tracing.ObjectAttributes("Machine", req.Namespace, req.Name)...)
+	defer func() { tracing.EndSpan(span, reterr) }()
 	logger := r.Log.WithValues("machine", req.Name, "namespace", req.Namespace)
 
 	// Fetch the Machine instance
@@ -249,6 +252,14 @@ func (r *MachineReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cl
 }
 
 func (r *MachineReconciler) reconcileMetrics(_ context.Context, m *clusterv1.Machine) {
+	// Drop the series of a Machine which is going away, so they don't outlive the object.
+	if !m.DeletionTimestamp.IsZero() && !controllerutil.ContainsFinalizer(m, clusterv1.MachineFinalizer) {
+		metrics.MachineBootstrapReady.DeleteLabelValues(m.Name, m.Namespace, m.Spec.ClusterName)
+		metrics.MachineInfrastructureReady.DeleteLabelValues(m.Name, m.Namespace, m.Spec.ClusterName)
+		metrics.MachineNodeReady.DeleteLabelValues(m.Name, m.Namespace, m.Spec.ClusterName)
+		return
+	}
+
 	if m.Status.BootstrapReady {
 		metrics.MachineBootstrapReady.WithLabelValues(m.Name, m.Namespace, m.Spec.ClusterName).Set(1)
 	} else {
@@ -285,11 +296,20 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 		// Drain node before deletion.
 		if _, exists := m.ObjectMeta.Annotations[clusterv1.ExcludeNodeDrainingAnnotation]; !exists {
 			logger.Info("Draining node", "node", m.Status.NodeRef.Name)
+			// Record when the drain operation starts in the DrainingSucceeded condition, so the drain duration is
+			// measured across requeues; the condition is left untouched until the drain operation succeeds.
+			if conditions.Get(m, clusterv1.DrainingSucceededCondition) == nil {
+				conditions.MarkFalse(m, clusterv1.DrainingSucceededCondition, clusterv1.DrainingReason, clusterv1.ConditionSeverityInfo, "Draining the node before deletion")
+			}
 			if err := r.drainNode(ctx, cluster, m.Status.NodeRef.Name, m.Name); err != nil {
-				r.recorder.Eventf(m, corev1.EventTypeWarning, "FailedDrainNode", "error draining Machine's node %q: %v", m.Status.NodeRef.Name, err)
+				capirecord.FailedDrainNode.Emit(r.recorder, m, m.Status.NodeRef.Name, err)
 				return ctrl.Result{}, err
 			}
-			r.recorder.Eventf(m, corev1.EventTypeNormal, "SuccessfulDrainNode", "success draining Machine's node %q", m.Status.NodeRef.Name)
+			if drainingCondition := conditions.Get(m, clusterv1.DrainingSucceededCondition); drainingCondition.Status != corev1.ConditionTrue {
+				metrics.MachineDrainDuration.WithLabelValues(m.Namespace, m.Spec.ClusterName).Observe(time.Since(drainingCondition.LastTransitionTime.Time).Seconds())
+				capirecord.SuccessfulDrainNode.Emit(r.recorder, m, m.Status.NodeRef.Name)
+				conditions.MarkTrue(m, clusterv1.DrainingSucceededCondition)
+			}
 		}
 	}
 
@@ -313,11 +333,12 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 		})
 		if waitErr != nil {
 			logger.Error(deleteNodeErr, "Timed out deleting node, moving on", "node", m.Status.NodeRef.Name)
-			r.recorder.Eventf(m, corev1.EventTypeWarning, "FailedDeleteNode", "error deleting Machine's node: %v", deleteNodeErr)
+			capirecord.FailedDeleteNode.Emit(r.recorder, m, deleteNodeErr)
 		}
 	}
 
 	controllerutil.RemoveFinalizer(m, clusterv1.MachineFinalizer)
+	metrics.MachineDeletionDuration.WithLabelValues(m.Namespace, m.Spec.ClusterName).Observe(time.Since(m.DeletionTimestamp.Time).Seconds())
 	return ctrl.Result{}, nil
 }
 
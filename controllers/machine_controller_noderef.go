@@ -23,9 +23,11 @@ import (
 	"github.com/pkg/errors"
 	apicorev1 "k8s.io/api/core/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/metrics"
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
+	capirecord "sigs.k8s.io/cluster-api/util/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -71,14 +73,15 @@ func (r *MachineReconciler) reconcileNodeRef(ctx context.Context, cluster *clust
 				"cannot assign NodeRef to Machine %q in namespace %q, no matching Node", machine.Name, machine.Namespace)
 		}
 		logger.Error(err, "Failed to assign NodeRef")
-		r.recorder.Event(machine, apicorev1.EventTypeWarning, "FailedSetNodeRef", err.Error())
+		capirecord.FailedSetNodeRef.Emit(r.recorder, machine, err)
 		return err
 	}
 
 	// Set the Machine NodeRef.
 	machine.Status.NodeRef = nodeRef
+	metrics.MachineProvisioningDuration.WithLabelValues(machine.Namespace, machine.Spec.ClusterName).Observe(time.Since(machine.CreationTimestamp.Time).Seconds())
 	logger.Info("Set Machine's NodeRef", "noderef", machine.Status.NodeRef.Name)
-	r.recorder.Event(machine, apicorev1.EventTypeNormal, "SuccessfulSetNodeRef", machine.Status.NodeRef.Name)
+	capirecord.SuccessfulSetNodeRef.Emit(r.recorder, machine, machine.Status.NodeRef.Name)
 	return nil
 }
 
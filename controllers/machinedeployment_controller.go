@@ -22,7 +22,6 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
-	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -33,6 +32,8 @@ import (
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/predicates"
+	capirecord "sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/cluster-api/util/tracing"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -91,12 +92,13 @@ func (r *MachineDeploymentReconciler) SetupWithManager(mgr ctrl.Manager, options
 		return errors.Wrap(err, "failed to add Watch for Clusters to controller manager")
 	}
 
-	r.recorder = mgr.GetEventRecorderFor("machinedeployment-controller")
+	r.recorder = capirecord.NewRateLimitedRecorder(mgr.GetEventRecorderFor("machinedeployment-controller"), capirecord.DefaultDuplicateEventsInterval)
 	return nil
 }
 
 func (r *MachineDeploymentReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr error) {
-	ctx := context.Background()
+	ctx, span := tracing.StartSpan(context.Background(), "MachineDeploymentReconciler.Reconcile", tracing.ObjectAttributes("MachineDeployment", req.Namespace, req.Name)...)
+	defer func() { tracing.EndSpan(span, reterr) }()
 	logger := r.Log.WithValues("machinedeployment", req.Name, "namespace", req.Namespace)
 
 	// Fetch the MachineDeployment instance.
@@ -144,7 +146,7 @@ func (r *MachineDeploymentReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result
 	result, err := r.reconcile(ctx, cluster, deployment)
 	if err != nil {
 		logger.Error(err, "Failed to reconcile MachineDeployment")
-		r.recorder.Eventf(deployment, corev1.EventTypeWarning, "ReconcileError", "%v", err)
+		capirecord.ReconcileError.Emit(r.recorder, deployment, err)
 	}
 	return result, err
 }
@@ -196,6 +198,24 @@ func (r *MachineDeploymentReconciler) reconcile(ctx context.Context, cluster *cl
 		return ctrl.Result{}, r.sync(d, msList)
 	}
 
+	// Do not roll out machines with a minor version newer than the one the control plane has been rolled out to,
+	// as this is not supported by the Kubernetes version skew policy; scaling is still allowed.
+	if d.Spec.Template.Spec.Version != nil {
+		version, err := util.ParseMajorMinorPatch(*d.Spec.Template.Spec.Version)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		controlPlaneVersion, err := util.GetControlPlaneVersion(ctx, r.Client, cluster)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if controlPlaneVersion != nil && util.IsMinorVersionNewer(version, *controlPlaneVersion) {
+			logger.Info("Waiting for the control plane to be upgraded before rolling out the MachineDeployment", "version", *d.Spec.Template.Spec.Version)
+			capirecord.VersionSkewNotSupported.Emit(r.recorder, d, *d.Spec.Template.Spec.Version, controlPlaneVersion)
+			return ctrl.Result{}, r.sync(d, msList)
+		}
+	}
+
 	if d.Spec.Strategy.Type == clusterv1.RollingUpdateMachineDeploymentStrategyType {
 		return ctrl.Result{}, r.rolloutRolling(d, msList)
 	}
@@ -238,11 +258,11 @@ func (r *MachineDeploymentReconciler) getMachineSetsForDeployment(d *clusterv1.M
 		// Attempt to adopt machine if it meets previous conditions and it has no controller references.
 		if metav1.GetControllerOf(ms) == nil {
 			if err := r.adoptOrphan(d, ms); err != nil {
-				r.recorder.Eventf(d, corev1.EventTypeWarning, "FailedAdopt", "Failed to adopt MachineSet %q: %v", ms.Name, err)
+				capirecord.FailedAdopt.Emit(r.recorder, d, "MachineSet", ms.Name, err)
 				logger.Error(err, "Failed to adopt MachineSet into MachineDeployment", "machineset", ms.Name)
 				continue
 			}
-			r.recorder.Eventf(d, corev1.EventTypeNormal, "SuccessfulAdopt", "Adopted MachineSet %q", ms.Name)
+			capirecord.SuccessfulAdopt.Emit(r.recorder, d, "MachineSet", ms.Name)
 		}
 
 		if !metav1.IsControlledBy(ms, d) {
@@ -23,7 +23,6 @@ import (
 	"strconv"
 
 	"github.com/pkg/errors"
-	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	apirand "k8s.io/apimachinery/pkg/util/rand"
@@ -32,7 +31,9 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controllers/mdutil"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
+	capirecord "sigs.k8s.io/cluster-api/util/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -206,13 +207,13 @@ func (r *MachineDeploymentReconciler) getNewMachineSet(d *clusterv1.MachineDeplo
 		return nil, err
 	case err != nil:
 		logger.Error(err, "Failed to create new machine set", "machineset", newMS.Name)
-		r.recorder.Eventf(d, corev1.EventTypeWarning, "FailedCreate", "Failed to create MachineSet %q: %v", newMS.Name, err)
+		capirecord.FailedCreate.Emit(r.recorder, d, "MachineSet", newMS.Name, err)
 		return nil, err
 	}
 
 	if !alreadyExists {
 		logger.V(4).Info("Created new machine set", "machineset", createdMS.Name)
-		r.recorder.Eventf(d, corev1.EventTypeNormal, "SuccessfulCreate", "Created MachineSet %q", newMS.Name)
+		capirecord.SuccessfulCreate.Emit(r.recorder, d, "MachineSet", newMS.Name)
 	}
 
 	err = r.updateMachineDeployment(d, func(innerDeployment *clusterv1.MachineDeployment) {
@@ -342,9 +343,44 @@ func (r *MachineDeploymentReconciler) scale(deployment *clusterv1.MachineDeploym
 // syncDeploymentStatus checks if the status is up-to-date and sync it if necessary
 func (r *MachineDeploymentReconciler) syncDeploymentStatus(allMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet, d *clusterv1.MachineDeployment) error {
 	d.Status = calculateStatus(allMSs, newMS, d)
+	calculateConditions(allMSs, d)
 	return nil
 }
 
+// calculateConditions sets the conditions of the provided deployment by looking into its status and into the provided machine sets.
+func calculateConditions(allMSs []*clusterv1.MachineSet, deployment *clusterv1.MachineDeployment) {
+	// Set the Available condition, true if the minimum number of machines required by the rollout strategy are available.
+	minReplicasNeeded := *(deployment.Spec.Replicas) - mdutil.MaxUnavailable(*deployment)
+	if deployment.Status.AvailableReplicas >= minReplicasNeeded {
+		conditions.MarkTrue(deployment, clusterv1.MachineDeploymentAvailableCondition)
+	} else {
+		conditions.MarkFalse(deployment, clusterv1.MachineDeploymentAvailableCondition, clusterv1.WaitingForAvailableMachinesReason, clusterv1.ConditionSeverityWarning,
+			"Minimum availability requires %d replicas, current %d available", minReplicasNeeded, deployment.Status.AvailableReplicas)
+	}
+
+	// Aggregate the operational state of all the machine sets; while aggregating we are adding the
+	// source ref (reason@machineset/name) so the problem can be easily tracked down to its source machine set.
+	machineSetGetters := make([]conditions.Getter, 0, len(allMSs))
+	for _, ms := range allMSs {
+		if ms != nil {
+			machineSetGetters = append(machineSetGetters, ms)
+		}
+	}
+	if len(machineSetGetters) > 0 {
+		conditions.SetAggregate(deployment, clusterv1.MachinesReadyCondition, machineSetGetters, conditions.AddSourceRef())
+	} else {
+		conditions.Delete(deployment, clusterv1.MachinesReadyCondition)
+	}
+
+	// Always update the readyCondition with the summary of the MachineDeployment conditions.
+	conditions.SetSummary(deployment,
+		conditions.WithConditions(
+			clusterv1.MachineDeploymentAvailableCondition,
+			clusterv1.MachinesReadyCondition,
+		),
+	)
+}
+
 // calculateStatus calculates the latest status for the provided deployment by looking into the provided machine sets.
 func calculateStatus(allMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet, deployment *clusterv1.MachineDeployment) clusterv1.MachineDeploymentStatus {
 	availableReplicas := mdutil.GetAvailableReplicaCountForMachineSets(allMSs)
@@ -369,6 +405,7 @@ func calculateStatus(allMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet
 		ReadyReplicas:       mdutil.GetReadyReplicaCountForMachineSets(allMSs),
 		AvailableReplicas:   availableReplicas,
 		UnavailableReplicas: unavailableReplicas,
+		Conditions:          deployment.Status.Conditions,
 	}
 
 	if *deployment.Spec.Replicas == status.ReadyReplicas {
@@ -437,9 +474,9 @@ func (r *MachineDeploymentReconciler) scaleMachineSetOperation(ms *clusterv1.Mac
 
 		err = patchHelper.Patch(context.Background(), ms)
 		if err != nil {
-			r.recorder.Eventf(deployment, corev1.EventTypeWarning, "FailedScale", "Failed to scale MachineSet %q: %v", ms.Name, err)
+			capirecord.FailedScale.Emit(r.recorder, deployment, ms.Name, err)
 		} else if sizeNeedsUpdate {
-			r.recorder.Eventf(deployment, corev1.EventTypeNormal, "SuccessfulScale", "Scaled %s MachineSet %q to %d", scaleOperation, ms.Name, newScale)
+			capirecord.SuccessfulScale.Emit(r.recorder, deployment, scaleOperation, ms.Name, newScale)
 		}
 		return err
 	}
@@ -487,10 +524,10 @@ func (r *MachineDeploymentReconciler) cleanupDeployment(oldMSs []*clusterv1.Mach
 		if err := r.Client.Delete(context.Background(), ms); err != nil && !apierrors.IsNotFound(err) {
 			// Return error instead of aggregating and continuing DELETEs on the theory
 			// that we may be overloading the api server.
-			r.recorder.Eventf(deployment, corev1.EventTypeWarning, "FailedDelete", "Failed to delete MachineSet %q: %v", ms.Name, err)
+			capirecord.FailedDelete.Emit(r.recorder, deployment, "MachineSet", ms.Name, err)
 			return err
 		}
-		r.recorder.Eventf(deployment, corev1.EventTypeNormal, "SuccessfulDelete", "Deleted MachineSet %q", ms.Name)
+		capirecord.SuccessfulDelete.Emit(r.recorder, deployment, "MachineSet", ms.Name)
 	}
 
 	return nil
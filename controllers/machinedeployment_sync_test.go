@@ -22,9 +22,11 @@ import (
 	. "github.com/onsi/gomega"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/util/conditions"
 )
 
 func TestMachineDeploymentSyncStatus(t *testing.T) {
@@ -220,3 +222,73 @@ func TestMachineDeploymentSyncStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestMachineDeploymentCalculateConditions(t *testing.T) {
+	newDeployment := func(availableReplicas int32) *clusterv1.MachineDeployment {
+		maxSurge := intstr.FromInt(1)
+		maxUnavailable := intstr.FromInt(1)
+		return &clusterv1.MachineDeployment{
+			Spec: clusterv1.MachineDeploymentSpec{
+				Replicas: pointer.Int32Ptr(3),
+				Strategy: &clusterv1.MachineDeploymentStrategy{
+					Type: clusterv1.RollingUpdateMachineDeploymentStrategyType,
+					RollingUpdate: &clusterv1.MachineRollingUpdateDeployment{
+						MaxSurge:       &maxSurge,
+						MaxUnavailable: &maxUnavailable,
+					},
+				},
+			},
+			Status: clusterv1.MachineDeploymentStatus{
+				AvailableReplicas: availableReplicas,
+			},
+		}
+	}
+	newMachineSet := func(name string, ready *clusterv1.Condition) *clusterv1.MachineSet {
+		ms := &clusterv1.MachineSet{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		conditions.Set(ms, ready)
+		return ms
+	}
+
+	tests := []struct {
+		name                string
+		deployment          *clusterv1.MachineDeployment
+		machineSets         []*clusterv1.MachineSet
+		expectAvailable     bool
+		expectMachinesReady *clusterv1.Condition
+	}{
+		{
+			name:                "available with all the machine sets ready",
+			deployment:          newDeployment(2),
+			machineSets:         []*clusterv1.MachineSet{newMachineSet("ms-1", conditions.TrueCondition(clusterv1.ReadyCondition)), nil},
+			expectAvailable:     true,
+			expectMachinesReady: conditions.TrueCondition(clusterv1.MachinesReadyCondition),
+		},
+		{
+			name:       "not available with a machine set not ready",
+			deployment: newDeployment(1),
+			machineSets: []*clusterv1.MachineSet{
+				newMachineSet("ms-1", conditions.TrueCondition(clusterv1.ReadyCondition)),
+				newMachineSet("ms-2", conditions.FalseCondition(clusterv1.ReadyCondition, clusterv1.MachineCreationFailedReason, clusterv1.ConditionSeverityError, "")),
+			},
+			expectAvailable:     false,
+			expectMachinesReady: conditions.FalseCondition(clusterv1.MachinesReadyCondition, clusterv1.MachineCreationFailedReason+"@/ms-2", clusterv1.ConditionSeverityError, "1 of 2 completed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			calculateConditions(tt.machineSets, tt.deployment)
+
+			g.Expect(conditions.IsTrue(tt.deployment, clusterv1.MachineDeploymentAvailableCondition)).To(Equal(tt.expectAvailable))
+			g.Expect(conditions.IsTrue(tt.deployment, clusterv1.ReadyCondition)).To(Equal(tt.expectAvailable))
+
+			machinesReady := conditions.Get(tt.deployment, clusterv1.MachinesReadyCondition)
+			g.Expect(machinesReady).NotTo(BeNil())
+			g.Expect(machinesReady.Status).To(Equal(tt.expectMachinesReady.Status))
+			g.Expect(machinesReady.Reason).To(Equal(tt.expectMachinesReady.Reason))
+			g.Expect(machinesReady.Message).To(Equal(tt.expectMachinesReady.Message))
+		})
+	}
+}
@@ -38,6 +38,8 @@ import (
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/predicates"
+	capirecord "sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/cluster-api/util/tracing"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -53,6 +55,7 @@ const (
 
 	// EventRemediationRestricted is emitted in case when machine remediation
 	// is restricted by remediation circuit shorting logic
+	// Deprecated: use record.RemediationRestricted from sigs.k8s.io/cluster-api/util/record
 	EventRemediationRestricted string = "RemediationRestricted"
 )
 
@@ -104,13 +107,14 @@ func (r *MachineHealthCheckReconciler) SetupWithManager(mgr ctrl.Manager, option
 	}
 
 	r.controller = controller
-	r.recorder = mgr.GetEventRecorderFor("machinehealthcheck-controller")
+	r.recorder = capirecord.NewRateLimitedRecorder(mgr.GetEventRecorderFor("machinehealthcheck-controller"), capirecord.DefaultDuplicateEventsInterval)
 	r.scheme = mgr.GetScheme()
 	return nil
 }
 
 func (r *MachineHealthCheckReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr error) {
-	ctx := context.Background()
+	ctx, span := tracing.StartSpan(context.Background(), "MachineHealthCheckReconciler.Reconcile", tracing.ObjectAttributes("MachineHealthCheck", req.Namespace, req.Name)...)
+	defer func() { tracing.EndSpan(span, reterr) }()
 	logger := r.Log.WithValues("machinehealthcheck", req.Name, "namespace", req.Namespace)
 
 	// Fetch the MachineHealthCheck instance
@@ -165,7 +169,7 @@ func (r *MachineHealthCheckReconciler) Reconcile(req ctrl.Request) (_ ctrl.Resul
 	result, err := r.reconcile(ctx, logger, cluster, m)
 	if err != nil {
 		logger.Error(err, "Failed to reconcile MachineHealthCheck")
-		r.recorder.Eventf(m, corev1.EventTypeWarning, "ReconcileError", "%v", err)
+		capirecord.ReconcileError.Emit(r.recorder, m, err)
 
 		// Requeue immediately if any errors occurred
 		return ctrl.Result{}, err
@@ -215,15 +219,11 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 			"unhealthy targets", len(unhealthy),
 		)
 
-		r.recorder.Eventf(
-			m,
-			corev1.EventTypeWarning,
-			EventRemediationRestricted,
-			"Remediation restricted due to exceeded number of unhealthy machines (total: %v, unhealthy: %v, maxUnhealthy: %v)",
-			totalTargets,
-			m.Status.CurrentHealthy,
-			m.Spec.MaxUnhealthy,
-		)
+		conditions.MarkFalse(m, clusterv1.RemediationAllowedCondition, clusterv1.TooManyUnhealthyReason, clusterv1.ConditionSeverityWarning,
+			"Remediation is not allowed, the number of not started or unhealthy machines exceeds maxUnhealthy (total: %v, unhealthy: %v, maxUnhealthy: %v)",
+			totalTargets, len(unhealthy), m.Spec.MaxUnhealthy)
+
+		capirecord.RemediationRestricted.Emit(r.recorder, m, totalTargets, m.Status.CurrentHealthy, m.Spec.MaxUnhealthy)
 		for _, t := range append(healthy, unhealthy...) {
 			if err := t.patchHelper.Patch(ctx, t.Machine); err != nil {
 				return ctrl.Result{}, errors.Wrapf(err, "Failed to patch machine status for machine %q", t.Machine.Name)
@@ -238,6 +238,8 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 		"unhealthy targets", len(unhealthy),
 	)
 
+	conditions.MarkTrue(m, clusterv1.RemediationAllowedCondition)
+
 	// mark for remediation
 	errList := []error{}
 	for _, t := range unhealthy {
@@ -247,13 +249,7 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 		if err := t.patchHelper.Patch(ctx, t.Machine); err != nil {
 			return ctrl.Result{}, errors.Wrapf(err, "Failed to patch unhealthy machine status for machine %q", t.Machine.Name)
 		}
-		r.recorder.Eventf(
-			t.Machine,
-			corev1.EventTypeNormal,
-			EventMachineMarkedUnhealthy,
-			"Machine %v has been marked as unhealthy",
-			t.string(),
-		)
+		capirecord.MachineMarkedUnhealthy.Emit(r.recorder, t.Machine, t.string())
 	}
 	for _, t := range healthy {
 		logger.V(3).Info("patching machine", "machine", t.Machine.GetName())
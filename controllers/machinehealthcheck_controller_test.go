@@ -280,7 +280,7 @@ var _ = Describe("MachineHealthCheck", func() {
 					if err != nil {
 						return nil
 					}
-					return &mhc.Status
+					return withoutConditions(mhc.Status)
 				}, timeout).Should(Equal(&clusterv1.MachineHealthCheckStatus{
 					ExpectedMachines: 2,
 					CurrentHealthy:   2},
@@ -302,7 +302,7 @@ var _ = Describe("MachineHealthCheck", func() {
 					if err != nil {
 						return nil
 					}
-					return &mhc.Status
+					return withoutConditions(mhc.Status)
 				}, timeout).Should(Equal(&clusterv1.MachineHealthCheckStatus{
 					ExpectedMachines: 3,
 					CurrentHealthy:   2},
@@ -326,12 +326,21 @@ var _ = Describe("MachineHealthCheck", func() {
 					if err != nil {
 						return nil
 					}
-					return &mhc.Status
+					return withoutConditions(mhc.Status)
 				}, timeout).Should(Equal(&clusterv1.MachineHealthCheckStatus{
 					ExpectedMachines: 3,
 					CurrentHealthy:   1},
 				))
 
+				// Make sure remediation is reported as not allowed.
+				Eventually(func() bool {
+					if err := testEnv.Get(ctx, util.ObjectKey(mhc), mhc); err != nil {
+						return false
+					}
+					return conditions.IsFalse(mhc, clusterv1.RemediationAllowedCondition) &&
+						conditions.GetReason(mhc, clusterv1.RemediationAllowedCondition) == clusterv1.TooManyUnhealthyReason
+				}, timeout).Should(BeTrue())
+
 				// Calculate how many Machines have health check succeeded = false.
 				Eventually(func() (unhealthy int) {
 					machines := &clusterv1.MachineList{}
@@ -385,7 +394,7 @@ var _ = Describe("MachineHealthCheck", func() {
 				if err != nil {
 					return nil
 				}
-				return &mhc.Status
+				return withoutConditions(mhc.Status)
 			}, timeout).Should(Equal(&clusterv1.MachineHealthCheckStatus{
 				ExpectedMachines: 3,
 				CurrentHealthy:   2},
@@ -448,7 +457,7 @@ var _ = Describe("MachineHealthCheck", func() {
 				if err != nil {
 					return nil
 				}
-				return &mhc.Status
+				return withoutConditions(mhc.Status)
 			}, timeout).Should(Equal(&clusterv1.MachineHealthCheckStatus{
 				ExpectedMachines: 3,
 				CurrentHealthy:   2},
@@ -513,7 +522,7 @@ var _ = Describe("MachineHealthCheck", func() {
 				if err != nil {
 					return nil
 				}
-				return &mhc.Status
+				return withoutConditions(mhc.Status)
 			}, timeout).Should(Equal(&clusterv1.MachineHealthCheckStatus{
 				ExpectedMachines: 3,
 				CurrentHealthy:   2},
@@ -569,7 +578,7 @@ var _ = Describe("MachineHealthCheck", func() {
 				if err != nil {
 					return nil
 				}
-				return &mhc.Status
+				return withoutConditions(mhc.Status)
 			}, timeout).Should(Equal(&clusterv1.MachineHealthCheckStatus{
 				ExpectedMachines: 1,
 				CurrentHealthy:   1},
@@ -592,7 +601,7 @@ var _ = Describe("MachineHealthCheck", func() {
 				if err != nil {
 					return nil
 				}
-				return &mhc.Status
+				return withoutConditions(mhc.Status)
 			}, timeout).Should(Equal(&clusterv1.MachineHealthCheckStatus{
 				ExpectedMachines: 1,
 				CurrentHealthy:   0},
@@ -1330,3 +1339,10 @@ func ownerReferenceForCluster(ctx context.Context, c *clusterv1.Cluster) metav1.
 		UID:        cc.UID,
 	}
 }
+
+// withoutConditions returns a copy of the MachineHealthCheck status without conditions,
+// so it can be compared ignoring the condition transition times.
+func withoutConditions(status clusterv1.MachineHealthCheckStatus) *clusterv1.MachineHealthCheckStatus {
+	status.Conditions = nil
+	return &status
+}
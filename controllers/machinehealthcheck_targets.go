@@ -30,6 +30,7 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
+	capirecord "sigs.k8s.io/cluster-api/util/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -49,9 +50,11 @@ const (
 	// Deprecated: no longer in use
 	EventMachineDeleted string = "MachineDeleted"
 	// EventMachineMarkedUnhealthy is emitted when machine was successfully marked as unhealthy
+	// Deprecated: use record.MachineMarkedUnhealthy from sigs.k8s.io/cluster-api/util/record
 	EventMachineMarkedUnhealthy string = "MachineMarkedUnhealthy"
 	// EventDetectedUnhealthy is emitted in case a node associated with a
 	// machine was detected unhealthy
+	// Deprecated: use record.DetectedUnhealthy from sigs.k8s.io/cluster-api/util/record
 	EventDetectedUnhealthy string = "DetectedUnhealthy"
 )
 
@@ -247,14 +250,7 @@ func (r *MachineHealthCheckReconciler) healthCheckTargets(targets []healthCheckT
 
 		if nextCheck > 0 {
 			logger.V(3).Info("Target is likely to go unhealthy", "timeUntilUnhealthy", nextCheck.Truncate(time.Second).String())
-			r.recorder.Eventf(
-				t.Machine,
-				corev1.EventTypeNormal,
-				EventDetectedUnhealthy,
-				"Machine %v has unhealthy node %v",
-				t.string(),
-				t.nodeName(),
-			)
+			capirecord.DetectedUnhealthy.Emit(r.recorder, t.Machine, t.string(), t.nodeName())
 			nextCheckTimes = append(nextCheckTimes, nextCheck)
 			continue
 		}
@@ -25,6 +25,7 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -41,6 +42,8 @@ import (
 	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/predicates"
+	capirecord "sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/cluster-api/util/tracing"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -106,13 +109,14 @@ func (r *MachineSetReconciler) SetupWithManager(mgr ctrl.Manager, options contro
 		return errors.Wrap(err, "failed to add Watch for Clusters to controller manager")
 	}
 
-	r.recorder = mgr.GetEventRecorderFor("machineset-controller")
+	r.recorder = capirecord.NewRateLimitedRecorder(mgr.GetEventRecorderFor("machineset-controller"), capirecord.DefaultDuplicateEventsInterval)
 	r.scheme = mgr.GetScheme()
 	return nil
 }
 
-func (r *MachineSetReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
-	ctx := context.Background()
+func (r *MachineSetReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr error) {
+	ctx, span := tracing.StartSpan(context.Background(), "MachineSetReconciler.Reconcile", tracing.ObjectAttributes("MachineSet", req.Namespace, req.Name)...)
+	defer func() { tracing.EndSpan(span, reterr) }()
 	logger := r.Log.WithValues("machineset", req.Name, "namespace", req.Namespace)
 
 	machineSet := &clusterv1.MachineSet{}
@@ -146,7 +150,7 @@ func (r *MachineSetReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error)
 	result, err := r.reconcile(ctx, cluster, machineSet)
 	if err != nil {
 		logger.Error(err, "Failed to reconcile MachineSet")
-		r.recorder.Eventf(machineSet, corev1.EventTypeWarning, "ReconcileError", "%v", err)
+		capirecord.ReconcileError.Emit(r.recorder, machineSet, err)
 	}
 	return result, err
 }
@@ -218,11 +222,11 @@ func (r *MachineSetReconciler) reconcile(ctx context.Context, cluster *clusterv1
 		if metav1.GetControllerOf(machine) == nil {
 			if err := r.adoptOrphan(ctx, machineSet, machine); err != nil {
 				logger.Error(err, "Failed to adopt Machine", "machine", machine.Name)
-				r.recorder.Eventf(machineSet, corev1.EventTypeWarning, "FailedAdopt", "Failed to adopt Machine %q: %v", machine.Name, err)
+				capirecord.FailedAdopt.Emit(r.recorder, machineSet, "Machine", machine.Name, err)
 				continue
 			}
 			logger.Info("Adopted Machine", "machine", machine.Name)
-			r.recorder.Eventf(machineSet, corev1.EventTypeNormal, "SuccessfulAdopt", "Adopted Machine %q", machine.Name)
+			capirecord.SuccessfulAdopt.Emit(r.recorder, machineSet, "Machine", machine.Name)
 		}
 
 		filteredMachines = append(filteredMachines, machine)
@@ -250,9 +254,9 @@ func (r *MachineSetReconciler) reconcile(ctx context.Context, cluster *clusterv1
 		return ctrl.Result{}, errors.Wrap(err, "failed to remediate machines")
 	}
 
-	syncErr := r.syncReplicas(ctx, machineSet, filteredMachines)
-
 	ms := machineSet.DeepCopy()
+	syncErr := r.syncReplicas(ctx, ms, filteredMachines)
+
 	newStatus, err := r.calculateStatus(ctx, cluster, ms, filteredMachines)
 	if err != nil {
 		return ctrl.Result{}, errors.Wrapf(err, "failed to calculate MachineSet's Status")
@@ -338,6 +342,7 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 					Labels:      machine.Labels,
 				})
 				if err != nil {
+					conditions.MarkFalse(ms, clusterv1.MachinesCreatedCondition, clusterv1.BootstrapTemplateCloningFailedReason, clusterv1.ConditionSeverityError, "%v", err)
 					return errors.Wrapf(err, "failed to clone bootstrap configuration for MachineSet %q in namespace %q", ms.Name, ms.Namespace)
 				}
 				machine.Spec.Bootstrap.ConfigRef = bootstrapRef
@@ -351,14 +356,17 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 				Labels:      machine.Labels,
 			})
 			if err != nil {
+				conditions.MarkFalse(ms, clusterv1.MachinesCreatedCondition, clusterv1.InfrastructureTemplateCloningFailedReason, clusterv1.ConditionSeverityError, "%v", err)
 				return errors.Wrapf(err, "failed to clone infrastructure configuration for MachineSet %q in namespace %q", ms.Name, ms.Namespace)
 			}
 			machine.Spec.InfrastructureRef = *infraRef
 
 			if err := r.Client.Create(ctx, machine); err != nil {
 				logger.Error(err, "Unable to create Machine", "machine", machine.Name)
-				r.recorder.Eventf(ms, corev1.EventTypeWarning, "FailedCreate", "Failed to create machine %q: %v", machine.Name, err)
+				capirecord.FailedCreate.Emit(r.recorder, ms, "Machine", machine.Name, err)
 				errs = append(errs, err)
+				conditions.MarkFalse(ms, clusterv1.MachinesCreatedCondition, clusterv1.MachineCreationFailedReason,
+					clusterv1.ConditionSeverityError, "%v", err)
 
 				// Try to cleanup the external objects if the Machine creation failed.
 				if err := r.Client.Delete(ctx, util.ObjectReferenceToUnstructured(*infraRef)); !apierrors.IsNotFound(err) {
@@ -373,16 +381,18 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 			}
 
 			logger.Info(fmt.Sprintf("Created machine %d of %d with name %q", i+1, diff, machine.Name))
-			r.recorder.Eventf(ms, corev1.EventTypeNormal, "SuccessfulCreate", "Created machine %q", machine.Name)
+			capirecord.SuccessfulCreate.Emit(r.recorder, ms, "Machine", machine.Name)
 			machineList = append(machineList, machine)
 		}
 
 		if len(errs) > 0 {
 			return kerrors.NewAggregate(errs)
 		}
+		conditions.MarkTrue(ms, clusterv1.MachinesCreatedCondition)
 		return r.waitForMachineCreation(machineList)
 	case diff > 0:
 		logger.Info("Too many replicas", "need", *(ms.Spec.Replicas), "deleting", diff)
+		conditions.MarkTrue(ms, clusterv1.MachinesCreatedCondition)
 
 		deletePriorityFunc, err := getDeletePriorityFunc(ms)
 		if err != nil {
@@ -395,12 +405,12 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 		for _, machine := range machinesToDelete {
 			if err := r.Client.Delete(ctx, machine); err != nil {
 				logger.Error(err, "Unable to delete Machine", "machine", machine.Name)
-				r.recorder.Eventf(ms, corev1.EventTypeWarning, "FailedDelete", "Failed to delete machine %q: %v", machine.Name, err)
+				capirecord.FailedDelete.Emit(r.recorder, ms, "Machine", machine.Name, err)
 				errs = append(errs, err)
 				continue
 			}
 			logger.Info("Deleted machine", "machine", machine.Name)
-			r.recorder.Eventf(ms, corev1.EventTypeNormal, "SuccessfulDelete", "Deleted machine %q", machine.Name)
+			capirecord.SuccessfulDelete.Emit(r.recorder, ms, "Machine", machine.Name)
 		}
 
 		if len(errs) > 0 {
@@ -409,6 +419,7 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 		return r.waitForMachineDeletion(machinesToDelete)
 	}
 
+	conditions.MarkTrue(ms, clusterv1.MachinesCreatedCondition)
 	return nil
 }
 
@@ -587,6 +598,27 @@ func (r *MachineSetReconciler) shouldAdopt(ms *clusterv1.MachineSet) bool {
 
 func (r *MachineSetReconciler) calculateStatus(ctx context.Context, cluster *clusterv1.Cluster, ms *clusterv1.MachineSet, filteredMachines []*clusterv1.Machine) (*clusterv1.MachineSetStatus, error) {
 	logger := r.Log.WithValues("machineset", ms.Name, "namespace", ms.Namespace)
+
+	// Aggregate the operational state of all the machines; while aggregating we are adding the
+	// source ref (reason@machine/name) so the problem can be easily tracked down to its source machine.
+	if len(filteredMachines) > 0 {
+		machineGetters := make([]conditions.Getter, 0, len(filteredMachines))
+		for _, machine := range filteredMachines {
+			machineGetters = append(machineGetters, machine)
+		}
+		conditions.SetAggregate(ms, clusterv1.MachinesReadyCondition, machineGetters, conditions.AddSourceRef())
+	} else {
+		conditions.Delete(ms, clusterv1.MachinesReadyCondition)
+	}
+
+	// Always update the readyCondition with the summary of the MachineSet conditions.
+	conditions.SetSummary(ms,
+		conditions.WithConditions(
+			clusterv1.MachinesCreatedCondition,
+			clusterv1.MachinesReadyCondition,
+		),
+	)
+
 	newStatus := ms.Status.DeepCopy()
 
 	// Copy label selector to its status counterpart in string format.
@@ -649,6 +681,7 @@ func (r *MachineSetReconciler) patchMachineSetStatus(ctx context.Context, ms *cl
 		ms.Status.FullyLabeledReplicas == newStatus.FullyLabeledReplicas &&
 		ms.Status.ReadyReplicas == newStatus.ReadyReplicas &&
 		ms.Status.AvailableReplicas == newStatus.AvailableReplicas &&
+		equality.Semantic.DeepEqual(ms.Status.Conditions, newStatus.Conditions) &&
 		ms.Generation == ms.Status.ObservedGeneration {
 		return ms, nil
 	}
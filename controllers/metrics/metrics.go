@@ -64,6 +64,16 @@ var (
 		[]string{"cluster", "namespace"},
 	)
 
+	// ClusterRemoteConnectionHealthy is a metric that is set to 1 if the last
+	// probe of the connection to the cluster API server succeeded and 0 if it failed.
+	ClusterRemoteConnectionHealthy = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "capi_cluster_remote_connection_healthy",
+			Help: "Cluster API server answered the last connection probe if set to 1 and not if 0.",
+		},
+		[]string{"cluster", "namespace"},
+	)
+
 	// MachineBootstrapReady is a metric that is set to 1 if machine bootstrap
 	// is ready and 0 if it is not.
 	MachineBootstrapReady = prometheus.NewGaugeVec(
@@ -93,6 +103,39 @@ var (
 		},
 		[]string{"machine", "namespace", "cluster"},
 	)
+
+	// MachineProvisioningDuration is a metric that observes the time elapsed
+	// from the creation of a machine to its NodeRef being set.
+	MachineProvisioningDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "capi_machine_provisioning_duration_seconds",
+			Help:    "Time elapsed from the creation of a Machine to its NodeRef being set.",
+			Buckets: prometheus.ExponentialBuckets(15, 2, 10),
+		},
+		[]string{"namespace", "cluster"},
+	)
+
+	// MachineDrainDuration is a metric that observes the time spent draining
+	// the node of a machine being deleted.
+	MachineDrainDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "capi_machine_drain_duration_seconds",
+			Help:    "Time spent draining the Node of a Machine being deleted.",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+		},
+		[]string{"namespace", "cluster"},
+	)
+
+	// MachineDeletionDuration is a metric that observes the time elapsed
+	// from the deletion timestamp of a machine to the removal of its finalizer.
+	MachineDeletionDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "capi_machine_deletion_duration_seconds",
+			Help:    "Time elapsed from the deletion of a Machine to the removal of its finalizer.",
+			Buckets: prometheus.ExponentialBuckets(5, 2, 10),
+		},
+		[]string{"namespace", "cluster"},
+	)
 )
 
 func init() {
@@ -101,8 +144,12 @@ func init() {
 		ClusterInfrastructureReady,
 		ClusterKubeconfigReady,
 		ClusterFailureSet,
+		ClusterRemoteConnectionHealthy,
 		MachineBootstrapReady,
 		MachineInfrastructureReady,
 		MachineNodeReady,
+		MachineProvisioningDuration,
+		MachineDrainDuration,
+		MachineDeletionDuration,
 	)
 }
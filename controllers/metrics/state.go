@@ -0,0 +1,255 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	objectLabels    = []string{"kind", "name", "namespace", "cluster"}
+	conditionLabels = append(append([]string{}, objectLabels...), "type", "status")
+
+	replicasDesiredDesc = prometheus.NewDesc(
+		"capi_replicas_desired",
+		"Number of desired replicas of a scalable Cluster API object.",
+		objectLabels, nil,
+	)
+	replicasReadyDesc = prometheus.NewDesc(
+		"capi_replicas_ready",
+		"Number of ready replicas of a scalable Cluster API object.",
+		objectLabels, nil,
+	)
+	replicasAvailableDesc = prometheus.NewDesc(
+		"capi_replicas_available",
+		"Number of available replicas of a scalable Cluster API object.",
+		objectLabels, nil,
+	)
+	replicasUpdatedDesc = prometheus.NewDesc(
+		"capi_replicas_updated",
+		"Number of up-to-date replicas of a scalable Cluster API object.",
+		objectLabels, nil,
+	)
+	conditionDesc = prometheus.NewDesc(
+		"capi_condition",
+		"Condition of a Cluster API object; the series with the current status of the condition is set to 1, the others to 0.",
+		conditionLabels, nil,
+	)
+
+	conditionStatuses = []corev1.ConditionStatus{corev1.ConditionTrue, corev1.ConditionFalse, corev1.ConditionUnknown}
+)
+
+// ObjectState is the state of a Cluster API object exported by the StateCollector.
+type ObjectState struct {
+	Kind      string
+	Name      string
+	Namespace string
+	Cluster   string
+
+	// Replicas is nil for kinds which are not scalable.
+	Replicas *ReplicaCounts
+
+	Conditions clusterv1.Conditions
+}
+
+// ReplicaCounts are the replica counts of a scalable Cluster API object;
+// counts not reported by a kind are left nil and are not exported.
+type ReplicaCounts struct {
+	Desired   *int32
+	Ready     *int32
+	Available *int32
+	Updated   *int32
+}
+
+// StateLister returns the state of all the objects of a kind.
+type StateLister func(ctx context.Context, c client.Reader) ([]ObjectState, error)
+
+// StateCollector is a prometheus.Collector exporting replica counts and conditions of Cluster API objects.
+// The state is read at scrape time, usually from the manager's cache, so series for deleted
+// objects are dropped as soon as the objects are gone.
+type StateCollector struct {
+	Client  client.Reader
+	Log     logr.Logger
+	Listers []StateLister
+}
+
+// Describe implements prometheus.Collector.
+func (c *StateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- replicasDesiredDesc
+	ch <- replicasReadyDesc
+	ch <- replicasAvailableDesc
+	ch <- replicasUpdatedDesc
+	ch <- conditionDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *StateCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx := context.Background()
+	for _, lister := range c.Listers {
+		states, err := lister(ctx, c.Client)
+		if err != nil {
+			c.Log.Error(err, "Failed to list objects for the state metrics")
+			continue
+		}
+		for i := range states {
+			collectState(ch, &states[i])
+		}
+	}
+}
+
+func collectState(ch chan<- prometheus.Metric, s *ObjectState) {
+	labels := []string{s.Kind, s.Name, s.Namespace, s.Cluster}
+
+	if r := s.Replicas; r != nil {
+		for desc, v := range map[*prometheus.Desc]*int32{
+			replicasDesiredDesc:   r.Desired,
+			replicasReadyDesc:     r.Ready,
+			replicasAvailableDesc: r.Available,
+			replicasUpdatedDesc:   r.Updated,
+		} {
+			if v == nil {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(*v), labels...)
+		}
+	}
+
+	for _, condition := range s.Conditions {
+		for _, status := range conditionStatuses {
+			v := 0.0
+			if condition.Status == status {
+				v = 1
+			}
+			ch <- prometheus.MustNewConstMetric(conditionDesc, prometheus.GaugeValue, v,
+				append(labels, string(condition.Type), string(status))...)
+		}
+	}
+}
+
+// ClusterStates is a StateLister for Clusters.
+func ClusterStates(ctx context.Context, c client.Reader) ([]ObjectState, error) {
+	list := &clusterv1.ClusterList{}
+	if err := c.List(ctx, list); err != nil {
+		return nil, err
+	}
+	states := make([]ObjectState, 0, len(list.Items))
+	for _, o := range list.Items {
+		states = append(states, ObjectState{
+			Kind:       "Cluster",
+			Name:       o.Name,
+			Namespace:  o.Namespace,
+			Cluster:    o.Name,
+			Conditions: o.Status.Conditions,
+		})
+	}
+	return states, nil
+}
+
+// MachineStates is a StateLister for Machines.
+func MachineStates(ctx context.Context, c client.Reader) ([]ObjectState, error) {
+	list := &clusterv1.MachineList{}
+	if err := c.List(ctx, list); err != nil {
+		return nil, err
+	}
+	states := make([]ObjectState, 0, len(list.Items))
+	for _, o := range list.Items {
+		states = append(states, ObjectState{
+			Kind:       "Machine",
+			Name:       o.Name,
+			Namespace:  o.Namespace,
+			Cluster:    o.Spec.ClusterName,
+			Conditions: o.Status.Conditions,
+		})
+	}
+	return states, nil
+}
+
+// MachineSetStates is a StateLister for MachineSets.
+func MachineSetStates(ctx context.Context, c client.Reader) ([]ObjectState, error) {
+	list := &clusterv1.MachineSetList{}
+	if err := c.List(ctx, list); err != nil {
+		return nil, err
+	}
+	states := make([]ObjectState, 0, len(list.Items))
+	for _, o := range list.Items {
+		o := o
+		states = append(states, ObjectState{
+			Kind:      "MachineSet",
+			Name:      o.Name,
+			Namespace: o.Namespace,
+			Cluster:   o.Spec.ClusterName,
+			Replicas: &ReplicaCounts{
+				Desired:   o.Spec.Replicas,
+				Ready:     &o.Status.ReadyReplicas,
+				Available: &o.Status.AvailableReplicas,
+			},
+			Conditions: o.Status.Conditions,
+		})
+	}
+	return states, nil
+}
+
+// MachineDeploymentStates is a StateLister for MachineDeployments.
+func MachineDeploymentStates(ctx context.Context, c client.Reader) ([]ObjectState, error) {
+	list := &clusterv1.MachineDeploymentList{}
+	if err := c.List(ctx, list); err != nil {
+		return nil, err
+	}
+	states := make([]ObjectState, 0, len(list.Items))
+	for _, o := range list.Items {
+		o := o
+		states = append(states, ObjectState{
+			Kind:      "MachineDeployment",
+			Name:      o.Name,
+			Namespace: o.Namespace,
+			Cluster:   o.Spec.ClusterName,
+			Replicas: &ReplicaCounts{
+				Desired:   o.Spec.Replicas,
+				Ready:     &o.Status.ReadyReplicas,
+				Available: &o.Status.AvailableReplicas,
+				Updated:   &o.Status.UpdatedReplicas,
+			},
+			Conditions: o.Status.Conditions,
+		})
+	}
+	return states, nil
+}
+
+// MachineHealthCheckStates is a StateLister for MachineHealthChecks.
+func MachineHealthCheckStates(ctx context.Context, c client.Reader) ([]ObjectState, error) {
+	list := &clusterv1.MachineHealthCheckList{}
+	if err := c.List(ctx, list); err != nil {
+		return nil, err
+	}
+	states := make([]ObjectState, 0, len(list.Items))
+	for _, o := range list.Items {
+		states = append(states, ObjectState{
+			Kind:       "MachineHealthCheck",
+			Name:       o.Name,
+			Namespace:  o.Namespace,
+			Cluster:    o.Spec.ClusterName,
+			Conditions: o.Status.Conditions,
+		})
+	}
+	return states, nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestStateCollector(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+	md := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "md",
+			Namespace: "default",
+		},
+		Spec: clusterv1.MachineDeploymentSpec{
+			ClusterName: "test-cluster",
+			Replicas:    pointer.Int32Ptr(3),
+		},
+		Status: clusterv1.MachineDeploymentStatus{
+			ReadyReplicas:     2,
+			AvailableReplicas: 2,
+			UpdatedReplicas:   3,
+			Conditions: clusterv1.Conditions{
+				{Type: clusterv1.ReadyCondition, Status: corev1.ConditionFalse},
+			},
+		},
+	}
+	c := fake.NewFakeClientWithScheme(scheme, md)
+
+	collector := &StateCollector{
+		Client:  c,
+		Log:     log.Log,
+		Listers: []StateLister{MachineDeploymentStates},
+	}
+
+	expected := `
+# HELP capi_condition Condition of a Cluster API object; the series with the current status of the condition is set to 1, the others to 0.
+# TYPE capi_condition gauge
+capi_condition{cluster="test-cluster",kind="MachineDeployment",name="md",namespace="default",status="False",type="Ready"} 1
+capi_condition{cluster="test-cluster",kind="MachineDeployment",name="md",namespace="default",status="True",type="Ready"} 0
+capi_condition{cluster="test-cluster",kind="MachineDeployment",name="md",namespace="default",status="Unknown",type="Ready"} 0
+# HELP capi_replicas_available Number of available replicas of a scalable Cluster API object.
+# TYPE capi_replicas_available gauge
+capi_replicas_available{cluster="test-cluster",kind="MachineDeployment",name="md",namespace="default"} 2
+# HELP capi_replicas_desired Number of desired replicas of a scalable Cluster API object.
+# TYPE capi_replicas_desired gauge
+capi_replicas_desired{cluster="test-cluster",kind="MachineDeployment",name="md",namespace="default"} 3
+# HELP capi_replicas_ready Number of ready replicas of a scalable Cluster API object.
+# TYPE capi_replicas_ready gauge
+capi_replicas_ready{cluster="test-cluster",kind="MachineDeployment",name="md",namespace="default"} 2
+# HELP capi_replicas_updated Number of up-to-date replicas of a scalable Cluster API object.
+# TYPE capi_replicas_updated gauge
+capi_replicas_updated{cluster="test-cluster",kind="MachineDeployment",name="md",namespace="default"} 3
+`
+	g.Expect(testutil.CollectAndCompare(collector, strings.NewReader(expected))).To(Succeed())
+
+	// Series are dropped as soon as the object is deleted.
+	g.Expect(c.Delete(context.Background(), md)).To(Succeed())
+	g.Expect(testutil.CollectAndCount(collector)).To(Equal(0))
+}
@@ -18,8 +18,10 @@ package remote
 
 import (
 	"context"
+	"net"
 
 	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -30,6 +32,18 @@ import (
 // ClusterClientGetter returns a new remote client.
 type ClusterClientGetter func(ctx context.Context, c client.Client, cluster client.ObjectKey, scheme *runtime.Scheme) (client.Client, error)
 
+// ClusterDialer returns the function dialing the API server of the given Cluster, or nil if the
+// API server is dialed directly.
+type ClusterDialer func(cluster client.ObjectKey) func(ctx context.Context, network, address string) (net.Conn, error)
+
+var clusterDialer ClusterDialer
+
+// SetClusterDialer sets the ClusterDialer used by RESTConfig, e.g. for reaching workload clusters through
+// a reverse tunnel. It must be called before any client for a workload cluster is created.
+func SetClusterDialer(dialer ClusterDialer) {
+	clusterDialer = dialer
+}
+
 // NewClusterClient returns a Client for interacting with a remote Cluster using the given scheme for encoding and decoding objects.
 func NewClusterClient(ctx context.Context, c client.Client, cluster client.ObjectKey, scheme *runtime.Scheme) (client.Client, error) {
 	restConfig, err := RESTConfig(ctx, c, cluster)
@@ -43,17 +57,56 @@ func NewClusterClient(ctx context.Context, c client.Client, cluster client.Objec
 	return ret, nil
 }
 
-// RESTConfig returns a configuration instance to be used with a Kubernetes client.
+// NewAdminClusterClient returns a Client for interacting with a remote Cluster as cluster admin, using the given
+// scheme for encoding and decoding objects.
+func NewAdminClusterClient(ctx context.Context, c client.Client, cluster client.ObjectKey, scheme *runtime.Scheme) (client.Client, error) {
+	restConfig, err := AdminRESTConfig(ctx, c, cluster)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create client for Cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+	return ret, nil
+}
+
+// RESTConfig returns a configuration instance to be used with a Kubernetes client. The configuration authenticates
+// as the ServiceAccount of the Cluster API controllers in the Cluster if its Kubeconfig exists, and falls back to
+// the admin Kubeconfig otherwise.
 func RESTConfig(ctx context.Context, c client.Reader, cluster client.ObjectKey) (*restclient.Config, error) {
+	kubeConfig, err := kcfg.ControllerFromSecret(ctx, c, cluster)
+	if apierrors.IsNotFound(err) {
+		return AdminRESTConfig(ctx, c, cluster)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to retrieve controller kubeconfig secret for Cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+
+	return restConfigFromKubeconfig(cluster, kubeConfig)
+}
+
+// AdminRESTConfig returns a configuration instance to be used with a Kubernetes client, authenticating with the
+// admin Kubeconfig of the Cluster. It is meant for the few operations requiring more permissions than the ones
+// of the ServiceAccount of the Cluster API controllers, e.g. creating this ServiceAccount.
+func AdminRESTConfig(ctx context.Context, c client.Reader, cluster client.ObjectKey) (*restclient.Config, error) {
 	kubeConfig, err := kcfg.FromSecret(ctx, c, cluster)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to retrieve kubeconfig secret for Cluster %s/%s", cluster.Namespace, cluster.Name)
 	}
 
+	return restConfigFromKubeconfig(cluster, kubeConfig)
+}
+
+func restConfigFromKubeconfig(cluster client.ObjectKey, kubeConfig []byte) (*restclient.Config, error) {
 	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeConfig)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to create REST configuration for Cluster %s/%s", cluster.Namespace, cluster.Name)
 	}
 
+	if clusterDialer != nil {
+		restConfig.Dial = clusterDialer(cluster)
+	}
+
 	return restConfig, nil
 }
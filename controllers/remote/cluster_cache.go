@@ -25,6 +25,7 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -34,6 +35,10 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/metrics"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/tracing"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -48,9 +53,15 @@ import (
 const (
 	defaultClientTimeout = 10 * time.Second
 
-	healthCheckPollInterval       = 10 * time.Second
-	healthCheckRequestTimeout     = 5 * time.Second
-	healthCheckUnhealthyThreshold = 10
+	// DefaultHealthCheckPollInterval is the default interval between the probes of the connection to a cluster.
+	DefaultHealthCheckPollInterval = 10 * time.Second
+
+	// DefaultHealthCheckRequestTimeout is the default timeout of a probe of the connection to a cluster.
+	DefaultHealthCheckRequestTimeout = 5 * time.Second
+
+	// DefaultHealthCheckUnhealthyThreshold is the default number of consecutive failed probes after which
+	// the connection to a cluster is considered unhealthy, and its cache is stopped.
+	DefaultHealthCheckUnhealthyThreshold = 10
 )
 
 // clusterCache embeds cache.Cache and combines it with a stop channel.
@@ -89,10 +100,14 @@ type ClusterCacheTracker struct {
 
 	watchesLock sync.RWMutex
 	watches     map[client.ObjectKey]map[watchInfo]struct{}
+
+	healthCheckInterval           time.Duration
+	healthCheckRequestTimeout     time.Duration
+	healthCheckUnhealthyThreshold int
 }
 
 // NewClusterCacheTracker creates a new ClusterCacheTracker.
-func NewClusterCacheTracker(log logr.Logger, manager ctrl.Manager) (*ClusterCacheTracker, error) {
+func NewClusterCacheTracker(log logr.Logger, manager ctrl.Manager, options ...func(*ClusterCacheTracker)) (*ClusterCacheTracker, error) {
 	m := &ClusterCacheTracker{
 		log:               log,
 		client:            manager.GetClient(),
@@ -102,9 +117,35 @@ func NewClusterCacheTracker(log logr.Logger, manager ctrl.Manager) (*ClusterCach
 		watches:           make(map[client.ObjectKey]map[watchInfo]struct{}),
 	}
 
+	for _, option := range options {
+		option(m)
+	}
+
 	return m, nil
 }
 
+// HealthCheckPollInterval sets the interval between the probes of the connection to a cluster.
+func HealthCheckPollInterval(interval time.Duration) func(*ClusterCacheTracker) {
+	return func(m *ClusterCacheTracker) {
+		m.healthCheckInterval = interval
+	}
+}
+
+// HealthCheckRequestTimeout sets the timeout of a probe of the connection to a cluster.
+func HealthCheckRequestTimeout(timeout time.Duration) func(*ClusterCacheTracker) {
+	return func(m *ClusterCacheTracker) {
+		m.healthCheckRequestTimeout = timeout
+	}
+}
+
+// HealthCheckUnhealthyThreshold sets the number of consecutive failed probes after which the connection to
+// a cluster is considered unhealthy.
+func HealthCheckUnhealthyThreshold(threshold int) func(*ClusterCacheTracker) {
+	return func(m *ClusterCacheTracker) {
+		m.healthCheckUnhealthyThreshold = threshold
+	}
+}
+
 // Watcher is a scoped-down interface from Controller that only knows how to watch.
 type Watcher interface {
 	// Watch watches src for changes, sending events to eventHandler if they pass predicates.
@@ -230,8 +271,13 @@ func (m *ClusterCacheTracker) Watch(ctx context.Context, input WatchInput) error
 }
 
 // GetClient returns a client for the given cluster.
+// Calls to the returned client are traced as child spans of the span in ctx, if any.
 func (m *ClusterCacheTracker) GetClient(ctx context.Context, cluster client.ObjectKey) (client.Client, error) {
-	return m.getOrCreateDelegatingClient(ctx, cluster)
+	c, err := m.getOrCreateDelegatingClient(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return tracing.WrapClient(c, attribute.String("cluster", cluster.String())), nil
 }
 
 // getOrCreateClusterClient returns a delegating client for the specified cluster, creating a new one if needed.
@@ -351,9 +397,12 @@ func (m *ClusterCacheTracker) newClusterCache(ctx context.Context, cluster clien
 	go remoteCache.Start(cc.stop)
 	// Start cluster healthcheck!!!
 	go m.healthCheckCluster(&healthCheckInput{
-		stop:    cc.stop,
-		cluster: cluster,
-		cfg:     config,
+		stop:               cc.stop,
+		cluster:            cluster,
+		cfg:                config,
+		interval:           m.healthCheckInterval,
+		requestTimeout:     m.healthCheckRequestTimeout,
+		unhealthyThreshold: m.healthCheckUnhealthyThreshold,
 	})
 
 	return cc, nil
@@ -380,13 +429,13 @@ type healthCheckInput struct {
 // validate sets default values if optional parameters are not set
 func (h *healthCheckInput) validate() {
 	if h.interval == 0 {
-		h.interval = healthCheckPollInterval
+		h.interval = DefaultHealthCheckPollInterval
 	}
 	if h.requestTimeout == 0 {
-		h.requestTimeout = healthCheckRequestTimeout
+		h.requestTimeout = DefaultHealthCheckRequestTimeout
 	}
 	if h.unhealthyThreshold == 0 {
-		h.unhealthyThreshold = healthCheckUnhealthyThreshold
+		h.unhealthyThreshold = DefaultHealthCheckUnhealthyThreshold
 	}
 	if h.path == "" {
 		h.path = "/"
@@ -396,11 +445,14 @@ func (h *healthCheckInput) validate() {
 // healthCheckCluster will poll the cluster's API at the path given and, if there are
 // `unhealthyThreshold` consecutive failures, will deem the cluster unhealthy.
 // Once the cluster is deemed unhealthy, the cluster's cache is stopped and removed.
+// The result of each probe is reported on the RemoteConnectionProbe condition of the Cluster
+// and on the ClusterRemoteConnectionHealthy metric.
 func (m *ClusterCacheTracker) healthCheckCluster(in *healthCheckInput) {
 	// populate optional params for healthCheckInput
 	in.validate()
 
 	unhealthyCount := 0
+	var lastSuccess time.Time
 
 	runHealthCheckWithThreshold := func() (bool, error) {
 		cluster := &clusterv1.Cluster{}
@@ -430,8 +482,15 @@ func (m *ClusterCacheTracker) healthCheckCluster(in *healthCheckInput) {
 		err := healthCheckPath(in.cfg, in.requestTimeout, in.path)
 		if err != nil {
 			unhealthyCount++
+			metrics.ClusterRemoteConnectionHealthy.WithLabelValues(in.cluster.Name, in.cluster.Namespace).Set(0)
 		} else {
 			unhealthyCount = 0
+			lastSuccess = time.Now()
+			metrics.ClusterRemoteConnectionHealthy.WithLabelValues(in.cluster.Name, in.cluster.Namespace).Set(1)
+		}
+
+		if condErr := m.setRemoteConnectionProbeCondition(context.TODO(), cluster, err, lastSuccess, unhealthyCount >= in.unhealthyThreshold); condErr != nil {
+			m.log.Error(condErr, "Failed to report remote connection probe", "namespace", in.cluster.Namespace, "cluster", in.cluster.Name)
 		}
 
 		if unhealthyCount >= in.unhealthyThreshold {
@@ -448,6 +507,12 @@ func (m *ClusterCacheTracker) healthCheckCluster(in *healthCheckInput) {
 	// An error returned implies the health check has failed a sufficient number of
 	// times for the cluster to be considered unhealthy
 	if err != nil {
+		if apierrors.IsNotFound(err) {
+			metrics.ClusterRemoteConnectionHealthy.DeleteLabelValues(in.cluster.Name, in.cluster.Namespace)
+		} else {
+			m.log.Error(err, "Remote connection is unhealthy, stopping the cluster cache", "namespace", in.cluster.Namespace, "cluster", in.cluster.Name)
+		}
+
 		c := m.getClusterCache(in.cluster)
 		if c == nil {
 			return
@@ -461,6 +526,40 @@ func (m *ClusterCacheTracker) healthCheckCluster(in *healthCheckInput) {
 	}
 }
 
+// setRemoteConnectionProbeCondition reports the result of a probe of the connection to the cluster on its
+// RemoteConnectionProbe condition, including the last error and the time of the last successful probe.
+// The Cluster is patched only if the condition changed, so successive identical results don't generate writes.
+func (m *ClusterCacheTracker) setRemoteConnectionProbeCondition(ctx context.Context, cluster *clusterv1.Cluster, probeErr error, lastSuccess time.Time, unhealthy bool) error {
+	patchHelper, err := patch.NewHelper(cluster, m.client)
+	if err != nil {
+		return err
+	}
+
+	var condition *clusterv1.Condition
+	if probeErr == nil {
+		condition = conditions.TrueCondition(clusterv1.RemoteConnectionProbeCondition)
+	} else {
+		severity := clusterv1.ConditionSeverityWarning
+		if unhealthy {
+			severity = clusterv1.ConditionSeverityError
+		}
+		lastSuccessMessage := "never succeeded"
+		if !lastSuccess.IsZero() {
+			lastSuccessMessage = fmt.Sprintf("last succeeded at %s", lastSuccess.UTC().Format(time.RFC3339))
+		}
+		condition = conditions.FalseCondition(clusterv1.RemoteConnectionProbeCondition, clusterv1.RemoteConnectionFailedReason, severity,
+			"Remote connection probe %s: %v", lastSuccessMessage, probeErr)
+	}
+
+	if current := conditions.Get(cluster, clusterv1.RemoteConnectionProbeCondition); current != nil &&
+		current.Status == condition.Status && current.Severity == condition.Severity && current.Message == condition.Message {
+		return nil
+	}
+
+	conditions.Set(cluster, condition)
+	return patchHelper.Patch(ctx, cluster)
+}
+
 // healthCheckPath attempts to request a given absolute path from the API server
 // defined in the rest.Config and returns any errors that occurred during the request.
 func healthCheckPath(sourceCfg *rest.Config, requestTimeout time.Duration, path string) error {
@@ -523,6 +622,8 @@ func (r *ClusterCacheReconciler) Reconcile(req reconcile.Request) (reconcile.Res
 
 	log.V(4).Info("Cluster no longer exists")
 
+	metrics.ClusterRemoteConnectionHealthy.DeleteLabelValues(req.Name, req.Namespace)
+
 	c := r.Tracker.getClusterCache(req.NamespacedName)
 	if c == nil {
 		log.V(4).Info("No current cluster cache exists - nothing to do")
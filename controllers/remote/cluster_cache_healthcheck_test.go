@@ -20,18 +20,23 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"testing"
 	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/kubeconfig"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
@@ -176,3 +181,51 @@ var _ = Describe("ClusterCache HealthCheck suite", func() {
 		})
 	})
 })
+
+func TestSetRemoteConnectionProbeCondition(t *testing.T) {
+	g := NewWithT(t)
+
+	testScheme := runtime.NewScheme()
+	g.Expect(clusterv1.AddToScheme(testScheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "test-cluster",
+		},
+	}
+	c := fake.NewFakeClientWithScheme(testScheme, cluster)
+	m := &ClusterCacheTracker{client: c}
+	ctx := context.Background()
+
+	// The health check gets the Cluster before each probe.
+	getCluster := func() *clusterv1.Cluster {
+		updated := &clusterv1.Cluster{}
+		g.Expect(c.Get(ctx, util.ObjectKey(cluster), updated)).To(Succeed())
+		return updated
+	}
+	getCondition := func() *clusterv1.Condition {
+		return conditions.Get(getCluster(), clusterv1.RemoteConnectionProbeCondition)
+	}
+
+	// A failed probe reports the error, without a previous success.
+	g.Expect(m.setRemoteConnectionProbeCondition(ctx, getCluster(), errors.New("connection refused"), time.Time{}, false)).To(Succeed())
+	condition := getCondition()
+	g.Expect(condition).NotTo(BeNil())
+	g.Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(condition.Reason).To(Equal(clusterv1.RemoteConnectionFailedReason))
+	g.Expect(condition.Severity).To(Equal(clusterv1.ConditionSeverityWarning))
+	g.Expect(condition.Message).To(Equal("Remote connection probe never succeeded: connection refused"))
+
+	// A successful probe marks the condition True.
+	g.Expect(m.setRemoteConnectionProbeCondition(ctx, getCluster(), nil, time.Now(), false)).To(Succeed())
+	g.Expect(getCondition().Status).To(Equal(corev1.ConditionTrue))
+
+	// Failures reaching the unhealthy threshold report the time of the last success with an Error severity.
+	lastSuccess := time.Date(2020, time.June, 1, 10, 0, 0, 0, time.UTC)
+	g.Expect(m.setRemoteConnectionProbeCondition(ctx, getCluster(), errors.New("i/o timeout"), lastSuccess, true)).To(Succeed())
+	condition = getCondition()
+	g.Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+	g.Expect(condition.Severity).To(Equal(clusterv1.ConditionSeverityError))
+	g.Expect(condition.Message).To(Equal("Remote connection probe last succeeded at 2020-06-01T10:00:00Z: i/o timeout"))
+}
@@ -18,9 +18,12 @@ package remote
 
 import (
 	"context"
+	"net"
+	"strings"
 	"testing"
 
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -76,6 +79,16 @@ users:
 		},
 	}
 
+	validControllerSecret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test1-controller-kubeconfig",
+			Namespace: "test",
+		},
+		Data: map[string][]byte{
+			secret.KubeconfigDataName: []byte(strings.Replace(validKubeConfig, "test-cluster-api.nodomain", "controller.nodomain", 1)),
+		},
+	}
+
 	invalidSecret = &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "test2-kubeconfig",
@@ -107,6 +120,37 @@ func TestNewClusterClient(t *testing.T) {
 		gs.Expect(restConfig.Host).To(Equal("https://test-cluster-api.nodomain.example.com:6443"))
 	})
 
+	t.Run("cluster with a controller kubeconfig", func(t *testing.T) {
+		gs := NewWithT(t)
+
+		client := fake.NewFakeClientWithScheme(testScheme, validSecret, validControllerSecret)
+		restConfig, err := RESTConfig(ctx, client, clusterWithValidKubeConfig)
+		gs.Expect(err).NotTo(HaveOccurred())
+		gs.Expect(restConfig.Host).To(Equal("https://controller.nodomain.example.com:6443"))
+
+		restConfig, err = AdminRESTConfig(ctx, client, clusterWithValidKubeConfig)
+		gs.Expect(err).NotTo(HaveOccurred())
+		gs.Expect(restConfig.Host).To(Equal("https://test-cluster-api.nodomain.example.com:6443"))
+	})
+
+	t.Run("cluster with a dialer", func(t *testing.T) {
+		gs := NewWithT(t)
+
+		var dialed client.ObjectKey
+		SetClusterDialer(func(cluster client.ObjectKey) func(ctx context.Context, network, address string) (net.Conn, error) {
+			return func(_ context.Context, _, _ string) (net.Conn, error) {
+				dialed = cluster
+				return nil, errors.New("tunnel not connected")
+			}
+		})
+		defer SetClusterDialer(nil)
+
+		client := fake.NewFakeClientWithScheme(testScheme, validSecret)
+		_, err := NewClusterClient(ctx, client, clusterWithValidKubeConfig, testScheme)
+		gs.Expect(err).To(MatchError(ContainSubstring("tunnel not connected")))
+		gs.Expect(dialed).To(Equal(clusterWithValidKubeConfig))
+	})
+
 	t.Run("cluster with no kubeconfig", func(t *testing.T) {
 		gs := NewWithT(t)
 
@@ -66,3 +66,69 @@ const (
 	// ScalingDownReason (Severity=Info) documents a KubeadmControlPlane that is decreasing the number of replicas.
 	ScalingDownReason = "ScalingDown"
 )
+
+const (
+	// EtcdClusterHealthyCondition documents the overall etcd cluster's health, both for a managed etcd running
+	// on the control plane nodes and for an external etcd cluster.
+	EtcdClusterHealthyCondition clusterv1.ConditionType = "EtcdClusterHealthy"
+
+	// EtcdClusterUnhealthyReason (Severity=Warning) documents a KubeadmControlPlane object detecting that the
+	// etcd cluster is not passing the health checks.
+	EtcdClusterUnhealthyReason = "EtcdClusterUnhealthy"
+)
+
+const (
+	// CertificateAuthoritiesTrustedCondition documents that the new certificate authorities of a rotation were added
+	// to the trust bundles of the cluster, i.e. to the certificate authority Secrets and to the cluster-info ConfigMap.
+	CertificateAuthoritiesTrustedCondition clusterv1.ConditionType = "CertificateAuthoritiesTrusted"
+
+	// CertificateAuthoritiesControlPlaneRolledOutCondition documents that the control plane machines were rolled out
+	// with the certificate authorities of the current phase of a rotation.
+	CertificateAuthoritiesControlPlaneRolledOutCondition clusterv1.ConditionType = "CertificateAuthoritiesControlPlaneRolledOut"
+
+	// CertificateAuthoritiesWorkersRolledOutCondition documents that the MachineDeployments, MachinePools and the other
+	// worker Machines of the cluster were rolled out with the certificate authorities of the current phase of a rotation.
+	CertificateAuthoritiesWorkersRolledOutCondition clusterv1.ConditionType = "CertificateAuthoritiesWorkersRolledOut"
+
+	// CertificateAuthoritiesKubeconfigReissuedCondition documents that the kubeconfig Secrets of the cluster were
+	// reissued with credentials signed by the new certificate authorities of a rotation.
+	CertificateAuthoritiesKubeconfigReissuedCondition clusterv1.ConditionType = "CertificateAuthoritiesKubeconfigReissued"
+
+	// CertificateAuthoritiesOldRemovedCondition documents that the previous certificate authorities were removed from
+	// the trust bundles of the cluster, completing a rotation.
+	CertificateAuthoritiesOldRemovedCondition clusterv1.ConditionType = "CertificateAuthoritiesOldRemoved"
+
+	// CertificateAuthoritiesRotationInProgressReason (Severity=Info) documents a KubeadmControlPlane object waiting
+	// for a phase of the rotation of the certificate authorities to be completed.
+	CertificateAuthoritiesRotationInProgressReason = "CertificateAuthoritiesRotationInProgress"
+
+	// CertificateAuthoritiesWaitingForRemovalConfirmationReason (Severity=Info) documents a KubeadmControlPlane object
+	// waiting for the removal of the previous certificate authorities to be confirmed with the
+	// RemoveOldCertificateAuthoritiesAnnotation.
+	CertificateAuthoritiesWaitingForRemovalConfirmationReason = "CertificateAuthoritiesWaitingForRemovalConfirmation"
+
+	// CertificateAuthoritiesRotationFailedReason (Severity=Warning) documents a KubeadmControlPlane controller
+	// detecting an error while rotating the certificate authorities; those kind of errors are usually temporary
+	// and the controller automatically recover from them.
+	CertificateAuthoritiesRotationFailedReason = "CertificateAuthoritiesRotationFailed"
+)
+
+// Conditions and condition Reasons for the EtcdBackup object
+
+const (
+	// EtcdSnapshotSucceededCondition documents that the last etcd snapshot scheduled by an EtcdBackup was
+	// successfully taken and stored in the destination.
+	EtcdSnapshotSucceededCondition clusterv1.ConditionType = "EtcdSnapshotSucceeded"
+
+	// EtcdSnapshotFailedReason (Severity=Error) documents an EtcdBackup controller failing to take a snapshot or
+	// to store it in the destination; the controller retries at the next scheduled time.
+	EtcdSnapshotFailedReason = "EtcdSnapshotFailed"
+
+	// EtcdBackupNotSupportedReason (Severity=Error) documents an EtcdBackup targeting a Cluster whose etcd cannot
+	// be backed up, e.g. because it is not managed by a KubeadmControlPlane or it is using an external etcd.
+	EtcdBackupNotSupportedReason = "EtcdBackupNotSupported"
+
+	// WaitingForControlPlaneInitializedReason (Severity=Info) documents an EtcdBackup waiting for the control plane
+	// of the target Cluster to be initialized before taking snapshots.
+	WaitingForControlPlaneInitializedReason = "WaitingForControlPlaneInitialized"
+)
@@ -0,0 +1,188 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+)
+
+const (
+	// DefaultEtcdBackupRetention is the number of snapshots kept when EtcdBackupSpec.Retention is not set.
+	DefaultEtcdBackupRetention = 7
+
+	// EtcdBackupS3AccessKeyIDKey is the key of the access key ID in the S3 credentials secret.
+	EtcdBackupS3AccessKeyIDKey = "accessKeyID"
+
+	// EtcdBackupS3SecretAccessKeyKey is the key of the secret access key in the S3 credentials secret.
+	EtcdBackupS3SecretAccessKeyKey = "secretAccessKey"
+)
+
+// EtcdBackupSpec defines the desired state of EtcdBackup.
+type EtcdBackupSpec struct {
+	// ClusterName is the name of the Cluster whose etcd is backed up; the Cluster control plane must be
+	// a KubeadmControlPlane running a stacked etcd.
+	// +kubebuilder:validation:MinLength=1
+	ClusterName string `json:"clusterName"`
+
+	// Schedule is the schedule for taking snapshots, in cron format, e.g. "0 */6 * * *".
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// Retention is the number of snapshots to keep; when a new snapshot is taken, the oldest snapshots
+	// exceeding this number are deleted from the destination. Defaults to 7.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Retention *int32 `json:"retention,omitempty"`
+
+	// Suspend tells the controller to suspend taking snapshots; it does not apply to snapshots already taken.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// Destination defines where snapshots are stored.
+	Destination EtcdBackupDestination `json:"destination"`
+}
+
+// EtcdBackupDestination defines where snapshots are stored; exactly one destination must be set.
+type EtcdBackupDestination struct {
+	// PersistentVolumeClaim stores snapshots on a PersistentVolumeClaim mounted into the
+	// KubeadmControlPlane controller manager.
+	// +optional
+	PersistentVolumeClaim *EtcdBackupPersistentVolumeClaim `json:"persistentVolumeClaim,omitempty"`
+
+	// S3 stores snapshots in an S3-compatible object store.
+	// +optional
+	S3 *EtcdBackupS3 `json:"s3,omitempty"`
+}
+
+// EtcdBackupPersistentVolumeClaim defines a PersistentVolumeClaim destination for snapshots.
+type EtcdBackupPersistentVolumeClaim struct {
+	// ClaimName is the name of the PersistentVolumeClaim, in the namespace of the EtcdBackup; the claim must
+	// be mounted into the KubeadmControlPlane controller manager under the etcd backups directory, in a sub
+	// directory named as the namespace and the claim (by default /var/lib/etcd-backups/<namespace>/<claimName>).
+	// +kubebuilder:validation:MinLength=1
+	ClaimName string `json:"claimName"`
+
+	// Path is the directory, relative to the root of the volume, where snapshots are stored.
+	// Defaults to the root of the volume.
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// EtcdBackupS3 defines an S3-compatible object store destination for snapshots.
+type EtcdBackupS3 struct {
+	// Endpoint is the URL of the object store, e.g. https://s3.us-east-1.amazonaws.com or https://minio:9000.
+	// +kubebuilder:validation:MinLength=1
+	Endpoint string `json:"endpoint"`
+
+	// Bucket is the name of the bucket where snapshots are stored.
+	// +kubebuilder:validation:MinLength=1
+	Bucket string `json:"bucket"`
+
+	// Prefix is prepended to the name of the snapshots, e.g. a folder in the bucket.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Region is the region of the bucket. Defaults to us-east-1.
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// CredentialsSecret is a reference to a secret in the EtcdBackup namespace, containing the accessKeyID
+	// and secretAccessKey keys.
+	CredentialsSecret corev1.LocalObjectReference `json:"credentialsSecret"`
+
+	// InsecureSkipTLSVerify disables the verification of the object store's serving certificate.
+	// +optional
+	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty"`
+}
+
+// EtcdSnapshot defines a snapshot stored in the destination.
+type EtcdSnapshot struct {
+	// Name is the name of the snapshot.
+	Name string `json:"name"`
+
+	// Location is where the snapshot is stored, e.g. a path on the volume or an s3:// URL.
+	Location string `json:"location"`
+
+	// Size is the size of the snapshot in bytes.
+	Size int64 `json:"size"`
+
+	// Time is the time the snapshot was taken at.
+	Time metav1.Time `json:"time"`
+}
+
+// EtcdBackupStatus defines the observed state of EtcdBackup.
+type EtcdBackupStatus struct {
+	// LastScheduleTime is the last time a snapshot was scheduled.
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// LastSuccessfulTime is the last time a snapshot was successfully taken.
+	// +optional
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+
+	// LastSnapshotSize is the size in bytes of the last snapshot successfully taken.
+	// +optional
+	LastSnapshotSize int64 `json:"lastSnapshotSize,omitempty"`
+
+	// Snapshots is the list of snapshots currently kept in the destination, from the oldest to the newest.
+	// +optional
+	Snapshots []EtcdSnapshot `json:"snapshots,omitempty"`
+
+	// Conditions defines current service state of the EtcdBackup.
+	// +optional
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=etcdbackups,scope=Namespaced,categories=cluster-api
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".spec.clusterName",description="Cluster whose etcd is backed up"
+// +kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.schedule",description="Schedule for taking snapshots"
+// +kubebuilder:printcolumn:name="Last Successful",type="date",JSONPath=".status.lastSuccessfulTime",description="Last time a snapshot was successfully taken"
+// +kubebuilder:printcolumn:name="Size",type="integer",JSONPath=".status.lastSnapshotSize",description="Size in bytes of the last snapshot"
+
+// EtcdBackup is the Schema for the etcdbackups API.
+type EtcdBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   EtcdBackupSpec   `json:"spec,omitempty"`
+	Status EtcdBackupStatus `json:"status,omitempty"`
+}
+
+func (in *EtcdBackup) GetConditions() clusterv1.Conditions {
+	return in.Status.Conditions
+}
+
+func (in *EtcdBackup) SetConditions(conditions clusterv1.Conditions) {
+	in.Status.Conditions = conditions
+}
+
+// +kubebuilder:object:root=true
+
+// EtcdBackupList contains a list of EtcdBackup.
+type EtcdBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EtcdBackup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&EtcdBackup{}, &EtcdBackupList{})
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/cron"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+func (in *EtcdBackup) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(in).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-controlplane-cluster-x-k8s-io-v1alpha3-etcdbackup,mutating=true,failurePolicy=fail,matchPolicy=Equivalent,groups=controlplane.cluster.x-k8s.io,resources=etcdbackups,versions=v1alpha3,name=default.etcdbackup.controlplane.cluster.x-k8s.io,sideEffects=None
+// +kubebuilder:webhook:verbs=create;update,path=/validate-controlplane-cluster-x-k8s-io-v1alpha3-etcdbackup,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=controlplane.cluster.x-k8s.io,resources=etcdbackups,versions=v1alpha3,name=validation.etcdbackup.controlplane.cluster.x-k8s.io,sideEffects=None
+
+var _ webhook.Defaulter = &EtcdBackup{}
+var _ webhook.Validator = &EtcdBackup{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (in *EtcdBackup) Default() {
+	if in.Spec.Retention == nil {
+		retention := int32(DefaultEtcdBackupRetention)
+		in.Spec.Retention = &retention
+	}
+}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (in *EtcdBackup) ValidateCreate() error {
+	allErrs := in.validateCommon()
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(GroupVersion.WithKind("EtcdBackup").GroupKind(), in.Name, allErrs)
+	}
+	return nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (in *EtcdBackup) ValidateUpdate(old runtime.Object) error {
+	allErrs := in.validateCommon()
+
+	prev := old.(*EtcdBackup)
+	if in.Spec.ClusterName != prev.Spec.ClusterName {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "clusterName"), "field is immutable"))
+	}
+
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(GroupVersion.WithKind("EtcdBackup").GroupKind(), in.Name, allErrs)
+	}
+	return nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (in *EtcdBackup) ValidateDelete() error {
+	return nil
+}
+
+func (in *EtcdBackup) validateCommon() (allErrs field.ErrorList) {
+	specPath := field.NewPath("spec")
+
+	if in.Spec.ClusterName == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("clusterName"), "cluster name is required"))
+	}
+
+	if _, err := cron.Parse(in.Spec.Schedule); err != nil {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("schedule"), in.Spec.Schedule, err.Error()))
+	}
+
+	if in.Spec.Retention != nil && *in.Spec.Retention < 1 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("retention"), *in.Spec.Retention, "must be greater than or equal to 1"))
+	}
+
+	destination := in.Spec.Destination
+	destinationPath := specPath.Child("destination")
+	switch {
+	case destination.PersistentVolumeClaim == nil && destination.S3 == nil:
+		allErrs = append(allErrs, field.Required(destinationPath, "one of persistentVolumeClaim or s3 must be set"))
+	case destination.PersistentVolumeClaim != nil && destination.S3 != nil:
+		allErrs = append(allErrs, field.Forbidden(destinationPath, "only one of persistentVolumeClaim or s3 can be set"))
+	case destination.PersistentVolumeClaim != nil:
+		pvcPath := destinationPath.Child("persistentVolumeClaim")
+		if destination.PersistentVolumeClaim.ClaimName == "" {
+			allErrs = append(allErrs, field.Required(pvcPath.Child("claimName"), "claim name is required"))
+		} else {
+			for _, msg := range validation.IsDNS1123Subdomain(destination.PersistentVolumeClaim.ClaimName) {
+				allErrs = append(allErrs, field.Invalid(pvcPath.Child("claimName"), destination.PersistentVolumeClaim.ClaimName, msg))
+			}
+		}
+		if p := destination.PersistentVolumeClaim.Path; path.IsAbs(p) || strings.Contains(p, "..") {
+			allErrs = append(allErrs, field.Invalid(pvcPath.Child("path"), p, "must be a relative path not containing '..'"))
+		}
+	case destination.S3 != nil:
+		s3Path := destinationPath.Child("s3")
+		if u, err := url.Parse(destination.S3.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(s3Path.Child("endpoint"), destination.S3.Endpoint, "must be a valid http or https URL"))
+		}
+		if destination.S3.Bucket == "" {
+			allErrs = append(allErrs, field.Required(s3Path.Child("bucket"), "bucket is required"))
+		}
+		if destination.S3.CredentialsSecret.Name == "" {
+			allErrs = append(allErrs, field.Required(s3Path.Child("credentialsSecret", "name"), "credentials secret name is required"))
+		}
+	}
+
+	return allErrs
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestEtcdBackupDefault(t *testing.T) {
+	g := NewWithT(t)
+
+	b := &EtcdBackup{}
+	b.Default()
+	g.Expect(b.Spec.Retention).To(Equal(pointer.Int32Ptr(DefaultEtcdBackupRetention)))
+}
+
+func TestEtcdBackupValidateCreate(t *testing.T) {
+	valid := &EtcdBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "foo",
+		},
+		Spec: EtcdBackupSpec{
+			ClusterName: "cluster1",
+			Schedule:    "0 */6 * * *",
+			Retention:   pointer.Int32Ptr(3),
+			Destination: EtcdBackupDestination{
+				PersistentVolumeClaim: &EtcdBackupPersistentVolumeClaim{ClaimName: "backups"},
+			},
+		},
+	}
+
+	validS3 := valid.DeepCopy()
+	validS3.Spec.Destination = EtcdBackupDestination{
+		S3: &EtcdBackupS3{
+			Endpoint:          "https://minio:9000",
+			Bucket:            "backups",
+			CredentialsSecret: corev1.LocalObjectReference{Name: "minio-credentials"},
+		},
+	}
+
+	invalidSchedule := valid.DeepCopy()
+	invalidSchedule.Spec.Schedule = "every day"
+
+	invalidRetention := valid.DeepCopy()
+	invalidRetention.Spec.Retention = pointer.Int32Ptr(0)
+
+	noDestination := valid.DeepCopy()
+	noDestination.Spec.Destination = EtcdBackupDestination{}
+
+	twoDestinations := validS3.DeepCopy()
+	twoDestinations.Spec.Destination.PersistentVolumeClaim = valid.Spec.Destination.PersistentVolumeClaim
+
+	invalidClaimName := valid.DeepCopy()
+	invalidClaimName.Spec.Destination.PersistentVolumeClaim.ClaimName = "Backups_1"
+
+	absolutePath := valid.DeepCopy()
+	absolutePath.Spec.Destination.PersistentVolumeClaim.Path = "/etc"
+
+	escapingPath := valid.DeepCopy()
+	escapingPath.Spec.Destination.PersistentVolumeClaim.Path = "foo/../../bar"
+
+	invalidS3Endpoint := validS3.DeepCopy()
+	invalidS3Endpoint.Spec.Destination.S3.Endpoint = "minio:9000"
+
+	missingS3Credentials := validS3.DeepCopy()
+	missingS3Credentials.Spec.Destination.S3.CredentialsSecret.Name = ""
+
+	tests := []struct {
+		name      string
+		expectErr bool
+		backup    *EtcdBackup
+	}{
+		{
+			name:      "should succeed when given a valid config with a PersistentVolumeClaim destination",
+			expectErr: false,
+			backup:    valid,
+		},
+		{
+			name:      "should succeed when given a valid config with an S3 destination",
+			expectErr: false,
+			backup:    validS3,
+		},
+		{
+			name:      "should return error when the schedule is invalid",
+			expectErr: true,
+			backup:    invalidSchedule,
+		},
+		{
+			name:      "should return error when the retention is less than 1",
+			expectErr: true,
+			backup:    invalidRetention,
+		},
+		{
+			name:      "should return error when no destination is set",
+			expectErr: true,
+			backup:    noDestination,
+		},
+		{
+			name:      "should return error when more than one destination is set",
+			expectErr: true,
+			backup:    twoDestinations,
+		},
+		{
+			name:      "should return error when the claim name is invalid",
+			expectErr: true,
+			backup:    invalidClaimName,
+		},
+		{
+			name:      "should return error when the path on the volume is absolute",
+			expectErr: true,
+			backup:    absolutePath,
+		},
+		{
+			name:      "should return error when the path on the volume contains '..'",
+			expectErr: true,
+			backup:    escapingPath,
+		},
+		{
+			name:      "should return error when the S3 endpoint is not a valid URL",
+			expectErr: true,
+			backup:    invalidS3Endpoint,
+		},
+		{
+			name:      "should return error when the S3 credentials secret is not set",
+			expectErr: true,
+			backup:    missingS3Credentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			if tt.expectErr {
+				g.Expect(tt.backup.ValidateCreate()).NotTo(Succeed())
+			} else {
+				g.Expect(tt.backup.ValidateCreate()).To(Succeed())
+			}
+		})
+	}
+}
+
+func TestEtcdBackupValidateUpdate(t *testing.T) {
+	g := NewWithT(t)
+
+	before := &EtcdBackup{
+		Spec: EtcdBackupSpec{
+			ClusterName: "cluster1",
+			Schedule:    "@daily",
+			Destination: EtcdBackupDestination{
+				PersistentVolumeClaim: &EtcdBackupPersistentVolumeClaim{ClaimName: "backups"},
+			},
+		},
+	}
+
+	scheduleChanged := before.DeepCopy()
+	scheduleChanged.Spec.Schedule = "@hourly"
+	g.Expect(scheduleChanged.ValidateUpdate(before)).To(Succeed())
+
+	clusterChanged := before.DeepCopy()
+	clusterChanged.Spec.ClusterName = "cluster2"
+	g.Expect(clusterChanged.ValidateUpdate(before)).NotTo(Succeed())
+}
@@ -31,9 +31,36 @@ const (
 
 	// SkipCoreDNSAnnotation annotation explicitly skips reconciling CoreDNS if set
 	SkipCoreDNSAnnotation = "controlplane.cluster.x-k8s.io/skip-coredns"
+
+	// MachineCertificatesExpiryDateAnnotation annotation specifies the expiry date of the certificates of a control plane
+	// machine, in RFC3339 format; it is the earliest expiry date among the serving certificates of the API server and of etcd.
+	MachineCertificatesExpiryDateAnnotation = "controlplane.cluster.x-k8s.io/certificates-expiry"
+
+	// RotateCertificateAuthoritiesAnnotation annotation requests the rotation of the certificate authorities of the
+	// cluster, i.e. the cluster CA, the etcd CA, the front proxy CA and the service account key; it is removed
+	// once the rotation is started, and its progress is reported by the CertificateAuthorities* conditions.
+	RotateCertificateAuthoritiesAnnotation = "controlplane.cluster.x-k8s.io/rotate-certificate-authorities"
+
+	// RemoveOldCertificateAuthoritiesAnnotation annotation confirms the removal of the previous certificate
+	// authorities at the end of a rotation, once the credentials not reissued by Cluster API, e.g. the legacy
+	// ServiceAccount token Secrets of the workload cluster, were recreated; it is removed once the removal is started.
+	RemoveOldCertificateAuthoritiesAnnotation = "controlplane.cluster.x-k8s.io/remove-old-certificate-authorities"
+
+	// CertificateAuthoritiesHashAnnotation annotation specifies the hash of the certificate authorities a machine
+	// was bootstrapped with during a rotation of the certificate authorities; it is set on the control plane machines
+	// and on the machine template of the MachineDeployments of the cluster, for rolling them out, and on the kubeconfig
+	// Secrets of the KubeconfigRequests of the cluster, for reissuing them.
+	CertificateAuthoritiesHashAnnotation = "controlplane.cluster.x-k8s.io/certificate-authorities-hash"
+
+	// CertificateAuthoritiesOutdatedInstancesAnnotation annotation specifies the comma separated provider IDs of the
+	// instances of a MachinePool bootstrapped with the certificate authorities preceding the current phase of a
+	// rotation; the MachinePool is rolled out once all of them are replaced.
+	CertificateAuthoritiesOutdatedInstancesAnnotation = "controlplane.cluster.x-k8s.io/certificate-authorities-outdated-instances"
 )
 
 // KubeadmControlPlaneSpec defines the desired state of KubeadmControlPlane.
+// Changes to Version, InfrastructureTemplate and KubeadmConfigSpec trigger a rollout of the control plane
+// machines, while all the other fields are applied in place.
 type KubeadmControlPlaneSpec struct {
 	// Number of desired machines. Defaults to 1. When stacked etcd is used only
 	// odd numbers are permitted, as per [etcd best practice](https://etcd.io/docs/v3.3.12/faq/#why-an-odd-number-of-cluster-members).
@@ -48,6 +75,12 @@ type KubeadmControlPlaneSpec struct {
 	// offered by an infrastructure provider.
 	InfrastructureTemplate corev1.ObjectReference `json:"infrastructureTemplate"`
 
+	// MachineTemplate contains information about how control plane machines
+	// should be shaped; changes to it are applied in place to the existing
+	// machines without triggering a rollout.
+	// +optional
+	MachineTemplate *KubeadmControlPlaneMachineTemplate `json:"machineTemplate,omitempty"`
+
 	// KubeadmConfigSpec is a KubeadmConfigSpec
 	// to use for initializing and joining machines to the control plane.
 	KubeadmConfigSpec cabpkv1.KubeadmConfigSpec `json:"kubeadmConfigSpec"`
@@ -57,6 +90,31 @@ type KubeadmControlPlaneSpec struct {
 	// KubeadmControlPlane
 	// +optional
 	UpgradeAfter *metav1.Time `json:"upgradeAfter,omitempty"`
+
+	// RolloutBefore is a field to indicate a rollout should be performed
+	// if the specified criteria is met.
+	// +optional
+	RolloutBefore *RolloutBefore `json:"rolloutBefore,omitempty"`
+}
+
+// KubeadmControlPlaneMachineTemplate defines the template for the control plane machines.
+type KubeadmControlPlaneMachineTemplate struct {
+	// Standard object's metadata; labels and annotations are propagated to the
+	// control plane machines and to their infrastructure and bootstrap objects.
+	// NOTE: labels and annotations removed from the template are not removed
+	// from existing machines.
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#metadata
+	// +optional
+	ObjectMeta clusterv1.ObjectMeta `json:"metadata,omitempty"`
+}
+
+// RolloutBefore describes when a rollout should be performed on the KCP machines.
+type RolloutBefore struct {
+	// CertificatesExpiryDays indicates a rollout needs to be performed if the
+	// certificates of the control plane will expire within the specified days.
+	// +kubebuilder:validation:Minimum=7
+	// +optional
+	CertificatesExpiryDays *int32 `json:"certificatesExpiryDays,omitempty"`
 }
 
 // KubeadmControlPlaneStatus defines the observed state of KubeadmControlPlane.
@@ -91,6 +149,11 @@ type KubeadmControlPlaneStatus struct {
 	// +optional
 	UnavailableReplicas int32 `json:"unavailableReplicas,omitempty"`
 
+	// Version represents the minimum Kubernetes version for the control plane machines
+	// in the cluster, i.e. the version the control plane has actually been rolled out to.
+	// +optional
+	Version *string `json:"version,omitempty"`
+
 	// Initialized denotes whether or not the control plane has the
 	// uploaded kubeadm-config configmap.
 	// +optional
@@ -23,10 +23,13 @@ import (
 	"strings"
 
 	"github.com/coredns/corefile-migration/migration"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
 
 	jsonpatch "github.com/evanphx/json-patch"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/cluster-api/util"
@@ -100,9 +103,13 @@ func (in *KubeadmControlPlane) ValidateUpdate(old runtime.Object) error {
 		{spec, kubeadmConfigSpec, postKubeadmCommands},
 		{spec, kubeadmConfigSpec, files},
 		{spec, "infrastructureTemplate", "name"},
+		{spec, "machineTemplate"},
+		{spec, "machineTemplate", "*"},
 		{spec, "replicas"},
 		{spec, "version"},
 		{spec, "upgradeAfter"},
+		{spec, "rolloutBefore"},
+		{spec, "rolloutBefore", "*"},
 	}
 
 	allErrs := in.validateCommon()
@@ -145,6 +152,7 @@ func (in *KubeadmControlPlane) ValidateUpdate(old runtime.Object) error {
 
 	allErrs = append(allErrs, in.validateEtcd(prev)...)
 	allErrs = append(allErrs, in.validateCoreDNSVersion(prev)...)
+	allErrs = append(allErrs, in.validateVersion(prev.Spec.Version)...)
 
 	if len(allErrs) > 0 {
 		return apierrors.NewInvalid(GroupVersion.WithKind("KubeadmControlPlane").GroupKind(), in.Name, allErrs)
@@ -255,11 +263,67 @@ func (in *KubeadmControlPlane) validateCommon() (allErrs field.ErrorList) {
 		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "version"), in.Spec.Version, "must be a valid semantic version"))
 	}
 
+	if in.Spec.RolloutBefore != nil && in.Spec.RolloutBefore.CertificatesExpiryDays != nil && *in.Spec.RolloutBefore.CertificatesExpiryDays < 7 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(
+				field.NewPath("spec", "rolloutBefore", "certificatesExpiryDays"),
+				*in.Spec.RolloutBefore.CertificatesExpiryDays,
+				"must be greater than or equal to 7",
+			),
+		)
+	}
+
+	allErrs = append(allErrs, in.validateMachineTemplate()...)
 	allErrs = append(allErrs, in.validateCoreDNSImage()...)
 
 	return allErrs
 }
 
+// validateVersion enforces the Kubernetes version skew policy, which requires the control plane
+// to be upgraded by at most one minor version at a time.
+func (in *KubeadmControlPlane) validateVersion(previousVersion string) (allErrs field.ErrorList) {
+	fromVersion, err := util.ParseMajorMinorPatch(previousVersion)
+	if err != nil {
+		// The previous version was validated when it was set, so this should never happen.
+		return allErrs
+	}
+	toVersion, err := util.ParseMajorMinorPatch(in.Spec.Version)
+	if err != nil {
+		// Invalid versions are reported by validateCommon.
+		return allErrs
+	}
+
+	if toVersion.Major != fromVersion.Major || toVersion.Minor > fromVersion.Minor+1 {
+		allErrs = append(allErrs,
+			field.Forbidden(
+				field.NewPath("spec", "version"),
+				fmt.Sprintf("cannot upgrade from %s to %s, the control plane can be upgraded by at most one minor version at a time", previousVersion, in.Spec.Version),
+			),
+		)
+	}
+	return allErrs
+}
+
+func (in *KubeadmControlPlane) validateMachineTemplate() (allErrs field.ErrorList) {
+	if in.Spec.MachineTemplate == nil {
+		return allErrs
+	}
+
+	metadataPath := field.NewPath("spec", "machineTemplate", "metadata")
+	metadata := in.Spec.MachineTemplate.ObjectMeta
+	allErrs = append(allErrs, metav1validation.ValidateLabels(metadata.Labels, metadataPath.Child("labels"))...)
+	allErrs = append(allErrs, apivalidation.ValidateAnnotations(metadata.Annotations, metadataPath.Child("annotations"))...)
+
+	// The labels identifying the control plane machines are managed by KCP.
+	for _, key := range []string{clusterv1.ClusterLabelName, clusterv1.MachineControlPlaneLabelName, KubeadmControlPlaneHashLabelKey} {
+		if _, ok := metadata.Labels[key]; ok {
+			allErrs = append(allErrs, field.Forbidden(metadataPath.Child("labels").Key(key), "label is managed by the KubeadmControlPlane controller"))
+		}
+	}
+	return allErrs
+}
+
 func (in *KubeadmControlPlane) validateCoreDNSImage() (allErrs field.ErrorList) {
 	if in.Spec.KubeadmConfigSpec.ClusterConfiguration == nil {
 		return allErrs
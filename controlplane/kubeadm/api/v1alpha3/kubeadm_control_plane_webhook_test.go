@@ -25,6 +25,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha3"
 	kubeadmv1beta1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
 )
@@ -92,6 +93,28 @@ func TestKubeadmControlPlaneValidateCreate(t *testing.T) {
 	invalidVersion2 := valid.DeepCopy()
 	invalidVersion2.Spec.Version = "1.16.6"
 
+	validMachineTemplate := valid.DeepCopy()
+	validMachineTemplate.Spec.MachineTemplate = &KubeadmControlPlaneMachineTemplate{
+		ObjectMeta: clusterv1.ObjectMeta{
+			Labels:      map[string]string{"environment": "production"},
+			Annotations: map[string]string{"example.com/owner": "team-a"},
+		},
+	}
+
+	invalidMachineTemplateLabel := valid.DeepCopy()
+	invalidMachineTemplateLabel.Spec.MachineTemplate = &KubeadmControlPlaneMachineTemplate{
+		ObjectMeta: clusterv1.ObjectMeta{
+			Labels: map[string]string{"environment": "not a valid value"},
+		},
+	}
+
+	reservedMachineTemplateLabel := valid.DeepCopy()
+	reservedMachineTemplateLabel.Spec.MachineTemplate = &KubeadmControlPlaneMachineTemplate{
+		ObjectMeta: clusterv1.ObjectMeta{
+			Labels: map[string]string{clusterv1.ClusterLabelName: "other-cluster"},
+		},
+	}
+
 	tests := []struct {
 		name      string
 		expectErr bool
@@ -142,6 +165,21 @@ func TestKubeadmControlPlaneValidateCreate(t *testing.T) {
 			expectErr: true,
 			kcp:       invalidVersion1,
 		},
+		{
+			name:      "should succeed when given a valid machine template",
+			expectErr: false,
+			kcp:       validMachineTemplate,
+		},
+		{
+			name:      "should return error when the machine template has an invalid label",
+			expectErr: true,
+			kcp:       invalidMachineTemplateLabel,
+		},
+		{
+			name:      "should return error when the machine template has a label managed by KCP",
+			expectErr: true,
+			kcp:       reservedMachineTemplateLabel,
+		},
 	}
 
 	for _, tt := range tests {
@@ -232,6 +270,38 @@ func TestKubeadmControlPlaneValidateUpdate(t *testing.T) {
 	validUpdate.Spec.Replicas = pointer.Int32Ptr(5)
 	now := metav1.NewTime(time.Now())
 	validUpdate.Spec.UpgradeAfter = &now
+	validUpdate.Spec.RolloutBefore = &RolloutBefore{
+		CertificatesExpiryDays: pointer.Int32Ptr(21),
+	}
+	validUpdate.Spec.MachineTemplate = &KubeadmControlPlaneMachineTemplate{
+		ObjectMeta: clusterv1.ObjectMeta{
+			Labels:      map[string]string{"environment": "production"},
+			Annotations: map[string]string{"example.com/owner": "team-a"},
+		},
+	}
+
+	upgradeOneMinor := before.DeepCopy()
+	upgradeOneMinor.Spec.Version = "v1.17.2"
+
+	upgradeTwoMinors := before.DeepCopy()
+	upgradeTwoMinors.Spec.Version = "v1.18.0"
+
+	upgradeMajor := before.DeepCopy()
+	upgradeMajor.Spec.Version = "v2.16.6"
+
+	removeMachineTemplate := validUpdate.DeepCopy()
+	removeMachineTemplate.Spec.MachineTemplate = nil
+
+	updateRolloutBefore := validUpdate.DeepCopy()
+	updateRolloutBefore.Spec.RolloutBefore.CertificatesExpiryDays = pointer.Int32Ptr(30)
+
+	removeRolloutBefore := validUpdate.DeepCopy()
+	removeRolloutBefore.Spec.RolloutBefore = nil
+
+	invalidRolloutBeforeCertificatesExpiryDays := before.DeepCopy()
+	invalidRolloutBeforeCertificatesExpiryDays.Spec.RolloutBefore = &RolloutBefore{
+		CertificatesExpiryDays: pointer.Int32Ptr(5),
+	}
 
 	scaleToZero := before.DeepCopy()
 	scaleToZero.Spec.Replicas = pointer.Int32Ptr(0)
@@ -423,6 +493,30 @@ func TestKubeadmControlPlaneValidateUpdate(t *testing.T) {
 			before:    before,
 			kcp:       validUpdate,
 		},
+		{
+			name:      "should succeed when upgrading by one minor version",
+			expectErr: false,
+			before:    before,
+			kcp:       upgradeOneMinor,
+		},
+		{
+			name:      "should return error when upgrading by more than one minor version",
+			expectErr: true,
+			before:    before,
+			kcp:       upgradeTwoMinors,
+		},
+		{
+			name:      "should return error when upgrading the major version",
+			expectErr: true,
+			before:    before,
+			kcp:       upgradeMajor,
+		},
+		{
+			name:      "should succeed when removing the machine template",
+			expectErr: false,
+			before:    validUpdate,
+			kcp:       removeMachineTemplate,
+		},
 		{
 			name:      "should return error when trying to mutate the kubeadmconfigspec initconfiguration",
 			expectErr: true,
@@ -441,6 +535,24 @@ func TestKubeadmControlPlaneValidateUpdate(t *testing.T) {
 			before:    before,
 			kcp:       validUpdateKubeadmConfigJoin,
 		},
+		{
+			name:      "should succeed when updating the rolloutBefore criteria",
+			expectErr: false,
+			before:    validUpdate,
+			kcp:       updateRolloutBefore,
+		},
+		{
+			name:      "should succeed when removing the rolloutBefore criteria",
+			expectErr: false,
+			before:    validUpdate,
+			kcp:       removeRolloutBefore,
+		},
+		{
+			name:      "should return error when certificatesExpiryDays is less than 7",
+			expectErr: true,
+			before:    before,
+			kcp:       invalidRolloutBeforeCertificatesExpiryDays,
+		},
 		{
 			name:      "should return error when trying to scale to zero",
 			expectErr: true,
@@ -0,0 +1,571 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/machinefilters"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/feature"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/kubeconfig"
+	"sigs.k8s.io/cluster-api/util/secret"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+// certificateAuthorityPurposes are the certificate authorities rotated by the KubeadmControlPlane controller.
+var certificateAuthorityPurposes = []secret.Purpose{secret.ClusterCA, secret.EtcdCA, secret.FrontProxyCA, secret.ServiceAccount}
+
+// reconcileCertificateAuthoritiesRotation rotates the certificate authorities of the cluster, if requested with the
+// RotateCertificateAuthoritiesAnnotation. The rotation goes through the phases defined by secret.RotationPhase; in
+// each of them the trust bundle of the cluster-info ConfigMap is updated, the kubeconfig Secrets are reissued, and
+// the control plane and worker machines are rolled out before moving to the next phase; the last phase, removing the
+// previous certificate authorities, must be confirmed with the RemoveOldCertificateAuthoritiesAnnotation. The phase is stored in the
+// certificate authority Secrets, so the rotation resumes where it was left after a restart of the controller.
+// NOTE: the control plane machines are rolled out by the regular rollout, given controlPlane.CertificateAuthoritiesHash.
+func (r *KubeadmControlPlaneReconciler) reconcileCertificateAuthoritiesRotation(ctx context.Context, controlPlane *internal.ControlPlane) error {
+	logger := controlPlane.Logger()
+	kcp := controlPlane.KCP
+	cluster := controlPlane.Cluster
+
+	cas, err := r.getRotatableCertificateAuthorities(ctx, cluster, kcp)
+	if err != nil {
+		return err
+	}
+	phase, err := r.syncRotationPhase(ctx, cas)
+	if err != nil {
+		return err
+	}
+
+	_, requested := kcp.Annotations[controlplanev1.RotateCertificateAuthoritiesAnnotation]
+	if phase == "" {
+		if !requested {
+			return nil
+		}
+		if len(cas) == 0 {
+			logger.Info("Ignoring the rotation of the certificate authorities, none of them is managed by the KubeadmControlPlane")
+			delete(kcp.Annotations, controlplanev1.RotateCertificateAuthoritiesAnnotation)
+			return nil
+		}
+
+		// NOTE: the annotation is kept if the rotation can't be started, so it is retried.
+		logger.Info("Starting the rotation of the certificate authorities")
+		if phase, err = r.advanceRotation(ctx, cas, phase); err != nil {
+			conditions.MarkFalse(kcp, controlplanev1.CertificateAuthoritiesTrustedCondition, controlplanev1.CertificateAuthoritiesRotationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return err
+		}
+		for _, condition := range []clusterv1.ConditionType{
+			controlplanev1.CertificateAuthoritiesKubeconfigReissuedCondition,
+			controlplanev1.CertificateAuthoritiesOldRemovedCondition,
+		} {
+			conditions.MarkFalse(kcp, condition, controlplanev1.CertificateAuthoritiesRotationInProgressReason, clusterv1.ConditionSeverityInfo, "Waiting for the new certificate authorities to be trusted by all the machines")
+		}
+		markMachinesRolloutInProgress(kcp)
+	}
+
+	// The rotation is stored in the certificate authority Secrets once started, and further requests are ignored
+	// while it is in progress, so the request can be removed.
+	delete(kcp.Annotations, controlplanev1.RotateCertificateAuthoritiesAnnotation)
+
+	caHash := certificateAuthoritiesHash(cas)
+	controlPlane.CertificateAuthoritiesHash = caHash
+
+	// Update the CA certificates trusted by the joining nodes.
+	trustCondition := controlplanev1.CertificateAuthoritiesTrustedCondition
+	if phase == secret.RotationRemoveOld {
+		trustCondition = controlplanev1.CertificateAuthoritiesOldRemovedCondition
+	}
+	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, util.ObjectKey(cluster))
+	if err != nil {
+		return errors.Wrap(err, "failed to create remote cluster client")
+	}
+	if clusterCA, ok := cas[secret.ClusterCA]; ok {
+		if err := workloadCluster.UpdateClusterInfoCertificateAuthorities(ctx, clusterCA.Data[secret.TLSCrtDataName]); err != nil {
+			conditions.MarkFalse(kcp, trustCondition, controlplanev1.CertificateAuthoritiesRotationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return errors.Wrap(err, "failed to update the certificate authorities of the cluster-info ConfigMap")
+		}
+	}
+	if phase == secret.RotationTrustNew {
+		conditions.MarkTrue(kcp, controlplanev1.CertificateAuthoritiesTrustedCondition)
+	}
+
+	// Reissue the kubeconfigs, so they trust the current CA bundle and use credentials signed by the current CAs.
+	if err := r.reissueKubeconfigs(ctx, cluster, kcp, caHash); err != nil {
+		conditions.MarkFalse(kcp, controlplanev1.CertificateAuthoritiesKubeconfigReissuedCondition, controlplanev1.CertificateAuthoritiesRotationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return err
+	}
+	pendingKubeconfigs := 0
+	if clusterCA, ok := cas[secret.ClusterCA]; ok {
+		if pendingKubeconfigs, err = r.reissueRequestedKubeconfigs(ctx, cluster, clusterCA.Data[secret.TLSCrtDataName], caHash); err != nil {
+			conditions.MarkFalse(kcp, controlplanev1.CertificateAuthoritiesKubeconfigReissuedCondition, controlplanev1.CertificateAuthoritiesRotationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return err
+		}
+	}
+	if phase != secret.RotationTrustNew && pendingKubeconfigs == 0 {
+		conditions.MarkTrue(kcp, controlplanev1.CertificateAuthoritiesKubeconfigReissuedCondition)
+	}
+
+	// Wait for the control plane machines to be rolled out with the current CAs.
+	pendingMachines := controlPlane.Machines.AnyFilter(
+		machinefilters.Not(machinefilters.MatchesCertificateAuthoritiesHash(caHash)),
+		machinefilters.Not(machinefilters.IsReady()),
+		func(machine *clusterv1.Machine) bool { return machine.Status.NodeRef == nil },
+	)
+	if len(pendingMachines) > 0 || len(controlPlane.Machines) != int(*kcp.Spec.Replicas) {
+		conditions.MarkFalse(kcp, controlplanev1.CertificateAuthoritiesControlPlaneRolledOutCondition, controlplanev1.CertificateAuthoritiesRotationInProgressReason, clusterv1.ConditionSeverityInfo,
+			"Waiting for %d control plane machines to be rolled out for the %s phase", len(pendingMachines), phase)
+		return nil
+	}
+	conditions.MarkTrue(kcp, controlplanev1.CertificateAuthoritiesControlPlaneRolledOutCondition)
+
+	// Wait for the worker machines to be rolled out with the current CAs.
+	pending, err := r.rolloutWorkers(ctx, cluster, caHash)
+	if err != nil {
+		conditions.MarkFalse(kcp, controlplanev1.CertificateAuthoritiesWorkersRolledOutCondition, controlplanev1.CertificateAuthoritiesRotationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return err
+	}
+	if len(pending) > 0 {
+		conditions.MarkFalse(kcp, controlplanev1.CertificateAuthoritiesWorkersRolledOutCondition, controlplanev1.CertificateAuthoritiesRotationInProgressReason, clusterv1.ConditionSeverityInfo,
+			"Waiting for %s to be rolled out for the %s phase", strings.Join(pending, ", "), phase)
+		return nil
+	}
+	conditions.MarkTrue(kcp, controlplanev1.CertificateAuthoritiesWorkersRolledOutCondition)
+
+	// Wait for the kubeconfigs of the KubeconfigRequests to trust the current CAs, as the API server certificates
+	// are signed by the new cluster CA in the next phases.
+	if pendingKubeconfigs > 0 {
+		conditions.MarkFalse(kcp, controlplanev1.CertificateAuthoritiesKubeconfigReissuedCondition, controlplanev1.CertificateAuthoritiesRotationInProgressReason, clusterv1.ConditionSeverityInfo,
+			"Waiting for %d kubeconfigs of KubeconfigRequests to be reissued for the %s phase", pendingKubeconfigs, phase)
+		return nil
+	}
+
+	// The previous CAs are removed only once confirmed, as the credentials not reissued by the controller, e.g. the
+	// legacy ServiceAccount token Secrets used by kube-proxy, CoreDNS or the CNI, stop working without them.
+	_, removalConfirmed := kcp.Annotations[controlplanev1.RemoveOldCertificateAuthoritiesAnnotation]
+	if phase.Next() == secret.RotationRemoveOld && !removalConfirmed {
+		conditions.MarkFalse(kcp, controlplanev1.CertificateAuthoritiesOldRemovedCondition, controlplanev1.CertificateAuthoritiesWaitingForRemovalConfirmationReason, clusterv1.ConditionSeverityInfo,
+			"Waiting for the ServiceAccount token Secrets to be recreated, and the removal to be confirmed with the %s annotation", controlplanev1.RemoveOldCertificateAuthoritiesAnnotation)
+		return nil
+	}
+
+	// All the machines are using the current CAs, move to the next phase.
+	next, err := r.advanceRotation(ctx, cas, phase)
+	if err != nil {
+		conditions.MarkFalse(kcp, trustCondition, controlplanev1.CertificateAuthoritiesRotationFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return err
+	}
+	if next == secret.RotationRemoveOld {
+		delete(kcp.Annotations, controlplanev1.RemoveOldCertificateAuthoritiesAnnotation)
+		conditions.MarkFalse(kcp, controlplanev1.CertificateAuthoritiesOldRemovedCondition, controlplanev1.CertificateAuthoritiesRotationInProgressReason, clusterv1.ConditionSeverityInfo,
+			"Waiting for the new certificate authorities to be trusted by all the machines")
+	}
+	if next == "" {
+		logger.Info("Completed the rotation of the certificate authorities")
+		conditions.MarkTrue(kcp, controlplanev1.CertificateAuthoritiesOldRemovedCondition)
+		controlPlane.CertificateAuthoritiesHash = ""
+		return nil
+	}
+	logger.Info("Moved the rotation of the certificate authorities to the next phase", "phase", next)
+	markMachinesRolloutInProgress(kcp)
+	controlPlane.CertificateAuthoritiesHash = certificateAuthoritiesHash(cas)
+	return nil
+}
+
+// getRotatableCertificateAuthorities returns the Secrets of the certificate authorities generated by the
+// KubeadmControlPlane; user provided certificate authorities, e.g. the ones of an external etcd, are not rotated.
+func (r *KubeadmControlPlaneReconciler) getRotatableCertificateAuthorities(ctx context.Context, cluster *clusterv1.Cluster, kcp *controlplanev1.KubeadmControlPlane) (map[secret.Purpose]*corev1.Secret, error) {
+	cas := map[secret.Purpose]*corev1.Secret{}
+	for _, purpose := range certificateAuthorityPurposes {
+		s, err := secret.Get(ctx, r.Client, util.ObjectKey(cluster), purpose)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to get the %s certificate authority Secret", purpose)
+		}
+		if !util.IsControlledBy(s, kcp) {
+			continue
+		}
+		cas[purpose] = s
+	}
+	return cas, nil
+}
+
+// syncRotationPhase returns the current phase of the rotation of the certificate authorities. If the controller
+// was interrupted while moving the Secrets to the next phase, the Secrets lagging behind are moved to it too.
+func (r *KubeadmControlPlaneReconciler) syncRotationPhase(ctx context.Context, cas map[secret.Purpose]*corev1.Secret) (secret.RotationPhase, error) {
+	phases := map[secret.RotationPhase]bool{}
+	for _, s := range cas {
+		phases[secret.GetRotationPhase(s)] = true
+	}
+	switch len(phases) {
+	case 0:
+		return "", nil
+	case 1:
+		for phase := range phases {
+			return phase, nil
+		}
+	case 2:
+		for phase := range phases {
+			if phases[phase.Next()] {
+				return r.advanceRotation(ctx, cas, phase)
+			}
+		}
+	}
+	return "", errors.New("the certificate authority Secrets are in inconsistent rotation phases")
+}
+
+// advanceRotation moves the certificate authority Secrets in the given phase to the next one, and returns it.
+func (r *KubeadmControlPlaneReconciler) advanceRotation(ctx context.Context, cas map[secret.Purpose]*corev1.Secret, phase secret.RotationPhase) (secret.RotationPhase, error) {
+	for _, purpose := range certificateAuthorityPurposes {
+		s, ok := cas[purpose]
+		if !ok || secret.GetRotationPhase(s) != phase {
+			continue
+		}
+		if err := secret.AdvanceRotation(s, purpose); err != nil {
+			return phase, err
+		}
+		if err := r.Client.Update(ctx, s); err != nil {
+			return phase, errors.Wrapf(err, "failed to update the %s certificate authority Secret", purpose)
+		}
+	}
+	return phase.Next(), nil
+}
+
+// reissueKubeconfigs reissues the kubeconfig Secrets of the cluster once for each phase of a rotation: the admin
+// kubeconfig is regenerated, and the controller kubeconfig is deleted for being reissued by the Cluster controller
+// with a token signed by the current service account key.
+func (r *KubeadmControlPlaneReconciler) reissueKubeconfigs(ctx context.Context, cluster *clusterv1.Cluster, kcp *controlplanev1.KubeadmControlPlane, caHash string) error {
+	configSecret, err := secret.Get(ctx, r.Client, util.ObjectKey(cluster), secret.Kubeconfig)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve kubeconfig Secret")
+	}
+	if configSecret.Annotations[controlplanev1.CertificateAuthoritiesHashAnnotation] == caHash {
+		return nil
+	}
+
+	controllerKubeconfig := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: cluster.Namespace,
+			Name:      secret.Name(cluster.Name, secret.ControllerKubeconfig),
+		},
+	}
+	if err := r.Client.Delete(ctx, controllerKubeconfig); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to delete the controller kubeconfig Secret")
+	}
+
+	if configSecret.Annotations == nil {
+		configSecret.Annotations = map[string]string{}
+	}
+	configSecret.Annotations[controlplanev1.CertificateAuthoritiesHashAnnotation] = caHash
+	// Only regenerate owned secrets, like for the rotation of the client certificate.
+	if !util.IsControlledBy(configSecret, kcp) {
+		return errors.Wrap(r.Client.Update(ctx, configSecret), "failed to update kubeconfig Secret")
+	}
+	return errors.Wrap(kubeconfig.RegenerateSecret(ctx, r.Client, configSecret), "failed to regenerate kubeconfig")
+}
+
+// reissueRequestedKubeconfigs requests the kubeconfigs issued for the KubeconfigRequests of the cluster to be reissued
+// when they don't trust the given CA certificates, by setting the hash of the certificate authorities on their Secret,
+// which requeues the KubeconfigRequest owning it; it returns how many of them are not yet reissued.
+func (r *KubeadmControlPlaneReconciler) reissueRequestedKubeconfigs(ctx context.Context, cluster *clusterv1.Cluster, caData []byte, caHash string) (int, error) {
+	secrets := &corev1.SecretList{}
+	if err := r.Client.List(ctx, secrets, client.InNamespace(cluster.Namespace), client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name}); err != nil {
+		return 0, errors.Wrap(err, "failed to list Secrets")
+	}
+
+	pending := 0
+	for i := range secrets.Items {
+		s := &secrets.Items[i]
+		owner := metav1.GetControllerOf(s)
+		if owner == nil || owner.Kind != "KubeconfigRequest" {
+			continue
+		}
+		if gv, err := schema.ParseGroupVersion(owner.APIVersion); err != nil || gv.Group != expv1.GroupVersion.Group {
+			continue
+		}
+
+		outdated, err := kubeconfig.NeedsCertificateAuthoritiesUpdate(s, caData)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to check the kubeconfig of Secret %s", s.Name)
+		}
+		if !outdated {
+			continue
+		}
+		pending++
+		if s.Annotations[controlplanev1.CertificateAuthoritiesHashAnnotation] == caHash {
+			continue
+		}
+		patch := client.MergeFrom(s.DeepCopy())
+		if s.Annotations == nil {
+			s.Annotations = map[string]string{}
+		}
+		s.Annotations[controlplanev1.CertificateAuthoritiesHashAnnotation] = caHash
+		if err := r.Client.Patch(ctx, s, patch); err != nil {
+			return 0, errors.Wrapf(err, "failed to patch Secret %s", s.Name)
+		}
+	}
+	return pending, nil
+}
+
+// rolloutWorkers rolls out the worker machines of the cluster with the certificate authorities of the given hash,
+// and returns a description of the workers not yet rolled out.
+//
+// Machines not owned by a MachineDeployment can't be rolled out by the controller; they block the rotation until they
+// are replaced, or their credentials are renewed, and annotated with the hash of the certificate authorities, so the
+// previous certificate authorities are never removed while they are still used.
+func (r *KubeadmControlPlaneReconciler) rolloutWorkers(ctx context.Context, cluster *clusterv1.Cluster, caHash string) ([]string, error) {
+	var pending []string
+
+	machineDeployments, err := r.rolloutMachineDeployments(ctx, cluster, caHash)
+	if err != nil {
+		return nil, err
+	}
+	if machineDeployments > 0 {
+		pending = append(pending, fmt.Sprintf("%d MachineDeployments", machineDeployments))
+	}
+
+	if feature.Gates.Enabled(feature.MachinePool) {
+		machinePools, err := r.rolloutMachinePools(ctx, cluster, caHash)
+		if err != nil {
+			return nil, err
+		}
+		if machinePools > 0 {
+			pending = append(pending, fmt.Sprintf("%d MachinePools", machinePools))
+		}
+	}
+
+	machines := &clusterv1.MachineList{}
+	if err := r.Client.List(ctx, machines, client.InNamespace(cluster.Namespace), client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name}); err != nil {
+		return nil, errors.Wrap(err, "failed to list Machines")
+	}
+	var unowned []string
+	for i := range machines.Items {
+		machine := &machines.Items[i]
+		if _, ok := machine.Labels[clusterv1.MachineControlPlaneLabelName]; ok {
+			continue
+		}
+		if _, ok := machine.Labels[clusterv1.MachineDeploymentLabelName]; ok {
+			continue
+		}
+		if machine.Annotations[controlplanev1.CertificateAuthoritiesHashAnnotation] != caHash {
+			unowned = append(unowned, machine.Name)
+		}
+	}
+	if len(unowned) > 0 {
+		sort.Strings(unowned)
+		pending = append(pending, fmt.Sprintf("Machines %s, not owned by a MachineDeployment, to be replaced and annotated with %s=%s",
+			strings.Join(unowned, ", "), controlplanev1.CertificateAuthoritiesHashAnnotation, caHash))
+	}
+	return pending, nil
+}
+
+// rolloutMachineDeployments sets the hash of the certificate authorities in the machine template of the
+// MachineDeployments of the cluster, and returns how many of them are not yet rolled out.
+func (r *KubeadmControlPlaneReconciler) rolloutMachineDeployments(ctx context.Context, cluster *clusterv1.Cluster, caHash string) (int, error) {
+	machineDeployments := &clusterv1.MachineDeploymentList{}
+	if err := r.Client.List(ctx, machineDeployments, client.InNamespace(cluster.Namespace), client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name}); err != nil {
+		return 0, errors.Wrap(err, "failed to list MachineDeployments")
+	}
+
+	pending := 0
+	for i := range machineDeployments.Items {
+		md := &machineDeployments.Items[i]
+		if md.Spec.Template.Annotations[controlplanev1.CertificateAuthoritiesHashAnnotation] != caHash {
+			patch := client.MergeFrom(md.DeepCopy())
+			if md.Spec.Template.Annotations == nil {
+				md.Spec.Template.Annotations = map[string]string{}
+			}
+			md.Spec.Template.Annotations[controlplanev1.CertificateAuthoritiesHashAnnotation] = caHash
+			if err := r.Client.Patch(ctx, md, patch); err != nil {
+				return 0, errors.Wrapf(err, "failed to patch MachineDeployment %s", md.Name)
+			}
+			pending++
+			continue
+		}
+		if !machineDeploymentRolledOut(md) {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// machineDeploymentRolledOut returns true if all the machines of a MachineDeployment are up to date and available.
+func machineDeploymentRolledOut(md *clusterv1.MachineDeployment) bool {
+	replicas := int32(1)
+	if md.Spec.Replicas != nil {
+		replicas = *md.Spec.Replicas
+	}
+	return md.Status.ObservedGeneration >= md.Generation &&
+		md.Status.Replicas == replicas &&
+		md.Status.UpdatedReplicas == replicas &&
+		md.Status.AvailableReplicas == replicas
+}
+
+// rolloutMachinePools sets the hash of the certificate authorities in the machine template of the MachinePools of
+// the cluster, and returns how many of them are not yet rolled out.
+// NOTE: the instances of a MachinePool are replaced by its infrastructure provider, when the template changes.
+func (r *KubeadmControlPlaneReconciler) rolloutMachinePools(ctx context.Context, cluster *clusterv1.Cluster, caHash string) (int, error) {
+	machinePools := &expv1.MachinePoolList{}
+	if err := r.Client.List(ctx, machinePools, client.InNamespace(cluster.Namespace), client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name}); err != nil {
+		return 0, errors.Wrap(err, "failed to list MachinePools")
+	}
+
+	pending := 0
+	for i := range machinePools.Items {
+		mp := &machinePools.Items[i]
+		if mp.Spec.Template.Annotations[controlplanev1.CertificateAuthoritiesHashAnnotation] != caHash {
+			patch := client.MergeFrom(mp.DeepCopy())
+			if mp.Spec.Template.Annotations == nil {
+				mp.Spec.Template.Annotations = map[string]string{}
+			}
+			mp.Spec.Template.Annotations[controlplanev1.CertificateAuthoritiesHashAnnotation] = caHash
+			if mp.Annotations == nil {
+				mp.Annotations = map[string]string{}
+			}
+			mp.Annotations[controlplanev1.CertificateAuthoritiesOutdatedInstancesAnnotation] = strings.Join(mp.Spec.ProviderIDList, ",")
+			if err := r.Client.Patch(ctx, mp, patch); err != nil {
+				return 0, errors.Wrapf(err, "failed to patch MachinePool %s", mp.Name)
+			}
+			pending++
+			continue
+		}
+		if !machinePoolRolledOut(mp) {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// machinePoolRolledOut returns true if all the instances of a MachinePool outdated when its template was changed
+// have been replaced, and all its replicas are ready and available.
+func machinePoolRolledOut(mp *expv1.MachinePool) bool {
+	replicas := int32(1)
+	if mp.Spec.Replicas != nil {
+		replicas = *mp.Spec.Replicas
+	}
+	if int32(len(mp.Spec.ProviderIDList)) != replicas ||
+		mp.Status.Replicas != replicas ||
+		mp.Status.ReadyReplicas != replicas ||
+		mp.Status.AvailableReplicas != replicas {
+		return false
+	}
+
+	outdated := strings.Split(mp.Annotations[controlplanev1.CertificateAuthoritiesOutdatedInstancesAnnotation], ",")
+	for _, providerID := range mp.Spec.ProviderIDList {
+		for _, outdatedProviderID := range outdated {
+			if providerID == outdatedProviderID {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// markMachinesRolloutInProgress marks the rollout of the machines as in progress, when entering a rotation phase.
+func markMachinesRolloutInProgress(kcp *controlplanev1.KubeadmControlPlane) {
+	conditions.MarkFalse(kcp, controlplanev1.CertificateAuthoritiesControlPlaneRolledOutCondition, controlplanev1.CertificateAuthoritiesRotationInProgressReason, clusterv1.ConditionSeverityInfo, "Waiting for the control plane machines to be rolled out")
+	conditions.MarkFalse(kcp, controlplanev1.CertificateAuthoritiesWorkersRolledOutCondition, controlplanev1.CertificateAuthoritiesRotationInProgressReason, clusterv1.ConditionSeverityInfo, "Waiting for the control plane machines to be rolled out")
+}
+
+// certificateAuthoritiesHash returns a hash of the trust bundles of the certificate authorities.
+func certificateAuthoritiesHash(cas map[secret.Purpose]*corev1.Secret) string {
+	hasher := fnv.New32a()
+	for _, purpose := range certificateAuthorityPurposes {
+		if s, ok := cas[purpose]; ok {
+			_, _ = hasher.Write(s.Data[secret.TLSCrtDataName])
+		}
+	}
+	return fmt.Sprintf("%d", hasher.Sum32())
+}
+
+// MachineDeploymentToKubeadmControlPlane is a handler.ToRequestsFunc to be used to enqueue requests for reconciliation
+// for KubeadmControlPlane based on updates to the MachineDeployments rolled out for a rotation of the certificate authorities.
+func (r *KubeadmControlPlaneReconciler) MachineDeploymentToKubeadmControlPlane(o handler.MapObject) []ctrl.Request {
+	md, ok := o.Object.(*clusterv1.MachineDeployment)
+	if !ok {
+		r.Log.Error(nil, fmt.Sprintf("Expected a MachineDeployment but got a %T", o.Object))
+		return nil
+	}
+	if _, ok := md.Spec.Template.Annotations[controlplanev1.CertificateAuthoritiesHashAnnotation]; !ok {
+		return nil
+	}
+
+	cluster := &clusterv1.Cluster{}
+	if err := r.Client.Get(context.Background(), client.ObjectKey{Namespace: md.Namespace, Name: md.Spec.ClusterName}, cluster); err != nil {
+		return nil
+	}
+	return r.ClusterToKubeadmControlPlane(handler.MapObject{Meta: cluster, Object: cluster})
+}
+
+// MachinePoolToKubeadmControlPlane is a handler.ToRequestsFunc to be used to enqueue requests for reconciliation
+// for KubeadmControlPlane based on updates to the MachinePools rolled out for a rotation of the certificate authorities.
+func (r *KubeadmControlPlaneReconciler) MachinePoolToKubeadmControlPlane(o handler.MapObject) []ctrl.Request {
+	mp, ok := o.Object.(*expv1.MachinePool)
+	if !ok {
+		r.Log.Error(nil, fmt.Sprintf("Expected a MachinePool but got a %T", o.Object))
+		return nil
+	}
+	if _, ok := mp.Spec.Template.Annotations[controlplanev1.CertificateAuthoritiesHashAnnotation]; !ok {
+		return nil
+	}
+
+	cluster := &clusterv1.Cluster{}
+	if err := r.Client.Get(context.Background(), client.ObjectKey{Namespace: mp.Namespace, Name: mp.Spec.ClusterName}, cluster); err != nil {
+		return nil
+	}
+	return r.ClusterToKubeadmControlPlane(handler.MapObject{Meta: cluster, Object: cluster})
+}
+
+// SecretToKubeadmControlPlane is a handler.ToRequestsFunc to be used to enqueue requests for reconciliation
+// for KubeadmControlPlane based on updates to the kubeconfig Secrets reissued for a rotation of the certificate authorities.
+func (r *KubeadmControlPlaneReconciler) SecretToKubeadmControlPlane(o handler.MapObject) []ctrl.Request {
+	s, ok := o.Object.(*corev1.Secret)
+	if !ok {
+		r.Log.Error(nil, fmt.Sprintf("Expected a Secret but got a %T", o.Object))
+		return nil
+	}
+	if _, ok := s.Annotations[controlplanev1.CertificateAuthoritiesHashAnnotation]; !ok {
+		return nil
+	}
+	clusterName, ok := s.Labels[clusterv1.ClusterLabelName]
+	if !ok {
+		return nil
+	}
+
+	cluster := &clusterv1.Cluster{}
+	if err := r.Client.Get(context.Background(), client.ObjectKey{Namespace: s.Namespace, Name: clusterName}, cluster); err != nil {
+		return nil
+	}
+	return r.ClusterToKubeadmControlPlane(handler.MapObject{Meta: cluster, Object: cluster})
+}
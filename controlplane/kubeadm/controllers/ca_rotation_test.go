@@ -0,0 +1,442 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/cert"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/feature"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/kubeconfig"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestKubeadmControlPlaneReconciler_reconcileCertificateAuthoritiesRotation(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	cluster, kcp, _ := createClusterWithControlPlane()
+	cluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{Host: "test.local", Port: 6443}
+	kcp.UID = "kcp-uid"
+	kcp.Spec.Replicas = pointer.Int32Ptr(1)
+	kcp.Annotations = map[string]string{controlplanev1.RotateCertificateAuthoritiesAnnotation: ""}
+	controllerRef := *metav1.NewControllerRef(kcp, controlplanev1.GroupVersion.WithKind("KubeadmControlPlane"))
+
+	objs := []runtime.Object{cluster.DeepCopy(), kcp.DeepCopy()}
+	certificates := secret.NewCertificatesForInitialControlPlane(&kubeadmv1.ClusterConfiguration{})
+	g.Expect(certificates.Generate()).To(Succeed())
+	for _, c := range certificates {
+		objs = append(objs, c.AsSecret(util.ObjectKey(cluster), controllerRef))
+	}
+	controllerKubeconfig := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: cluster.Namespace,
+			Name:      secret.Name(cluster.Name, secret.ControllerKubeconfig),
+		},
+	}
+	md := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: cluster.Namespace,
+			Name:      "md",
+			Labels:    map[string]string{clusterv1.ClusterLabelName: cluster.Name},
+		},
+		Spec: clusterv1.MachineDeploymentSpec{
+			ClusterName: cluster.Name,
+			Replicas:    pointer.Int32Ptr(2),
+		},
+	}
+	userKubeconfig := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: cluster.Namespace,
+			Name:      "jane-user-kubeconfig",
+			Labels:    map[string]string{clusterv1.ClusterLabelName: cluster.Name},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(&expv1.KubeconfigRequest{ObjectMeta: metav1.ObjectMeta{Name: "jane"}}, expv1.GroupVersion.WithKind("KubeconfigRequest")),
+			},
+		},
+	}
+	objs = append(objs, controllerKubeconfig, md)
+	fakeClient := newFakeClient(g, objs...)
+	g.Expect(kubeconfig.CreateSecretWithOwner(ctx, fakeClient, util.ObjectKey(cluster), cluster.Spec.ControlPlaneEndpoint.String(), controllerRef)).To(Succeed())
+	reissueUserKubeconfig := func() {
+		data, err := kubeconfig.GenerateForUser(ctx, fakeClient, util.ObjectKey(cluster), "https://test.local:6443", "jane", nil, time.Hour)
+		g.Expect(err).NotTo(HaveOccurred())
+		current := &corev1.Secret{}
+		if err := fakeClient.Get(ctx, util.ObjectKey(userKubeconfig), current); apierrors.IsNotFound(err) {
+			current = userKubeconfig.DeepCopy()
+			current.Data = map[string][]byte{secret.KubeconfigDataName: data}
+			g.Expect(fakeClient.Create(ctx, current)).To(Succeed())
+			return
+		}
+		current.Data = map[string][]byte{secret.KubeconfigDataName: data}
+		g.Expect(fakeClient.Update(ctx, current)).To(Succeed())
+	}
+	reissueUserKubeconfig()
+
+	clusterInfo := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: metav1.NamespacePublic,
+			Name:      "cluster-info",
+		},
+		Data: map[string]string{
+			"kubeconfig": "apiVersion: v1\nkind: Config\nclusters:\n- name: \"\"\n  cluster:\n    server: https://test.local:6443\n",
+		},
+	}
+	workloadClient := fake.NewFakeClientWithScheme(scheme.Scheme, clusterInfo)
+
+	r := &KubeadmControlPlaneReconciler{
+		Client: fakeClient,
+		Log:    log.Log,
+		managementCluster: &fakeManagementCluster{
+			Workload: fakeWorkloadCluster{Workload: &internal.Workload{Client: workloadClient}},
+		},
+	}
+
+	newMachine := func(name, caHash string) *clusterv1.Machine {
+		m, _ := createMachineNodePair(name, cluster, kcp, true)
+		if caHash != "" {
+			m.Annotations = map[string]string{controlplanev1.CertificateAuthoritiesHashAnnotation: caHash}
+		}
+		conditions.MarkTrue(m, clusterv1.ReadyCondition)
+		return m
+	}
+	reconcile := func(machines ...*clusterv1.Machine) *internal.ControlPlane {
+		controlPlane := internal.NewControlPlane(cluster, kcp, internal.NewFilterableMachineCollection(machines...))
+		g.Expect(r.reconcileCertificateAuthoritiesRotation(ctx, controlPlane)).To(Succeed())
+		return controlPlane
+	}
+	getSecret := func(purpose secret.Purpose) *corev1.Secret {
+		s, err := secret.Get(ctx, fakeClient, util.ObjectKey(cluster), purpose)
+		g.Expect(err).NotTo(HaveOccurred())
+		return s
+	}
+	expectPhase := func(phase secret.RotationPhase) {
+		for _, purpose := range certificateAuthorityPurposes {
+			g.Expect(secret.GetRotationPhase(getSecret(purpose))).To(Equal(phase), string(purpose))
+		}
+	}
+	expectClusterInfoCAs := func() {
+		cm := &corev1.ConfigMap{}
+		g.Expect(workloadClient.Get(ctx, client.ObjectKey{Namespace: metav1.NamespacePublic, Name: "cluster-info"}, cm)).To(Succeed())
+		config, err := clientcmd.Load([]byte(cm.Data["kubeconfig"]))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(config.Clusters[""].CertificateAuthorityData).To(Equal(getSecret(secret.ClusterCA).Data[secret.TLSCrtDataName]))
+	}
+	expectKubeconfigReissued := func(caHash string) {
+		kubeconfigSecret := getSecret(secret.Kubeconfig)
+		g.Expect(kubeconfigSecret.Annotations).To(HaveKeyWithValue(controlplanev1.CertificateAuthoritiesHashAnnotation, caHash))
+		config, err := clientcmd.Load(kubeconfigSecret.Data[secret.KubeconfigDataName])
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(config.Clusters[cluster.Name].CertificateAuthorityData).To(Equal(getSecret(secret.ClusterCA).Data[secret.TLSCrtDataName]))
+		err = fakeClient.Get(ctx, util.ObjectKey(controllerKubeconfig), &corev1.Secret{})
+		g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	}
+	rolloutMachineDeployment := func(caHash string) {
+		current := &clusterv1.MachineDeployment{}
+		g.Expect(fakeClient.Get(ctx, util.ObjectKey(md), current)).To(Succeed())
+		g.Expect(current.Spec.Template.Annotations).To(HaveKeyWithValue(controlplanev1.CertificateAuthoritiesHashAnnotation, caHash))
+		current.Status = clusterv1.MachineDeploymentStatus{Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2}
+		g.Expect(fakeClient.Update(ctx, current)).To(Succeed())
+	}
+
+	originalCA := getSecret(secret.ClusterCA).Data[secret.TLSCrtDataName]
+	machine := newMachine("machine-0", "")
+
+	// Nothing happens until the rotation is requested.
+	kcpWithoutAnnotation := kcp.DeepCopy()
+	kcpWithoutAnnotation.Annotations = nil
+	controlPlane := internal.NewControlPlane(cluster, kcpWithoutAnnotation, internal.NewFilterableMachineCollection(machine))
+	g.Expect(r.reconcileCertificateAuthoritiesRotation(ctx, controlPlane)).To(Succeed())
+	g.Expect(controlPlane.CertificateAuthoritiesHash).To(BeEmpty())
+	expectPhase("")
+
+	// The request is kept while the rotation can't be started, so it is retried.
+	r.Client = &failingUpdateClient{Client: fakeClient}
+	controlPlane = internal.NewControlPlane(cluster, kcp, internal.NewFilterableMachineCollection(machine))
+	g.Expect(r.reconcileCertificateAuthoritiesRotation(ctx, controlPlane)).NotTo(Succeed())
+	g.Expect(kcp.Annotations).To(HaveKey(controlplanev1.RotateCertificateAuthoritiesAnnotation))
+	g.Expect(conditions.IsFalse(kcp, controlplanev1.CertificateAuthoritiesTrustedCondition)).To(BeTrue())
+	expectPhase("")
+	r.Client = fakeClient
+
+	// The rotation starts by trusting the new CAs, and rolls out the control plane machines.
+	controlPlane = reconcile(machine)
+	g.Expect(kcp.Annotations).NotTo(HaveKey(controlplanev1.RotateCertificateAuthoritiesAnnotation))
+	expectPhase(secret.RotationTrustNew)
+	bundle, err := cert.ParseCertsPEM(getSecret(secret.ClusterCA).Data[secret.TLSCrtDataName])
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(bundle).To(HaveLen(2))
+	expectClusterInfoCAs()
+	caHash := controlPlane.CertificateAuthoritiesHash
+	g.Expect(caHash).NotTo(BeEmpty())
+	expectKubeconfigReissued(caHash)
+	g.Expect(conditions.IsTrue(kcp, controlplanev1.CertificateAuthoritiesTrustedCondition)).To(BeTrue())
+	g.Expect(conditions.IsFalse(kcp, controlplanev1.CertificateAuthoritiesControlPlaneRolledOutCondition)).To(BeTrue())
+	g.Expect(controlPlane.MachinesNeedingRollout()).To(ConsistOf(machine))
+	g.Expect(controlPlane.NewMachine(&corev1.ObjectReference{}, nil, nil).Annotations).To(HaveKeyWithValue(controlplanev1.CertificateAuthoritiesHashAnnotation, caHash))
+
+	// Once the control plane machines are rolled out, the worker machines are rolled out.
+	machine = newMachine("machine-1", caHash)
+	reconcile(machine)
+	expectPhase(secret.RotationTrustNew)
+	g.Expect(conditions.IsTrue(kcp, controlplanev1.CertificateAuthoritiesControlPlaneRolledOutCondition)).To(BeTrue())
+	g.Expect(conditions.IsFalse(kcp, controlplanev1.CertificateAuthoritiesWorkersRolledOutCondition)).To(BeTrue())
+
+	// The kubeconfigs of the KubeconfigRequests are requested to be reissued, and block the next phase until they are.
+	rolloutMachineDeployment(caHash)
+	reconcile(machine)
+	expectPhase(secret.RotationTrustNew)
+	g.Expect(conditions.GetReason(kcp, controlplanev1.CertificateAuthoritiesKubeconfigReissuedCondition)).To(Equal(controlplanev1.CertificateAuthoritiesRotationInProgressReason))
+	g.Expect(fakeClient.Get(ctx, util.ObjectKey(userKubeconfig), userKubeconfig)).To(Succeed())
+	g.Expect(userKubeconfig.Annotations).To(HaveKeyWithValue(controlplanev1.CertificateAuthoritiesHashAnnotation, caHash))
+	reissueUserKubeconfig()
+
+	// Once all the machines are rolled out, the new CAs sign and the kubeconfigs are reissued.
+	controlPlane = reconcile(machine)
+	expectPhase(secret.RotationSignWithNew)
+	g.Expect(conditions.IsFalse(kcp, controlplanev1.CertificateAuthoritiesControlPlaneRolledOutCondition)).To(BeTrue())
+	g.Expect(controlPlane.CertificateAuthoritiesHash).NotTo(Equal(caHash))
+	controlPlane = reconcile(machine)
+	caHash = controlPlane.CertificateAuthoritiesHash
+	expectClusterInfoCAs()
+	expectKubeconfigReissued(caHash)
+	g.Expect(conditions.IsFalse(kcp, controlplanev1.CertificateAuthoritiesKubeconfigReissuedCondition)).To(BeTrue())
+	reissueUserKubeconfig()
+	reconcile(machine)
+	g.Expect(conditions.IsTrue(kcp, controlplanev1.CertificateAuthoritiesKubeconfigReissuedCondition)).To(BeTrue())
+
+	// Controller kubeconfigs issued in the meantime are not deleted again in the same phase.
+	g.Expect(fakeClient.Create(ctx, controllerKubeconfig.DeepCopy())).To(Succeed())
+	reconcile(machine)
+	g.Expect(fakeClient.Get(ctx, util.ObjectKey(controllerKubeconfig), &corev1.Secret{})).To(Succeed())
+
+	// Once all the machines are rolled out again, the old CAs are removed when confirmed.
+	machine = newMachine("machine-2", caHash)
+	reconcile(machine)
+	rolloutMachineDeployment(caHash)
+	reissueUserKubeconfig()
+	reconcile(machine)
+	expectPhase(secret.RotationSignWithNew)
+	g.Expect(conditions.GetReason(kcp, controlplanev1.CertificateAuthoritiesOldRemovedCondition)).To(Equal(controlplanev1.CertificateAuthoritiesWaitingForRemovalConfirmationReason))
+	kcp.Annotations = map[string]string{controlplanev1.RemoveOldCertificateAuthoritiesAnnotation: ""}
+	controlPlane = reconcile(machine)
+	expectPhase(secret.RotationRemoveOld)
+	g.Expect(kcp.Annotations).NotTo(HaveKey(controlplanev1.RemoveOldCertificateAuthoritiesAnnotation))
+	caHash = controlPlane.CertificateAuthoritiesHash
+	reconcile(machine)
+	bundle, err = cert.ParseCertsPEM(getSecret(secret.ClusterCA).Data[secret.TLSCrtDataName])
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(bundle).To(HaveLen(1))
+	g.Expect(getSecret(secret.ClusterCA).Data[secret.TLSCrtDataName]).NotTo(Equal(originalCA))
+	expectClusterInfoCAs()
+	expectKubeconfigReissued(caHash)
+	g.Expect(conditions.IsFalse(kcp, controlplanev1.CertificateAuthoritiesOldRemovedCondition)).To(BeTrue())
+
+	// Once all the machines are rolled out a last time, the rotation is completed.
+	machine = newMachine("machine-3", caHash)
+	reconcile(machine)
+	rolloutMachineDeployment(caHash)
+	reissueUserKubeconfig()
+	controlPlane = reconcile(machine)
+	expectPhase("")
+	g.Expect(controlPlane.CertificateAuthoritiesHash).To(BeEmpty())
+	for _, condition := range []clusterv1.ConditionType{
+		controlplanev1.CertificateAuthoritiesTrustedCondition,
+		controlplanev1.CertificateAuthoritiesControlPlaneRolledOutCondition,
+		controlplanev1.CertificateAuthoritiesWorkersRolledOutCondition,
+		controlplanev1.CertificateAuthoritiesKubeconfigReissuedCondition,
+		controlplanev1.CertificateAuthoritiesOldRemovedCondition,
+	} {
+		g.Expect(conditions.IsTrue(kcp, condition)).To(BeTrue(), string(condition))
+	}
+}
+
+func TestKubeadmControlPlaneReconciler_syncRotationPhase(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	cluster, kcp, _ := createClusterWithControlPlane()
+	controllerRef := *metav1.NewControllerRef(kcp, controlplanev1.GroupVersion.WithKind("KubeadmControlPlane"))
+	certificates := secret.NewCertificatesForInitialControlPlane(&kubeadmv1.ClusterConfiguration{})
+	g.Expect(certificates.Generate()).To(Succeed())
+
+	cas := map[secret.Purpose]*corev1.Secret{}
+	objs := []runtime.Object{}
+	for _, c := range certificates {
+		s := c.AsSecret(util.ObjectKey(cluster), controllerRef)
+		cas[c.Purpose] = s
+		objs = append(objs, s)
+	}
+	r := &KubeadmControlPlaneReconciler{
+		Client: newFakeClient(g, objs...),
+		Log:    log.Log,
+	}
+
+	// The controller was interrupted while starting the rotation.
+	g.Expect(secret.AdvanceRotation(cas[secret.ClusterCA], secret.ClusterCA)).To(Succeed())
+	phase, err := r.syncRotationPhase(ctx, cas)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(phase).To(Equal(secret.RotationTrustNew))
+	for purpose, s := range cas {
+		g.Expect(secret.GetRotationPhase(s)).To(Equal(secret.RotationTrustNew), string(purpose))
+	}
+
+	// Secrets more than a phase apart are reported.
+	g.Expect(secret.AdvanceRotation(cas[secret.ClusterCA], secret.ClusterCA)).To(Succeed())
+	g.Expect(secret.AdvanceRotation(cas[secret.ClusterCA], secret.ClusterCA)).To(Succeed())
+	_, err = r.syncRotationPhase(ctx, cas)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestKubeadmControlPlaneReconciler_MachineDeploymentToKubeadmControlPlane(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster, kcp, _ := createClusterWithControlPlane()
+	r := &KubeadmControlPlaneReconciler{
+		Client: newFakeClient(g, cluster.DeepCopy()),
+		Log:    log.Log,
+	}
+
+	md := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: cluster.Namespace,
+			Name:      "md",
+		},
+		Spec: clusterv1.MachineDeploymentSpec{
+			ClusterName: cluster.Name,
+		},
+	}
+	g.Expect(r.MachineDeploymentToKubeadmControlPlane(handler.MapObject{Meta: md, Object: md})).To(BeEmpty())
+
+	md.Spec.Template.Annotations = map[string]string{controlplanev1.CertificateAuthoritiesHashAnnotation: "hash"}
+	requests := r.MachineDeploymentToKubeadmControlPlane(handler.MapObject{Meta: md, Object: md})
+	g.Expect(requests).To(HaveLen(1))
+	g.Expect(requests[0].NamespacedName).To(Equal(util.ObjectKey(kcp)))
+}
+
+func TestKubeadmControlPlaneReconciler_rolloutWorkers(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	g.Expect(feature.MutableGates.Set("MachinePool=true")).To(Succeed())
+	defer func() {
+		g.Expect(feature.MutableGates.Set("MachinePool=false")).To(Succeed())
+	}()
+	g.Expect(expv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	cluster, kcp, _ := createClusterWithControlPlane()
+	mp := &expv1.MachinePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: cluster.Namespace,
+			Name:      "mp",
+			Labels:    map[string]string{clusterv1.ClusterLabelName: cluster.Name},
+		},
+		Spec: expv1.MachinePoolSpec{
+			ClusterName:    cluster.Name,
+			Replicas:       pointer.Int32Ptr(2),
+			ProviderIDList: []string{"instance-0", "instance-1"},
+		},
+	}
+	newWorker := func(name string, labels map[string]string) *clusterv1.Machine {
+		machine := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: cluster.Namespace,
+				Name:      name,
+				Labels:    map[string]string{clusterv1.ClusterLabelName: cluster.Name},
+			},
+			Spec: clusterv1.MachineSpec{
+				ClusterName: cluster.Name,
+			},
+		}
+		for k, v := range labels {
+			machine.Labels[k] = v
+		}
+		return machine
+	}
+	controlPlaneMachine, _ := createMachineNodePair("control-plane", cluster, kcp, true)
+	unowned := newWorker("unowned", nil)
+	fakeClient := newFakeClient(g, cluster.DeepCopy(), mp.DeepCopy(), controlPlaneMachine, unowned.DeepCopy(),
+		newWorker("owned", map[string]string{clusterv1.MachineDeploymentLabelName: "md"}))
+	r := &KubeadmControlPlaneReconciler{
+		Client: fakeClient,
+		Log:    log.Log,
+	}
+
+	// The template of the MachinePools is changed, and the Machines not owned by a MachineDeployment block the rotation.
+	pending, err := r.rolloutWorkers(ctx, cluster, "hash")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pending).To(HaveLen(2))
+	g.Expect(pending[0]).To(Equal("1 MachinePools"))
+	g.Expect(pending[1]).To(ContainSubstring("Machines unowned, not owned by a MachineDeployment"))
+	g.Expect(fakeClient.Get(ctx, util.ObjectKey(mp), mp)).To(Succeed())
+	g.Expect(mp.Spec.Template.Annotations).To(HaveKeyWithValue(controlplanev1.CertificateAuthoritiesHashAnnotation, "hash"))
+	g.Expect(mp.Annotations).To(HaveKeyWithValue(controlplanev1.CertificateAuthoritiesOutdatedInstancesAnnotation, "instance-0,instance-1"))
+
+	// The MachinePools are rolled out once all their outdated instances are replaced.
+	mp.Spec.ProviderIDList = []string{"instance-0", "instance-2"}
+	mp.Status = expv1.MachinePoolStatus{Replicas: 2, ReadyReplicas: 2, AvailableReplicas: 2}
+	g.Expect(fakeClient.Update(ctx, mp)).To(Succeed())
+	pending, err = r.rolloutWorkers(ctx, cluster, "hash")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pending).To(HaveLen(2))
+
+	mp.Spec.ProviderIDList = []string{"instance-3", "instance-2"}
+	g.Expect(fakeClient.Update(ctx, mp)).To(Succeed())
+	pending, err = r.rolloutWorkers(ctx, cluster, "hash")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pending).To(HaveLen(1))
+
+	// The Machines not owned by a MachineDeployment are rolled out once annotated with the hash.
+	g.Expect(fakeClient.Get(ctx, util.ObjectKey(unowned), unowned)).To(Succeed())
+	unowned.Annotations = map[string]string{controlplanev1.CertificateAuthoritiesHashAnnotation: "hash"}
+	g.Expect(fakeClient.Update(ctx, unowned)).To(Succeed())
+	pending, err = r.rolloutWorkers(ctx, cluster, "hash")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pending).To(BeEmpty())
+}
+
+// failingUpdateClient is a client failing all the updates.
+type failingUpdateClient struct {
+	client.Client
+}
+
+func (c *failingUpdateClient) Update(_ context.Context, _ runtime.Object, _ ...client.UpdateOption) error {
+	return errors.New("update failed")
+}
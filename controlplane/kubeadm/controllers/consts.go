@@ -30,4 +30,7 @@ const (
 	// dependentCertRequeueAfter is how long to wait before checking again to see if
 	// dependent certificates have been created.
 	dependentCertRequeueAfter = 30 * time.Second
+
+	// etcdSnapshotTimeout is the maximum time allowed for streaming an etcd snapshot and saving it to the destination.
+	etcdSnapshotTimeout = 10 * time.Minute
 )
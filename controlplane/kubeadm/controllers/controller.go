@@ -31,6 +31,8 @@ import (
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
+	capirecord "sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/cluster-api/util/tracing"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -47,6 +49,8 @@ import (
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/hash"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/machinefilters"
 	capierrors "sigs.k8s.io/cluster-api/errors"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/feature"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
@@ -56,13 +60,15 @@ import (
 )
 
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;patch
-// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=configmaps,namespace=kube-system,verbs=get;list;watch;create
 // +kubebuilder:rbac:groups=rbac,resources=roles,namespace=kube-system,verbs=get;list;watch;create
 // +kubebuilder:rbac:groups=rbac,resources=rolebindings,namespace=kube-system,verbs=get;list;watch;create
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io;bootstrap.cluster.x-k8s.io;controlplane.cluster.x-k8s.io,resources=*,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;machines/status,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinedeployments,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=exp.cluster.x-k8s.io,resources=machinepools,verbs=get;list;watch;patch
 
 // KubeadmControlPlaneReconciler reconciles a KubeadmControlPlane object
 type KubeadmControlPlaneReconciler struct {
@@ -98,9 +104,41 @@ func (r *KubeadmControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager, optio
 		return errors.Wrap(err, "failed adding Watch for Clusters to controller manager")
 	}
 
+	err = c.Watch(
+		&source.Kind{Type: &clusterv1.MachineDeployment{}},
+		&handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.MachineDeploymentToKubeadmControlPlane),
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed adding Watch for MachineDeployments to controller manager")
+	}
+
+	err = c.Watch(
+		&source.Kind{Type: &corev1.Secret{}},
+		&handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.SecretToKubeadmControlPlane),
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed adding Watch for Secrets to controller manager")
+	}
+
+	if feature.Gates.Enabled(feature.MachinePool) {
+		err = c.Watch(
+			&source.Kind{Type: &expv1.MachinePool{}},
+			&handler.EnqueueRequestsFromMapFunc{
+				ToRequests: handler.ToRequestsFunc(r.MachinePoolToKubeadmControlPlane),
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed adding Watch for MachinePools to controller manager")
+		}
+	}
+
 	r.scheme = mgr.GetScheme()
 	r.controller = c
-	r.recorder = mgr.GetEventRecorderFor("kubeadm-control-plane-controller")
+	r.recorder = capirecord.NewRateLimitedRecorder(mgr.GetEventRecorderFor("kubeadm-control-plane-controller"), capirecord.DefaultDuplicateEventsInterval)
 
 	if r.managementCluster == nil {
 		r.managementCluster = &internal.Management{Client: r.Client}
@@ -114,7 +152,8 @@ func (r *KubeadmControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager, optio
 
 func (r *KubeadmControlPlaneReconciler) Reconcile(req ctrl.Request) (res ctrl.Result, reterr error) {
 	logger := r.Log.WithValues("namespace", req.Namespace, "kubeadmControlPlane", req.Name)
-	ctx := context.Background()
+	ctx, span := tracing.StartSpan(context.Background(), "KubeadmControlPlaneReconciler.Reconcile", tracing.ObjectAttributes("KubeadmControlPlane", req.Namespace, req.Name)...)
+	defer func() { tracing.EndSpan(span, reterr) }()
 
 	// Fetch the KubeadmControlPlane instance.
 	kcp := &controlplanev1.KubeadmControlPlane{}
@@ -279,10 +318,35 @@ func (r *KubeadmControlPlaneReconciler) reconcile(ctx context.Context, cluster *
 
 	controlPlane := internal.NewControlPlane(cluster, kcp, ownedMachines)
 
+	// Propagate the in-place mutable fields from the machine template to the existing control plane machines.
+	if err := r.syncMachines(ctx, controlPlane); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to sync control plane machines")
+	}
+
 	// Aggregate the operational state of all the machines; while aggregating we are adding the
 	// source ref (reason@machine/name) so the problem can be easily tracked down to its source machine.
 	conditions.SetAggregate(controlPlane.KCP, controlplanev1.MachinesReadyCondition, ownedMachines.ConditionGetters(), conditions.AddSourceRef())
 
+	// Rotate the certificate authorities, if requested; this sets the certificate authorities the control plane
+	// machines have to be rolled out with.
+	if err := r.reconcileCertificateAuthoritiesRotation(ctx, controlPlane); err != nil {
+		logger.Error(err, "failed to reconcile the rotation of the certificate authorities")
+		return ctrl.Result{}, err
+	}
+
+	// Record the certificates expiry date for the control plane machines, if required by the rollout criteria.
+	// NOTE: errors are not blocking, given that the workload cluster might not be reachable yet.
+	if err := r.reconcileCertificateExpiries(ctx, controlPlane); err != nil {
+		logger.Error(err, "failed to reconcile certificate expiries for control plane machines")
+	}
+
+	// Report the health of the external etcd cluster, if any; this is not blocking, given that the external etcd
+	// cluster is not managed by the control plane.
+	// NOTE: this is done before rollout and scale operations, so the health is reported once per reconcile.
+	if !controlPlane.IsEtcdManaged() {
+		r.reconcileExternalEtcdHealth(ctx, cluster, controlPlane)
+	}
+
 	// Control plane machines rollout due to configuration changes (e.g. upgrades) takes precedence over other operations.
 	needRollout := controlPlane.MachinesNeedingRollout()
 	switch {
@@ -387,13 +451,62 @@ func (r *KubeadmControlPlaneReconciler) reconcileDelete(ctx context.Context, clu
 	}
 	if len(errs) > 0 {
 		err := kerrors.NewAggregate(errs)
-		r.recorder.Eventf(kcp, corev1.EventTypeWarning, "FailedDelete",
-			"Failed to delete control plane Machines for cluster %s/%s control plane: %v", cluster.Namespace, cluster.Name, err)
+		capirecord.FailedDelete.Emit(r.recorder, kcp, "control plane Machines of Cluster", util.ObjectKey(cluster).String(), err)
 		return ctrl.Result{}, err
 	}
 	return ctrl.Result{}, &capierrors.RequeueAfterError{RequeueAfter: deleteRequeueAfter}
 }
 
+// reconcileCertificateExpiries records the expiry date of the certificates of the control plane machines in the
+// MachineCertificatesExpiryDateAnnotation, so machines with certificates expiring soon can be rolled out.
+// NOTE: the expiry date is read only once for each machine, given that certificates are not renewed in place.
+func (r *KubeadmControlPlaneReconciler) reconcileCertificateExpiries(ctx context.Context, controlPlane *internal.ControlPlane) error {
+	// Return if there are no rollout criteria based on certificate expiry.
+	if controlPlane.KCP.Spec.RolloutBefore == nil || controlPlane.KCP.Spec.RolloutBefore.CertificatesExpiryDays == nil {
+		return nil
+	}
+
+	machines := controlPlane.Machines.Filter(
+		machinefilters.Not(machinefilters.HasAnnotationKey(controlplanev1.MachineCertificatesExpiryDateAnnotation)),
+		machinefilters.Not(machinefilters.HasDeletionTimestamp),
+	)
+	if len(machines) == 0 {
+		return nil
+	}
+
+	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, util.ObjectKey(controlPlane.Cluster))
+	if err != nil {
+		return errors.Wrap(err, "failed to create remote cluster client")
+	}
+
+	for _, m := range machines {
+		// Machines without a node are not yet ready for probing certificates.
+		if m.Status.NodeRef == nil {
+			continue
+		}
+
+		expiry, err := workloadCluster.GetCertificatesExpiry(ctx, m.Status.NodeRef.Name, controlPlane.APIServerBindPort())
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the certificates expiry date for machine %s", m.Name)
+		}
+
+		patchHelper, err := patch.NewHelper(m, r.Client)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create patch helper for machine %s", m.Name)
+		}
+		annotations := m.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[controlplanev1.MachineCertificatesExpiryDateAnnotation] = expiry.UTC().Format(time.RFC3339)
+		m.SetAnnotations(annotations)
+		if err := patchHelper.Patch(ctx, m); err != nil {
+			return errors.Wrapf(err, "failed to patch machine %s", m.Name)
+		}
+	}
+	return nil
+}
+
 // ClusterToKubeadmControlPlane is a handler.ToRequestsFunc to be used to enqueue requests for reconciliation
 // for KubeadmControlPlane based on updates to a Cluster.
 func (r *KubeadmControlPlaneReconciler) ClusterToKubeadmControlPlane(o handler.MapObject) []ctrl.Request {
@@ -420,27 +533,31 @@ func (r *KubeadmControlPlaneReconciler) reconcileHealth(ctx context.Context, clu
 	// Do a health check of the Control Plane components
 	if err := r.managementCluster.TargetClusterControlPlaneIsHealthy(ctx, util.ObjectKey(cluster)); err != nil {
 		logger.V(2).Info("Waiting for control plane to pass control plane health check to continue reconciliation", "cause", err)
-		r.recorder.Eventf(kcp, corev1.EventTypeWarning, "ControlPlaneUnhealthy",
-			"Waiting for control plane to pass control plane health check to continue reconciliation: %v", err)
+		capirecord.ControlPlaneUnhealthy.Emit(r.recorder, kcp, "control plane", "continuing reconciliation", err)
 		return &capierrors.RequeueAfterError{RequeueAfter: healthCheckFailedRequeueAfter}
 	}
 
-	// Ensure etcd is healthy
-	if err := r.managementCluster.TargetClusterEtcdIsHealthy(ctx, util.ObjectKey(cluster)); err != nil {
-		// If there are any etcd members that do not have corresponding nodes, remove them from etcd and from the kubeadm configmap.
-		// This will solve issues related to manual control-plane machine deletion.
-		workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, util.ObjectKey(cluster))
-		if err != nil {
-			return err
-		}
-		if err := workloadCluster.ReconcileEtcdMembers(ctx); err != nil {
-			logger.V(2).Info("Failed attempt to remove potential hanging etcd members to pass etcd health check to continue reconciliation", "cause", err)
-		}
+	// Ensure etcd is healthy.
+	// NOTE: external etcd clusters are not managed by the control plane, so their health is only reported by reconcile
+	// and it does not block reconciliation.
+	if controlPlane.IsEtcdManaged() {
+		if err := r.managementCluster.TargetClusterEtcdIsHealthy(ctx, util.ObjectKey(cluster)); err != nil {
+			conditions.MarkFalse(kcp, controlplanev1.EtcdClusterHealthyCondition, controlplanev1.EtcdClusterUnhealthyReason, clusterv1.ConditionSeverityWarning, err.Error())
+			// If there are any etcd members that do not have corresponding nodes, remove them from etcd and from the kubeadm configmap.
+			// This will solve issues related to manual control-plane machine deletion.
+			workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, util.ObjectKey(cluster))
+			if err != nil {
+				return err
+			}
+			if err := workloadCluster.ReconcileEtcdMembers(ctx); err != nil {
+				logger.V(2).Info("Failed attempt to remove potential hanging etcd members to pass etcd health check to continue reconciliation", "cause", err)
+			}
 
-		logger.V(2).Info("Waiting for control plane to pass etcd health check to continue reconciliation", "cause", err)
-		r.recorder.Eventf(kcp, corev1.EventTypeWarning, "ControlPlaneUnhealthy",
-			"Waiting for control plane to pass etcd health check to continue reconciliation: %v", err)
-		return &capierrors.RequeueAfterError{RequeueAfter: healthCheckFailedRequeueAfter}
+			logger.V(2).Info("Waiting for control plane to pass etcd health check to continue reconciliation", "cause", err)
+			capirecord.ControlPlaneUnhealthy.Emit(r.recorder, kcp, "etcd", "continuing reconciliation", err)
+			return &capierrors.RequeueAfterError{RequeueAfter: healthCheckFailedRequeueAfter}
+		}
+		conditions.MarkTrue(kcp, controlplanev1.EtcdClusterHealthyCondition)
 	}
 
 	// We need this check for scale up as well as down to avoid scaling up when there is a machine being deleted.
@@ -453,6 +570,19 @@ func (r *KubeadmControlPlaneReconciler) reconcileHealth(ctx context.Context, clu
 	return nil
 }
 
+// reconcileExternalEtcdHealth performs health checks for the external etcd cluster used by the control plane,
+// reporting the result in the EtcdClusterHealthy condition.
+func (r *KubeadmControlPlaneReconciler) reconcileExternalEtcdHealth(ctx context.Context, cluster *clusterv1.Cluster, controlPlane *internal.ControlPlane) {
+	logger := controlPlane.Logger()
+
+	if err := r.managementCluster.TargetClusterExternalEtcdIsHealthy(ctx, util.ObjectKey(cluster), controlPlane.ExternalEtcdEndpoints()); err != nil {
+		logger.V(2).Info("External etcd cluster is not passing the health check", "cause", err)
+		conditions.MarkFalse(controlPlane.KCP, controlplanev1.EtcdClusterHealthyCondition, controlplanev1.EtcdClusterUnhealthyReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return
+	}
+	conditions.MarkTrue(controlPlane.KCP, controlplanev1.EtcdClusterHealthyCondition)
+}
+
 func (r *KubeadmControlPlaneReconciler) adoptMachines(ctx context.Context, kcp *controlplanev1.KubeadmControlPlane, machines internal.FilterableMachineCollection, cluster *clusterv1.Cluster) error {
 	// We do an uncached full quorum read against the KCP to avoid re-adopting Machines the garbage collector just intentionally orphaned
 	// See https://github.com/kubernetes/kubernetes/issues/42639
@@ -494,7 +624,8 @@ func (r *KubeadmControlPlaneReconciler) adoptMachines(ctx context.Context, kcp *
 		}
 
 		if !util.IsSupportedVersionSkew(kcpVersion, machineVersion) {
-			r.recorder.Eventf(kcp, corev1.EventTypeWarning, "AdoptionFailed", "Could not adopt Machine %s/%s: its version (%q) is outside supported +/- one minor version skew from KCP's (%q)", m.Namespace, m.Name, *m.Spec.Version, kcp.Spec.Version)
+			capirecord.FailedAdopt.Emit(r.recorder, kcp, "Machine", util.ObjectKey(m).String(),
+				errors.Errorf("its version (%q) is outside supported +/- one minor version skew from KCP's (%q)", *m.Spec.Version, kcp.Spec.Version))
 			// avoid returning an error here so we don't cause the KCP controller to spin until the operator clarifies their intent
 			return nil
 		}
@@ -594,7 +594,7 @@ func TestKubeadmControlPlaneReconciler_adoption(t *testing.T) {
 		}
 
 		g.Expect(r.reconcile(context.Background(), cluster, kcp)).To(Equal(ctrl.Result{}))
-		// Message: Warning AdoptionFailed Could not adopt Machine test/test0: its version ("v1.15.0") is outside supported +/- one minor version skew from KCP's ("v1.17.0")
+		// Message: Warning FailedAdopt Failed to adopt Machine "test/test0": its version ("v1.15.0") is outside supported +/- one minor version skew from KCP's ("v1.17.0")
 		g.Expect(recorder.Events).To(Receive(ContainSubstring("minor version")))
 
 		machineList := &clusterv1.MachineList{}
@@ -1189,6 +1189,58 @@ func TestKubeadmControlPlaneReconciler_reconcileDelete(t *testing.T) {
 
 // test utils
 
+func TestKubeadmControlPlaneReconciler_reconcileCertificateExpiries(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster, kcp, _ := createClusterWithControlPlane()
+	kcp.Spec.RolloutBefore = &controlplanev1.RolloutBefore{
+		CertificatesExpiryDays: pointer.Int32Ptr(21),
+	}
+
+	expiry := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// machine without the certificates expiry annotation
+	m1, _ := createMachineNodePair("machine-1", cluster, kcp, true)
+
+	// machine with the certificates expiry annotation already set
+	m2, _ := createMachineNodePair("machine-2", cluster, kcp, true)
+	m2.SetAnnotations(map[string]string{controlplanev1.MachineCertificatesExpiryDateAnnotation: "2020-01-01T00:00:00Z"})
+
+	// machine without a node
+	m3, _ := createMachineNodePair("machine-3", cluster, kcp, true)
+	m3.Status.NodeRef = nil
+
+	fakeClient := newFakeClient(g, cluster.DeepCopy(), kcp.DeepCopy(), m1.DeepCopy(), m2.DeepCopy(), m3.DeepCopy())
+
+	r := &KubeadmControlPlaneReconciler{
+		Client: fakeClient,
+		Log:    log.Log,
+		managementCluster: &fakeManagementCluster{
+			Workload: fakeWorkloadCluster{
+				CertificatesExpiry: expiry,
+			},
+		},
+	}
+
+	controlPlane := internal.NewControlPlane(cluster, kcp, internal.NewFilterableMachineCollection(m1, m2, m3))
+	g.Expect(r.reconcileCertificateExpiries(context.Background(), controlPlane)).To(Succeed())
+
+	machine1 := &clusterv1.Machine{}
+	g.Expect(fakeClient.Get(context.Background(), util.ObjectKey(m1), machine1)).To(Succeed())
+	g.Expect(machine1.Annotations).To(HaveKeyWithValue(controlplanev1.MachineCertificatesExpiryDateAnnotation, "2021-01-01T00:00:00Z"))
+
+	machine2 := &clusterv1.Machine{}
+	g.Expect(fakeClient.Get(context.Background(), util.ObjectKey(m2), machine2)).To(Succeed())
+	g.Expect(machine2.Annotations).To(HaveKeyWithValue(controlplanev1.MachineCertificatesExpiryDateAnnotation, "2020-01-01T00:00:00Z"))
+
+	machine3 := &clusterv1.Machine{}
+	g.Expect(fakeClient.Get(context.Background(), util.ObjectKey(m3), machine3)).To(Succeed())
+	g.Expect(machine3.Annotations).NotTo(HaveKey(controlplanev1.MachineCertificatesExpiryDateAnnotation))
+
+	// the machine with certificates expired must be rolled out
+	g.Expect(controlPlane.MachinesNeedingRollout()).To(ConsistOf(m1, m2))
+}
+
 func newFakeClient(g *WithT, initObjs ...runtime.Object) client.Client {
 	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
 	g.Expect(bootstrapv1.AddToScheme(scheme.Scheme)).To(Succeed())
@@ -1323,3 +1375,41 @@ func newCluster(namespacedName *types.NamespacedName) *clusterv1.Cluster {
 		},
 	}
 }
+
+func TestKubeadmControlPlaneReconciler_reconcileExternalEtcdHealth(t *testing.T) {
+	tests := []struct {
+		name        string
+		etcdHealthy bool
+	}{
+		{
+			name:        "marks the etcd cluster healthy if the external etcd health check passes",
+			etcdHealthy: true,
+		},
+		{
+			name:        "marks the etcd cluster unhealthy if the external etcd health check fails",
+			etcdHealthy: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster, kcp, _ := createClusterWithControlPlane()
+			kcp.Spec.KubeadmConfigSpec.ClusterConfiguration = &kubeadmv1.ClusterConfiguration{
+				Etcd: kubeadmv1.Etcd{External: &kubeadmv1.ExternalEtcd{Endpoints: []string{"https://etcd:2379"}}},
+			}
+
+			r := &KubeadmControlPlaneReconciler{
+				Log:               log.Log,
+				managementCluster: &fakeManagementCluster{EtcdHealthy: tt.etcdHealthy},
+			}
+			controlPlane := &internal.ControlPlane{
+				KCP:     kcp,
+				Cluster: cluster,
+			}
+
+			r.reconcileExternalEtcdHealth(context.Background(), cluster, controlPlane)
+			g.Expect(conditions.IsTrue(kcp, controlplanev1.EtcdClusterHealthyCondition)).To(Equal(tt.etcdHealthy))
+		})
+	}
+}
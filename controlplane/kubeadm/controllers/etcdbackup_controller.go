@@ -0,0 +1,367 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/cron"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/etcdbackup"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	capirecord "sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/cluster-api/util/tracing"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// DefaultEtcdBackupsDir is the directory where the PersistentVolumeClaims used as destination for etcd
+// snapshots are mounted into the controller manager, each one in a sub directory named as the claim.
+const DefaultEtcdBackupsDir = "/var/lib/etcd-backups"
+
+// +kubebuilder:rbac:groups=controlplane.cluster.x-k8s.io,resources=etcdbackups;etcdbackups/status,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;patch
+
+// EtcdBackupReconciler reconciles an EtcdBackup object, taking snapshots of the etcd of the target Cluster
+// according to the schedule.
+type EtcdBackupReconciler struct {
+	Client client.Client
+	Log    logr.Logger
+
+	// BackupsDir is the directory where the PersistentVolumeClaims used as destination are mounted.
+	BackupsDir string
+
+	recorder          record.EventRecorder
+	managementCluster internal.ManagementCluster
+
+	// now returns the current time; it can be overridden for testing.
+	now func() time.Time
+}
+
+func (r *EtcdBackupReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
+	c, err := ctrl.NewControllerManagedBy(mgr).
+		For(&controlplanev1.EtcdBackup{}).
+		WithOptions(options).
+		WithEventFilter(predicates.ResourceNotPaused(r.Log)).
+		Build(r)
+	if err != nil {
+		return errors.Wrap(err, "failed setting up with a controller manager")
+	}
+
+	err = c.Watch(
+		&source.Kind{Type: &clusterv1.Cluster{}},
+		&handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.ClusterToEtcdBackups),
+		},
+		predicates.ClusterUnpaused(r.Log),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed adding Watch for Clusters to controller manager")
+	}
+
+	r.recorder = capirecord.NewRateLimitedRecorder(mgr.GetEventRecorderFor("etcd-backup-controller"), capirecord.DefaultDuplicateEventsInterval)
+
+	if r.managementCluster == nil {
+		r.managementCluster = &internal.Management{Client: r.Client}
+	}
+	if r.BackupsDir == "" {
+		r.BackupsDir = DefaultEtcdBackupsDir
+	}
+	if r.now == nil {
+		r.now = time.Now
+	}
+
+	return nil
+}
+
+// ClusterToEtcdBackups is a handler.ToRequestsFunc to be used to enqueue requests for reconciliation
+// for the EtcdBackups targeting a Cluster.
+func (r *EtcdBackupReconciler) ClusterToEtcdBackups(o handler.MapObject) []ctrl.Request {
+	c, ok := o.Object.(*clusterv1.Cluster)
+	if !ok {
+		r.Log.Error(nil, fmt.Sprintf("Expected a Cluster but got a %T", o.Object))
+		return nil
+	}
+
+	backups := &controlplanev1.EtcdBackupList{}
+	if err := r.Client.List(context.Background(), backups, client.InNamespace(c.Namespace)); err != nil {
+		r.Log.Error(err, "Failed to list EtcdBackups", "namespace", c.Namespace)
+		return nil
+	}
+
+	var requests []ctrl.Request
+	for _, b := range backups.Items {
+		if b.Spec.ClusterName == c.Name {
+			requests = append(requests, ctrl.Request{NamespacedName: util.ObjectKey(&b)})
+		}
+	}
+	return requests
+}
+
+func (r *EtcdBackupReconciler) Reconcile(req ctrl.Request) (res ctrl.Result, reterr error) {
+	logger := r.Log.WithValues("namespace", req.Namespace, "etcdBackup", req.Name)
+	ctx, span := tracing.StartSpan(context.Background(), "EtcdBackupReconciler.Reconcile", tracing.ObjectAttributes("EtcdBackup", req.Namespace, req.Name)...)
+	defer func() { tracing.EndSpan(span, reterr) }()
+
+	// Fetch the EtcdBackup instance.
+	backup := &controlplanev1.EtcdBackup{}
+	if err := r.Client.Get(ctx, req.NamespacedName, backup); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Fetch the target Cluster.
+	cluster, err := util.GetClusterByName(ctx, r.Client, backup.Namespace, backup.Spec.ClusterName)
+	if err != nil {
+		logger.Error(err, "Failed to retrieve the target Cluster from the API Server")
+		return ctrl.Result{}, err
+	}
+	logger = logger.WithValues("cluster", cluster.Name)
+
+	if annotations.IsPaused(cluster, backup) {
+		logger.Info("Reconciliation is paused for this object")
+		return ctrl.Result{}, nil
+	}
+
+	// Initialize the patch helper.
+	patchHelper, err := patch.NewHelper(backup, r.Client)
+	if err != nil {
+		logger.Error(err, "Failed to configure the patch helper")
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	defer func() {
+		// Always attempt to Patch the EtcdBackup object and status after each reconciliation.
+		if err := patchHelper.Patch(ctx, backup); err != nil {
+			logger.Error(err, "Failed to patch EtcdBackup")
+			reterr = kerrors.NewAggregate([]error{reterr, err})
+		}
+	}()
+
+	return r.reconcile(ctx, logger, cluster, backup)
+}
+
+func (r *EtcdBackupReconciler) reconcile(ctx context.Context, logger logr.Logger, cluster *clusterv1.Cluster, backup *controlplanev1.EtcdBackup) (ctrl.Result, error) {
+	if backup.Spec.Suspend {
+		logger.V(2).Info("Taking snapshots is suspended")
+		return ctrl.Result{}, nil
+	}
+
+	schedule, err := cron.Parse(backup.Spec.Schedule)
+	if err != nil {
+		conditions.MarkFalse(backup, controlplanev1.EtcdSnapshotSucceededCondition, controlplanev1.EtcdSnapshotFailedReason, clusterv1.ConditionSeverityError, "Invalid schedule: %v", err)
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.checkClusterIsSupported(ctx, cluster); err != nil {
+		conditions.MarkFalse(backup, controlplanev1.EtcdSnapshotSucceededCondition, controlplanev1.EtcdBackupNotSupportedReason, clusterv1.ConditionSeverityError, err.Error())
+		return ctrl.Result{}, nil
+	}
+
+	// Wait for the control plane to be initialized; the Cluster watch triggers a new reconciliation when it happens.
+	if !cluster.Status.ControlPlaneInitialized {
+		conditions.MarkFalse(backup, controlplanev1.EtcdSnapshotSucceededCondition, controlplanev1.WaitingForControlPlaneInitializedReason, clusterv1.ConditionSeverityInfo, "")
+		return ctrl.Result{}, nil
+	}
+
+	now := r.now()
+	lastScheduleTime := backup.CreationTimestamp.Time
+	if backup.Status.LastScheduleTime != nil {
+		lastScheduleTime = backup.Status.LastScheduleTime.Time
+	}
+	next := schedule.Next(lastScheduleTime)
+	if next.IsZero() {
+		conditions.MarkFalse(backup, controlplanev1.EtcdSnapshotSucceededCondition, controlplanev1.EtcdSnapshotFailedReason, clusterv1.ConditionSeverityError, "The schedule %q never matches", backup.Spec.Schedule)
+		return ctrl.Result{}, nil
+	}
+	if now.Before(next) {
+		return ctrl.Result{RequeueAfter: next.Sub(now)}, nil
+	}
+
+	// NOTE: if more than one scheduled time was missed, e.g. because the controller was not running, a single
+	// snapshot is taken.
+	backup.Status.LastScheduleTime = &metav1.Time{Time: now}
+	logger.Info("Taking etcd snapshot")
+	if err := r.takeSnapshot(ctx, logger, cluster, backup, now); err != nil {
+		logger.Error(err, "Failed to take etcd snapshot")
+		capirecord.FailedSnapshot.Emit(r.recorder, backup, cluster.Namespace, cluster.Name, err)
+		conditions.MarkFalse(backup, controlplanev1.EtcdSnapshotSucceededCondition, controlplanev1.EtcdSnapshotFailedReason, clusterv1.ConditionSeverityError, err.Error())
+	} else {
+		capirecord.SuccessfulSnapshot.Emit(r.recorder, backup, cluster.Namespace, cluster.Name, backup.Status.LastSnapshotSize)
+		conditions.MarkTrue(backup, controlplanev1.EtcdSnapshotSucceededCondition)
+	}
+
+	// Requeue for the next scheduled snapshot; failed snapshots are retried at the next scheduled time too.
+	if next := schedule.Next(now); !next.IsZero() {
+		return ctrl.Result{RequeueAfter: next.Sub(now)}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// checkClusterIsSupported checks the Cluster's control plane is a KubeadmControlPlane running a stacked etcd,
+// which is the only configuration where the etcd leader can be reached through the control plane nodes.
+func (r *EtcdBackupReconciler) checkClusterIsSupported(ctx context.Context, cluster *clusterv1.Cluster) error {
+	ref := cluster.Spec.ControlPlaneRef
+	if ref == nil || ref.Kind != "KubeadmControlPlane" {
+		return errors.Errorf("the control plane of cluster %s/%s is not a KubeadmControlPlane", cluster.Namespace, cluster.Name)
+	}
+
+	kcp := &controlplanev1.KubeadmControlPlane{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, kcp); err != nil {
+		return errors.Wrapf(err, "failed to get the control plane of cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+	controlPlane := internal.NewControlPlane(cluster, kcp, nil)
+	if !controlPlane.IsEtcdManaged() {
+		return errors.Errorf("cluster %s/%s is using an external etcd", cluster.Namespace, cluster.Name)
+	}
+	return nil
+}
+
+// takeSnapshot streams a snapshot from the etcd leader into a temporary file, saves it to the destination and
+// deletes the snapshots exceeding the retention.
+func (r *EtcdBackupReconciler) takeSnapshot(ctx context.Context, logger logr.Logger, cluster *clusterv1.Cluster, backup *controlplanev1.EtcdBackup, now time.Time) error {
+	store, err := r.getStore(ctx, backup)
+	if err != nil {
+		return err
+	}
+
+	workloadCluster, err := r.managementCluster.GetWorkloadCluster(ctx, util.ObjectKey(cluster))
+	if err != nil {
+		return errors.Wrap(err, "failed to create client to workload cluster")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, etcdSnapshotTimeout)
+	defer cancel()
+
+	snapshot, err := workloadCluster.EtcdSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+	defer snapshot.Close()
+
+	f, err := ioutil.TempFile("", "etcd-snapshot-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary file for the etcd snapshot")
+	}
+	defer os.Remove(f.Name())
+
+	size, err := io.Copy(f, snapshot)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to read etcd snapshot")
+	}
+	if size == 0 {
+		return errors.New("etcd returned an empty snapshot")
+	}
+
+	name := fmt.Sprintf("%s-%s.db", cluster.Name, now.UTC().Format("20060102-150405"))
+	location, err := store.Save(ctx, name, f.Name())
+	if err != nil {
+		return err
+	}
+
+	backup.Status.Snapshots = append(backup.Status.Snapshots, controlplanev1.EtcdSnapshot{
+		Name:     name,
+		Location: location,
+		Size:     size,
+		Time:     metav1.Time{Time: now},
+	})
+	backup.Status.LastSuccessfulTime = &metav1.Time{Time: now}
+	backup.Status.LastSnapshotSize = size
+
+	// Delete the oldest snapshots exceeding the retention; if deletion fails, the snapshot is kept in the list
+	// so deletion is retried after the next snapshot.
+	retention := controlplanev1.DefaultEtcdBackupRetention
+	if backup.Spec.Retention != nil {
+		retention = int(*backup.Spec.Retention)
+	}
+	for len(backup.Status.Snapshots) > retention {
+		oldest := backup.Status.Snapshots[0]
+		if err := store.Delete(ctx, oldest.Name); err != nil {
+			logger.Error(err, "Failed to delete etcd snapshot exceeding the retention", "snapshot", oldest.Name)
+			break
+		}
+		backup.Status.Snapshots = backup.Status.Snapshots[1:]
+	}
+	return nil
+}
+
+// getStore returns the Store for the EtcdBackup destination.
+func (r *EtcdBackupReconciler) getStore(ctx context.Context, backup *controlplanev1.EtcdBackup) (etcdbackup.Store, error) {
+	destination := backup.Spec.Destination
+	switch {
+	case destination.PersistentVolumeClaim != nil:
+		// The claim is mounted in a directory of the namespace of the EtcdBackup, so EtcdBackups of different
+		// namespaces never share their snapshots.
+		claimName := destination.PersistentVolumeClaim.ClaimName
+		claimKey := client.ObjectKey{Namespace: backup.Namespace, Name: claimName}
+		if err := r.Client.Get(ctx, claimKey, &corev1.PersistentVolumeClaim{}); err != nil {
+			return nil, errors.Wrapf(err, "failed to get PersistentVolumeClaim %s", claimKey)
+		}
+
+		// Make sure the volume is mounted, otherwise snapshots are written to the controller manager's file system.
+		volumeDir := filepath.Join(r.BackupsDir, backup.Namespace, claimName)
+		if _, err := os.Stat(volumeDir); err != nil {
+			return nil, errors.Wrapf(err, "PersistentVolumeClaim %s is not mounted into the controller manager at %s", destination.PersistentVolumeClaim.ClaimName, volumeDir)
+		}
+		return etcdbackup.NewLocalStore(filepath.Join(volumeDir, destination.PersistentVolumeClaim.Path)), nil
+	case destination.S3 != nil:
+		credentials := &corev1.Secret{}
+		key := client.ObjectKey{Namespace: backup.Namespace, Name: destination.S3.CredentialsSecret.Name}
+		if err := r.Client.Get(ctx, key, credentials); err != nil {
+			return nil, errors.Wrapf(err, "failed to get S3 credentials secret %s", key)
+		}
+		return etcdbackup.NewS3Store(etcdbackup.S3Config{
+			Endpoint:              destination.S3.Endpoint,
+			Bucket:                destination.S3.Bucket,
+			Prefix:                destination.S3.Prefix,
+			Region:                destination.S3.Region,
+			AccessKeyID:           string(credentials.Data[controlplanev1.EtcdBackupS3AccessKeyIDKey]),
+			SecretAccessKey:       string(credentials.Data[controlplanev1.EtcdBackupS3SecretAccessKeyKey]),
+			InsecureSkipTLSVerify: destination.S3.InsecureSkipTLSVerify,
+		})
+	}
+	return nil, errors.New("no destination defined")
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/klogr"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+func TestEtcdBackupReconciler_reconcile(t *testing.T) {
+	creationTime := time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	setup := func(t *testing.T, g *WithT, etcdManaged bool, controlPlaneInitialized bool) (*EtcdBackupReconciler, *clusterv1.Cluster, *controlplanev1.EtcdBackup, string) {
+		t.Helper()
+
+		cluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "foo"},
+			Spec: clusterv1.ClusterSpec{
+				ControlPlaneRef: &corev1.ObjectReference{
+					Kind:       "KubeadmControlPlane",
+					Namespace:  "test",
+					Name:       "foo",
+					APIVersion: controlplanev1.GroupVersion.String(),
+				},
+			},
+			Status: clusterv1.ClusterStatus{ControlPlaneInitialized: controlPlaneInitialized},
+		}
+		kcp := &controlplanev1.KubeadmControlPlane{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "foo"},
+			Spec:       controlplanev1.KubeadmControlPlaneSpec{Version: "v1.16.6"},
+		}
+		if !etcdManaged {
+			kcp.Spec.KubeadmConfigSpec.ClusterConfiguration = &kubeadmv1.ClusterConfiguration{
+				Etcd: kubeadmv1.Etcd{External: &kubeadmv1.ExternalEtcd{Endpoints: []string{"https://etcd:2379"}}},
+			}
+		}
+		backup := &controlplanev1.EtcdBackup{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "test",
+				Name:              "foo-backup",
+				CreationTimestamp: metav1.Time{Time: creationTime},
+			},
+			Spec: controlplanev1.EtcdBackupSpec{
+				ClusterName: "foo",
+				Schedule:    "0 * * * *",
+				Retention:   pointer.Int32Ptr(2),
+				Destination: controlplanev1.EtcdBackupDestination{
+					PersistentVolumeClaim: &controlplanev1.EtcdBackupPersistentVolumeClaim{ClaimName: "backups", Path: "foo"},
+				},
+			},
+		}
+
+		dir, err := ioutil.TempDir("", "etcd-backups")
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(os.MkdirAll(filepath.Join(dir, "test", "backups"), 0700)).To(Succeed())
+		claim := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "backups"}}
+
+		fakeClient := newFakeClient(g, cluster.DeepCopy(), kcp.DeepCopy(), backup.DeepCopy(), claim)
+		r := &EtcdBackupReconciler{
+			Client:     fakeClient,
+			Log:        klogr.New(),
+			BackupsDir: dir,
+			recorder:   record.NewFakeRecorder(32),
+			managementCluster: &fakeManagementCluster{
+				Workload: fakeWorkloadCluster{Snapshot: []byte("snapshot data")},
+			},
+		}
+		return r, cluster, backup, dir
+	}
+
+	t.Run("waits for the next scheduled time", func(t *testing.T) {
+		g := NewWithT(t)
+		r, cluster, backup, dir := setup(t, g, true, true)
+		defer os.RemoveAll(dir)
+		r.now = func() time.Time { return creationTime.Add(10 * time.Minute) }
+
+		result, err := r.reconcile(context.Background(), r.Log, cluster, backup)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result.RequeueAfter).To(Equal(50 * time.Minute))
+		g.Expect(backup.Status.LastScheduleTime).To(BeNil())
+		g.Expect(backup.Status.Snapshots).To(BeEmpty())
+	})
+
+	t.Run("takes snapshots and enforces the retention", func(t *testing.T) {
+		g := NewWithT(t)
+		r, cluster, backup, dir := setup(t, g, true, true)
+		defer os.RemoveAll(dir)
+
+		for i := 0; i < 3; i++ {
+			now := creationTime.Add(time.Duration(i+1) * time.Hour)
+			r.now = func() time.Time { return now }
+
+			result, err := r.reconcile(context.Background(), r.Log, cluster, backup)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(result.RequeueAfter).To(Equal(time.Hour))
+			g.Expect(conditions.IsTrue(backup, controlplanev1.EtcdSnapshotSucceededCondition)).To(BeTrue())
+			g.Expect(backup.Status.LastScheduleTime.Time).To(Equal(now))
+			g.Expect(backup.Status.LastSuccessfulTime.Time).To(Equal(now))
+			g.Expect(backup.Status.LastSnapshotSize).To(Equal(int64(len("snapshot data"))))
+		}
+
+		g.Expect(backup.Status.Snapshots).To(HaveLen(2))
+		g.Expect(backup.Status.Snapshots[0].Name).To(Equal("foo-20200601-120000.db"))
+		g.Expect(backup.Status.Snapshots[1].Name).To(Equal("foo-20200601-130000.db"))
+
+		files, err := ioutil.ReadDir(filepath.Join(dir, "test", "backups", "foo"))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(files).To(HaveLen(2))
+		for i, f := range files {
+			g.Expect(f.Name()).To(Equal(backup.Status.Snapshots[i].Name))
+		}
+	})
+
+	t.Run("reports a failure when the volume is not mounted", func(t *testing.T) {
+		g := NewWithT(t)
+		r, cluster, backup, dir := setup(t, g, true, true)
+		defer os.RemoveAll(dir)
+		r.now = func() time.Time { return creationTime.Add(time.Hour) }
+		backup.Spec.Destination.PersistentVolumeClaim.ClaimName = "not-mounted"
+
+		result, err := r.reconcile(context.Background(), r.Log, cluster, backup)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result.RequeueAfter).To(Equal(time.Hour))
+		g.Expect(conditions.IsFalse(backup, controlplanev1.EtcdSnapshotSucceededCondition)).To(BeTrue())
+		g.Expect(conditions.GetReason(backup, controlplanev1.EtcdSnapshotSucceededCondition)).To(Equal(controlplanev1.EtcdSnapshotFailedReason))
+		g.Expect(backup.Status.LastScheduleTime).NotTo(BeNil())
+		g.Expect(backup.Status.LastSuccessfulTime).To(BeNil())
+	})
+
+	t.Run("reports a failure when the claim is not in the namespace of the EtcdBackup", func(t *testing.T) {
+		g := NewWithT(t)
+		r, cluster, backup, dir := setup(t, g, true, true)
+		defer os.RemoveAll(dir)
+		r.now = func() time.Time { return creationTime.Add(time.Hour) }
+		// The claim of another namespace is mounted, but can't be used.
+		g.Expect(os.MkdirAll(filepath.Join(dir, "other", "backups"), 0700)).To(Succeed())
+		backup.Namespace = "other"
+
+		_, err := r.reconcile(context.Background(), r.Log, cluster, backup)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(conditions.GetReason(backup, controlplanev1.EtcdSnapshotSucceededCondition)).To(Equal(controlplanev1.EtcdSnapshotFailedReason))
+		g.Expect(conditions.GetMessage(backup, controlplanev1.EtcdSnapshotSucceededCondition)).To(ContainSubstring("PersistentVolumeClaim other/backups"))
+		files, err := ioutil.ReadDir(filepath.Join(dir, "other", "backups"))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(files).To(BeEmpty())
+	})
+
+	t.Run("waits for the control plane to be initialized", func(t *testing.T) {
+		g := NewWithT(t)
+		r, cluster, backup, dir := setup(t, g, true, false)
+		defer os.RemoveAll(dir)
+		r.now = func() time.Time { return creationTime.Add(time.Hour) }
+
+		_, err := r.reconcile(context.Background(), r.Log, cluster, backup)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(conditions.GetReason(backup, controlplanev1.EtcdSnapshotSucceededCondition)).To(Equal(controlplanev1.WaitingForControlPlaneInitializedReason))
+		g.Expect(backup.Status.Snapshots).To(BeEmpty())
+	})
+
+	t.Run("does not support external etcd", func(t *testing.T) {
+		g := NewWithT(t)
+		r, cluster, backup, dir := setup(t, g, false, true)
+		defer os.RemoveAll(dir)
+		r.now = func() time.Time { return creationTime.Add(time.Hour) }
+
+		_, err := r.reconcile(context.Background(), r.Log, cluster, backup)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(conditions.GetReason(backup, controlplanev1.EtcdSnapshotSucceededCondition)).To(Equal(controlplanev1.EtcdBackupNotSupportedReason))
+		g.Expect(backup.Status.Snapshots).To(BeEmpty())
+	})
+
+	t.Run("does nothing when suspended", func(t *testing.T) {
+		g := NewWithT(t)
+		r, cluster, backup, dir := setup(t, g, true, true)
+		defer os.RemoveAll(dir)
+		r.now = func() time.Time { return creationTime.Add(time.Hour) }
+		backup.Spec.Suspend = true
+
+		result, err := r.reconcile(context.Background(), r.Log, cluster, backup)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(result).To(Equal(ctrl.Result{}))
+		g.Expect(backup.Status.LastScheduleTime).To(BeNil())
+	})
+}
+
+func TestEtcdBackupReconciler_ClusterToEtcdBackups(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "foo"}}
+	matching := &controlplanev1.EtcdBackup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "foo-backup"},
+		Spec:       controlplanev1.EtcdBackupSpec{ClusterName: "foo"},
+	}
+	otherCluster := &controlplanev1.EtcdBackup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "bar-backup"},
+		Spec:       controlplanev1.EtcdBackupSpec{ClusterName: "bar"},
+	}
+	otherNamespace := &controlplanev1.EtcdBackup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "foo-backup"},
+		Spec:       controlplanev1.EtcdBackupSpec{ClusterName: "foo"},
+	}
+
+	r := &EtcdBackupReconciler{
+		Client: newFakeClient(g, matching, otherCluster, otherNamespace),
+		Log:    klogr.New(),
+	}
+
+	requests := r.ClusterToEtcdBackups(handler.MapObject{Meta: cluster.GetObjectMeta(), Object: cluster})
+	g.Expect(requests).To(HaveLen(1))
+	g.Expect(requests[0].Namespace).To(Equal("test"))
+	g.Expect(requests[0].Name).To(Equal("foo-backup"))
+}
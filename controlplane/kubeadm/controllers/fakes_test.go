@@ -17,8 +17,12 @@ limitations under the License.
 package controllers
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"io"
+	"io/ioutil"
+	"time"
 
 	"github.com/blang/semver"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -71,9 +75,29 @@ func (f *fakeManagementCluster) TargetClusterEtcdIsHealthy(_ context.Context, _
 	return nil
 }
 
+func (f *fakeManagementCluster) TargetClusterExternalEtcdIsHealthy(_ context.Context, _ client.ObjectKey, _ []string) error {
+	if !f.EtcdHealthy {
+		return errors.New("external etcd is not healthy")
+	}
+	return nil
+}
+
 type fakeWorkloadCluster struct {
 	*internal.Workload
-	Status internal.ClusterStatus
+	Status             internal.ClusterStatus
+	CertificatesExpiry time.Time
+	Snapshot           []byte
+}
+
+func (f fakeWorkloadCluster) GetCertificatesExpiry(_ context.Context, _ string, _ int) (time.Time, error) {
+	return f.CertificatesExpiry, nil
+}
+
+func (f fakeWorkloadCluster) EtcdSnapshot(_ context.Context) (io.ReadCloser, error) {
+	if f.Snapshot == nil {
+		return nil, errors.New("no snapshot available")
+	}
+	return ioutil.NopCloser(bytes.NewReader(f.Snapshot)), nil
 }
 
 func (f fakeWorkloadCluster) ForwardEtcdLeadership(_ context.Context, _ *clusterv1.Machine, _ *clusterv1.Machine) error {
@@ -25,6 +25,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apiserver/pkg/storage/names"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
@@ -134,7 +135,8 @@ func (r *KubeadmControlPlaneReconciler) cloneConfigsAndGenerateMachine(ctx conte
 		Namespace:   kcp.Namespace,
 		OwnerRef:    infraCloneOwner,
 		ClusterName: cluster.Name,
-		Labels:      internal.ControlPlaneLabelsForClusterWithHash(cluster.Name, hash.Compute(&kcp.Spec)),
+		Labels:      internal.ControlPlaneMachineLabelsForClusterWithHash(kcp, cluster.Name, hash.Compute(&kcp.Spec)),
+		Annotations: internal.ControlPlaneMachineAnnotations(kcp),
 	})
 	if err != nil {
 		// Safe to return early here since no resources have been created yet.
@@ -199,7 +201,8 @@ func (r *KubeadmControlPlaneReconciler) generateKubeadmConfig(ctx context.Contex
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            names.SimpleNameGenerator.GenerateName(kcp.Name + "-"),
 			Namespace:       kcp.Namespace,
-			Labels:          internal.ControlPlaneLabelsForClusterWithHash(cluster.Name, hash.Compute(&kcp.Spec)),
+			Labels:          internal.ControlPlaneMachineLabelsForClusterWithHash(kcp, cluster.Name, hash.Compute(&kcp.Spec)),
+			Annotations:     internal.ControlPlaneMachineAnnotations(kcp),
 			OwnerReferences: []metav1.OwnerReference{owner},
 		},
 		Spec: *spec,
@@ -223,9 +226,10 @@ func (r *KubeadmControlPlaneReconciler) generateKubeadmConfig(ctx context.Contex
 func (r *KubeadmControlPlaneReconciler) generateMachine(ctx context.Context, kcp *controlplanev1.KubeadmControlPlane, cluster *clusterv1.Cluster, infraRef, bootstrapRef *corev1.ObjectReference, failureDomain *string) error {
 	machine := &clusterv1.Machine{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      names.SimpleNameGenerator.GenerateName(kcp.Name + "-"),
-			Namespace: kcp.Namespace,
-			Labels:    internal.ControlPlaneLabelsForClusterWithHash(cluster.Name, hash.Compute(&kcp.Spec)),
+			Name:        names.SimpleNameGenerator.GenerateName(kcp.Name + "-"),
+			Namespace:   kcp.Namespace,
+			Labels:      internal.ControlPlaneMachineLabelsForClusterWithHash(kcp, cluster.Name, hash.Compute(&kcp.Spec)),
+			Annotations: internal.ControlPlaneMachineAnnotations(kcp),
 			OwnerReferences: []metav1.OwnerReference{
 				*metav1.NewControllerRef(kcp, controlplanev1.GroupVersion.WithKind("KubeadmControlPlane")),
 			},
@@ -246,3 +250,93 @@ func (r *KubeadmControlPlaneReconciler) generateMachine(ctx context.Context, kcp
 	}
 	return nil
 }
+
+// syncMachines propagates the labels and annotations from the KubeadmControlPlane machine template to the existing
+// control plane machines and to their infrastructure and bootstrap objects, without triggering a rollout.
+func (r *KubeadmControlPlaneReconciler) syncMachines(ctx context.Context, controlPlane *internal.ControlPlane) error {
+	if controlPlane.KCP.Spec.MachineTemplate == nil {
+		return nil
+	}
+	labels := controlPlane.KCP.Spec.MachineTemplate.ObjectMeta.Labels
+	annotations := controlPlane.KCP.Spec.MachineTemplate.ObjectMeta.Annotations
+	if len(labels) == 0 && len(annotations) == 0 {
+		return nil
+	}
+
+	for _, m := range controlPlane.Machines {
+		if !m.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		if err := r.patchMetadata(ctx, m, labels, annotations); err != nil {
+			return errors.Wrapf(err, "failed to update metadata of machine %s", m.Name)
+		}
+
+		infraObj, err := external.Get(ctx, r.Client, &m.Spec.InfrastructureRef, m.Namespace)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get infrastructure object for machine %s", m.Name)
+		}
+		if err := r.patchMetadata(ctx, infraObj, labels, annotations); err != nil {
+			return errors.Wrapf(err, "failed to update metadata of infrastructure object for machine %s", m.Name)
+		}
+
+		// Machines adopted by KCP might not have a bootstrap config.
+		if m.Spec.Bootstrap.ConfigRef == nil {
+			continue
+		}
+		bootstrapObj, err := external.Get(ctx, r.Client, m.Spec.Bootstrap.ConfigRef, m.Namespace)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get bootstrap config for machine %s", m.Name)
+		}
+		if err := r.patchMetadata(ctx, bootstrapObj, labels, annotations); err != nil {
+			return errors.Wrapf(err, "failed to update metadata of bootstrap config for machine %s", m.Name)
+		}
+	}
+	return nil
+}
+
+// patchMetadata adds the given labels and annotations to the object, patching it only if something changed.
+func (r *KubeadmControlPlaneReconciler) patchMetadata(ctx context.Context, obj metadataObject, labels, annotations map[string]string) error {
+	if containsAll(obj.GetLabels(), labels) && containsAll(obj.GetAnnotations(), annotations) {
+		return nil
+	}
+
+	patchHelper, err := patch.NewHelper(obj, r.Client)
+	if err != nil {
+		return err
+	}
+	obj.SetLabels(mergeMaps(obj.GetLabels(), labels))
+	obj.SetAnnotations(mergeMaps(obj.GetAnnotations(), annotations))
+	return patchHelper.Patch(ctx, obj)
+}
+
+// metadataObject is an object whose labels and annotations can be patched.
+type metadataObject interface {
+	metav1.Object
+	runtime.Object
+}
+
+// containsAll returns true if m contains all the keys in other with the same values.
+func containsAll(m, other map[string]string) bool {
+	for key, value := range other {
+		if v, ok := m[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeMaps returns a copy of m with all the keys in other added.
+func mergeMaps(m, other map[string]string) map[string]string {
+	if len(m) == 0 && len(other) == 0 {
+		return m
+	}
+	merged := make(map[string]string, len(m)+len(other))
+	for key, value := range m {
+		merged[key] = value
+	}
+	for key, value := range other {
+		merged[key] = value
+	}
+	return merged
+}
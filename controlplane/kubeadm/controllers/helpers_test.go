@@ -31,6 +31,7 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha3"
 	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
+	"sigs.k8s.io/cluster-api/controllers/external"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/hash"
@@ -242,6 +243,12 @@ func TestCloneConfigsAndGenerateMachine(t *testing.T) {
 				Name:       genericMachineTemplate.GetName(),
 				Namespace:  cluster.Namespace,
 			},
+			MachineTemplate: &controlplanev1.KubeadmControlPlaneMachineTemplate{
+				ObjectMeta: clusterv1.ObjectMeta{
+					Labels:      map[string]string{"environment": "test"},
+					Annotations: map[string]string{"example.com/owner": "team-a"},
+				},
+			},
 			Version: "v1.16.6",
 		},
 	}
@@ -278,6 +285,22 @@ func TestCloneConfigsAndGenerateMachine(t *testing.T) {
 		g.Expect(m.Spec.Bootstrap.ConfigRef.Name).To(HavePrefix(kcp.Name))
 		g.Expect(m.Spec.Bootstrap.ConfigRef.APIVersion).To(Equal(bootstrapv1.GroupVersion.String()))
 		g.Expect(m.Spec.Bootstrap.ConfigRef.Kind).To(Equal("KubeadmConfig"))
+
+		// The labels and annotations from the machine template are propagated to the machine, and to its
+		// infrastructure and bootstrap objects.
+		g.Expect(m.Labels).To(HaveKeyWithValue("environment", "test"))
+		g.Expect(m.Labels).To(HaveKeyWithValue(controlplanev1.KubeadmControlPlaneHashLabelKey, hash.Compute(&kcp.Spec)))
+		g.Expect(m.Annotations).To(HaveKeyWithValue("example.com/owner", "team-a"))
+
+		infraObj, err := external.Get(context.Background(), fakeClient, &m.Spec.InfrastructureRef, m.Namespace)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(infraObj.GetLabels()).To(HaveKeyWithValue("environment", "test"))
+		g.Expect(infraObj.GetAnnotations()).To(HaveKeyWithValue("example.com/owner", "team-a"))
+
+		bootstrapConfig := &bootstrapv1.KubeadmConfig{}
+		g.Expect(fakeClient.Get(context.Background(), client.ObjectKey{Namespace: m.Namespace, Name: m.Spec.Bootstrap.ConfigRef.Name}, bootstrapConfig)).To(Succeed())
+		g.Expect(bootstrapConfig.Labels).To(HaveKeyWithValue("environment", "test"))
+		g.Expect(bootstrapConfig.Annotations).To(HaveKeyWithValue("example.com/owner", "team-a"))
 	}
 }
 
@@ -394,6 +417,109 @@ func TestKubeadmControlPlaneReconciler_generateKubeadmConfig(t *testing.T) {
 }
 
 // TODO
+func TestKubeadmControlPlaneReconciler_syncMachines(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "test",
+		},
+	}
+
+	kcp := &controlplanev1.KubeadmControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kcp-foo",
+			Namespace: cluster.Namespace,
+		},
+		Spec: controlplanev1.KubeadmControlPlaneSpec{
+			Version: "v1.16.6",
+		},
+	}
+	originalHash := hash.Compute(&kcp.Spec)
+
+	infraObj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"kind":       "GenericMachine",
+			"apiVersion": "generic.io/v1",
+			"metadata": map[string]interface{}{
+				"name":      "infra-foo",
+				"namespace": cluster.Namespace,
+				"labels": map[string]interface{}{
+					"existing": "label",
+				},
+			},
+		},
+	}
+	bootstrapConfig := &bootstrapv1.KubeadmConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "bootstrap-foo",
+			Namespace: cluster.Namespace,
+		},
+	}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "machine-foo",
+			Namespace: cluster.Namespace,
+			Labels:    internal.ControlPlaneLabelsForClusterWithHash(cluster.Name, originalHash),
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: cluster.Name,
+			InfrastructureRef: corev1.ObjectReference{
+				Kind:       infraObj.GetKind(),
+				APIVersion: infraObj.GetAPIVersion(),
+				Name:       infraObj.GetName(),
+				Namespace:  cluster.Namespace,
+			},
+			Bootstrap: clusterv1.Bootstrap{
+				ConfigRef: &corev1.ObjectReference{
+					Kind:       "KubeadmConfig",
+					APIVersion: bootstrapv1.GroupVersion.String(),
+					Name:       bootstrapConfig.Name,
+					Namespace:  cluster.Namespace,
+				},
+			},
+		},
+	}
+
+	fakeClient := newFakeClient(g, machine.DeepCopy(), infraObj.DeepCopy(), bootstrapConfig.DeepCopy())
+	r := &KubeadmControlPlaneReconciler{
+		Client:   fakeClient,
+		Log:      log.Log,
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	// Changing the machine template does not change the configuration hash, so it does not trigger a rollout.
+	kcp.Spec.MachineTemplate = &controlplanev1.KubeadmControlPlaneMachineTemplate{
+		ObjectMeta: clusterv1.ObjectMeta{
+			Labels:      map[string]string{"environment": "test"},
+			Annotations: map[string]string{"example.com/owner": "team-a"},
+		},
+	}
+	g.Expect(hash.Compute(&kcp.Spec)).To(Equal(originalHash))
+
+	controlPlane := internal.NewControlPlane(cluster, kcp, internal.NewFilterableMachineCollection(machine))
+	g.Expect(controlPlane.MachinesNeedingRollout()).To(BeEmpty())
+	g.Expect(r.syncMachines(context.Background(), controlPlane)).To(Succeed())
+
+	updatedMachine := &clusterv1.Machine{}
+	g.Expect(fakeClient.Get(context.Background(), util.ObjectKey(machine), updatedMachine)).To(Succeed())
+	g.Expect(updatedMachine.Labels).To(HaveKeyWithValue("environment", "test"))
+	g.Expect(updatedMachine.Labels).To(HaveKeyWithValue(controlplanev1.KubeadmControlPlaneHashLabelKey, originalHash))
+	g.Expect(updatedMachine.Annotations).To(HaveKeyWithValue("example.com/owner", "team-a"))
+
+	updatedInfraObj, err := external.Get(context.Background(), fakeClient, &machine.Spec.InfrastructureRef, machine.Namespace)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(updatedInfraObj.GetLabels()).To(HaveKeyWithValue("existing", "label"))
+	g.Expect(updatedInfraObj.GetLabels()).To(HaveKeyWithValue("environment", "test"))
+	g.Expect(updatedInfraObj.GetAnnotations()).To(HaveKeyWithValue("example.com/owner", "team-a"))
+
+	updatedBootstrapConfig := &bootstrapv1.KubeadmConfig{}
+	g.Expect(fakeClient.Get(context.Background(), util.ObjectKey(bootstrapConfig), updatedBootstrapConfig)).To(Succeed())
+	g.Expect(updatedBootstrapConfig.Labels).To(HaveKeyWithValue("environment", "test"))
+	g.Expect(updatedBootstrapConfig.Annotations).To(HaveKeyWithValue("example.com/owner", "team-a"))
+}
+
 func TestReconcileExternalReference(t *testing.T) {}
 
 // TODO
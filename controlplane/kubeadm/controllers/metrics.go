@@ -0,0 +1,62 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/metrics"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// KubeadmControlPlaneStates is a metrics.StateLister for KubeadmControlPlanes.
+func KubeadmControlPlaneStates(ctx context.Context, c client.Reader) ([]metrics.ObjectState, error) {
+	list := &controlplanev1.KubeadmControlPlaneList{}
+	if err := c.List(ctx, list); err != nil {
+		return nil, err
+	}
+	states := make([]metrics.ObjectState, 0, len(list.Items))
+	for _, o := range list.Items {
+		o := o
+		states = append(states, metrics.ObjectState{
+			Kind:      "KubeadmControlPlane",
+			Name:      o.Name,
+			Namespace: o.Namespace,
+			Cluster:   ownerClusterName(o.OwnerReferences),
+			Replicas: &metrics.ReplicaCounts{
+				Desired: o.Spec.Replicas,
+				Ready:   &o.Status.ReadyReplicas,
+				Updated: &o.Status.UpdatedReplicas,
+			},
+			Conditions: o.Status.Conditions,
+		})
+	}
+	return states, nil
+}
+
+// ownerClusterName returns the name of the Cluster owning an object, if any.
+func ownerClusterName(refs []metav1.OwnerReference) string {
+	for _, ref := range refs {
+		if ref.Kind == "Cluster" && ref.APIVersion == clusterv1.GroupVersion.String() {
+			return ref.Name
+		}
+	}
+	return ""
+}
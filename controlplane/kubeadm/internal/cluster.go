@@ -35,6 +35,7 @@ import (
 	"sigs.k8s.io/cluster-api/controllers/remote"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/machinefilters"
 	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/cluster-api/util/signer"
 )
 
 // ManagementCluster defines all behaviors necessary for something to function as a management cluster.
@@ -43,6 +44,7 @@ type ManagementCluster interface {
 
 	GetMachinesForCluster(ctx context.Context, cluster client.ObjectKey, filters ...machinefilters.Func) (FilterableMachineCollection, error)
 	TargetClusterEtcdIsHealthy(ctx context.Context, clusterKey client.ObjectKey) error
+	TargetClusterExternalEtcdIsHealthy(ctx context.Context, clusterKey client.ObjectKey, endpoints []string) error
 	TargetClusterControlPlaneIsHealthy(ctx context.Context, clusterKey client.ObjectKey) error
 	GetWorkloadCluster(ctx context.Context, clusterKey client.ObjectKey) (WorkloadCluster, error)
 }
@@ -102,6 +104,28 @@ func (m *Management) GetWorkloadCluster(ctx context.Context, clusterKey client.O
 		return nil, &RemoteClusterConnectionError{Name: clusterKey.String(), Err: err}
 	}
 
+	cfg, err := m.getEtcdTLSConfig(ctx, clusterKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Workload{
+		Client:          c,
+		CoreDNSMigrator: &CoreDNSMigrator{},
+		etcdClientGenerator: &etcdClientGenerator{
+			restConfig: restConfig,
+			tlsConfig:  cfg,
+		},
+		etcdTLSConfig: cfg,
+		servingCertificateGetter: &proxyServingCertificateGetter{
+			restConfig: restConfig,
+		},
+	}, nil
+}
+
+// getEtcdTLSConfig returns the TLS configuration used for connecting to the workload cluster's etcd.
+// If the etcd CA key or an external signer is available, the cluster is using a managed etcd and a new client certificate
+// is issued; otherwise the cluster is using an external etcd, and the user supplied apiserver-etcd-client certificate is used.
+func (m *Management) getEtcdTLSConfig(ctx context.Context, clusterKey client.ObjectKey) (*tls.Config, error) {
 	etcdCASecret := &corev1.Secret{}
 	etcdCAObjectKey := ctrlclient.ObjectKey{
 		Namespace: clusterKey.Namespace,
@@ -114,30 +138,47 @@ func (m *Management) GetWorkloadCluster(ctx context.Context, clusterKey client.O
 	if !ok {
 		return nil, errors.Errorf("etcd tls crt does not exist for cluster %s/%s", clusterKey.Namespace, clusterKey.Name)
 	}
-	keyData, ok := etcdCASecret.Data[secret.TLSKeyDataName]
-	if !ok {
-		return nil, errors.Errorf("etcd tls key does not exist for cluster %s/%s", clusterKey.Namespace, clusterKey.Name)
+	caPool := x509.NewCertPool()
+	caPool.AppendCertsFromPEM(crtData)
+
+	etcdSigner, err := signer.FromSecret(etcdCASecret)
+	if err == signer.ErrNoSigner {
+		clientCert, err := m.getExternalEtcdClientCert(ctx, clusterKey)
+		if err != nil {
+			return nil, err
+		}
+		return &tls.Config{
+			RootCAs:      caPool,
+			Certificates: []tls.Certificate{clientCert},
+		}, nil
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	clientCert, err := generateClientCert(crtData, keyData)
+	clientCert, err := generateClientCert(ctx, etcdSigner)
 	if err != nil {
 		return nil, err
 	}
-	caPool := x509.NewCertPool()
-	caPool.AppendCertsFromPEM(crtData)
 	cfg := &tls.Config{
 		RootCAs:      caPool,
 		Certificates: []tls.Certificate{clientCert},
 	}
 	cfg.InsecureSkipVerify = true
-	return &Workload{
-		Client:          c,
-		CoreDNSMigrator: &CoreDNSMigrator{},
-		etcdClientGenerator: &etcdClientGenerator{
-			restConfig: restConfig,
-			tlsConfig:  cfg,
-		},
-	}, nil
+	return cfg, nil
+}
+
+// getExternalEtcdClientCert returns the user supplied client certificate for connecting to an external etcd.
+func (m *Management) getExternalEtcdClientCert(ctx context.Context, clusterKey client.ObjectKey) (tls.Certificate, error) {
+	clientSecret, err := secret.Get(ctx, m.Client, clusterKey, secret.APIServerEtcdClient)
+	if err != nil {
+		return tls.Certificate{}, errors.Wrapf(err, "failed to get secret; etcd client certificate %s/%s", clusterKey.Namespace, secret.Name(clusterKey.Name, secret.APIServerEtcdClient))
+	}
+	clientCert, err := tls.X509KeyPair(clientSecret.Data[secret.TLSCrtDataName], clientSecret.Data[secret.TLSKeyDataName])
+	if err != nil {
+		return tls.Certificate{}, errors.Wrapf(err, "invalid etcd client certificate for cluster %s/%s", clusterKey.Namespace, clusterKey.Name)
+	}
+	return clientCert, nil
 }
 
 type healthCheck func(context.Context) (HealthCheckResult, error)
@@ -200,3 +241,13 @@ func (m *Management) TargetClusterEtcdIsHealthy(ctx context.Context, clusterKey
 	}
 	return m.healthCheck(ctx, cluster.EtcdIsHealthy, clusterKey)
 }
+
+// TargetClusterExternalEtcdIsHealthy runs a series of checks over the endpoints of an external etcd cluster
+// used by the target cluster.
+func (m *Management) TargetClusterExternalEtcdIsHealthy(ctx context.Context, clusterKey client.ObjectKey, endpoints []string) error {
+	cluster, err := m.GetWorkloadCluster(ctx, clusterKey)
+	if err != nil {
+		return err
+	}
+	return cluster.ExternalEtcdIsHealthy(ctx, endpoints)
+}
@@ -36,3 +36,31 @@ func ControlPlaneLabelsForCluster(clusterName string) map[string]string {
 		clusterv1.MachineControlPlaneLabelName: "",
 	}
 }
+
+// ControlPlaneMachineLabelsForClusterWithHash returns a set of labels to add to a control plane machine and to its
+// infrastructure and bootstrap objects; the labels from the KubeadmControlPlane machine template are added to the
+// labels identifying the control plane machines for this specific cluster and configuration hash.
+func ControlPlaneMachineLabelsForClusterWithHash(kcp *controlplanev1.KubeadmControlPlane, clusterName string, hash string) map[string]string {
+	labels := map[string]string{}
+	if kcp.Spec.MachineTemplate != nil {
+		for key, value := range kcp.Spec.MachineTemplate.ObjectMeta.Labels {
+			labels[key] = value
+		}
+	}
+	for key, value := range ControlPlaneLabelsForClusterWithHash(clusterName, hash) {
+		labels[key] = value
+	}
+	return labels
+}
+
+// ControlPlaneMachineAnnotations returns a set of annotations to add to a control plane machine and to its
+// infrastructure and bootstrap objects, as defined in the KubeadmControlPlane machine template.
+func ControlPlaneMachineAnnotations(kcp *controlplanev1.KubeadmControlPlane) map[string]string {
+	annotations := map[string]string{}
+	if kcp.Spec.MachineTemplate != nil {
+		for key, value := range kcp.Spec.MachineTemplate.ObjectMeta.Annotations {
+			annotations[key] = value
+		}
+	}
+	return annotations
+}
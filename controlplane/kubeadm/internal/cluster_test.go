@@ -32,8 +32,11 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/machinefilters"
+	"sigs.k8s.io/cluster-api/util/secret"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func podReady(isReady corev1.ConditionStatus) corev1.PodCondition {
@@ -472,3 +475,86 @@ func nilNodeRef(machine clusterv1.Machine) clusterv1.Machine {
 	machine.Status.NodeRef = nil
 	return machine
 }
+
+func TestManagementCluster_getEtcdTLSConfig(t *testing.T) {
+	g := NewWithT(t)
+
+	certificates := secret.NewCertificatesForInitialControlPlane(&kubeadmv1.ClusterConfiguration{})
+	g.Expect(certificates.Generate()).To(Succeed())
+	etcdCA := certificates.GetByPurpose(secret.EtcdCA).KeyPair
+	// any key pair can be used as a user supplied client certificate.
+	etcdClient := certificates.GetByPurpose(secret.ClusterCA).KeyPair
+
+	clusterKey := client.ObjectKey{Namespace: "default", Name: "cluster-name"}
+	etcdCASecret := func(withKey bool) *corev1.Secret {
+		s := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: clusterKey.Namespace, Name: secret.Name(clusterKey.Name, secret.EtcdCA)},
+			Data:       map[string][]byte{secret.TLSCrtDataName: etcdCA.Cert},
+		}
+		if withKey {
+			s.Data[secret.TLSKeyDataName] = etcdCA.Key
+		}
+		return s
+	}
+	etcdCASecretWithSigner := etcdCASecret(false)
+	etcdCASecretWithSigner.Annotations = map[string]string{secret.ExternalSignerURLAnnotation: "https://127.0.0.1:0/v1/pki/sign-verbatim/etcd"}
+	etcdClientSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: clusterKey.Namespace, Name: secret.Name(clusterKey.Name, secret.APIServerEtcdClient)},
+		Data: map[string][]byte{
+			secret.TLSCrtDataName: etcdClient.Cert,
+			secret.TLSKeyDataName: etcdClient.Key,
+		},
+	}
+
+	tests := []struct {
+		name                   string
+		objs                   []runtime.Object
+		wantErr                bool
+		wantInsecureSkipVerify bool
+	}{
+		{
+			name:                   "generates a client certificate for managed etcd",
+			objs:                   []runtime.Object{etcdCASecret(true)},
+			wantInsecureSkipVerify: true,
+		},
+		{
+			name: "uses the user supplied client certificate for external etcd",
+			objs: []runtime.Object{etcdCASecret(false), etcdClientSecret},
+		},
+		{
+			name:    "fails if the user supplied client certificate for external etcd does not exist",
+			objs:    []runtime.Object{etcdCASecret(false)},
+			wantErr: true,
+		},
+		{
+			name:    "fails if the external signer of the etcd CA is not available",
+			objs:    []runtime.Object{etcdCASecretWithSigner, etcdClientSecret},
+			wantErr: true,
+		},
+		{
+			name:    "fails if the etcd CA does not exist",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			scheme := runtime.NewScheme()
+			g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+			m := &Management{
+				Client: fake.NewFakeClientWithScheme(scheme, tt.objs...),
+			}
+			cfg, err := m.getEtcdTLSConfig(context.Background(), clusterKey)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cfg.Certificates).To(HaveLen(1))
+			g.Expect(cfg.RootCAs).NotTo(BeNil())
+			g.Expect(cfg.InsecureSkipVerify).To(Equal(tt.wantInsecureSkipVerify))
+		})
+	}
+}
@@ -35,6 +35,10 @@ type ControlPlane struct {
 	KCP      *controlplanev1.KubeadmControlPlane
 	Cluster  *clusterv1.Cluster
 	Machines FilterableMachineCollection
+
+	// CertificateAuthoritiesHash is the hash of the certificate authorities of the cluster while they are being
+	// rotated; machines bootstrapped with other certificate authorities need to be rolled out.
+	CertificateAuthoritiesHash string
 }
 
 // NewControlPlane returns an instantiated ControlPlane.
@@ -74,6 +78,18 @@ func (c *ControlPlane) SpecHash() string {
 	return hash.Compute(&c.KCP.Spec)
 }
 
+// APIServerBindPort returns the port where the API server listens on control plane nodes.
+func (c *ControlPlane) APIServerBindPort() int {
+	spec := c.KCP.Spec.KubeadmConfigSpec
+	if spec.InitConfiguration != nil && spec.InitConfiguration.LocalAPIEndpoint.BindPort != 0 {
+		return int(spec.InitConfiguration.LocalAPIEndpoint.BindPort)
+	}
+	if spec.JoinConfiguration != nil && spec.JoinConfiguration.ControlPlane != nil && spec.JoinConfiguration.ControlPlane.LocalAPIEndpoint.BindPort != 0 {
+		return int(spec.JoinConfiguration.ControlPlane.LocalAPIEndpoint.BindPort)
+	}
+	return DefaultAPIServerPort
+}
+
 // AsOwnerReference returns an owner reference to the KubeadmControlPlane.
 func (c *ControlPlane) AsOwnerReference() *metav1.OwnerReference {
 	return &metav1.OwnerReference{
@@ -93,22 +109,41 @@ func (c *ControlPlane) EtcdImageData() (string, string) {
 	return "", ""
 }
 
+// IsEtcdManaged returns true if the control plane relies on a stacked etcd cluster running on the control plane
+// nodes, false if it is using an external etcd cluster.
+func (c *ControlPlane) IsEtcdManaged() bool {
+	return c.KCP.Spec.KubeadmConfigSpec.ClusterConfiguration == nil || c.KCP.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.External == nil
+}
+
+// ExternalEtcdEndpoints returns the endpoints of the external etcd cluster, if any.
+func (c *ControlPlane) ExternalEtcdEndpoints() []string {
+	if c.IsEtcdManaged() {
+		return nil
+	}
+	return c.KCP.Spec.KubeadmConfigSpec.ClusterConfiguration.Etcd.External.Endpoints
+}
+
 // MachinesNeedingRollout return a list of machines that need to be rolled out due to configuration changes.
 //
 // NOTE: Expiration of the spec.UpgradeAfter value forces inclusion of all the machines in this set even if
 // no changes have been made to the KubeadmControlPlane.
+// NOTE: Machines with certificates expiring within the spec.RolloutBefore.CertificatesExpiryDays window are
+// included in this set too.
+// NOTE: Machines not bootstrapped with the certificate authorities being rotated are included in this set too.
 func (c *ControlPlane) MachinesNeedingRollout() FilterableMachineCollection {
 	now := metav1.Now()
+	filters := []machinefilters.Func{
+		machinefilters.Not(machinefilters.MatchesConfigurationHash(c.SpecHash())),
+		machinefilters.ShouldRolloutBefore(&now, c.KCP.Spec.RolloutBefore),
+	}
 	if c.KCP.Spec.UpgradeAfter != nil && c.KCP.Spec.UpgradeAfter.Before(&now) {
-		return c.Machines.AnyFilter(
-			machinefilters.Not(machinefilters.MatchesConfigurationHash(c.SpecHash())),
-			machinefilters.OlderThan(c.KCP.Spec.UpgradeAfter),
-		)
+		filters = append(filters, machinefilters.OlderThan(c.KCP.Spec.UpgradeAfter))
+	}
+	if c.CertificateAuthoritiesHash != "" {
+		filters = append(filters, machinefilters.Not(machinefilters.MatchesCertificateAuthoritiesHash(c.CertificateAuthoritiesHash)))
 	}
 
-	return c.Machines.Filter(
-		machinefilters.Not(machinefilters.MatchesConfigurationHash(c.SpecHash())),
-	)
+	return c.Machines.AnyFilter(filters...)
 }
 
 // MachineInFailureDomainWithMostMachines returns the first matching failure domain with machines that has the most control-plane machines on it.
@@ -177,7 +212,8 @@ func (c *ControlPlane) GenerateKubeadmConfig(spec *bootstrapv1.KubeadmConfigSpec
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            names.SimpleNameGenerator.GenerateName(c.KCP.Name + "-"),
 			Namespace:       c.KCP.Namespace,
-			Labels:          ControlPlaneLabelsForClusterWithHash(c.Cluster.Name, c.SpecHash()),
+			Labels:          ControlPlaneMachineLabelsForClusterWithHash(c.KCP, c.Cluster.Name, c.SpecHash()),
+			Annotations:     ControlPlaneMachineAnnotations(c.KCP),
 			OwnerReferences: []metav1.OwnerReference{owner},
 		},
 		Spec: *spec,
@@ -187,11 +223,17 @@ func (c *ControlPlane) GenerateKubeadmConfig(spec *bootstrapv1.KubeadmConfigSpec
 
 // NewMachine returns a machine configured to be a part of the control plane.
 func (c *ControlPlane) NewMachine(infraRef, bootstrapRef *corev1.ObjectReference, failureDomain *string) *clusterv1.Machine {
+	annotations := ControlPlaneMachineAnnotations(c.KCP)
+	if c.CertificateAuthoritiesHash != "" {
+		annotations[controlplanev1.CertificateAuthoritiesHashAnnotation] = c.CertificateAuthoritiesHash
+	}
+
 	return &clusterv1.Machine{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      names.SimpleNameGenerator.GenerateName(c.KCP.Name + "-"),
-			Namespace: c.KCP.Namespace,
-			Labels:    ControlPlaneLabelsForClusterWithHash(c.Cluster.Name, c.SpecHash()),
+			Name:        names.SimpleNameGenerator.GenerateName(c.KCP.Name + "-"),
+			Namespace:   c.KCP.Namespace,
+			Labels:      ControlPlaneMachineLabelsForClusterWithHash(c.KCP, c.Cluster.Name, c.SpecHash()),
+			Annotations: annotations,
 			OwnerReferences: []metav1.OwnerReference{
 				*metav1.NewControllerRef(c.KCP, controlplanev1.GroupVersion.WithKind("KubeadmControlPlane")),
 			},
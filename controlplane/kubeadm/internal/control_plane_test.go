@@ -28,6 +28,7 @@ import (
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha3"
+	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
 )
 
@@ -134,6 +135,24 @@ var _ = Describe("Control Plane", func() {
 					})
 				})
 
+				Context("That has a rolloutBefore value set", func() {
+					BeforeEach(func() {
+						controlPlane.KCP.Spec.RolloutBefore = &controlplanev1.RolloutBefore{
+							CertificatesExpiryDays: pointer.Int32Ptr(21),
+						}
+						controlPlane.Machines["machine-1"].SetAnnotations(map[string]string{
+							controlplanev1.MachineCertificatesExpiryDateAnnotation: time.Now().Add(10 * 24 * time.Hour).Format(time.RFC3339),
+						})
+						controlPlane.Machines["machine-2"].SetAnnotations(map[string]string{
+							controlplanev1.MachineCertificatesExpiryDateAnnotation: time.Now().Add(100 * 24 * time.Hour).Format(time.RFC3339),
+						})
+					})
+					It("should return machines with certificates expiring within the rollout window", func() {
+						Expect(controlPlane.MachinesNeedingRollout()).To(HaveLen(1))
+						Expect(controlPlane.MachinesNeedingRollout()).To(HaveKey("machine-1"))
+					})
+				})
+
 				Context("That has an upgradeAfter value set", func() {
 					Context("That is in the future", func() {
 						BeforeEach(func() {
@@ -169,6 +188,38 @@ var _ = Describe("Control Plane", func() {
 		})
 	})
 
+	Describe("APIServerBindPort", func() {
+		It("should return the default port if not specified", func() {
+			Expect(controlPlane.APIServerBindPort()).To(Equal(DefaultAPIServerPort))
+		})
+		It("should return the port specified in the init configuration", func() {
+			controlPlane.KCP.Spec.KubeadmConfigSpec.InitConfiguration = &kubeadmv1.InitConfiguration{
+				LocalAPIEndpoint: kubeadmv1.APIEndpoint{BindPort: 8443},
+			}
+			Expect(controlPlane.APIServerBindPort()).To(Equal(8443))
+		})
+	})
+
+	Describe("Etcd", func() {
+		It("should be managed if no cluster configuration is specified", func() {
+			Expect(controlPlane.IsEtcdManaged()).To(BeTrue())
+			Expect(controlPlane.ExternalEtcdEndpoints()).To(BeEmpty())
+		})
+		It("should be managed if local etcd is specified", func() {
+			controlPlane.KCP.Spec.KubeadmConfigSpec.ClusterConfiguration = &kubeadmv1.ClusterConfiguration{
+				Etcd: kubeadmv1.Etcd{Local: &kubeadmv1.LocalEtcd{}},
+			}
+			Expect(controlPlane.IsEtcdManaged()).To(BeTrue())
+		})
+		It("should not be managed if external etcd is specified", func() {
+			controlPlane.KCP.Spec.KubeadmConfigSpec.ClusterConfiguration = &kubeadmv1.ClusterConfiguration{
+				Etcd: kubeadmv1.Etcd{External: &kubeadmv1.ExternalEtcd{Endpoints: []string{"https://etcd:2379"}}},
+			}
+			Expect(controlPlane.IsEtcdManaged()).To(BeFalse())
+			Expect(controlPlane.ExternalEtcdEndpoints()).To(ConsistOf("https://etcd:2379"))
+		})
+	})
+
 	Describe("Generating components", func() {
 		Context("That is after machine creation time", func() {
 			BeforeEach(func() {
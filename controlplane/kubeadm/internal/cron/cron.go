@@ -0,0 +1,197 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cron implements parsing of schedules in the standard five fields cron format.
+package cron
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxYears is the number of years Next looks ahead before giving up on finding a matching time.
+const maxYears = 5
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type bounds struct {
+	name     string
+	min, max uint
+}
+
+var (
+	minutes     = bounds{"minute", 0, 59}
+	hours       = bounds{"hour", 0, 23}
+	daysOfMonth = bounds{"day of month", 1, 31}
+	months      = bounds{"month", 1, 12}
+	// 7 is accepted as an alias for Sunday.
+	daysOfWeek = bounds{"day of week", 0, 7}
+)
+
+// Schedule is a parsed cron schedule.
+type Schedule struct {
+	minute, hour, dayOfMonth, month, dayOfWeek uint64
+
+	// dayOfMonthAny and dayOfWeekAny track if the corresponding field is "*"; when both the day fields are
+	// restricted, a time matches if any of them matches.
+	dayOfMonthAny, dayOfWeekAny bool
+}
+
+// Parse parses a schedule in the "minute hour day-of-month month day-of-week" format; each field supports
+// "*", single values, ranges ("1-5"), steps ("*/15", "1-30/10") and comma separated lists of them.
+// The @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly macros are supported as well.
+func Parse(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if macro, ok := macros[spec]; ok {
+		spec = macro
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("invalid schedule %q: expected 5 fields, found %d", spec, len(fields))
+	}
+
+	s := &Schedule{}
+	var err error
+	if s.minute, _, err = parseField(fields[0], minutes); err != nil {
+		return nil, err
+	}
+	if s.hour, _, err = parseField(fields[1], hours); err != nil {
+		return nil, err
+	}
+	if s.dayOfMonth, s.dayOfMonthAny, err = parseField(fields[2], daysOfMonth); err != nil {
+		return nil, err
+	}
+	if s.month, _, err = parseField(fields[3], months); err != nil {
+		return nil, err
+	}
+	if s.dayOfWeek, s.dayOfWeekAny, err = parseField(fields[4], daysOfWeek); err != nil {
+		return nil, err
+	}
+	// Sunday can be expressed both as 0 and 7.
+	if s.dayOfWeek&(1<<7) != 0 {
+		s.dayOfWeek |= 1
+	}
+	return s, nil
+}
+
+// parseField parses a field of a cron schedule, returning the bitset of the matching values and if the field is "*".
+func parseField(field string, b bounds) (uint64, bool, error) {
+	var bits uint64
+	for _, expr := range strings.Split(field, ",") {
+		rangeExpr, step := expr, uint(1)
+		if i := strings.Index(expr, "/"); i >= 0 {
+			rangeExpr = expr[:i]
+			s, err := strconv.ParseUint(expr[i+1:], 10, 8)
+			if err != nil || s == 0 {
+				return 0, false, errors.Errorf("invalid step %q for the %s field", expr[i+1:], b.name)
+			}
+			step = uint(s)
+		}
+
+		var start, end uint
+		switch {
+		case rangeExpr == "*":
+			start, end = b.min, b.max
+		case strings.Contains(rangeExpr, "-"):
+			parts := strings.SplitN(rangeExpr, "-", 2)
+			var err error
+			if start, err = parseValue(parts[0], b); err != nil {
+				return 0, false, err
+			}
+			if end, err = parseValue(parts[1], b); err != nil {
+				return 0, false, err
+			}
+			if start > end {
+				return 0, false, errors.Errorf("invalid range %q for the %s field", rangeExpr, b.name)
+			}
+		default:
+			v, err := parseValue(rangeExpr, b)
+			if err != nil {
+				return 0, false, err
+			}
+			start, end = v, v
+			// A single value with a step means from value to the max, e.g. 5/10.
+			if step > 1 {
+				end = b.max
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, field == "*", nil
+}
+
+func parseValue(value string, b bounds) (uint, error) {
+	v, err := strconv.ParseUint(value, 10, 8)
+	if err != nil {
+		return 0, errors.Errorf("invalid value %q for the %s field", value, b.name)
+	}
+	if uint(v) < b.min || uint(v) > b.max {
+		return 0, errors.Errorf("value %d out of range [%d-%d] for the %s field", v, b.min, b.max, b.name)
+	}
+	return uint(v), nil
+}
+
+// Next returns the first time matching the schedule strictly after the given time, in the location of the given time.
+// It returns the zero time if no matching time is found within the next five years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + maxYears
+
+	for t.Year() <= limit {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dayOfMonth&(1<<uint(t.Day())) != 0
+	dowMatch := s.dayOfWeek&(1<<uint(t.Weekday())) != 0
+	if s.dayOfMonthAny || s.dayOfWeekAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cron
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr bool
+	}{
+		{name: "every minute", spec: "* * * * *"},
+		{name: "lists, ranges and steps", spec: "0,30 1-5 */2 1-12/3 1-5"},
+		{name: "macros", spec: "@daily"},
+		{name: "sunday as 7", spec: "0 0 * * 7"},
+		{name: "fails for too few fields", spec: "* * * *", wantErr: true},
+		{name: "fails for values out of range", spec: "60 * * * *", wantErr: true},
+		{name: "fails for invalid values", spec: "a * * * *", wantErr: true},
+		{name: "fails for invalid ranges", spec: "* 5-1 * * *", wantErr: true},
+		{name: "fails for zero steps", spec: "*/0 * * * *", wantErr: true},
+		{name: "fails for unknown macros", spec: "@sometimes", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			_, err := Parse(tt.spec)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	// Wednesday.
+	now := time.Date(2020, 7, 15, 10, 30, 20, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		spec string
+		want time.Time
+	}{
+		{
+			name: "every minute",
+			spec: "* * * * *",
+			want: time.Date(2020, 7, 15, 10, 31, 0, 0, time.UTC),
+		},
+		{
+			name: "every 15 minutes",
+			spec: "*/15 * * * *",
+			want: time.Date(2020, 7, 15, 10, 45, 0, 0, time.UTC),
+		},
+		{
+			name: "daily, next day",
+			spec: "@daily",
+			want: time.Date(2020, 7, 16, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "hourly at a given minute, same hour",
+			spec: "40 * * * *",
+			want: time.Date(2020, 7, 15, 10, 40, 0, 0, time.UTC),
+		},
+		{
+			name: "weekly on sunday",
+			spec: "0 3 * * 7",
+			want: time.Date(2020, 7, 19, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "monthly, next month",
+			spec: "0 0 1 * *",
+			want: time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "day of month or day of week when both are restricted",
+			spec: "0 0 20 * 5",
+			want: time.Date(2020, 7, 17, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "yearly, next year",
+			spec: "@yearly",
+			want: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "never matching",
+			spec: "0 0 31 2 *",
+			want: time.Time{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			s, err := Parse(tt.spec)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(s.Next(now)).To(Equal(tt.want))
+		})
+	}
+}
@@ -19,6 +19,7 @@ package etcd
 import (
 	"context"
 	"crypto/tls"
+	"io"
 	"net"
 
 	"github.com/pkg/errors"
@@ -41,6 +42,7 @@ type etcd interface {
 	MemberUpdate(ctx context.Context, id uint64, peerURLs []string) (*clientv3.MemberUpdateResponse, error)
 	MoveLeader(ctx context.Context, id uint64) (*clientv3.MoveLeaderResponse, error)
 	Status(ctx context.Context, endpoint string) (*clientv3.StatusResponse, error)
+	Snapshot(ctx context.Context) (io.ReadCloser, error)
 }
 
 // Client wraps an etcd client formatting its output to something more consumable.
@@ -129,11 +131,13 @@ func NewEtcdClient(endpoints []string, dialer GRPCDial, tlsConfig *tls.Config) (
 }
 
 // NewClientWithEtcd configures our response formatter (Client) with an etcd client and endpoint.
+// Calls to the etcd client are traced as child spans of the span in ctx, if any.
 func NewClientWithEtcd(ctx context.Context, etcdClient etcd) (*Client, error) {
 	endpoints := etcdClient.Endpoints()
 	if len(endpoints) == 0 {
 		return nil, errors.New("etcd client was not configured with any endpoints")
 	}
+	etcdClient = newTracingAdapter(etcdClient)
 
 	status, err := etcdClient.Status(ctx, endpoints[0])
 	if err != nil {
@@ -207,6 +211,16 @@ func (c *Client) UpdateMemberPeerURLs(ctx context.Context, id uint64, peerURLs [
 	return members, nil
 }
 
+// Snapshot streams a snapshot of the etcd backend database from the member the client is connected to.
+// The caller is responsible for closing the returned reader.
+func (c *Client) Snapshot(ctx context.Context) (io.ReadCloser, error) {
+	snapshot, err := c.EtcdClient.Snapshot(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get etcd snapshot")
+	}
+	return snapshot, nil
+}
+
 // Alarms retrieves all alarms on a cluster.
 func (c *Client) Alarms(ctx context.Context) ([]MemberAlarm, error) {
 	alarmResponse, err := c.EtcdClient.AlarmList(ctx)
@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"context"
+	"io"
+
+	"go.etcd.io/etcd/clientv3"
+	"go.opentelemetry.io/otel/attribute"
+	"sigs.k8s.io/cluster-api/util/tracing"
+)
+
+// tracingAdapter wraps etcd calls in spans, so slow or failing calls to etcd members
+// show up in the trace of the reconcile which issued them.
+type tracingAdapter struct {
+	etcd
+	endpoint string
+}
+
+func newTracingAdapter(c etcd) *tracingAdapter {
+	a := &tracingAdapter{etcd: c}
+	if endpoints := c.Endpoints(); len(endpoints) > 0 {
+		a.endpoint = endpoints[0]
+	}
+	return a
+}
+
+func (a *tracingAdapter) start(ctx context.Context, name string) (context.Context, func(error)) {
+	ctx, span := tracing.StartSpan(ctx, "etcd."+name, attribute.String("etcd.endpoint", a.endpoint))
+	return ctx, func(err error) { tracing.EndSpan(span, err) }
+}
+
+func (a *tracingAdapter) AlarmList(ctx context.Context) (_ *clientv3.AlarmResponse, err error) {
+	ctx, end := a.start(ctx, "AlarmList")
+	defer func() { end(err) }()
+	return a.etcd.AlarmList(ctx)
+}
+
+func (a *tracingAdapter) MemberList(ctx context.Context) (_ *clientv3.MemberListResponse, err error) {
+	ctx, end := a.start(ctx, "MemberList")
+	defer func() { end(err) }()
+	return a.etcd.MemberList(ctx)
+}
+
+func (a *tracingAdapter) MemberRemove(ctx context.Context, id uint64) (_ *clientv3.MemberRemoveResponse, err error) {
+	ctx, end := a.start(ctx, "MemberRemove")
+	defer func() { end(err) }()
+	return a.etcd.MemberRemove(ctx, id)
+}
+
+func (a *tracingAdapter) MemberUpdate(ctx context.Context, id uint64, peerURLs []string) (_ *clientv3.MemberUpdateResponse, err error) {
+	ctx, end := a.start(ctx, "MemberUpdate")
+	defer func() { end(err) }()
+	return a.etcd.MemberUpdate(ctx, id, peerURLs)
+}
+
+func (a *tracingAdapter) MoveLeader(ctx context.Context, id uint64) (_ *clientv3.MoveLeaderResponse, err error) {
+	ctx, end := a.start(ctx, "MoveLeader")
+	defer func() { end(err) }()
+	return a.etcd.MoveLeader(ctx, id)
+}
+
+func (a *tracingAdapter) Status(ctx context.Context, endpoint string) (_ *clientv3.StatusResponse, err error) {
+	ctx, end := a.start(ctx, "Status")
+	defer func() { end(err) }()
+	return a.etcd.Status(ctx, endpoint)
+}
+
+func (a *tracingAdapter) Snapshot(ctx context.Context) (_ io.ReadCloser, err error) {
+	ctx, end := a.start(ctx, "Snapshot")
+	defer func() { end(err) }()
+	return a.etcd.Snapshot(ctx)
+}
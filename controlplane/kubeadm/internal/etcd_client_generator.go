@@ -19,6 +19,8 @@ package internal
 import (
 	"context"
 	"crypto/tls"
+	"net"
+
 	"github.com/pkg/errors"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 
@@ -63,6 +65,24 @@ func (c *etcdClientGenerator) forNodes(ctx context.Context, nodes []corev1.Node)
 	return customClient, nil
 }
 
+// forEndpoints takes a list of etcd client endpoints and returns a client connecting to them directly, without going
+// through the workload cluster API server; this is used for external etcd clusters not running on control plane nodes.
+func (c *etcdClientGenerator) forEndpoints(ctx context.Context, endpoints []string) (*etcd.Client, error) {
+	dialer := &net.Dialer{}
+	dial := func(ctx context.Context, addr string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "tcp", addr)
+	}
+	etcdclient, err := etcd.NewEtcdClient(endpoints, dial, c.tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	customClient, err := etcd.NewClientWithEtcd(ctx, etcdclient)
+	if err != nil {
+		return nil, err
+	}
+	return customClient, nil
+}
+
 // forLeader takes a list of nodes and returns a client to the leader node
 func (c *etcdClientGenerator) forLeader(ctx context.Context, nodes []corev1.Node) (*etcd.Client, error) {
 	var errs []error
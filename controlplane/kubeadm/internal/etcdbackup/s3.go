@@ -0,0 +1,257 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdbackup
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultS3Region is the region used for signing requests when none is specified; S3-compatible
+	// object stores like MinIO accept it by default.
+	DefaultS3Region = "us-east-1"
+
+	s3Service      = "s3"
+	s3Algorithm    = "AWS4-HMAC-SHA256"
+	amzDateFormat  = "20060102T150405Z"
+	amzShortFormat = "20060102"
+)
+
+// S3Config is the configuration for storing snapshots in an S3-compatible object store.
+type S3Config struct {
+	// Endpoint is the URL of the object store, e.g. https://minio.example.com:9000.
+	Endpoint string
+
+	// Bucket is the name of the bucket where snapshots are stored.
+	Bucket string
+
+	// Prefix is prepended to the name of the snapshots, e.g. a folder in the bucket.
+	Prefix string
+
+	// Region is the region of the bucket; it defaults to DefaultS3Region.
+	Region string
+
+	// AccessKeyID and SecretAccessKey are the credentials used for signing requests.
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// InsecureSkipTLSVerify disables the verification of the object store's serving certificate.
+	InsecureSkipTLSVerify bool
+}
+
+// s3Store stores snapshots in an S3-compatible object store using path-style requests signed with
+// AWS Signature Version 4.
+type s3Store struct {
+	config   S3Config
+	endpoint *url.URL
+	client   *http.Client
+
+	// now is used for signing requests; it can be overridden for testing.
+	now func() time.Time
+}
+
+// NewS3Store returns a Store saving snapshots into an S3-compatible object store.
+func NewS3Store(config S3Config) (Store, error) {
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid S3 endpoint %q", config.Endpoint)
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, errors.Errorf("invalid S3 endpoint %q: the scheme must be http or https", config.Endpoint)
+	}
+	if config.Bucket == "" {
+		return nil, errors.New("the S3 bucket is required")
+	}
+	if config.Region == "" {
+		config.Region = DefaultS3Region
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.InsecureSkipTLSVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec
+	}
+
+	return &s3Store{
+		config:   config,
+		endpoint: endpoint,
+		client:   &http.Client{Transport: transport},
+		now:      time.Now,
+	}, nil
+}
+
+func (s *s3Store) Save(ctx context.Context, name, snapshotPath string) (string, error) {
+	f, err := os.Open(snapshotPath)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to open snapshot %s", snapshotPath)
+	}
+	defer f.Close()
+
+	// The payload hash is part of the signature, so the snapshot is read twice: first for hashing, then for uploading.
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read snapshot %s", snapshotPath)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", errors.Wrapf(err, "failed to read snapshot %s", snapshotPath)
+	}
+
+	key := s.key(name)
+	req, err := s.newRequest(ctx, http.MethodPut, key, ioutil.NopCloser(f), hex.EncodeToString(h.Sum(nil)))
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = size
+
+	if err := s.do(req, http.StatusOK); err != nil {
+		return "", errors.Wrapf(err, "failed to upload snapshot to bucket %s", s.config.Bucket)
+	}
+	return fmt.Sprintf("s3://%s/%s", s.config.Bucket, key), nil
+}
+
+func (s *s3Store) Delete(ctx context.Context, name string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, s.key(name), nil, emptyPayloadHash())
+	if err != nil {
+		return err
+	}
+	// S3 returns NoContent even for objects that do not exist; some compatible stores return NotFound instead.
+	if err := s.do(req, http.StatusNoContent, http.StatusOK, http.StatusNotFound); err != nil {
+		return errors.Wrapf(err, "failed to delete snapshot from bucket %s", s.config.Bucket)
+	}
+	return nil
+}
+
+func (s *s3Store) key(name string) string {
+	return strings.TrimPrefix(path.Join(s.config.Prefix, name), "/")
+}
+
+func (s *s3Store) newRequest(ctx context.Context, method, key string, body io.ReadCloser, payloadHash string) (*http.Request, error) {
+	u := *s.endpoint
+	u.Path = path.Join("/", u.Path, s.config.Bucket, key)
+	u.RawPath = uriEncodePath(u.Path)
+
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create S3 request")
+	}
+	req = req.WithContext(ctx)
+	s.sign(req, payloadHash)
+	return req, nil
+}
+
+func (s *s3Store) do(req *http.Request, expectedStatusCodes ...int) error {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	for _, code := range expectedStatusCodes {
+		if resp.StatusCode == code {
+			return nil
+		}
+	}
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	return errors.Errorf("unexpected response %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
+
+// sign adds the AWS Signature Version 4 authorization header to the request, signing the host,
+// x-amz-content-sha256 and x-amz-date headers.
+func (s *s3Store) sign(req *http.Request, payloadHash string) {
+	now := s.now().UTC()
+	amzDate := now.Format(amzDateFormat)
+	shortDate := now.Format(amzShortFormat)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncodePath(req.URL.Path),
+		req.URL.Query().Encode(),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{shortDate, s.config.Region, s3Service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), shortDate)
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// uriEncodePath encodes a path as required by AWS Signature Version 4, i.e. encoding every byte except
+// unreserved characters and the path separator.
+func uriEncodePath(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func emptyPayloadHash() string {
+	return sha256Hex(nil)
+}
+
+func sha256Hex(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package etcdbackup implements the destinations where etcd snapshots are stored.
+package etcdbackup
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// Store defines the operations on a destination for etcd snapshots.
+type Store interface {
+	// Save stores the snapshot read from the file at snapshotPath with the given name, and returns its location.
+	Save(ctx context.Context, name, snapshotPath string) (string, error)
+
+	// Delete deletes the snapshot with the given name; deleting a snapshot that does not exist is not an error.
+	Delete(ctx context.Context, name string) error
+}
+
+// localStore stores snapshots in a directory of the local file system, e.g. a volume backed by a
+// PersistentVolumeClaim mounted into the controller manager.
+type localStore struct {
+	dir string
+}
+
+// NewLocalStore returns a Store saving snapshots into the given directory, creating it if it does not exist.
+func NewLocalStore(dir string) Store {
+	return &localStore{dir: dir}
+}
+
+func (s *localStore) Save(_ context.Context, name, snapshotPath string) (string, error) {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return "", errors.Wrapf(err, "failed to create snapshots directory %s", s.dir)
+	}
+
+	location := filepath.Join(s.dir, name)
+	if err := copyFile(snapshotPath, location); err != nil {
+		return "", errors.Wrapf(err, "failed to save snapshot to %s", location)
+	}
+	return location, nil
+}
+
+func (s *localStore) Delete(_ context.Context, name string) error {
+	location := filepath.Join(s.dir, name)
+	if err := os.Remove(location); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to delete snapshot %s", location)
+	}
+	return nil
+}
+
+// copyFile copies src into dst through a temporary file, so a partially written snapshot never shows up as dst.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
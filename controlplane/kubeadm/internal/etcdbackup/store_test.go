@@ -0,0 +1,55 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdbackup
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestLocalStore(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "etcdbackup")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	snapshotPath := filepath.Join(dir, "snapshot")
+	g.Expect(ioutil.WriteFile(snapshotPath, []byte("snapshot data"), 0600)).To(Succeed())
+
+	s := NewLocalStore(filepath.Join(dir, "backups", "cluster1"))
+
+	location, err := s.Save(context.Background(), "snapshot-1.db", snapshotPath)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(location).To(Equal(filepath.Join(dir, "backups", "cluster1", "snapshot-1.db")))
+
+	data, err := ioutil.ReadFile(location)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(data)).To(Equal("snapshot data"))
+
+	g.Expect(s.Delete(context.Background(), "snapshot-1.db")).To(Succeed())
+	_, err = os.Stat(location)
+	g.Expect(os.IsNotExist(err)).To(BeTrue())
+
+	// Deleting a snapshot that does not exist is not an error.
+	g.Expect(s.Delete(context.Background(), "snapshot-1.db")).To(Succeed())
+}
@@ -20,7 +20,7 @@ limitations under the License.
 //   - Empty type is removed
 //   - Sortable data type is removed in favor of util.MachinesByCreationTimestamp
 //   - nil checks added to account for the pointer
-//   - Added Filter, AnyFilter, and Oldest methods
+//   - Added Filter, AnyFilter, Oldest and LowestVersion methods
 //   - Added NewFilterableMachineCollectionFromMachineList initializer
 //   - Updated Has to also check for equality of Machines
 //   - Removed unused methods
@@ -30,6 +30,7 @@ package internal
 import (
 	"sort"
 
+	"github.com/blang/semver"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/machinefilters"
 	"sigs.k8s.io/cluster-api/util"
@@ -145,3 +146,24 @@ func (s FilterableMachineCollection) ConditionGetters() []conditions.Getter {
 	}
 	return res
 }
+
+// LowestVersion returns the lowest Kubernetes version among the machines in the collection, looking only at
+// major.minor.patch; it returns nil if none of the machines defines a valid version.
+func (s FilterableMachineCollection) LowestVersion() *string {
+	var lowest *semver.Version
+	var lowestVersion *string
+	for _, m := range s {
+		if m.Spec.Version == nil {
+			continue
+		}
+		v, err := util.ParseMajorMinorPatch(*m.Spec.Version)
+		if err != nil {
+			continue
+		}
+		if lowest == nil || util.IsVersionNewer(*lowest, v) {
+			lowest = &v
+			lowestVersion = m.Spec.Version
+		}
+	}
+	return lowestVersion
+}
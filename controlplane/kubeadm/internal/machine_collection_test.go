@@ -23,6 +23,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 )
 
@@ -51,6 +52,17 @@ var _ = Describe("Machine Collection", func() {
 				Expect(sortedMachines[len(sortedMachines)-1].Name).To(Equal("machine-5"))
 			})
 		})
+		Context("LowestVersion", func() {
+			It("should return nil if no machine defines a version", func() {
+				Expect(collection.LowestVersion()).To(BeNil())
+			})
+			It("should return the lowest version among the machines", func() {
+				collection["machine-1"].Spec.Version = pointer.StringPtr("v1.18.2")
+				collection["machine-2"].Spec.Version = pointer.StringPtr("v1.17.4")
+				collection["machine-3"].Spec.Version = pointer.StringPtr("v1.18.0")
+				Expect(collection.LowestVersion()).To(Equal(pointer.StringPtr("v1.17.4")))
+			})
+		})
 	})
 })
 
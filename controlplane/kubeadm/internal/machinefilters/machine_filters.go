@@ -17,6 +17,8 @@ limitations under the License.
 package machinefilters
 
 import (
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
@@ -149,6 +151,20 @@ func MatchesConfigurationHash(configHash string) Func {
 	}
 }
 
+// MatchesCertificateAuthoritiesHash returns a filter to find all machines
+// that were bootstrapped with the certificate authorities with the given hash.
+func MatchesCertificateAuthoritiesHash(caHash string) Func {
+	return func(machine *clusterv1.Machine) bool {
+		if machine == nil {
+			return false
+		}
+		if hash, ok := machine.Annotations[controlplanev1.CertificateAuthoritiesHashAnnotation]; ok {
+			return hash == caHash
+		}
+		return false
+	}
+}
+
 // IsReady returns a filter to find all machines with the ReadyCondition equals to True.
 func IsReady() Func {
 	return func(machine *clusterv1.Machine) bool {
@@ -170,6 +186,26 @@ func OlderThan(t *metav1.Time) Func {
 	}
 }
 
+// ShouldRolloutBefore returns a filter to find all machines whose certificates
+// will expire within the number of days specified in the KubeadmControlPlane RolloutBefore.
+func ShouldRolloutBefore(reconciliationTime *metav1.Time, rolloutBefore *controlplanev1.RolloutBefore) Func {
+	return func(machine *clusterv1.Machine) bool {
+		if machine == nil || rolloutBefore == nil || rolloutBefore.CertificatesExpiryDays == nil {
+			return false
+		}
+		value, ok := machine.Annotations[controlplanev1.MachineCertificatesExpiryDateAnnotation]
+		if !ok {
+			return false
+		}
+		expiry, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return false
+		}
+		rolloutThreshold := reconciliationTime.Add(time.Duration(*rolloutBefore.CertificatesExpiryDays) * 24 * time.Hour)
+		return expiry.Before(rolloutThreshold)
+	}
+}
+
 // HasAnnotationKey returns a filter to find all machines that have the
 // specified Annotation key present
 func HasAnnotationKey(key string) Func {
@@ -25,6 +25,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/machinefilters"
 )
@@ -118,6 +119,26 @@ func TestMatchesConfigurationHash(t *testing.T) {
 	})
 }
 
+func TestMatchesCertificateAuthoritiesHash(t *testing.T) {
+	t.Run("machine with certificate authorities hash returns true", func(t *testing.T) {
+		g := NewWithT(t)
+		m := &clusterv1.Machine{}
+		m.SetAnnotations(map[string]string{controlplanev1.CertificateAuthoritiesHashAnnotation: "hashValue"})
+		g.Expect(machinefilters.MatchesCertificateAuthoritiesHash("hashValue")(m)).To(BeTrue())
+	})
+	t.Run("machine with wrong certificate authorities hash returns false", func(t *testing.T) {
+		g := NewWithT(t)
+		m := &clusterv1.Machine{}
+		m.SetAnnotations(map[string]string{controlplanev1.CertificateAuthoritiesHashAnnotation: "notHashValue"})
+		g.Expect(machinefilters.MatchesCertificateAuthoritiesHash("hashValue")(m)).To(BeFalse())
+	})
+	t.Run("machine without certificate authorities hash returns false", func(t *testing.T) {
+		g := NewWithT(t)
+		m := &clusterv1.Machine{}
+		g.Expect(machinefilters.MatchesCertificateAuthoritiesHash("hashValue")(m)).To(BeFalse())
+	})
+}
+
 func TestOlderThan(t *testing.T) {
 	t.Run("machine with creation timestamp older than given returns true", func(t *testing.T) {
 		g := NewWithT(t)
@@ -142,6 +163,45 @@ func TestOlderThan(t *testing.T) {
 	})
 }
 
+func TestShouldRolloutBefore(t *testing.T) {
+	now := metav1.Now()
+	rolloutBefore := &controlplanev1.RolloutBefore{
+		CertificatesExpiryDays: pointer.Int32Ptr(21),
+	}
+	withExpiry := func(expiry time.Time) *clusterv1.Machine {
+		m := &clusterv1.Machine{}
+		m.SetAnnotations(map[string]string{controlplanev1.MachineCertificatesExpiryDateAnnotation: expiry.Format(time.RFC3339)})
+		return m
+	}
+
+	t.Run("machine with certificates expiring within the rollout window returns true", func(t *testing.T) {
+		g := NewWithT(t)
+		m := withExpiry(now.Add(10 * 24 * time.Hour))
+		g.Expect(machinefilters.ShouldRolloutBefore(&now, rolloutBefore)(m)).To(BeTrue())
+	})
+	t.Run("machine with certificates expiring after the rollout window returns false", func(t *testing.T) {
+		g := NewWithT(t)
+		m := withExpiry(now.Add(30 * 24 * time.Hour))
+		g.Expect(machinefilters.ShouldRolloutBefore(&now, rolloutBefore)(m)).To(BeFalse())
+	})
+	t.Run("machine without the certificates expiry annotation returns false", func(t *testing.T) {
+		g := NewWithT(t)
+		m := &clusterv1.Machine{}
+		g.Expect(machinefilters.ShouldRolloutBefore(&now, rolloutBefore)(m)).To(BeFalse())
+	})
+	t.Run("machine with an invalid certificates expiry annotation returns false", func(t *testing.T) {
+		g := NewWithT(t)
+		m := &clusterv1.Machine{}
+		m.SetAnnotations(map[string]string{controlplanev1.MachineCertificatesExpiryDateAnnotation: "invalid"})
+		g.Expect(machinefilters.ShouldRolloutBefore(&now, rolloutBefore)(m)).To(BeFalse())
+	})
+	t.Run("returns false if rolloutBefore is not set", func(t *testing.T) {
+		g := NewWithT(t)
+		m := withExpiry(now.Add(10 * 24 * time.Hour))
+		g.Expect(machinefilters.ShouldRolloutBefore(&now, nil)(m)).To(BeFalse())
+	})
+}
+
 func TestHashAnnotationKey(t *testing.T) {
 	t.Run("machine with specified annotation returns true", func(t *testing.T) {
 		g := NewWithT(t)
@@ -66,7 +66,7 @@ func NewDialer(p Proxy, options ...func(*Dialer) error) (*Dialer, error) {
 	if err != nil {
 		return nil, err
 	}
-	proxyTransport, upgrader, err := spdy.RoundTripperFor(p.KubeConfig)
+	proxyTransport, upgrader, err := roundTripperFor(p.KubeConfig)
 	if err != nil {
 		return nil, err
 	}
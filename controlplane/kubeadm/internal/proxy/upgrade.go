@@ -0,0 +1,129 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	apispdy "k8s.io/apimachinery/pkg/util/httpstream/spdy"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// roundTripperFor returns a round tripper and upgrader to use with SPDY, like spdy.RoundTripperFor, but
+// dialing the API server with the Dial function of the config if any, e.g. for reaching it through a tunnel.
+func roundTripperFor(config *rest.Config) (http.RoundTripper, spdy.Upgrader, error) {
+	if config.Dial == nil {
+		return spdy.RoundTripperFor(config)
+	}
+
+	tlsConfig, err := rest.TLSConfigFor(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	upgradeRoundTripper := &dialUpgradeRoundTripper{
+		dial:      config.Dial,
+		tlsConfig: tlsConfig,
+	}
+	wrapper, err := rest.HTTPWrappersForConfig(config, upgradeRoundTripper)
+	if err != nil {
+		return nil, nil, err
+	}
+	return wrapper, upgradeRoundTripper, nil
+}
+
+// dialUpgradeRoundTripper upgrades a request to SPDY over a connection created with a dial function.
+// Like the upstream SpdyRoundTripper, it is meant to be used for a single request.
+type dialUpgradeRoundTripper struct {
+	dial      func(ctx context.Context, network, address string) (net.Conn, error)
+	tlsConfig *tls.Config
+
+	conn net.Conn
+}
+
+// RoundTrip dials the host of the request and upgrades the connection.
+func (d *dialUpgradeRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	if req.URL.Port() == "" {
+		port := "80"
+		if req.URL.Scheme == "https" {
+			port = "443"
+		}
+		host = net.JoinHostPort(req.URL.Hostname(), port)
+	}
+
+	conn, err := d.dial(req.Context(), "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL.Scheme == "https" {
+		tlsConfig := &tls.Config{}
+		if d.tlsConfig != nil {
+			tlsConfig = d.tlsConfig.Clone()
+		}
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = req.URL.Hostname()
+		}
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	clone := req.Clone(req.Context())
+	clone.Header.Add(httpstream.HeaderConnection, httpstream.HeaderUpgrade)
+	clone.Header.Add(httpstream.HeaderUpgrade, apispdy.HeaderSpdy31)
+	if err := clone.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), clone)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	d.conn = conn
+	return resp, nil
+}
+
+// NewConnection validates the upgrade response and creates the SPDY connection.
+func (d *dialUpgradeRoundTripper) NewConnection(resp *http.Response) (httpstream.Connection, error) {
+	connectionHeader := strings.ToLower(resp.Header.Get(httpstream.HeaderConnection))
+	upgradeHeader := strings.ToLower(resp.Header.Get(httpstream.HeaderUpgrade))
+	if resp.StatusCode != http.StatusSwitchingProtocols ||
+		!strings.Contains(connectionHeader, strings.ToLower(httpstream.HeaderUpgrade)) ||
+		!strings.Contains(upgradeHeader, strings.ToLower(apispdy.HeaderSpdy31)) {
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, errors.Errorf("unable to upgrade connection: %s", strings.TrimSpace(string(body)))
+	}
+
+	return apispdy.NewClientConnection(d.conn)
+}
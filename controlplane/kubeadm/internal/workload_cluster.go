@@ -18,13 +18,10 @@ package internal
 
 import (
 	"context"
-	"crypto/rand"
-	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
-	"crypto/x509/pkix"
 	"fmt"
-	"math/big"
+	"io"
 	"time"
 
 	"github.com/blang/semver"
@@ -39,6 +36,7 @@ import (
 	"sigs.k8s.io/cluster-api/util/certs"
 	containerutil "sigs.k8s.io/cluster-api/util/container"
 	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/signer"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -58,6 +56,7 @@ type WorkloadCluster interface {
 	ClusterStatus(ctx context.Context) (ClusterStatus, error)
 	ControlPlaneIsHealthy(ctx context.Context) (HealthCheckResult, error)
 	EtcdIsHealthy(ctx context.Context) (HealthCheckResult, error)
+	ExternalEtcdIsHealthy(ctx context.Context, endpoints []string) error
 
 	// Upgrade related tasks.
 	ReconcileKubeletRBACBinding(ctx context.Context, version semver.Version) error
@@ -74,15 +73,24 @@ type WorkloadCluster interface {
 	ForwardEtcdLeadership(ctx context.Context, machine *clusterv1.Machine, leaderCandidate *clusterv1.Machine) error
 	AllowBootstrapTokensToGetNodes(ctx context.Context) error
 
+	// Certificates related tasks.
+	GetCertificatesExpiry(ctx context.Context, nodeName string, apiServerPort int) (time.Time, error)
+	UpdateClusterInfoCertificateAuthorities(ctx context.Context, caData []byte) error
+
+	// Backup related tasks.
+	EtcdSnapshot(ctx context.Context) (io.ReadCloser, error)
+
 	// State recovery tasks.
 	ReconcileEtcdMembers(ctx context.Context) error
 }
 
 // Workload defines operations on workload clusters.
 type Workload struct {
-	Client              ctrlclient.Client
-	CoreDNSMigrator     coreDNSMigrator
-	etcdClientGenerator etcdClientFor
+	Client                   ctrlclient.Client
+	CoreDNSMigrator          coreDNSMigrator
+	etcdClientGenerator      etcdClientFor
+	etcdTLSConfig            *tls.Config
+	servingCertificateGetter servingCertificateGetter
 }
 
 func (w *Workload) getControlPlaneNodes(ctx context.Context) (*corev1.NodeList, error) {
@@ -291,52 +299,21 @@ func (w *Workload) ClusterStatus(ctx context.Context) (ClusterStatus, error) {
 	return status, nil
 }
 
-func generateClientCert(caCertEncoded, caKeyEncoded []byte) (tls.Certificate, error) {
+func generateClientCert(ctx context.Context, s signer.Signer) (tls.Certificate, error) {
 	privKey, err := certs.NewPrivateKey()
 	if err != nil {
 		return tls.Certificate{}, err
 	}
-	caCert, err := certs.DecodeCertPEM(caCertEncoded)
-	if err != nil {
-		return tls.Certificate{}, err
-	}
-	caKey, err := certs.DecodePrivateKeyPEM(caKeyEncoded)
-	if err != nil {
-		return tls.Certificate{}, err
-	}
-	x509Cert, err := newClientCert(caCert, privKey, caKey)
-	if err != nil {
-		return tls.Certificate{}, err
-	}
-	return tls.X509KeyPair(certs.EncodeCertPEM(x509Cert), certs.EncodePrivateKeyPEM(privKey))
-}
-
-func newClientCert(caCert *x509.Certificate, key *rsa.PrivateKey, caKey *rsa.PrivateKey) (*x509.Certificate, error) {
-	cfg := certs.Config{
+	cfg := &certs.Config{
 		CommonName: "cluster-api.x-k8s.io",
+		Usages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		Duration:   time.Hour * 24 * 365 * 10, // 10 years
 	}
-
-	now := time.Now().UTC()
-
-	tmpl := x509.Certificate{
-		SerialNumber: new(big.Int).SetInt64(0),
-		Subject: pkix.Name{
-			CommonName:   cfg.CommonName,
-			Organization: cfg.Organization,
-		},
-		NotBefore:   now.Add(time.Minute * -5),
-		NotAfter:    now.Add(time.Hour * 24 * 365 * 10), // 10 years
-		KeyUsage:    x509.KeyUsageDigitalSignature,
-		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
-	}
-
-	b, err := x509.CreateCertificate(rand.Reader, &tmpl, caCert, key.Public(), caKey)
+	x509Cert, err := s.Sign(ctx, privKey, cfg)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to create signed client certificate: %+v", tmpl)
+		return tls.Certificate{}, errors.Wrap(err, "failed to sign client certificate")
 	}
-
-	c, err := x509.ParseCertificate(b)
-	return c, errors.WithStack(err)
+	return tls.X509KeyPair(certs.EncodeCertPEM(x509Cert), certs.EncodePrivateKeyPEM(privKey))
 }
 
 func staticPodName(component, nodeName string) string {
@@ -0,0 +1,161 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal/proxy"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultAPIServerPort is the port where the API server listens on control plane nodes, unless differently
+	// specified in the kubeadm InitConfiguration/JoinConfiguration.
+	DefaultAPIServerPort = 6443
+
+	etcdClientPort = 2379
+
+	clusterInfoConfigMapName = "cluster-info"
+	clusterInfoKubeconfigKey = "kubeconfig"
+)
+
+// servingCertificateGetter gets the serving certificate of a component running in a static pod on a control plane node.
+type servingCertificateGetter interface {
+	getServingCertificate(ctx context.Context, podName string, port int, tlsConfig *tls.Config) (*x509.Certificate, error)
+}
+
+// proxyServingCertificateGetter gets serving certificates by opening a TLS connection to the pods
+// using the Kubernetes API Server port-forwarding.
+type proxyServingCertificateGetter struct {
+	restConfig *rest.Config
+}
+
+func (g *proxyServingCertificateGetter) getServingCertificate(ctx context.Context, podName string, port int, tlsConfig *tls.Config) (*x509.Certificate, error) {
+	p := proxy.Proxy{
+		Kind:       "pods",
+		Namespace:  metav1.NamespaceSystem,
+		KubeConfig: g.restConfig,
+		Port:       port,
+	}
+	dialer, err := proxy.NewDialer(p)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := dialer.DialContextWithAddr(ctx, podName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to pod %s", podName)
+	}
+	defer conn.Close()
+
+	// NB. the TLS connection is used only for reading the serving certificate, so it is not required to verify it.
+	cfg := &tls.Config{}
+	if tlsConfig != nil {
+		cfg = tlsConfig.Clone()
+	}
+	cfg.InsecureSkipVerify = true
+	tlsConn := tls.Client(conn, cfg)
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, errors.Wrapf(err, "failed TLS handshake with pod %s", podName)
+	}
+	certs := tlsConn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, errors.Errorf("pod %s did not provide a serving certificate", podName)
+	}
+	return certs[0], nil
+}
+
+// GetCertificatesExpiry returns the earliest expiry date among the serving certificates of the API server
+// and of etcd running on the given control plane node.
+func (w *Workload) GetCertificatesExpiry(ctx context.Context, nodeName string, apiServerPort int) (time.Time, error) {
+	if w.servingCertificateGetter == nil {
+		return time.Time{}, errors.New("failed to get certificates expiry: no connection to the workload cluster")
+	}
+
+	apiServerCert, err := w.servingCertificateGetter.getServingCertificate(ctx, staticPodName("kube-apiserver", nodeName), apiServerPort, &tls.Config{})
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "failed to get the API server serving certificate for node %s", nodeName)
+	}
+	expiry := apiServerCert.NotAfter
+
+	// Nodes using an external etcd cluster do not run an etcd static pod, so there is no serving certificate to check.
+	etcdPodKey := ctrlclient.ObjectKey{
+		Namespace: metav1.NamespaceSystem,
+		Name:      staticPodName("etcd", nodeName),
+	}
+	if err := w.Client.Get(ctx, etcdPodKey, &corev1.Pod{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return expiry, nil
+		}
+		return time.Time{}, errors.Wrapf(err, "failed to get the etcd pod for node %s", nodeName)
+	}
+
+	etcdCert, err := w.servingCertificateGetter.getServingCertificate(ctx, staticPodName("etcd", nodeName), etcdClientPort, w.etcdTLSConfig)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "failed to get the etcd serving certificate for node %s", nodeName)
+	}
+	if etcdCert.NotAfter.Before(expiry) {
+		expiry = etcdCert.NotAfter
+	}
+	return expiry, nil
+}
+
+// UpdateClusterInfoCertificateAuthorities sets the CA certificates trusted by the nodes joining the cluster, i.e. the
+// CA certificates of the kubeconfig stored in the cluster-info ConfigMap used for the kubeadm discovery.
+// NOTE: the signatures of the ConfigMap are updated by the bootstrap signer of the workload cluster.
+func (w *Workload) UpdateClusterInfoCertificateAuthorities(ctx context.Context, caData []byte) error {
+	configMapKey := ctrlclient.ObjectKey{Name: clusterInfoConfigMapName, Namespace: metav1.NamespacePublic}
+	clusterInfo, err := w.getConfigMap(ctx, configMapKey)
+	if err != nil {
+		return err
+	}
+
+	config, err := clientcmd.Load([]byte(clusterInfo.Data[clusterInfoKubeconfigKey]))
+	if err != nil {
+		return errors.Wrap(err, "failed to parse the cluster-info kubeconfig")
+	}
+	changed := false
+	for _, cluster := range config.Clusters {
+		if !bytes.Equal(cluster.CertificateAuthorityData, caData) {
+			cluster.CertificateAuthorityData = caData
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	out, err := clientcmd.Write(*config)
+	if err != nil {
+		return errors.Wrap(err, "failed to serialize the cluster-info kubeconfig")
+	}
+	clusterInfo.Data[clusterInfoKubeconfigKey] = string(out)
+	if err := w.Client.Update(ctx, clusterInfo); err != nil {
+		return errors.Wrap(err, "error updating cluster-info ConfigMap")
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeServingCertificateGetter struct {
+	certs map[string]*x509.Certificate
+}
+
+func (f *fakeServingCertificateGetter) getServingCertificate(_ context.Context, podName string, _ int, _ *tls.Config) (*x509.Certificate, error) {
+	cert, ok := f.certs[podName]
+	if !ok {
+		return nil, errors.Errorf("pod %s not found", podName)
+	}
+	return cert, nil
+}
+
+func TestGetCertificatesExpiry(t *testing.T) {
+	apiServerExpiry := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	etcdExpiry := time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		objs    []runtime.Object
+		certs   map[string]*x509.Certificate
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name: "returns the earliest expiry date",
+			objs: []runtime.Object{etcdPod("etcd-node1")},
+			certs: map[string]*x509.Certificate{
+				"kube-apiserver-node1": {NotAfter: apiServerExpiry},
+				"etcd-node1":           {NotAfter: etcdExpiry},
+			},
+			want: etcdExpiry,
+		},
+		{
+			name: "returns the API server expiry date for nodes using an external etcd",
+			certs: map[string]*x509.Certificate{
+				"kube-apiserver-node1": {NotAfter: apiServerExpiry},
+			},
+			want: apiServerExpiry,
+		},
+		{
+			name: "fails if the API server certificate can't be read",
+			objs: []runtime.Object{etcdPod("etcd-node1")},
+			certs: map[string]*x509.Certificate{
+				"etcd-node1": {NotAfter: etcdExpiry},
+			},
+			wantErr: true,
+		},
+		{
+			name: "fails if the etcd certificate can't be read",
+			objs: []runtime.Object{etcdPod("etcd-node1")},
+			certs: map[string]*x509.Certificate{
+				"kube-apiserver-node1": {NotAfter: apiServerExpiry},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			scheme := runtime.NewScheme()
+			g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+			w := &Workload{
+				Client:                   fake.NewFakeClientWithScheme(scheme, tt.objs...),
+				servingCertificateGetter: &fakeServingCertificateGetter{certs: tt.certs},
+			}
+			got, err := w.GetCertificatesExpiry(context.TODO(), "node1", DefaultAPIServerPort)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestUpdateClusterInfoCertificateAuthorities(t *testing.T) {
+	g := NewWithT(t)
+
+	clusterInfo := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterInfoConfigMapName,
+			Namespace: metav1.NamespacePublic,
+		},
+		Data: map[string]string{
+			clusterInfoKubeconfigKey: `apiVersion: v1
+kind: Config
+clusters:
+- name: ""
+  cluster:
+    certificate-authority-data: b2xk
+    server: https://10.0.0.1:6443
+contexts: null
+current-context: ""
+preferences: {}
+users: null
+`,
+			"jws-kubeconfig-abcdef": "signature",
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	w := &Workload{
+		Client: fake.NewFakeClientWithScheme(scheme, clusterInfo),
+	}
+	g.Expect(w.UpdateClusterInfoCertificateAuthorities(context.TODO(), []byte("bundle"))).To(Succeed())
+
+	updated := &corev1.ConfigMap{}
+	g.Expect(w.Client.Get(context.TODO(), ctrlclient.ObjectKey{Name: clusterInfoConfigMapName, Namespace: metav1.NamespacePublic}, updated)).To(Succeed())
+	config, err := clientcmd.Load([]byte(updated.Data[clusterInfoKubeconfigKey]))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(config.Clusters).To(HaveKey(""))
+	g.Expect(config.Clusters[""].CertificateAuthorityData).To(Equal([]byte("bundle")))
+	g.Expect(config.Clusters[""].Server).To(Equal("https://10.0.0.1:6443"))
+	g.Expect(updated.Data).To(HaveKeyWithValue("jws-kubeconfig-abcdef", "signature"))
+}
@@ -18,6 +18,7 @@ package internal
 
 import (
 	"context"
+	"io"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -32,6 +33,7 @@ import (
 type etcdClientFor interface {
 	forNodes(ctx context.Context, nodes []corev1.Node) (*etcd.Client, error)
 	forLeader(ctx context.Context, nodes []corev1.Node) (*etcd.Client, error)
+	forEndpoints(ctx context.Context, endpoints []string) (*etcd.Client, error)
 }
 
 // EtcdIsHealthy runs checks for every etcd member in the cluster to satisfy our definition of healthy.
@@ -132,6 +134,59 @@ func (w *Workload) EtcdIsHealthy(ctx context.Context) (HealthCheckResult, error)
 	return response, nil
 }
 
+// ExternalEtcdIsHealthy runs checks for every endpoint of an external etcd cluster, connecting to each of them
+// directly with the etcd client certificate supplied for the cluster.
+// Unlike EtcdIsHealthy, members are not matched with control plane nodes, given that they are not managed by
+// the control plane.
+func (w *Workload) ExternalEtcdIsHealthy(ctx context.Context, endpoints []string) error {
+	if len(endpoints) == 0 {
+		return errors.New("no external etcd endpoints defined")
+	}
+
+	var knownClusterID uint64
+	var alarmsChecked bool
+	var errs []error
+	for _, endpoint := range endpoints {
+		etcdClient, err := w.etcdClientGenerator.forEndpoints(ctx, []string{endpoint})
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to create etcd client for endpoint %s", endpoint))
+			continue
+		}
+
+		// List etcd members. This checks that the member is healthy, because the request goes through consensus.
+		members, err := etcdClient.Members(ctx)
+		etcdClient.Close()
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to list etcd members using endpoint %s", endpoint))
+			continue
+		}
+
+		// Check that the endpoint belongs to the same cluster as all other endpoints.
+		clusterID := uint64(0)
+		if len(members) > 0 {
+			clusterID = members[0].ClusterID
+		}
+		if knownClusterID == 0 {
+			knownClusterID = clusterID
+		} else if knownClusterID != clusterID {
+			errs = append(errs, errors.Errorf("etcd endpoint %s reports cluster ID %d, but all previously seen endpoints report cluster ID %d", endpoint, clusterID, knownClusterID))
+			continue
+		}
+
+		// Check that the members report no alarms; alarms are the same for all the endpoints of a cluster,
+		// so they are reported only once.
+		if !alarmsChecked {
+			alarmsChecked = true
+			for _, member := range members {
+				if len(member.Alarms) > 0 {
+					errs = append(errs, errors.Errorf("etcd member %s reports alarms: %v", member.Name, member.Alarms))
+				}
+			}
+		}
+	}
+	return kerrors.NewAggregate(errs)
+}
+
 // ReconcileEtcdMembers iterates over all etcd members and finds members that do not have corresponding nodes.
 // If there are any such members, it deletes them from etcd and removes their nodes from the kubeadm configmap so that kubeadm does not run etcd health checks on them.
 func (w *Workload) ReconcileEtcdMembers(ctx context.Context) error {
@@ -289,3 +344,35 @@ func (w *Workload) ForwardEtcdLeadership(ctx context.Context, machine *clusterv1
 	}
 	return nil
 }
+
+// EtcdSnapshot streams a snapshot of the etcd database from the etcd leader.
+// The caller is responsible for closing the returned reader, which closes the connection to etcd as well.
+func (w *Workload) EtcdSnapshot(ctx context.Context) (io.ReadCloser, error) {
+	nodes, err := w.getControlPlaneNodes(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list control plane nodes")
+	}
+
+	etcdClient, err := w.etcdClientGenerator.forLeader(ctx, nodes.Items)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create etcd client")
+	}
+
+	snapshot, err := etcdClient.Snapshot(ctx)
+	if err != nil {
+		etcdClient.Close()
+		return nil, err
+	}
+	return &etcdSnapshotReader{ReadCloser: snapshot, client: etcdClient}, nil
+}
+
+// etcdSnapshotReader closes the etcd client once the snapshot has been read.
+type etcdSnapshotReader struct {
+	io.ReadCloser
+	client *etcd.Client
+}
+
+func (r *etcdSnapshotReader) Close() error {
+	defer r.client.Close()
+	return r.ReadCloser.Close()
+}
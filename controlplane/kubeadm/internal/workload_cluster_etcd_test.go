@@ -83,6 +83,82 @@ func TestWorkload_EtcdIsHealthy(t *testing.T) {
 	}
 }
 
+func TestWorkload_ExternalEtcdIsHealthy(t *testing.T) {
+	members := []*pb.Member{
+		{Name: "etcd-1", ID: uint64(1)},
+		{Name: "etcd-2", ID: uint64(2)},
+	}
+
+	tests := []struct {
+		name                string
+		endpoints           []string
+		etcdClientGenerator etcdClientFor
+		expectErr           bool
+	}{
+		{
+			name:      "returns no error if all the endpoints are healthy",
+			endpoints: []string{"https://etcd-1:2379", "https://etcd-2:2379"},
+			etcdClientGenerator: &fakeEtcdClientGenerator{
+				forEndpointsClient: &etcd.Client{
+					EtcdClient: &fake2.FakeEtcdClient{
+						MemberListResponse: &clientv3.MemberListResponse{
+							Header:  &pb.ResponseHeader{ClusterId: uint64(1)},
+							Members: members,
+						},
+						AlarmResponse: &clientv3.AlarmResponse{},
+					},
+				},
+			},
+		},
+		{
+			name:                "returns error if no endpoints are defined",
+			etcdClientGenerator: &fakeEtcdClientGenerator{},
+			expectErr:           true,
+		},
+		{
+			name:                "returns error if it fails to connect to an endpoint",
+			endpoints:           []string{"https://etcd-1:2379"},
+			etcdClientGenerator: &fakeEtcdClientGenerator{forEndpointsErr: errors.New("no client")},
+			expectErr:           true,
+		},
+		{
+			name:      "returns error if a member reports alarms",
+			endpoints: []string{"https://etcd-1:2379"},
+			etcdClientGenerator: &fakeEtcdClientGenerator{
+				forEndpointsClient: &etcd.Client{
+					EtcdClient: &fake2.FakeEtcdClient{
+						MemberListResponse: &clientv3.MemberListResponse{
+							Header:  &pb.ResponseHeader{ClusterId: uint64(1)},
+							Members: members,
+						},
+						AlarmResponse: &clientv3.AlarmResponse{
+							Alarms: []*pb.AlarmMember{
+								{MemberID: uint64(2), Alarm: pb.AlarmType_NOSPACE},
+							},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			w := &Workload{
+				etcdClientGenerator: tt.etcdClientGenerator,
+			}
+			err := w.ExternalEtcdIsHealthy(context.TODO(), tt.endpoints)
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
+
 func TestUpdateEtcdVersionInKubeadmConfigMap(t *testing.T) {
 	kubeadmConfig := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
@@ -507,10 +583,12 @@ func TestForwardEtcdLeadership(t *testing.T) {
 }
 
 type fakeEtcdClientGenerator struct {
-	forNodesClient  *etcd.Client
-	forLeaderClient *etcd.Client
-	forNodesErr     error
-	forLeaderErr    error
+	forNodesClient     *etcd.Client
+	forLeaderClient    *etcd.Client
+	forEndpointsClient *etcd.Client
+	forNodesErr        error
+	forLeaderErr       error
+	forEndpointsErr    error
 }
 
 func (c *fakeEtcdClientGenerator) forNodes(_ context.Context, _ []corev1.Node) (*etcd.Client, error) {
@@ -521,6 +599,10 @@ func (c *fakeEtcdClientGenerator) forLeader(_ context.Context, _ []corev1.Node)
 	return c.forLeaderClient, c.forLeaderErr
 }
 
+func (c *fakeEtcdClientGenerator) forEndpoints(_ context.Context, _ []string) (*etcd.Client, error) {
+	return c.forEndpointsClient, c.forEndpointsErr
+}
+
 type podOption func(*corev1.Pod)
 
 func etcdPod(name string, options ...podOption) *corev1.Pod {
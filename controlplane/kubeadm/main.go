@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"math/rand"
 	"net/http"
@@ -33,12 +34,21 @@ import (
 	clusterv1alpha3 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	kubeadmbootstrapv1alpha3 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/version"
+	"sigs.k8s.io/cluster-api/controllers/metrics"
+	"sigs.k8s.io/cluster-api/controllers/remote"
 	kubeadmcontrolplanev1alpha3 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha3"
 	kubeadmcontrolplanecontrollers "sigs.k8s.io/cluster-api/controlplane/kubeadm/controllers"
+	expv1alpha3 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/feature"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/cluster-api/util/tracing"
+	"sigs.k8s.io/cluster-api/util/tunnel"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -52,6 +62,7 @@ func init() {
 
 	_ = clientgoscheme.AddToScheme(scheme)
 	_ = clusterv1alpha3.AddToScheme(scheme)
+	_ = expv1alpha3.AddToScheme(scheme)
 	_ = kubeadmcontrolplanev1alpha3.AddToScheme(scheme)
 	_ = kubeadmbootstrapv1alpha3.AddToScheme(scheme)
 	// +kubebuilder:scaffold:scheme
@@ -65,7 +76,14 @@ var (
 	leaderElectionRetryPeriod      time.Duration
 	watchNamespace                 string
 	profilerAddress                string
+	tracingExporter                string
+	tracingOTLPEndpoint            string
+	tunnelBindAddress              string
+	tunnelCertFile                 string
+	tunnelKeyFile                  string
 	kubeadmControlPlaneConcurrency int
+	etcdBackupConcurrency          int
+	etcdBackupsDir                 string
 	syncPeriod                     time.Duration
 	webhookPort                    int
 )
@@ -93,14 +111,37 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&profilerAddress, "profiler-address", "",
 		"Bind address to expose the pprof profiler (e.g. localhost:6060)")
 
+	fs.StringVar(&tracingExporter, "tracing-exporter", tracing.ExporterNone,
+		"Exporter for the OpenTelemetry traces of the reconciles, one of 'stdout' or 'otlp'. If unspecified, tracing is disabled.")
+
+	fs.StringVar(&tracingOTLPEndpoint, "tracing-otlp-endpoint", tracing.DefaultOTLPEndpoint,
+		"The OTLP/HTTP endpoint traces are sent to when using the 'otlp' tracing exporter.")
+
+	fs.StringVar(&tunnelBindAddress, "tunnel-bind-address", "",
+		"The address the reverse tunnel server binds to, for reaching workload clusters through the tunnel agent running in them (e.g. :8443). If unspecified, the tunnel server is disabled.")
+
+	fs.StringVar(&tunnelCertFile, "tunnel-tls-cert-file", "",
+		"The TLS certificate presented by the reverse tunnel server to the tunnel agents.")
+
+	fs.StringVar(&tunnelKeyFile, "tunnel-tls-private-key-file", "",
+		"The TLS private key of the reverse tunnel server.")
+
 	fs.IntVar(&kubeadmControlPlaneConcurrency, "kubeadmcontrolplane-concurrency", 10,
 		"Number of kubeadm control planes to process simultaneously")
 
+	fs.IntVar(&etcdBackupConcurrency, "etcdbackup-concurrency", 10,
+		"Number of etcd backups to process simultaneously")
+
+	fs.StringVar(&etcdBackupsDir, "etcd-backups-dir", kubeadmcontrolplanecontrollers.DefaultEtcdBackupsDir,
+		"Directory where the PersistentVolumeClaims used as destination for etcd backups are mounted, each one in a sub directory named as the claim")
+
 	fs.DurationVar(&syncPeriod, "sync-period", 10*time.Minute,
 		"The minimum interval at which watched resources are reconciled (e.g. 15m)")
 
 	fs.IntVar(&webhookPort, "webhook-port", 0,
 		"Webhook Server port, disabled by default. When enabled, the manager will only work as webhook server, no reconcilers are installed.")
+
+	feature.MutableGates.AddFlag(fs)
 }
 func main() {
 	rand.Seed(time.Now().UnixNano())
@@ -118,6 +159,16 @@ func main() {
 		}()
 	}
 
+	shutdownTracing, err := tracing.Setup(tracing.Options{
+		ServiceName:  "capi-kubeadm-control-plane-controller-manager",
+		Exporter:     tracingExporter,
+		OTLPEndpoint: tracingOTLPEndpoint,
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:             scheme,
 		MetricsBindAddress: metricsAddr,
@@ -137,6 +188,8 @@ func main() {
 	}
 
 	setupReconcilers(mgr)
+	setupTunnel(mgr)
+	setupMetrics(mgr)
 	setupWebhooks(mgr)
 
 	// +kubebuilder:scaffold:builder
@@ -145,6 +198,10 @@ func main() {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
+
+	if err := shutdownTracing(context.Background()); err != nil {
+		setupLog.Error(err, "problem flushing traces")
+	}
 }
 
 func setupReconcilers(mgr ctrl.Manager) {
@@ -152,6 +209,11 @@ func setupReconcilers(mgr ctrl.Manager) {
 		return
 	}
 
+	// Emit an Event for each condition transition of the objects reconciled by this manager, once patched.
+	conditions.AddChangeHandler(record.ConditionChangeHandler(
+		record.NewRateLimitedRecorder(mgr.GetEventRecorderFor("kubeadm-control-plane-manager"), record.DefaultDuplicateEventsInterval),
+	))
+
 	if err := (&kubeadmcontrolplanecontrollers.KubeadmControlPlaneReconciler{
 		Client: mgr.GetClient(),
 		Log:    ctrl.Log.WithName("controllers").WithName("KubeadmControlPlane"),
@@ -159,6 +221,49 @@ func setupReconcilers(mgr ctrl.Manager) {
 		setupLog.Error(err, "unable to create controller", "controller", "KubeadmControlPlane")
 		os.Exit(1)
 	}
+
+	if err := (&kubeadmcontrolplanecontrollers.EtcdBackupReconciler{
+		Client:     mgr.GetClient(),
+		Log:        ctrl.Log.WithName("controllers").WithName("EtcdBackup"),
+		BackupsDir: etcdBackupsDir,
+	}).SetupWithManager(mgr, concurrency(etcdBackupConcurrency)); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "EtcdBackup")
+		os.Exit(1)
+	}
+}
+
+func setupMetrics(mgr ctrl.Manager) {
+	if webhookPort != 0 {
+		return
+	}
+
+	if err := ctrlmetrics.Registry.Register(&metrics.StateCollector{
+		Client:  mgr.GetClient(),
+		Log:     ctrl.Log.WithName("metrics").WithName("StateCollector"),
+		Listers: []metrics.StateLister{kubeadmcontrolplanecontrollers.KubeadmControlPlaneStates},
+	}); err != nil {
+		setupLog.Error(err, "unable to register metrics collector")
+		os.Exit(1)
+	}
+}
+
+func setupTunnel(mgr ctrl.Manager) {
+	if webhookPort != 0 || tunnelBindAddress == "" {
+		return
+	}
+
+	server := &tunnel.Server{
+		Log:         ctrl.Log.WithName("tunnel"),
+		Client:      mgr.GetClient(),
+		BindAddress: tunnelBindAddress,
+		CertFile:    tunnelCertFile,
+		KeyFile:     tunnelKeyFile,
+	}
+	if err := mgr.Add(server); err != nil {
+		setupLog.Error(err, "unable to add tunnel server")
+		os.Exit(1)
+	}
+	remote.SetClusterDialer(server.DialContextFor)
 }
 
 func setupWebhooks(mgr ctrl.Manager) {
@@ -170,6 +275,11 @@ func setupWebhooks(mgr ctrl.Manager) {
 		setupLog.Error(err, "unable to create webhook", "webhook", "KubeadmControlPlane")
 		os.Exit(1)
 	}
+
+	if err := (&kubeadmcontrolplanev1alpha3.EtcdBackup{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "EtcdBackup")
+		os.Exit(1)
+	}
 }
 
 func concurrency(c int) controller.Options {
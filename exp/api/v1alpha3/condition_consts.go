@@ -0,0 +1,57 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+
+// Conditions and condition Reasons for the MachinePool object
+
+const (
+	// ReplicasReadyCondition reports an aggregate of current status of the replicas controlled by the MachinePool.
+	ReplicasReadyCondition clusterv1.ConditionType = "ReplicasReady"
+
+	// WaitingForReplicasReadyReason (Severity=Info) documents a machinepool waiting for the required replicas
+	// to be ready.
+	WaitingForReplicasReadyReason = "WaitingForReplicasReady"
+)
+
+// Conditions and condition Reasons for the KubeconfigRequest object
+
+const (
+	// KubeconfigIssuedCondition reports whether a valid kubeconfig has been issued for the KubeconfigRequest.
+	KubeconfigIssuedCondition clusterv1.ConditionType = "KubeconfigIssued"
+
+	// WaitingForControlPlaneInitializedReason (Severity=Info) documents a KubeconfigRequest waiting for the
+	// control plane of its Cluster to be initialized.
+	WaitingForControlPlaneInitializedReason = "WaitingForControlPlaneInitialized"
+
+	// KubeconfigIssueFailedReason (Severity=Warning) documents a KubeconfigRequest controller failing to
+	// issue the kubeconfig, e.g. because the cluster CA can't be found.
+	KubeconfigIssueFailedReason = "KubeconfigIssueFailed"
+
+	// KubeconfigExpiredReason (Severity=Info) documents a KubeconfigRequest whose kubeconfig expired
+	// and was not renewed.
+	KubeconfigExpiredReason = "KubeconfigExpired"
+
+	// RoleBindingsReadyCondition reports whether the RBAC bindings requested by the KubeconfigRequest
+	// exist in the workload cluster.
+	RoleBindingsReadyCondition clusterv1.ConditionType = "RoleBindingsReady"
+
+	// RoleBindingsFailedReason (Severity=Warning) documents a KubeconfigRequest controller failing to
+	// create or delete the RBAC bindings in the workload cluster.
+	RoleBindingsFailedReason = "RoleBindingsFailed"
+)
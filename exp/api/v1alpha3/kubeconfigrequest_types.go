@@ -0,0 +1,156 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+)
+
+const (
+	// KubeconfigRequestFinalizer is used to ensure deletion of the RBAC bindings in the workload cluster.
+	KubeconfigRequestFinalizer = "kubeconfigrequest.exp.cluster.x-k8s.io"
+
+	// KubeconfigRequestNameLabel is the label set on the RBAC bindings created in the workload cluster
+	// with the name of the KubeconfigRequest they belong to.
+	KubeconfigRequestNameLabel = "kubeconfigrequest.exp.cluster.x-k8s.io/name"
+
+	// KubeconfigRequestNamespaceLabel is the label set on the RBAC bindings created in the workload cluster
+	// with the namespace of the KubeconfigRequest they belong to.
+	KubeconfigRequestNamespaceLabel = "kubeconfigrequest.exp.cluster.x-k8s.io/namespace"
+
+	// DefaultKubeconfigRequestTTL is the default validity of the client certificate of a KubeconfigRequest.
+	DefaultKubeconfigRequestTTL = 24 * time.Hour
+)
+
+// ANCHOR: KubeconfigRequestSpec
+
+// KubeconfigRequestSpec defines the desired state of KubeconfigRequest
+type KubeconfigRequestSpec struct {
+	// ClusterName is the name of the Cluster the kubeconfig is requested for.
+	// +kubebuilder:validation:MinLength=1
+	ClusterName string `json:"clusterName"`
+
+	// Username is the user the kubeconfig authenticates as, i.e. the common name of its client certificate.
+	// +kubebuilder:validation:MinLength=1
+	Username string `json:"username"`
+
+	// Groups are the groups the user is a member of, i.e. the organizations of its client certificate.
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+
+	// TTL is how long the client certificate of the kubeconfig is valid for.
+	// Defaults to 24 hours.
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+
+	// AutoRenew defines whether the kubeconfig is renewed before its client certificate expires.
+	// If false, the kubeconfig Secret is deleted once the client certificate expired; updating
+	// the spec of an expired request issues a new kubeconfig.
+	// +optional
+	AutoRenew bool `json:"autoRenew,omitempty"`
+
+	// RoleBindings are the bindings of the user to cluster roles created in the workload cluster.
+	// The bindings are deleted when the kubeconfig expires or the request is deleted. As client
+	// certificates can't be revoked, removing the bindings is the only way to restrict the access
+	// of an issued kubeconfig before it expires.
+	// +optional
+	RoleBindings []KubeconfigRoleBinding `json:"roleBindings,omitempty"`
+}
+
+// KubeconfigRoleBinding binds the user of a KubeconfigRequest to a cluster role in the workload cluster.
+type KubeconfigRoleBinding struct {
+	// ClusterRole is the name of the ClusterRole the user is bound to.
+	// +kubebuilder:validation:MinLength=1
+	ClusterRole string `json:"clusterRole"`
+
+	// Namespace is the namespace the role is granted in, with a RoleBinding.
+	// If empty, the role is granted cluster-wide, with a ClusterRoleBinding.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ANCHOR_END: KubeconfigRequestSpec
+
+// ANCHOR: KubeconfigRequestStatus
+
+// KubeconfigRequestStatus defines the observed state of KubeconfigRequest
+type KubeconfigRequestStatus struct {
+	// SecretName is the name of the Secret holding the kubeconfig, in the namespace of the request.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// IssueTime is the time the current kubeconfig was issued.
+	// +optional
+	IssueTime *metav1.Time `json:"issueTime,omitempty"`
+
+	// ExpirationTime is the time the client certificate of the current kubeconfig expires.
+	// +optional
+	ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
+
+	// ObservedGeneration is the generation of the spec the current kubeconfig was issued for.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions define the current service state of the KubeconfigRequest.
+	// +optional
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+}
+
+// ANCHOR_END: KubeconfigRequestStatus
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=kubeconfigrequests,shortName=kcr,scope=Namespaced,categories=cluster-api
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".spec.clusterName",description="Cluster the kubeconfig is requested for"
+// +kubebuilder:printcolumn:name="Username",type="string",JSONPath=".spec.username",description="User the kubeconfig authenticates as"
+// +kubebuilder:printcolumn:name="Secret",type="string",JSONPath=".status.secretName",description="Secret holding the kubeconfig"
+// +kubebuilder:printcolumn:name="Expiration",type="date",JSONPath=".status.expirationTime",description="Time the kubeconfig expires"
+// +k8s:conversion-gen=false
+
+// KubeconfigRequest is the Schema for the kubeconfigrequests API
+type KubeconfigRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KubeconfigRequestSpec   `json:"spec,omitempty"`
+	Status KubeconfigRequestStatus `json:"status,omitempty"`
+}
+
+func (r *KubeconfigRequest) GetConditions() clusterv1.Conditions {
+	return r.Status.Conditions
+}
+
+func (r *KubeconfigRequest) SetConditions(conditions clusterv1.Conditions) {
+	r.Status.Conditions = conditions
+}
+
+// +kubebuilder:object:root=true
+
+// KubeconfigRequestList contains a list of KubeconfigRequest
+type KubeconfigRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KubeconfigRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KubeconfigRequest{}, &KubeconfigRequestList{})
+}
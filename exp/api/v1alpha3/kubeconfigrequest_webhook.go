@@ -0,0 +1,126 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/certs"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// systemPrefix is the prefix of the users and groups reserved to Kubernetes components, e.g. system:masters;
+// a KubeconfigRequest can't be used for issuing a kubeconfig with such a user or group.
+const systemPrefix = "system:"
+
+func (r *KubeconfigRequest) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-exp-cluster-x-k8s-io-v1alpha3-kubeconfigrequest,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=exp.cluster.x-k8s.io,resources=kubeconfigrequests,versions=v1alpha3,name=validation.exp.kubeconfigrequest.cluster.x-k8s.io,sideEffects=None
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-exp-cluster-x-k8s-io-v1alpha3-kubeconfigrequest,mutating=true,failurePolicy=fail,matchPolicy=Equivalent,groups=exp.cluster.x-k8s.io,resources=kubeconfigrequests,versions=v1alpha3,name=default.exp.kubeconfigrequest.cluster.x-k8s.io,sideEffects=None
+
+var _ webhook.Defaulter = &KubeconfigRequest{}
+var _ webhook.Validator = &KubeconfigRequest{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *KubeconfigRequest) Default() {
+	if r.Labels == nil {
+		r.Labels = make(map[string]string)
+	}
+	r.Labels[clusterv1.ClusterLabelName] = r.Spec.ClusterName
+
+	if r.Spec.TTL == nil {
+		r.Spec.TTL = &metav1.Duration{Duration: DefaultKubeconfigRequestTTL}
+	}
+}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *KubeconfigRequest) ValidateCreate() error {
+	return r.validate(nil)
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *KubeconfigRequest) ValidateUpdate(old runtime.Object) error {
+	oldRequest, ok := old.(*KubeconfigRequest)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a KubeconfigRequest but got a %T", old))
+	}
+	return r.validate(oldRequest)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *KubeconfigRequest) ValidateDelete() error {
+	return nil
+}
+
+func (r *KubeconfigRequest) validate(old *KubeconfigRequest) error {
+	var allErrs field.ErrorList
+
+	if strings.HasPrefix(r.Spec.Username, systemPrefix) {
+		allErrs = append(
+			allErrs,
+			field.Invalid(field.NewPath("spec", "username"), r.Spec.Username, fmt.Sprintf("must not start with %q", systemPrefix)),
+		)
+	}
+
+	for i, group := range r.Spec.Groups {
+		if strings.HasPrefix(group, systemPrefix) {
+			allErrs = append(
+				allErrs,
+				field.Invalid(field.NewPath("spec", "groups").Index(i), group, fmt.Sprintf("must not start with %q", systemPrefix)),
+			)
+		}
+	}
+
+	if r.Spec.TTL != nil && (r.Spec.TTL.Duration <= 0 || r.Spec.TTL.Duration > certs.DefaultCertDuration) {
+		allErrs = append(
+			allErrs,
+			field.Invalid(field.NewPath("spec", "ttl"), r.Spec.TTL.Duration.String(), fmt.Sprintf("must be positive and at most %s", certs.DefaultCertDuration)),
+		)
+	}
+
+	// The name of the request is set as a label on the bindings created in the workload cluster.
+	if len(r.Spec.RoleBindings) > 0 && len(r.Name) > validation.LabelValueMaxLength {
+		allErrs = append(
+			allErrs,
+			field.Invalid(field.NewPath("metadata", "name"), r.Name, fmt.Sprintf("must be no more than %d characters when spec.roleBindings is set", validation.LabelValueMaxLength)),
+		)
+	}
+
+	if old != nil && old.Spec.ClusterName != r.Spec.ClusterName {
+		allErrs = append(
+			allErrs,
+			field.Invalid(field.NewPath("spec", "clusterName"), r.Spec.ClusterName, "field is immutable"),
+		)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("KubeconfigRequest").GroupKind(), r.Name, allErrs)
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+)
+
+func TestKubeconfigRequestDefault(t *testing.T) {
+	g := NewWithT(t)
+
+	r := &KubeconfigRequest{
+		Spec: KubeconfigRequestSpec{
+			ClusterName: "test-cluster",
+			Username:    "jane",
+		},
+	}
+
+	r.Default()
+
+	g.Expect(r.Labels[clusterv1.ClusterLabelName]).To(Equal("test-cluster"))
+	g.Expect(r.Spec.TTL).To(Equal(&metav1.Duration{Duration: DefaultKubeconfigRequestTTL}))
+}
+
+func TestKubeconfigRequestValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      KubeconfigRequestSpec
+		expectErr bool
+	}{
+		{
+			name: "should succeed for a regular user and groups",
+			spec: KubeconfigRequestSpec{
+				Username: "jane",
+				Groups:   []string{"developers"},
+				TTL:      &metav1.Duration{Duration: time.Hour},
+			},
+			expectErr: false,
+		},
+		{
+			name: "should return error for a system user",
+			spec: KubeconfigRequestSpec{
+				Username: "system:kube-controller-manager",
+			},
+			expectErr: true,
+		},
+		{
+			name: "should return error for a system group",
+			spec: KubeconfigRequestSpec{
+				Username: "jane",
+				Groups:   []string{"developers", "system:masters"},
+			},
+			expectErr: true,
+		},
+		{
+			name: "should return error for a negative TTL",
+			spec: KubeconfigRequestSpec{
+				Username: "jane",
+				TTL:      &metav1.Duration{Duration: -time.Hour},
+			},
+			expectErr: true,
+		},
+		{
+			name: "should return error for a TTL longer than a year",
+			spec: KubeconfigRequestSpec{
+				Username: "jane",
+				TTL:      &metav1.Duration{Duration: 2 * 365 * 24 * time.Hour},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			r := &KubeconfigRequest{Spec: tt.spec}
+			if tt.expectErr {
+				g.Expect(r.ValidateCreate()).NotTo(Succeed())
+				g.Expect(r.ValidateUpdate(r)).NotTo(Succeed())
+			} else {
+				g.Expect(r.ValidateCreate()).To(Succeed())
+				g.Expect(r.ValidateUpdate(r)).To(Succeed())
+			}
+		})
+	}
+}
+
+func TestKubeconfigRequestClusterNameImmutable(t *testing.T) {
+	g := NewWithT(t)
+
+	oldRequest := &KubeconfigRequest{Spec: KubeconfigRequestSpec{ClusterName: "foo", Username: "jane"}}
+	newRequest := &KubeconfigRequest{Spec: KubeconfigRequestSpec{ClusterName: "bar", Username: "jane"}}
+
+	g.Expect(newRequest.ValidateUpdate(oldRequest)).NotTo(Succeed())
+}
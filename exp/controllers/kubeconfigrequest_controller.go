@@ -0,0 +1,471 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/controllers/remote"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/kubeconfig"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	capirecord "sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/cluster-api/util/tracing"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;patch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=exp.cluster.x-k8s.io,resources=kubeconfigrequests;kubeconfigrequests/status,verbs=get;list;watch;create;update;patch;delete
+
+// KubeconfigRequestReconciler reconciles a KubeconfigRequest object
+type KubeconfigRequestReconciler struct {
+	Client client.Client
+	Log    logr.Logger
+
+	recorder           record.EventRecorder
+	scheme             *runtime.Scheme
+	remoteClientGetter remote.ClusterClientGetter
+}
+
+func (r *KubeconfigRequestReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
+	clusterToKubeconfigRequests, err := util.ClusterToObjectsMapper(mgr.GetClient(), &expv1.KubeconfigRequestList{}, mgr.GetScheme())
+	if err != nil {
+		return err
+	}
+
+	c, err := ctrl.NewControllerManagedBy(mgr).
+		For(&expv1.KubeconfigRequest{}).
+		Owns(&corev1.Secret{}).
+		WithOptions(options).
+		WithEventFilter(predicates.ResourceNotPaused(r.Log)).
+		Build(r)
+	if err != nil {
+		return errors.Wrap(err, "failed setting up with a controller manager")
+	}
+	err = c.Watch(
+		&source.Kind{Type: &clusterv1.Cluster{}},
+		&handler.EnqueueRequestsFromMapFunc{
+			ToRequests: clusterToKubeconfigRequests,
+		},
+		predicates.ClusterUnpaused(r.Log),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed adding Watch for Cluster to controller manager")
+	}
+
+	r.recorder = capirecord.NewRateLimitedRecorder(mgr.GetEventRecorderFor("kubeconfigrequest-controller"), capirecord.DefaultDuplicateEventsInterval)
+	r.scheme = mgr.GetScheme()
+	if r.remoteClientGetter == nil {
+		// Binding the user to any ClusterRole requires the permissions of the cluster admin.
+		r.remoteClientGetter = remote.NewAdminClusterClient
+	}
+	return nil
+}
+
+func (r *KubeconfigRequestReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr error) {
+	ctx, span := tracing.StartSpan(context.Background(), "KubeconfigRequestReconciler.Reconcile", tracing.ObjectAttributes("KubeconfigRequest", req.Namespace, req.Name)...)
+	defer func() { tracing.EndSpan(span, reterr) }()
+	logger := r.Log.WithValues("kubeconfigrequest", req.NamespacedName)
+
+	kr := &expv1.KubeconfigRequest{}
+	if err := r.Client.Get(ctx, req.NamespacedName, kr); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Object not found, return. Created objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers.
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "Error reading the object - requeue the request.")
+		return ctrl.Result{}, err
+	}
+
+	cluster, err := util.GetClusterByName(ctx, r.Client, kr.Namespace, kr.Spec.ClusterName)
+	if err != nil {
+		// The bindings in the workload cluster are gone with the Cluster.
+		if apierrors.IsNotFound(errors.Cause(err)) && !kr.DeletionTimestamp.IsZero() {
+			controllerutil.RemoveFinalizer(kr, expv1.KubeconfigRequestFinalizer)
+			return ctrl.Result{}, r.Client.Update(ctx, kr)
+		}
+		return ctrl.Result{}, errors.Wrapf(err, "failed to get cluster %q for kubeconfigrequest %q in namespace %q",
+			kr.Spec.ClusterName, kr.Name, kr.Namespace)
+	}
+
+	// Return early if the object or Cluster is paused.
+	if annotations.IsPaused(cluster, kr) {
+		logger.Info("Reconciliation is paused for this object")
+		return ctrl.Result{}, nil
+	}
+
+	// Initialize the patch helper.
+	patchHelper, err := patch.NewHelper(kr, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	defer func() {
+		// Always update the readyCondition with the summary of the kubeconfig request conditions.
+		conditions.SetSummary(kr,
+			conditions.WithConditions(
+				expv1.KubeconfigIssuedCondition,
+				expv1.RoleBindingsReadyCondition,
+			),
+		)
+
+		// Always attempt to patch the object and status after each reconciliation.
+		if err := patchHelper.Patch(ctx, kr); err != nil {
+			reterr = kerrors.NewAggregate([]error{reterr, err})
+		}
+	}()
+
+	// Handle deletion reconciliation loop.
+	if !kr.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, cluster, kr)
+	}
+
+	// Handle normal reconciliation loop.
+	return r.reconcile(ctx, cluster, kr)
+}
+
+func (r *KubeconfigRequestReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cluster, kr *expv1.KubeconfigRequest) (ctrl.Result, error) {
+	// Ensure the KubeconfigRequest is owned by the Cluster it belongs to.
+	kr.OwnerReferences = util.EnsureOwnerRef(kr.OwnerReferences, metav1.OwnerReference{
+		APIVersion: clusterv1.GroupVersion.String(),
+		Kind:       "Cluster",
+		Name:       cluster.Name,
+		UID:        cluster.UID,
+	})
+
+	// If the KubeconfigRequest doesn't have a finalizer, add one.
+	controllerutil.AddFinalizer(kr, expv1.KubeconfigRequestFinalizer)
+
+	// The cluster CA and the API server are available once the control plane is initialized.
+	if !cluster.Status.ControlPlaneInitialized {
+		conditions.MarkFalse(kr, expv1.KubeconfigIssuedCondition, expv1.WaitingForControlPlaneInitializedReason, clusterv1.ConditionSeverityInfo, "")
+		return ctrl.Result{}, nil
+	}
+
+	res, err := r.reconcileKubeconfig(ctx, cluster, kr)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.reconcileRoleBindings(ctx, cluster, kr); err != nil {
+		return ctrl.Result{}, err
+	}
+	return res, nil
+}
+
+// reconcileKubeconfig issues, renews or expires the kubeconfig of the KubeconfigRequest, and returns
+// a result requeuing the request when the kubeconfig has to be renewed or expired.
+func (r *KubeconfigRequestReconciler) reconcileKubeconfig(ctx context.Context, cluster *clusterv1.Cluster, kr *expv1.KubeconfigRequest) (ctrl.Result, error) {
+	logger := r.Log.WithValues("kubeconfigrequest", kr.Name, "namespace", kr.Namespace, "cluster", cluster.Name)
+
+	kubeconfigSecret, err := r.getKubeconfigSecret(ctx, kr)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	now := time.Now()
+	// A new kubeconfig is issued when the spec changed since the current one was issued.
+	upToDate := kr.Status.ObservedGeneration == kr.Generation && kr.Status.IssueTime != nil && kr.Status.ExpirationTime != nil
+
+	if upToDate && isExpired(kr, now) {
+		if kubeconfigSecret != nil {
+			if err := r.Client.Delete(ctx, kubeconfigSecret); err != nil && !apierrors.IsNotFound(err) {
+				capirecord.FailedDelete.Emit(r.recorder, kr, "Secret", kubeconfigSecret.Name, err)
+				return ctrl.Result{}, errors.Wrapf(err, "failed to delete expired kubeconfig Secret %q", kubeconfigSecret.Name)
+			}
+			logger.Info("Deleted expired kubeconfig", "secret", kubeconfigSecret.Name)
+		}
+		conditions.MarkFalse(kr, expv1.KubeconfigIssuedCondition, expv1.KubeconfigExpiredReason, clusterv1.ConditionSeverityInfo,
+			"Kubeconfig expired at %s", kr.Status.ExpirationTime.Format(time.RFC3339))
+		return ctrl.Result{}, nil
+	}
+
+	// The kubeconfig is reissued, without extending its validity, when the cluster CA changed, e.g. while the
+	// certificate authorities of the cluster are rotated.
+	caOutdated := false
+	if upToDate && kubeconfigSecret != nil {
+		caSecret, err := secret.Get(ctx, r.Client, util.ObjectKey(cluster), secret.ClusterCA)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to get the cluster CA Secret")
+		}
+		caOutdated, err = kubeconfig.NeedsCertificateAuthoritiesUpdate(kubeconfigSecret, caSecret.Data[secret.TLSCrtDataName])
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if upToDate && kubeconfigSecret != nil && now.Before(renewalTime(kr)) && !caOutdated {
+		conditions.MarkTrue(kr, expv1.KubeconfigIssuedCondition)
+		return ctrl.Result{RequeueAfter: renewalTime(kr).Sub(now)}, nil
+	}
+
+	ttl := expv1.DefaultKubeconfigRequestTTL
+	if kr.Spec.TTL != nil {
+		ttl = kr.Spec.TTL.Duration
+	}
+	renew := !caOutdated || !now.Before(renewalTime(kr))
+	if !renew {
+		ttl = kr.Status.ExpirationTime.Sub(now)
+	}
+
+	endpoint := fmt.Sprintf("https://%s", cluster.Spec.ControlPlaneEndpoint.String())
+	data, err := kubeconfig.GenerateForUser(ctx, r.Client, util.ObjectKey(cluster), endpoint, kr.Spec.Username, kr.Spec.Groups, ttl)
+	if err != nil {
+		conditions.MarkFalse(kr, expv1.KubeconfigIssuedCondition, expv1.KubeconfigIssueFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return ctrl.Result{}, errors.Wrapf(err, "failed to generate kubeconfig for user %q", kr.Spec.Username)
+	}
+
+	if kubeconfigSecret == nil {
+		kubeconfigSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      kubeconfigSecretName(kr),
+				Namespace: kr.Namespace,
+				Labels: map[string]string{
+					clusterv1.ClusterLabelName: cluster.Name,
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(kr, expv1.GroupVersion.WithKind("KubeconfigRequest")),
+				},
+			},
+			Data: map[string][]byte{
+				secret.KubeconfigDataName: data,
+			},
+		}
+		if err := r.Client.Create(ctx, kubeconfigSecret); err != nil {
+			capirecord.FailedCreate.Emit(r.recorder, kr, "Secret", kubeconfigSecret.Name, err)
+			return ctrl.Result{}, errors.Wrapf(err, "failed to create kubeconfig Secret %q", kubeconfigSecret.Name)
+		}
+		capirecord.SuccessfulCreate.Emit(r.recorder, kr, "Secret", kubeconfigSecret.Name)
+	} else {
+		kubeconfigSecret.Data = map[string][]byte{
+			secret.KubeconfigDataName: data,
+		}
+		if err := r.Client.Update(ctx, kubeconfigSecret); err != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to update kubeconfig Secret %q", kubeconfigSecret.Name)
+		}
+	}
+	logger.Info("Issued kubeconfig", "secret", kubeconfigSecret.Name, "username", kr.Spec.Username, "ttl", ttl)
+
+	kr.Status.SecretName = kubeconfigSecret.Name
+	if renew {
+		kr.Status.IssueTime = &metav1.Time{Time: now}
+		kr.Status.ExpirationTime = &metav1.Time{Time: now.Add(ttl)}
+		kr.Status.ObservedGeneration = kr.Generation
+	}
+	conditions.MarkTrue(kr, expv1.KubeconfigIssuedCondition)
+
+	return ctrl.Result{RequeueAfter: renewalTime(kr).Sub(now)}, nil
+}
+
+// getKubeconfigSecret returns the kubeconfig Secret of the KubeconfigRequest, or nil if it doesn't exist.
+func (r *KubeconfigRequestReconciler) getKubeconfigSecret(ctx context.Context, kr *expv1.KubeconfigRequest) (*corev1.Secret, error) {
+	kubeconfigSecret := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: kr.Namespace, Name: kubeconfigSecretName(kr)}
+	if err := r.Client.Get(ctx, key, kubeconfigSecret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get kubeconfig Secret %q", key.Name)
+	}
+
+	// Never overwrite a Secret which doesn't belong to the request, e.g. the admin kubeconfig of a Cluster.
+	if !util.IsControlledBy(kubeconfigSecret, kr) {
+		return nil, errors.Errorf("Secret %q already exists and is not controlled by KubeconfigRequest %q", key.Name, kr.Name)
+	}
+	return kubeconfigSecret, nil
+}
+
+// reconcileRoleBindings creates the RBAC bindings of the KubeconfigRequest in the workload cluster, and deletes
+// the bindings no longer requested, or all of them once the kubeconfig expired.
+func (r *KubeconfigRequestReconciler) reconcileRoleBindings(ctx context.Context, cluster *clusterv1.Cluster, kr *expv1.KubeconfigRequest) error {
+	desired := kr.Spec.RoleBindings
+	if isExpired(kr, time.Now()) {
+		desired = nil
+	}
+
+	// Don't connect to the workload cluster if no binding was ever requested.
+	if len(desired) == 0 && !conditions.Has(kr, expv1.RoleBindingsReadyCondition) {
+		return nil
+	}
+
+	remoteClient, err := r.remoteClientGetter(ctx, r.Client, util.ObjectKey(cluster), r.scheme)
+	if err != nil {
+		conditions.MarkFalse(kr, expv1.RoleBindingsReadyCondition, expv1.RoleBindingsFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return errors.Wrap(err, "failed to create client to workload cluster")
+	}
+
+	if err := r.syncRoleBindings(ctx, remoteClient, kr, desired); err != nil {
+		conditions.MarkFalse(kr, expv1.RoleBindingsReadyCondition, expv1.RoleBindingsFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return err
+	}
+
+	if len(desired) == 0 {
+		conditions.Delete(kr, expv1.RoleBindingsReadyCondition)
+		return nil
+	}
+	conditions.MarkTrue(kr, expv1.RoleBindingsReadyCondition)
+	return nil
+}
+
+// syncRoleBindings makes the RBAC bindings of the KubeconfigRequest in the workload cluster match the desired ones.
+func (r *KubeconfigRequestReconciler) syncRoleBindings(ctx context.Context, remoteClient client.Client, kr *expv1.KubeconfigRequest, desired []expv1.KubeconfigRoleBinding) error {
+	existingClusterRoleBindings, existingRoleBindings, err := listRoleBindings(ctx, remoteClient, kr)
+	if err != nil {
+		return err
+	}
+
+	subjects := []rbacv1.Subject{{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: kr.Spec.Username}}
+	wanted := map[client.ObjectKey]bool{}
+	for _, binding := range desired {
+		name := roleBindingName(kr, binding.ClusterRole)
+		wanted[client.ObjectKey{Namespace: binding.Namespace, Name: name}] = true
+
+		objectMeta := metav1.ObjectMeta{Name: name, Namespace: binding.Namespace}
+		roleRef := rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: binding.ClusterRole}
+
+		var obj controllerutil.Object
+		var mutate controllerutil.MutateFn
+		if binding.Namespace == "" {
+			clusterRoleBinding := &rbacv1.ClusterRoleBinding{ObjectMeta: objectMeta}
+			obj, mutate = clusterRoleBinding, func() error {
+				clusterRoleBinding.Labels = roleBindingLabels(kr)
+				clusterRoleBinding.Subjects = subjects
+				clusterRoleBinding.RoleRef = roleRef
+				return nil
+			}
+		} else {
+			roleBinding := &rbacv1.RoleBinding{ObjectMeta: objectMeta}
+			obj, mutate = roleBinding, func() error {
+				roleBinding.Labels = roleBindingLabels(kr)
+				roleBinding.Subjects = subjects
+				roleBinding.RoleRef = roleRef
+				return nil
+			}
+		}
+		if _, err := controllerutil.CreateOrUpdate(ctx, remoteClient, obj, mutate); err != nil {
+			return errors.Wrapf(err, "failed to bind user %q to ClusterRole %q", kr.Spec.Username, binding.ClusterRole)
+		}
+	}
+
+	var errs []error
+	for i := range existingClusterRoleBindings.Items {
+		obj := &existingClusterRoleBindings.Items[i]
+		if !wanted[client.ObjectKey{Name: obj.Name}] {
+			if err := remoteClient.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, errors.Wrapf(err, "failed to delete ClusterRoleBinding %q", obj.Name))
+			}
+		}
+	}
+	for i := range existingRoleBindings.Items {
+		obj := &existingRoleBindings.Items[i]
+		if !wanted[client.ObjectKey{Namespace: obj.Namespace, Name: obj.Name}] {
+			if err := remoteClient.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, errors.Wrapf(err, "failed to delete RoleBinding %s/%s", obj.Namespace, obj.Name))
+			}
+		}
+	}
+	return kerrors.NewAggregate(errs)
+}
+
+func (r *KubeconfigRequestReconciler) reconcileDelete(ctx context.Context, cluster *clusterv1.Cluster, kr *expv1.KubeconfigRequest) (ctrl.Result, error) {
+	// The bindings in the workload cluster are gone with the Cluster.
+	if cluster.DeletionTimestamp.IsZero() && conditions.Has(kr, expv1.RoleBindingsReadyCondition) {
+		remoteClient, err := r.remoteClientGetter(ctx, r.Client, util.ObjectKey(cluster), r.scheme)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to create client to workload cluster")
+		}
+		if err := r.syncRoleBindings(ctx, remoteClient, kr, nil); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	controllerutil.RemoveFinalizer(kr, expv1.KubeconfigRequestFinalizer)
+	return ctrl.Result{}, nil
+}
+
+// listRoleBindings returns the RBAC bindings of the KubeconfigRequest in the workload cluster.
+func listRoleBindings(ctx context.Context, remoteClient client.Client, kr *expv1.KubeconfigRequest) (*rbacv1.ClusterRoleBindingList, *rbacv1.RoleBindingList, error) {
+	labels := client.MatchingLabels(roleBindingLabels(kr))
+
+	clusterRoleBindings := &rbacv1.ClusterRoleBindingList{}
+	if err := remoteClient.List(ctx, clusterRoleBindings, labels); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to list ClusterRoleBindings")
+	}
+	roleBindings := &rbacv1.RoleBindingList{}
+	if err := remoteClient.List(ctx, roleBindings, labels); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to list RoleBindings")
+	}
+	return clusterRoleBindings, roleBindings, nil
+}
+
+// roleBindingLabels returns the labels identifying the RBAC bindings of the KubeconfigRequest in the workload cluster.
+func roleBindingLabels(kr *expv1.KubeconfigRequest) map[string]string {
+	return map[string]string{
+		expv1.KubeconfigRequestNameLabel:      kr.Name,
+		expv1.KubeconfigRequestNamespaceLabel: kr.Namespace,
+	}
+}
+
+// kubeconfigSecretName returns the name of the Secret holding the kubeconfig of the KubeconfigRequest.
+func kubeconfigSecretName(kr *expv1.KubeconfigRequest) string {
+	return fmt.Sprintf("%s-user-kubeconfig", kr.Name)
+}
+
+// roleBindingName returns the name of the binding of the user of the KubeconfigRequest to the given ClusterRole;
+// the namespace of the request is part of the name as requests of different namespaces can target the same Cluster.
+func roleBindingName(kr *expv1.KubeconfigRequest, clusterRole string) string {
+	return fmt.Sprintf("kubeconfigrequest-%s-%s-%s", kr.Namespace, kr.Name, clusterRole)
+}
+
+// isExpired returns whether the kubeconfig of the KubeconfigRequest expired without being renewed.
+func isExpired(kr *expv1.KubeconfigRequest, now time.Time) bool {
+	return !kr.Spec.AutoRenew && kr.Status.ExpirationTime != nil && !now.Before(kr.Status.ExpirationTime.Time)
+}
+
+// renewalTime returns when the kubeconfig of the KubeconfigRequest has to be renewed, i.e. after two thirds of
+// its lifetime when AutoRenew is set, or when it expires otherwise.
+func renewalTime(kr *expv1.KubeconfigRequest) time.Time {
+	if !kr.Spec.AutoRenew {
+		return kr.Status.ExpirationTime.Time
+	}
+	lifetime := kr.Status.ExpirationTime.Sub(kr.Status.IssueTime.Time)
+	return kr.Status.IssueTime.Add(lifetime * 2 / 3)
+}
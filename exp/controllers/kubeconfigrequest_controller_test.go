@@ -0,0 +1,293 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	kubeadmv1beta1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
+	fakeremote "sigs.k8s.io/cluster-api/controllers/remote/fake"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newKubeconfigRequestTestObjects(g *WithT) (*clusterv1.Cluster, *corev1.Secret, *expv1.KubeconfigRequest) {
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-cluster",
+		},
+		Spec: clusterv1.ClusterSpec{
+			ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "10.0.0.1", Port: 6443},
+		},
+		Status: clusterv1.ClusterStatus{
+			ControlPlaneInitialized: true,
+		},
+	}
+
+	certificates := secret.NewCertificatesForInitialControlPlane(&kubeadmv1beta1.ClusterConfiguration{})
+	g.Expect(certificates.Generate()).To(Succeed())
+	caSecret := certificates.GetByPurpose(secret.ClusterCA).AsSecret(util.ObjectKey(cluster), metav1.OwnerReference{})
+
+	kr := &expv1.KubeconfigRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "jane",
+		},
+		Spec: expv1.KubeconfigRequestSpec{
+			ClusterName: cluster.Name,
+			Username:    "jane",
+			Groups:      []string{"developers"},
+			TTL:         &metav1.Duration{Duration: time.Hour},
+			RoleBindings: []expv1.KubeconfigRoleBinding{
+				{ClusterRole: "view"},
+				{ClusterRole: "edit", Namespace: "team-a"},
+			},
+		},
+	}
+	return cluster, caSecret, kr
+}
+
+func newKubeconfigRequestReconciler(c client.Client) *KubeconfigRequestReconciler {
+	return &KubeconfigRequestReconciler{
+		Client:             c,
+		Log:                log.Log,
+		recorder:           record.NewFakeRecorder(32),
+		scheme:             scheme.Scheme,
+		remoteClientGetter: fakeremote.NewClusterClient,
+	}
+}
+
+func TestKubeconfigRequestReconcile(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+	g.Expect(expv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	cluster, caSecret, kr := newKubeconfigRequestTestObjects(g)
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, cluster, caSecret, kr)
+	r := newKubeconfigRequestReconciler(c)
+	ctx := context.Background()
+	key := util.ObjectKey(kr)
+
+	// The kubeconfig is issued and the bindings are created.
+	res, err := r.Reconcile(reconcile.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(res.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+
+	g.Expect(c.Get(ctx, key, kr)).To(Succeed())
+	g.Expect(kr.Finalizers).To(ContainElement(expv1.KubeconfigRequestFinalizer))
+	g.Expect(kr.Status.SecretName).To(Equal("jane-user-kubeconfig"))
+	g.Expect(conditions.IsTrue(kr, expv1.KubeconfigIssuedCondition)).To(BeTrue())
+	g.Expect(conditions.IsTrue(kr, expv1.RoleBindingsReadyCondition)).To(BeTrue())
+
+	kubeconfigSecret := &corev1.Secret{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: kr.Status.SecretName}, kubeconfigSecret)).To(Succeed())
+	g.Expect(util.IsControlledBy(kubeconfigSecret, kr)).To(BeTrue())
+	config, err := clientcmd.Load(kubeconfigSecret.Data[secret.KubeconfigDataName])
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(config.Clusters[cluster.Name].Server).To(Equal("https://10.0.0.1:6443"))
+	clientCert, err := certs.DecodeCertPEM(config.AuthInfos["jane"].ClientCertificateData)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(clientCert.Subject.CommonName).To(Equal("jane"))
+	g.Expect(clientCert.Subject.Organization).To(ConsistOf("developers"))
+
+	clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Name: "kubeconfigrequest-default-jane-view"}, clusterRoleBinding)).To(Succeed())
+	g.Expect(clusterRoleBinding.RoleRef.Name).To(Equal("view"))
+	g.Expect(clusterRoleBinding.Subjects).To(ConsistOf(rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "jane"}))
+	roleBinding := &rbacv1.RoleBinding{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "team-a", Name: "kubeconfigrequest-default-jane-edit"}, roleBinding)).To(Succeed())
+	g.Expect(roleBinding.RoleRef.Name).To(Equal("edit"))
+
+	// Bindings no longer requested are deleted.
+	kr.Spec.RoleBindings = kr.Spec.RoleBindings[1:]
+	g.Expect(c.Update(ctx, kr)).To(Succeed())
+	_, err = r.Reconcile(reconcile.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+	err = c.Get(ctx, client.ObjectKey{Name: "kubeconfigrequest-default-jane-view"}, clusterRoleBinding)
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "team-a", Name: "kubeconfigrequest-default-jane-edit"}, roleBinding)).To(Succeed())
+
+	// The kubeconfig and the bindings are deleted once the kubeconfig expired.
+	g.Expect(c.Get(ctx, key, kr)).To(Succeed())
+	kr.Status.ExpirationTime = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+	g.Expect(c.Status().Update(ctx, kr)).To(Succeed())
+	res, err = r.Reconcile(reconcile.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(res.RequeueAfter).To(BeZero())
+
+	g.Expect(c.Get(ctx, key, kr)).To(Succeed())
+	g.Expect(conditions.IsFalse(kr, expv1.KubeconfigIssuedCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(kr, expv1.KubeconfigIssuedCondition)).To(Equal(expv1.KubeconfigExpiredReason))
+	g.Expect(conditions.Has(kr, expv1.RoleBindingsReadyCondition)).To(BeFalse())
+	err = c.Get(ctx, client.ObjectKey{Namespace: "default", Name: kr.Status.SecretName}, kubeconfigSecret)
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	err = c.Get(ctx, client.ObjectKey{Namespace: "team-a", Name: "kubeconfigrequest-default-jane-edit"}, roleBinding)
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+}
+
+func TestKubeconfigRequestReconcileRenew(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+	g.Expect(expv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	cluster, caSecret, kr := newKubeconfigRequestTestObjects(g)
+	kr.Spec.AutoRenew = true
+	kr.Spec.RoleBindings = nil
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, cluster, caSecret, kr)
+	r := newKubeconfigRequestReconciler(c)
+	ctx := context.Background()
+	key := util.ObjectKey(kr)
+
+	// Auto renewed kubeconfigs are renewed after two thirds of their lifetime.
+	res, err := r.Reconcile(reconcile.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(res.RequeueAfter).To(BeNumerically("~", 40*time.Minute, time.Minute))
+
+	g.Expect(c.Get(ctx, key, kr)).To(Succeed())
+	g.Expect(conditions.Has(kr, expv1.RoleBindingsReadyCondition)).To(BeFalse())
+	kr.Status.IssueTime = &metav1.Time{Time: time.Now().Add(-50 * time.Minute)}
+	kr.Status.ExpirationTime = &metav1.Time{Time: time.Now().Add(10 * time.Minute)}
+	g.Expect(c.Status().Update(ctx, kr)).To(Succeed())
+
+	_, err = r.Reconcile(reconcile.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(c.Get(ctx, key, kr)).To(Succeed())
+	g.Expect(conditions.IsTrue(kr, expv1.KubeconfigIssuedCondition)).To(BeTrue())
+	g.Expect(kr.Status.ExpirationTime.Time).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+}
+
+func TestKubeconfigRequestReconcileCertificateAuthoritiesRotation(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+	g.Expect(expv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	cluster, caSecret, kr := newKubeconfigRequestTestObjects(g)
+	kr.Spec.RoleBindings = nil
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, cluster, caSecret, kr)
+	r := newKubeconfigRequestReconciler(c)
+	ctx := context.Background()
+	key := util.ObjectKey(kr)
+
+	_, err := r.Reconcile(reconcile.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(c.Get(ctx, key, kr)).To(Succeed())
+	expirationTime := kr.Status.ExpirationTime
+
+	// The kubeconfig is reissued when the cluster CA changes, without extending its validity.
+	g.Expect(c.Get(ctx, util.ObjectKey(caSecret), caSecret)).To(Succeed())
+	g.Expect(secret.AdvanceRotation(caSecret, secret.ClusterCA)).To(Succeed())
+	g.Expect(c.Update(ctx, caSecret)).To(Succeed())
+
+	_, err = r.Reconcile(reconcile.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	kubeconfigSecret := &corev1.Secret{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: kr.Status.SecretName}, kubeconfigSecret)).To(Succeed())
+	config, err := clientcmd.Load(kubeconfigSecret.Data[secret.KubeconfigDataName])
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(config.Clusters[cluster.Name].CertificateAuthorityData).To(Equal(caSecret.Data[secret.TLSCrtDataName]))
+	g.Expect(c.Get(ctx, key, kr)).To(Succeed())
+	g.Expect(kr.Status.ExpirationTime.Time).To(BeTemporally("==", expirationTime.Time))
+	clientCert, err := certs.DecodeCertPEM(config.AuthInfos["jane"].ClientCertificateData)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(clientCert.NotAfter).To(BeTemporally("~", expirationTime.Time, time.Minute))
+}
+
+func TestKubeconfigRequestReconcileWaitsForControlPlane(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+	g.Expect(expv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	cluster, caSecret, kr := newKubeconfigRequestTestObjects(g)
+	cluster.Status.ControlPlaneInitialized = false
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, cluster, caSecret, kr)
+	r := newKubeconfigRequestReconciler(c)
+
+	_, err := r.Reconcile(reconcile.Request{NamespacedName: util.ObjectKey(kr)})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(c.Get(context.Background(), util.ObjectKey(kr), kr)).To(Succeed())
+	g.Expect(conditions.GetReason(kr, expv1.KubeconfigIssuedCondition)).To(Equal(expv1.WaitingForControlPlaneInitializedReason))
+	g.Expect(kr.Status.SecretName).To(BeEmpty())
+}
+
+func TestKubeconfigRequestDoesNotOverwriteSecrets(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+	g.Expect(expv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	cluster, caSecret, kr := newKubeconfigRequestTestObjects(g)
+	existing := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "jane-user-kubeconfig",
+		},
+	}
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, cluster, caSecret, kr, existing)
+	r := newKubeconfigRequestReconciler(c)
+
+	_, err := r.Reconcile(reconcile.Request{NamespacedName: util.ObjectKey(kr)})
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("is not controlled by"))
+}
+
+func TestKubeconfigRequestReconcileDelete(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+	g.Expect(expv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	cluster, caSecret, kr := newKubeconfigRequestTestObjects(g)
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, cluster, caSecret, kr)
+	r := newKubeconfigRequestReconciler(c)
+	ctx := context.Background()
+
+	_, err := r.Reconcile(reconcile.Request{NamespacedName: util.ObjectKey(kr)})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(c.Get(ctx, util.ObjectKey(kr), kr)).To(Succeed())
+
+	_, err = r.reconcileDelete(ctx, cluster, kr)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(kr.Finalizers).NotTo(ContainElement(expv1.KubeconfigRequestFinalizer))
+
+	clusterRoleBindings := &rbacv1.ClusterRoleBindingList{}
+	g.Expect(c.List(ctx, clusterRoleBindings)).To(Succeed())
+	g.Expect(clusterRoleBindings.Items).To(BeEmpty())
+	roleBindings := &rbacv1.RoleBindingList{}
+	g.Expect(c.List(ctx, roleBindings)).To(Succeed())
+	g.Expect(roleBindings.Items).To(BeEmpty())
+}
@@ -37,8 +37,11 @@ import (
 	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/predicates"
+	capirecord "sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/cluster-api/util/tracing"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -92,14 +95,15 @@ func (r *MachinePoolReconciler) SetupWithManager(mgr ctrl.Manager, options contr
 	}
 
 	r.controller = c
-	r.recorder = mgr.GetEventRecorderFor("machinepool-controller")
+	r.recorder = capirecord.NewRateLimitedRecorder(mgr.GetEventRecorderFor("machinepool-controller"), capirecord.DefaultDuplicateEventsInterval)
 	r.config = mgr.GetConfig()
 	r.scheme = mgr.GetScheme()
 	return nil
 }
 
 func (r *MachinePoolReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, reterr error) {
-	ctx := context.Background()
+	ctx, span := tracing.StartSpan(context.Background(), "MachinePoolReconciler.Reconcile", tracing.ObjectAttributes("MachinePool", req.Namespace, req.Name)...)
+	defer func() { tracing.EndSpan(span, reterr) }()
 	logger := r.Log.WithValues("machinepool", req.NamespacedName)
 
 	mp := &expv1.MachinePool{}
@@ -133,6 +137,15 @@ func (r *MachinePoolReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, rete
 	}
 
 	defer func() {
+		// Always update the readyCondition with the summary of the machine pool conditions.
+		conditions.SetSummary(mp,
+			conditions.WithConditions(
+				clusterv1.BootstrapReadyCondition,
+				clusterv1.InfrastructureReadyCondition,
+				expv1.ReplicasReadyCondition,
+			),
+		)
+
 		r.reconcilePhase(mp)
 		// TODO(jpang): add support for metrics.
 
@@ -172,6 +185,24 @@ func (r *MachinePoolReconciler) reconcile(ctx context.Context, cluster *clusterv
 	// If the MachinePool doesn't have a finalizer, add one.
 	controllerutil.AddFinalizer(mp, expv1.MachinePoolFinalizer)
 
+	// Do not reconcile the bootstrap and infrastructure objects for a minor version newer than the one the control
+	// plane has been rolled out to, as this is not supported by the Kubernetes version skew policy.
+	if mp.Spec.Template.Spec.Version != nil {
+		version, err := util.ParseMajorMinorPatch(*mp.Spec.Template.Spec.Version)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		controlPlaneVersion, err := util.GetControlPlaneVersion(ctx, r.Client, cluster)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if controlPlaneVersion != nil && util.IsMinorVersionNewer(version, *controlPlaneVersion) {
+			logger.Info("Waiting for the control plane to be upgraded before reconciling the MachinePool", "version", *mp.Spec.Template.Spec.Version)
+			capirecord.VersionSkewNotSupported.Emit(r.recorder, mp, *mp.Spec.Template.Spec.Version, controlPlaneVersion)
+			return ctrl.Result{}, r.reconcileNodeRefs(ctx, cluster, mp)
+		}
+	}
+
 	// Call the inner reconciliation methods.
 	reconciliationErrors := []error{
 		r.reconcileBootstrap(ctx, cluster, mp),
@@ -30,6 +30,8 @@ import (
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	capirecord "sigs.k8s.io/cluster-api/util/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -52,6 +54,7 @@ func (r *MachinePoolReconciler) reconcileNodeRefs(ctx context.Context, cluster *
 
 	// Check that the Machine doesn't already have a NodeRefs.
 	if mp.Status.Replicas == mp.Status.ReadyReplicas && len(mp.Status.NodeRefs) == int(mp.Status.ReadyReplicas) {
+		conditions.MarkTrue(mp, expv1.ReplicasReadyCondition)
 		return nil
 	}
 
@@ -85,7 +88,7 @@ func (r *MachinePoolReconciler) reconcileNodeRefs(ctx context.Context, cluster *
 			return errors.Wrapf(&capierrors.RequeueAfterError{RequeueAfter: 10 * time.Second},
 				"cannot assign NodeRefs to MachinePool, no matching Nodes")
 		}
-		r.recorder.Event(mp, apicorev1.EventTypeWarning, "FailedSetNodeRef", err.Error())
+		capirecord.FailedSetNodeRef.Emit(r.recorder, mp, err)
 		return errors.Wrapf(err, "failed to get node references")
 	}
 
@@ -95,12 +98,16 @@ func (r *MachinePoolReconciler) reconcileNodeRefs(ctx context.Context, cluster *
 	mp.Status.NodeRefs = nodeRefsResult.references
 
 	logger.Info("Set MachinePools's NodeRefs", "noderefs", mp.Status.NodeRefs)
-	r.recorder.Event(mp, apicorev1.EventTypeNormal, "SuccessfulSetNodeRefs", fmt.Sprintf("%+v", mp.Status.NodeRefs))
+	capirecord.SuccessfulSetNodeRef.Emit(r.recorder, mp, fmt.Sprintf("%+v", mp.Status.NodeRefs))
 
 	if mp.Status.Replicas != mp.Status.ReadyReplicas || len(nodeRefsResult.references) != int(mp.Status.ReadyReplicas) {
+		conditions.MarkFalse(mp, expv1.ReplicasReadyCondition, expv1.WaitingForReplicasReadyReason, clusterv1.ConditionSeverityInfo,
+			"%d of %d replicas ready", mp.Status.ReadyReplicas, mp.Status.Replicas)
 		return errors.Wrapf(&capierrors.RequeueAfterError{RequeueAfter: 30 * time.Second},
 			"NodeRefs != ReadyReplicas [%q != %q] for MachinePool %q in namespace %q", len(nodeRefsResult.references), mp.Status.ReadyReplicas, mp.Name, mp.Namespace)
 	}
+
+	conditions.MarkTrue(mp, expv1.ReplicasReadyCondition)
 	return nil
 }
 
@@ -0,0 +1,50 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"sigs.k8s.io/cluster-api/controllers/metrics"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MachinePoolStates is a metrics.StateLister for MachinePools.
+func MachinePoolStates(ctx context.Context, c client.Reader) ([]metrics.ObjectState, error) {
+	list := &expv1.MachinePoolList{}
+	if err := c.List(ctx, list); err != nil {
+		return nil, err
+	}
+	states := make([]metrics.ObjectState, 0, len(list.Items))
+	for _, o := range list.Items {
+		o := o
+		states = append(states, metrics.ObjectState{
+			Kind:      "MachinePool",
+			Name:      o.Name,
+			Namespace: o.Namespace,
+			Cluster:   o.Spec.ClusterName,
+			Replicas: &metrics.ReplicaCounts{
+				Desired:   o.Spec.Replicas,
+				Ready:     &o.Status.ReadyReplicas,
+				Available: &o.Status.AvailableReplicas,
+			},
+			Conditions: o.Status.Conditions,
+		})
+	}
+	return states, nil
+}
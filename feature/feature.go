@@ -31,6 +31,14 @@ const (
 	// owner: @
 	// alpha: v0.3
 	MachinePool featuregate.Feature = "MachinePool"
+
+	// owner: @
+	// alpha: v0.3
+	KubeconfigRequest featuregate.Feature = "KubeconfigRequest"
+
+	// owner: @
+	// alpha: v0.3
+	ControllerServiceAccount featuregate.Feature = "ControllerServiceAccount"
 )
 
 func init() {
@@ -41,5 +49,7 @@ func init() {
 // To add a new feature, define a key for it above and add it here.
 var defaultClusterAPIFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
 	// Every feature should be initiated here:
-	MachinePool: {Default: false, PreRelease: featuregate.Alpha},
+	MachinePool:              {Default: false, PreRelease: featuregate.Alpha},
+	KubeconfigRequest:        {Default: false, PreRelease: featuregate.Alpha},
+	ControllerServiceAccount: {Default: false, PreRelease: featuregate.Alpha},
 }
@@ -16,6 +16,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"math/rand"
 	"net/http"
@@ -33,14 +34,20 @@ import (
 	clusterv1alpha3 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/version"
 	"sigs.k8s.io/cluster-api/controllers"
+	"sigs.k8s.io/cluster-api/controllers/metrics"
 	"sigs.k8s.io/cluster-api/controllers/remote"
 	expv1alpha3 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
 	expcontrollers "sigs.k8s.io/cluster-api/exp/controllers"
 	"sigs.k8s.io/cluster-api/feature"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/record"
+	"sigs.k8s.io/cluster-api/util/tracing"
+	"sigs.k8s.io/cluster-api/util/tunnel"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -56,13 +63,23 @@ var (
 	leaderElectionRetryPeriod     time.Duration
 	watchNamespace                string
 	profilerAddress               string
+	tracingExporter               string
+	tracingOTLPEndpoint           string
+	tunnelBindAddress             string
+	tunnelCertFile                string
+	tunnelKeyFile                 string
 	clusterConcurrency            int
 	machineConcurrency            int
 	machineSetConcurrency         int
 	machineDeploymentConcurrency  int
 	machinePoolConcurrency        int
 	machineHealthCheckConcurrency int
+	kubeconfigRequestConcurrency  int
 	syncPeriod                    time.Duration
+	remoteProbeInterval           time.Duration
+	remoteProbeTimeout            time.Duration
+	remoteProbeUnhealthyThreshold int
+	controllerCredentialsRotation time.Duration
 	webhookPort                   int
 	healthAddr                    string
 )
@@ -101,6 +118,21 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&profilerAddress, "profiler-address", "",
 		"Bind address to expose the pprof profiler (e.g. localhost:6060)")
 
+	fs.StringVar(&tracingExporter, "tracing-exporter", tracing.ExporterNone,
+		"Exporter for the OpenTelemetry traces of the reconciles, one of 'stdout' or 'otlp'. If unspecified, tracing is disabled.")
+
+	fs.StringVar(&tracingOTLPEndpoint, "tracing-otlp-endpoint", tracing.DefaultOTLPEndpoint,
+		"The OTLP/HTTP endpoint traces are sent to when using the 'otlp' tracing exporter.")
+
+	fs.StringVar(&tunnelBindAddress, "tunnel-bind-address", "",
+		"The address the reverse tunnel server binds to, for reaching workload clusters through the tunnel agent running in them (e.g. :8443). If unspecified, the tunnel server is disabled.")
+
+	fs.StringVar(&tunnelCertFile, "tunnel-tls-cert-file", "",
+		"The TLS certificate presented by the reverse tunnel server to the tunnel agents.")
+
+	fs.StringVar(&tunnelKeyFile, "tunnel-tls-private-key-file", "",
+		"The TLS private key of the reverse tunnel server.")
+
 	fs.IntVar(&clusterConcurrency, "cluster-concurrency", 10,
 		"Number of clusters to process simultaneously")
 
@@ -119,9 +151,24 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&machineHealthCheckConcurrency, "machinehealthcheck-concurrency", 10,
 		"Number of machine health checks to process simultaneously")
 
+	fs.IntVar(&kubeconfigRequestConcurrency, "kubeconfigrequest-concurrency", 10,
+		"Number of kubeconfig requests to process simultaneously")
+
 	fs.DurationVar(&syncPeriod, "sync-period", 10*time.Minute,
 		"The minimum interval at which watched resources are reconciled (e.g. 15m)")
 
+	fs.DurationVar(&remoteProbeInterval, "remote-connection-probe-interval", remote.DefaultHealthCheckPollInterval,
+		"The interval at which the connection to the API server of each workload cluster is probed (e.g. 10s)")
+
+	fs.DurationVar(&remoteProbeTimeout, "remote-connection-probe-timeout", remote.DefaultHealthCheckRequestTimeout,
+		"The timeout of a probe of the connection to the API server of a workload cluster (e.g. 5s)")
+
+	fs.IntVar(&remoteProbeUnhealthyThreshold, "remote-connection-probe-unhealthy-threshold", remote.DefaultHealthCheckUnhealthyThreshold,
+		"Number of consecutive failed probes after which the connection to a workload cluster is considered unhealthy and reset")
+
+	fs.DurationVar(&controllerCredentialsRotation, "controller-credentials-rotation-period", 0,
+		"How often the token of the ServiceAccount used by the controllers in each workload cluster is rotated (e.g. 720h); disabled by default. Requires the ControllerServiceAccount feature gate.")
+
 	fs.IntVar(&webhookPort, "webhook-port", 0,
 		"Webhook Server port, disabled by default. When enabled, the manager will only work as webhook server, no reconcilers are installed.")
 
@@ -147,6 +194,16 @@ func main() {
 		}()
 	}
 
+	shutdownTracing, err := tracing.Setup(tracing.Options{
+		ServiceName:  "capi-controller-manager",
+		Exporter:     tracingExporter,
+		OTLPEndpoint: tracingOTLPEndpoint,
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
@@ -168,6 +225,8 @@ func main() {
 
 	setupChecks(mgr)
 	setupReconcilers(mgr)
+	setupTunnel(mgr)
+	setupMetrics(mgr)
 	setupWebhooks(mgr)
 
 	// +kubebuilder:scaffold:builder
@@ -176,6 +235,10 @@ func main() {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
+
+	if err := shutdownTracing(context.Background()); err != nil {
+		setupLog.Error(err, "problem flushing traces")
+	}
 }
 
 func setupChecks(mgr ctrl.Manager) {
@@ -190,16 +253,50 @@ func setupChecks(mgr ctrl.Manager) {
 	}
 }
 
+func setupMetrics(mgr ctrl.Manager) {
+	if webhookPort != 0 {
+		return
+	}
+
+	listers := []metrics.StateLister{
+		metrics.ClusterStates,
+		metrics.MachineStates,
+		metrics.MachineSetStates,
+		metrics.MachineDeploymentStates,
+		metrics.MachineHealthCheckStates,
+	}
+	if feature.Gates.Enabled(feature.MachinePool) {
+		listers = append(listers, expcontrollers.MachinePoolStates)
+	}
+
+	if err := ctrlmetrics.Registry.Register(&metrics.StateCollector{
+		Client:  mgr.GetClient(),
+		Log:     ctrl.Log.WithName("metrics").WithName("StateCollector"),
+		Listers: listers,
+	}); err != nil {
+		setupLog.Error(err, "unable to register metrics collector")
+		os.Exit(1)
+	}
+}
+
 func setupReconcilers(mgr ctrl.Manager) {
 	if webhookPort != 0 {
 		return
 	}
 
+	// Emit an Event for each condition transition of the objects reconciled by this manager, once patched.
+	conditions.AddChangeHandler(record.ConditionChangeHandler(
+		record.NewRateLimitedRecorder(mgr.GetEventRecorderFor("cluster-api-controller-manager"), record.DefaultDuplicateEventsInterval),
+	))
+
 	// Set up a ClusterCacheTracker and ClusterCacheReconciler to provide to controllers
 	// requiring a connection to a remote cluster
 	tracker, err := remote.NewClusterCacheTracker(
 		ctrl.Log.WithName("remote").WithName("ClusterCacheTracker"),
 		mgr,
+		remote.HealthCheckPollInterval(remoteProbeInterval),
+		remote.HealthCheckRequestTimeout(remoteProbeTimeout),
+		remote.HealthCheckUnhealthyThreshold(remoteProbeUnhealthyThreshold),
 	)
 	if err != nil {
 		setupLog.Error(err, "unable to create cluster cache tracker")
@@ -215,8 +312,9 @@ func setupReconcilers(mgr ctrl.Manager) {
 	}
 
 	if err := (&controllers.ClusterReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Cluster"),
+		Client:                              mgr.GetClient(),
+		Log:                                 ctrl.Log.WithName("controllers").WithName("Cluster"),
+		ControllerCredentialsRotationPeriod: controllerCredentialsRotation,
 	}).SetupWithManager(mgr, concurrency(clusterConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Cluster")
 		os.Exit(1)
@@ -254,6 +352,15 @@ func setupReconcilers(mgr ctrl.Manager) {
 			os.Exit(1)
 		}
 	}
+	if feature.Gates.Enabled(feature.KubeconfigRequest) {
+		if err := (&expcontrollers.KubeconfigRequestReconciler{
+			Client: mgr.GetClient(),
+			Log:    ctrl.Log.WithName("controllers").WithName("KubeconfigRequest"),
+		}).SetupWithManager(mgr, concurrency(kubeconfigRequestConcurrency)); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "KubeconfigRequest")
+			os.Exit(1)
+		}
+	}
 	if err := (&controllers.MachineHealthCheckReconciler{
 		Client:  mgr.GetClient(),
 		Log:     ctrl.Log.WithName("controllers").WithName("MachineHealthCheck"),
@@ -264,6 +371,25 @@ func setupReconcilers(mgr ctrl.Manager) {
 	}
 }
 
+func setupTunnel(mgr ctrl.Manager) {
+	if webhookPort != 0 || tunnelBindAddress == "" {
+		return
+	}
+
+	server := &tunnel.Server{
+		Log:         ctrl.Log.WithName("tunnel"),
+		Client:      mgr.GetClient(),
+		BindAddress: tunnelBindAddress,
+		CertFile:    tunnelCertFile,
+		KeyFile:     tunnelKeyFile,
+	}
+	if err := mgr.Add(server); err != nil {
+		setupLog.Error(err, "unable to add tunnel server")
+		os.Exit(1)
+	}
+	remote.SetClusterDialer(server.DialContextFor)
+}
+
 func setupWebhooks(mgr ctrl.Manager) {
 	if webhookPort == 0 {
 		return
@@ -332,6 +458,13 @@ func setupWebhooks(mgr ctrl.Manager) {
 		}
 	}
 
+	if feature.Gates.Enabled(feature.KubeconfigRequest) {
+		if err := (&expv1alpha3.KubeconfigRequest{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "KubeconfigRequest")
+			os.Exit(1)
+		}
+	}
+
 	if err := (&clusterv1alpha3.MachineHealthCheck{}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "MachineHealthCheck")
 		os.Exit(1)
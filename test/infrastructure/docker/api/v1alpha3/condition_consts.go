@@ -77,3 +77,24 @@ const (
 	// errors are usually transient and failed provisioning are automatically re-tried by the controller.
 	LoadBalancerProvisioningFailedReason = "LoadBalancerProvisioningFailed"
 )
+
+const (
+	// IdentityResolvedCondition documents the resolution of the DockerClusterIdentity referenced by a DockerCluster,
+	// including the check that the namespace of the DockerCluster is allowed to use it.
+	//
+	// NOTE: This condition exists only on DockerClusters with an identityRef.
+	IdentityResolvedCondition clusterv1.ConditionType = "IdentityResolved"
+
+	// InvalidIdentityReferenceReason (Severity=Error) documents a DockerCluster with an identityRef which is not
+	// a reference to a DockerClusterIdentity.
+	InvalidIdentityReferenceReason = "InvalidIdentityReference"
+
+	// IdentityNotAllowedReason (Severity=Error) documents a DockerCluster referencing a DockerClusterIdentity
+	// which does not allow the namespace of the DockerCluster.
+	IdentityNotAllowedReason = "IdentityNotAllowed"
+
+	// IdentityResolutionFailedReason (Severity=Warning) documents a DockerCluster controller detecting an error
+	// while resolving the DockerClusterIdentity referenced by the DockerCluster, e.g. because it does not exist yet;
+	// those kind of errors are usually transient and the resolution is automatically re-tried by the controller.
+	IdentityResolutionFailedReason = "IdentityResolutionFailed"
+)
@@ -42,6 +42,11 @@ type DockerClusterSpec struct {
 	// controllers to do what they will with the defined failure domains.
 	// +optional
 	FailureDomains clusterv1.FailureDomains `json:"failureDomains,omitempty"`
+
+	// IdentityRef is a reference to the DockerClusterIdentity defining the docker resources to be used for this cluster.
+	// If not set, the defaults of the docker daemon are used. This field is immutable.
+	// +optional
+	IdentityRef *clusterv1.ClusterIdentityReference `json:"identityRef,omitempty"`
 }
 
 // DockerClusterStatus defines the observed state of DockerCluster.
@@ -53,6 +58,11 @@ type DockerClusterStatus struct {
 	// will use this if we populate it.
 	FailureDomains clusterv1.FailureDomains `json:"failureDomains,omitempty"`
 
+	// Network is the docker network the containers of the cluster are attached to, as resolved from the
+	// DockerClusterIdentity when the cluster infrastructure is provisioned; empty for the docker default network.
+	// +optional
+	Network *string `json:"network,omitempty"`
+
 	// Conditions defines current service state of the DockerCluster.
 	// +optional
 	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"testing"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+)
+
+func TestDockerClusterValidateUpdate(t *testing.T) {
+	oldCluster := &DockerCluster{
+		Spec: DockerClusterSpec{
+			IdentityRef: &clusterv1.ClusterIdentityReference{
+				APIVersion: GroupVersion.String(),
+				Kind:       DockerClusterIdentityKind,
+				Name:       "tenant-a-identity",
+			},
+		},
+	}
+
+	changeIdentity := oldCluster.DeepCopy()
+	changeIdentity.Spec.IdentityRef.Name = "tenant-b-identity"
+
+	removeIdentity := oldCluster.DeepCopy()
+	removeIdentity.Spec.IdentityRef = nil
+
+	changeFailureDomains := oldCluster.DeepCopy()
+	changeFailureDomains.Spec.FailureDomains = clusterv1.FailureDomains{"fd1": clusterv1.FailureDomainSpec{ControlPlane: true}}
+
+	tests := []struct {
+		name       string
+		newCluster *DockerCluster
+		oldCluster *DockerCluster
+		wantError  bool
+	}{
+		{
+			name:       "return no error if no modification",
+			newCluster: oldCluster,
+			oldCluster: oldCluster,
+			wantError:  false,
+		},
+		{
+			name:       "return no error if fields other than identityRef are modified",
+			newCluster: changeFailureDomains,
+			oldCluster: oldCluster,
+			wantError:  false,
+		},
+		{
+			name:       "don't allow changing identityRef",
+			newCluster: changeIdentity,
+			oldCluster: oldCluster,
+			wantError:  true,
+		},
+		{
+			name:       "don't allow removing identityRef",
+			newCluster: removeIdentity,
+			oldCluster: oldCluster,
+			wantError:  true,
+		},
+		{
+			name:       "don't allow adding identityRef",
+			newCluster: oldCluster,
+			oldCluster: removeIdentity,
+			wantError:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.newCluster.ValidateUpdate(tt.oldCluster)
+			if (err != nil) != tt.wantError {
+				t.Errorf("unexpected result - wanted %+v, got %+v", tt.wantError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+)
+
+const (
+	// DockerClusterIdentityKind is the kind of the DockerClusterIdentity objects.
+	DockerClusterIdentityKind = "DockerClusterIdentity"
+)
+
+// DockerClusterIdentitySpec defines the desired state of DockerClusterIdentity.
+type DockerClusterIdentitySpec struct {
+	clusterv1.ClusterIdentitySpec `json:",inline"`
+
+	// Network is the docker network the containers of the clusters using this identity are attached to.
+	// If empty, the docker default bridge network is used.
+	// +optional
+	Network string `json:"network,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=dockerclusteridentities,scope=Cluster,categories=cluster-api
+// +kubebuilder:storageversion
+
+// DockerClusterIdentity is the Schema for the dockerclusteridentities API.
+// It defines the docker resources used by the DockerClusters referencing it, and the namespaces
+// allowed to use it.
+type DockerClusterIdentity struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DockerClusterIdentitySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DockerClusterIdentityList contains a list of DockerClusterIdentity
+type DockerClusterIdentityList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DockerClusterIdentity `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DockerClusterIdentity{}, &DockerClusterIdentityList{})
+}
@@ -67,6 +67,80 @@ func (in *DockerCluster) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DockerClusterIdentity) DeepCopyInto(out *DockerClusterIdentity) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DockerClusterIdentity.
+func (in *DockerClusterIdentity) DeepCopy() *DockerClusterIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(DockerClusterIdentity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DockerClusterIdentity) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DockerClusterIdentityList) DeepCopyInto(out *DockerClusterIdentityList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DockerClusterIdentity, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DockerClusterIdentityList.
+func (in *DockerClusterIdentityList) DeepCopy() *DockerClusterIdentityList {
+	if in == nil {
+		return nil
+	}
+	out := new(DockerClusterIdentityList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DockerClusterIdentityList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DockerClusterIdentitySpec) DeepCopyInto(out *DockerClusterIdentitySpec) {
+	*out = *in
+	in.ClusterIdentitySpec.DeepCopyInto(&out.ClusterIdentitySpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DockerClusterIdentitySpec.
+func (in *DockerClusterIdentitySpec) DeepCopy() *DockerClusterIdentitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DockerClusterIdentitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DockerClusterList) DeepCopyInto(out *DockerClusterList) {
 	*out = *in
@@ -110,6 +184,11 @@ func (in *DockerClusterSpec) DeepCopyInto(out *DockerClusterSpec) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.IdentityRef != nil {
+		in, out := &in.IdentityRef, &out.IdentityRef
+		*out = new(apiv1alpha3.ClusterIdentityReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DockerClusterSpec.
@@ -132,6 +211,11 @@ func (in *DockerClusterStatus) DeepCopyInto(out *DockerClusterStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.Network != nil {
+		in, out := &in.Network, &out.Network
+		*out = new(string)
+		**out = **in
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make(apiv1alpha3.Conditions, len(*in))
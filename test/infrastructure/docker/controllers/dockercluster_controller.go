@@ -22,11 +22,13 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	infrav1 "sigs.k8s.io/cluster-api/test/infrastructure/docker/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/test/infrastructure/docker/docker"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/identity"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/predicates"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -49,6 +51,8 @@ type DockerClusterReconciler struct {
 
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=dockerclusters,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=dockerclusters/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=dockerclusteridentities,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 
 // Reconcile reads that state of the cluster for a DockerCluster object and makes changes based on the state read
 // and what is in the DockerCluster.Spec
@@ -77,12 +81,6 @@ func (r *DockerClusterReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, re
 
 	log = log.WithValues("cluster", cluster.Name)
 
-	// Create a helper for managing a docker container hosting the loadbalancer.
-	externalLoadBalancer, err := docker.NewLoadBalancer(cluster.Name, log)
-	if err != nil {
-		return ctrl.Result{}, errors.Wrapf(err, "failed to create helper for managing the externalLoadBalancer")
-	}
-
 	// Initialize the patch helper
 	patchHelper, err := patch.NewHelper(dockerCluster, r)
 	if err != nil {
@@ -91,10 +89,12 @@ func (r *DockerClusterReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, re
 	// Always attempt to Patch the DockerCluster object and status after each reconciliation.
 	defer func() {
 		// always update the readyCondition; the summary is represented using the "1 of x completed" notation.
+		summaryConditions := []clusterv1.ConditionType{infrav1.LoadBalancerAvailableCondition}
+		if dockerCluster.Spec.IdentityRef != nil {
+			summaryConditions = append([]clusterv1.ConditionType{infrav1.IdentityResolvedCondition}, summaryConditions...)
+		}
 		conditions.SetSummary(dockerCluster,
-			conditions.WithConditions(
-				infrav1.LoadBalancerAvailableCondition,
-			),
+			conditions.WithConditions(summaryConditions...),
 			conditions.WithStepCounter(),
 		)
 
@@ -111,6 +111,27 @@ func (r *DockerClusterReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, re
 	// In the case of Docker, failure domains don't mean much so we simply copy the Spec into the Status.
 	dockerCluster.Status.FailureDomains = dockerCluster.Spec.FailureDomains
 
+	// Resolve the docker network to be used for the cluster from the DockerClusterIdentity referenced by the DockerCluster, if any,
+	// and record it in the status, so the containers of the cluster are not moved to another network if the identity changes.
+	// NB. the identity is not required for deleting the load balancer, so deletion is not blocked by a missing identity.
+	network := ""
+	switch {
+	case dockerCluster.Status.Network != nil:
+		network = *dockerCluster.Status.Network
+	case dockerCluster.DeletionTimestamp.IsZero():
+		network, err = reconcileIdentity(ctx, r.Client, dockerCluster)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		dockerCluster.Status.Network = &network
+	}
+
+	// Create a helper for managing a docker container hosting the loadbalancer.
+	externalLoadBalancer, err := docker.NewLoadBalancer(cluster.Name, network, log)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to create helper for managing the externalLoadBalancer")
+	}
+
 	// Handle deleted clusters
 	if !dockerCluster.DeletionTimestamp.IsZero() {
 		return reconcileDelete(ctx, dockerCluster, externalLoadBalancer)
@@ -120,6 +141,31 @@ func (r *DockerClusterReconciler) Reconcile(req ctrl.Request) (_ ctrl.Result, re
 	return reconcileNormal(ctx, dockerCluster, externalLoadBalancer)
 }
 
+// reconcileIdentity resolves the DockerClusterIdentity referenced by the DockerCluster, if any, and returns the docker
+// network defined by the identity.
+func reconcileIdentity(ctx context.Context, c client.Client, dockerCluster *infrav1.DockerCluster) (string, error) {
+	if dockerCluster.Spec.IdentityRef == nil {
+		conditions.Delete(dockerCluster, infrav1.IdentityResolvedCondition)
+		return "", nil
+	}
+
+	network, err := getNetwork(ctx, c, dockerCluster)
+	if err != nil {
+		switch errors.Cause(err) {
+		case errInvalidIdentityReference:
+			conditions.MarkFalse(dockerCluster, infrav1.IdentityResolvedCondition, infrav1.InvalidIdentityReferenceReason, clusterv1.ConditionSeverityError, err.Error())
+		case identity.ErrNamespaceNotAllowed:
+			conditions.MarkFalse(dockerCluster, infrav1.IdentityResolvedCondition, infrav1.IdentityNotAllowedReason, clusterv1.ConditionSeverityError, err.Error())
+		default:
+			conditions.MarkFalse(dockerCluster, infrav1.IdentityResolvedCondition, infrav1.IdentityResolutionFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		}
+		return "", errors.Wrap(err, "failed to resolve the identity")
+	}
+
+	conditions.MarkTrue(dockerCluster, infrav1.IdentityResolvedCondition)
+	return network, nil
+}
+
 func reconcileNormal(ctx context.Context, dockerCluster *infrav1.DockerCluster, externalLoadBalancer *docker.LoadBalancer) (ctrl.Result, error) {
 	// If the DockerCluster doesn't have finalizer, add it.
 	controllerutil.AddFinalizer(dockerCluster, infrav1.ClusterFinalizer)
@@ -170,6 +216,14 @@ func (r *DockerClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if err != nil {
 		return err
 	}
+	if err := c.Watch(
+		&source.Kind{Type: &infrav1.DockerClusterIdentity{}},
+		&handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.DockerClusterIdentityToDockerClusters),
+		},
+	); err != nil {
+		return err
+	}
 	return c.Watch(
 		&source.Kind{Type: &clusterv1.Cluster{}},
 		&handler.EnqueueRequestsFromMapFunc{
@@ -178,3 +232,33 @@ func (r *DockerClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		predicates.ClusterUnpaused(r.Log),
 	)
 }
+
+// DockerClusterIdentityToDockerClusters is a handler.ToRequestsFunc to be used to enqueue
+// requests for reconciliation of the DockerClusters referencing a DockerClusterIdentity.
+func (r *DockerClusterReconciler) DockerClusterIdentityToDockerClusters(o handler.MapObject) []ctrl.Request {
+	result := []ctrl.Request{}
+	dockerClusterIdentity, ok := o.Object.(*infrav1.DockerClusterIdentity)
+	if !ok {
+		r.Log.Error(errors.Errorf("expected a DockerClusterIdentity but got a %T", o.Object), "failed to get DockerClusters for DockerClusterIdentity")
+		return nil
+	}
+
+	dockerClusterList := &infrav1.DockerClusterList{}
+	if err := r.Client.List(context.TODO(), dockerClusterList); err != nil {
+		r.Log.Error(err, "failed to list DockerClusters", "DockerClusterIdentity", dockerClusterIdentity.Name)
+		return nil
+	}
+	for _, c := range dockerClusterList.Items {
+		ref := c.Spec.IdentityRef
+		if ref == nil || ref.Kind != infrav1.DockerClusterIdentityKind || ref.Name != dockerClusterIdentity.Name {
+			continue
+		}
+		if gv, err := schema.ParseGroupVersion(ref.APIVersion); err != nil || gv.Group != infrav1.GroupVersion.Group {
+			continue
+		}
+		name := client.ObjectKey{Namespace: c.Namespace, Name: c.Name}
+		result = append(result, ctrl.Request{NamespacedName: name})
+	}
+
+	return result
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	infrav1 "sigs.k8s.io/cluster-api/test/infrastructure/docker/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/identity"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// errInvalidIdentityReference is returned when the identityRef of a DockerCluster is not a reference to a DockerClusterIdentity.
+	errInvalidIdentityReference = errors.Errorf("identityRef must be a reference to a %s", infrav1.DockerClusterIdentityKind)
+)
+
+// getDockerClusterIdentity returns the DockerClusterIdentity referenced by a DockerCluster, or nil if the DockerCluster
+// does not reference an identity; an error is returned if the namespace of the DockerCluster is not allowed to use the identity.
+func getDockerClusterIdentity(ctx context.Context, c client.Client, dockerCluster *infrav1.DockerCluster) (*infrav1.DockerClusterIdentity, error) {
+	ref := dockerCluster.Spec.IdentityRef
+	if ref == nil {
+		return nil, nil
+	}
+
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil || gv.Group != infrav1.GroupVersion.Group || ref.Kind != infrav1.DockerClusterIdentityKind {
+		return nil, errors.WithStack(errInvalidIdentityReference)
+	}
+
+	obj, err := identity.Resolve(ctx, c, ref, dockerCluster.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	dockerClusterIdentity := &infrav1.DockerClusterIdentity{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), dockerClusterIdentity); err != nil {
+		return nil, errors.Wrapf(err, "failed to convert %s %q", infrav1.DockerClusterIdentityKind, ref.Name)
+	}
+	return dockerClusterIdentity, nil
+}
+
+// getNetwork returns the docker network to be used for the containers of a DockerCluster, as defined by the
+// DockerClusterIdentity referenced by the DockerCluster; if empty, the docker default network should be used.
+func getNetwork(ctx context.Context, c client.Client, dockerCluster *infrav1.DockerCluster) (string, error) {
+	dockerClusterIdentity, err := getDockerClusterIdentity(ctx, c, dockerCluster)
+	if err != nil {
+		return "", err
+	}
+	if dockerClusterIdentity == nil {
+		return "", nil
+	}
+	return dockerClusterIdentity.Spec.Network, nil
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/klogr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	infrav1 "sigs.k8s.io/cluster-api/test/infrastructure/docker/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/identity"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+func TestGetNetwork(t *testing.T) {
+	tests := []struct {
+		name           string
+		identityRef    *clusterv1.ClusterIdentityReference
+		want           string
+		wantErr        bool
+		wantInvalidRef bool
+		wantNotAllowed bool
+	}{
+		{
+			name:        "DockerCluster without identityRef uses the default network",
+			identityRef: nil,
+			want:        "",
+		},
+		{
+			name:        "DockerCluster referencing an identity allowing its namespace",
+			identityRef: newDockerClusterIdentityRef("tenant-a-identity"),
+			want:        "tenant-a",
+		},
+		{
+			name:           "DockerCluster referencing an identity not allowing its namespace",
+			identityRef:    newDockerClusterIdentityRef("tenant-b-identity"),
+			wantErr:        true,
+			wantNotAllowed: true,
+		},
+		{
+			name:        "DockerCluster referencing an identity which does not exist",
+			identityRef: newDockerClusterIdentityRef("does-not-exist"),
+			wantErr:     true,
+		},
+		{
+			name: "DockerCluster referencing an object which is not a DockerClusterIdentity",
+			identityRef: &clusterv1.ClusterIdentityReference{
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha3",
+				Kind:       "AWSClusterControllerIdentity",
+				Name:       "tenant-a-identity",
+			},
+			wantErr:        true,
+			wantInvalidRef: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			dockerCluster := newDockerCluster("my-cluster", "my-docker-cluster")
+			dockerCluster.Namespace = "tenant-a"
+			dockerCluster.Spec.IdentityRef = tt.identityRef
+
+			c := fake.NewFakeClientWithScheme(setupScheme(),
+				newDockerClusterIdentity("tenant-a-identity", "tenant-a", "tenant-a"),
+				newDockerClusterIdentity("tenant-b-identity", "tenant-b", "tenant-b"),
+			)
+
+			got, err := getNetwork(context.TODO(), c, dockerCluster)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(errors.Cause(err) == errInvalidIdentityReference).To(Equal(tt.wantInvalidRef))
+				g.Expect(errors.Cause(err) == identity.ErrNamespaceNotAllowed).To(Equal(tt.wantNotAllowed))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestDockerClusterReconciler_DockerClusterIdentityToDockerClusters(t *testing.T) {
+	g := NewWithT(t)
+
+	dockerClusterIdentity := newDockerClusterIdentity("tenant-a-identity", "tenant-a", "tenant-a")
+	dockerCluster1 := newDockerCluster("my-cluster-0", "my-docker-cluster-0")
+	dockerCluster1.Spec.IdentityRef = newDockerClusterIdentityRef("tenant-a-identity")
+	dockerCluster2 := newDockerCluster("my-cluster-1", "my-docker-cluster-1")
+	dockerCluster2.Spec.IdentityRef = newDockerClusterIdentityRef("tenant-b-identity")
+	// Intentionally without identityRef
+	dockerCluster3 := newDockerCluster("my-cluster-2", "my-docker-cluster-2")
+	// Intentionally referencing an identity of the same kind and name, but of another API group
+	dockerCluster4 := newDockerCluster("my-cluster-3", "my-docker-cluster-3")
+	dockerCluster4.Spec.IdentityRef = newDockerClusterIdentityRef("tenant-a-identity")
+	dockerCluster4.Spec.IdentityRef.APIVersion = "infrastructure.example.com/v1alpha3"
+
+	c := fake.NewFakeClientWithScheme(setupScheme(), dockerClusterIdentity, dockerCluster1, dockerCluster2, dockerCluster3, dockerCluster4)
+	r := DockerClusterReconciler{
+		Client: c,
+		Log:    klogr.New(),
+	}
+	mo := handler.MapObject{
+		Object: dockerClusterIdentity,
+	}
+	out := r.DockerClusterIdentityToDockerClusters(mo)
+	g.Expect(out).To(HaveLen(1))
+	g.Expect(out[0].Name).To(Equal("my-docker-cluster-0"))
+}
+
+func newDockerClusterIdentityRef(name string) *clusterv1.ClusterIdentityReference {
+	return &clusterv1.ClusterIdentityReference{
+		APIVersion: infrav1.GroupVersion.String(),
+		Kind:       infrav1.DockerClusterIdentityKind,
+		Name:       name,
+	}
+}
+
+func newDockerClusterIdentity(name, network string, allowedNamespaces ...string) *infrav1.DockerClusterIdentity {
+	return &infrav1.DockerClusterIdentity{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: infrav1.GroupVersion.String(),
+			Kind:       infrav1.DockerClusterIdentityKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: infrav1.DockerClusterIdentitySpec{
+			ClusterIdentitySpec: clusterv1.ClusterIdentitySpec{
+				AllowedNamespaces: &clusterv1.AllowedNamespaces{
+					NamespaceList: allowedNamespaces,
+				},
+			},
+			Network: network,
+		},
+	}
+}
@@ -32,7 +32,11 @@ import (
 const KubeadmContainerPort = 6443
 const ControlPlanePort = 6443
 
-type Manager struct{}
+// Manager creates the docker containers hosting the nodes and the load balancer of a cluster.
+type Manager struct {
+	// Network is the docker network the containers are attached to; if empty, the docker default bridge network is used.
+	Network string
+}
 
 func (m *Manager) CreateControlPlaneNode(name, image, clusterLabel, listenAddress string, port int32, mounts []v1alpha4.Mount, portMappings []v1alpha4.PortMapping) (*types.Node, error) {
 	// gets a random host port for the API server
@@ -51,7 +55,7 @@ func (m *Manager) CreateControlPlaneNode(name, image, clusterLabel, listenAddres
 		ContainerPort: KubeadmContainerPort,
 	})
 	node, err := createNode(
-		name, image, clusterLabel, constants.ControlPlaneNodeRoleValue, mounts, portMappingsWithAPIServer,
+		name, image, clusterLabel, constants.ControlPlaneNodeRoleValue, m.Network, mounts, portMappingsWithAPIServer,
 		// publish selected port for the API server
 		"--expose", fmt.Sprintf("%d", port),
 	)
@@ -63,7 +67,7 @@ func (m *Manager) CreateControlPlaneNode(name, image, clusterLabel, listenAddres
 }
 
 func (m *Manager) CreateWorkerNode(name, image, clusterLabel string, mounts []v1alpha4.Mount, portMappings []v1alpha4.PortMapping) (*types.Node, error) {
-	return createNode(name, image, clusterLabel, constants.WorkerNodeRoleValue, mounts, portMappings)
+	return createNode(name, image, clusterLabel, constants.WorkerNodeRoleValue, m.Network, mounts, portMappings)
 }
 
 func (m *Manager) CreateExternalLoadBalancerNode(name, image, clusterLabel, listenAddress string, port int32) (*types.Node, error) {
@@ -84,7 +88,7 @@ func (m *Manager) CreateExternalLoadBalancerNode(name, image, clusterLabel, list
 		ContainerPort: ControlPlanePort,
 	}}
 	node, err := createNode(name, image, clusterLabel, constants.ExternalLoadBalancerNodeRoleValue,
-		nil, portMappings,
+		m.Network, nil, portMappings,
 		// publish selected port for the control plane
 		"--expose", fmt.Sprintf("%d", port),
 	)
@@ -95,7 +99,7 @@ func (m *Manager) CreateExternalLoadBalancerNode(name, image, clusterLabel, list
 	return node, nil
 }
 
-func createNode(name, image, clusterLabel, role string, mounts []v1alpha4.Mount, portMappings []v1alpha4.PortMapping, extraArgs ...string) (*types.Node, error) {
+func createNode(name, image, clusterLabel, role, network string, mounts []v1alpha4.Mount, portMappings []v1alpha4.PortMapping, extraArgs ...string) (*types.Node, error) {
 	runArgs := []string{
 		"--detach", // run the container detached
 		"--tty",    // allocate a tty for entrypoint logs
@@ -125,8 +129,13 @@ func createNode(name, image, clusterLabel, role string, mounts []v1alpha4.Mount,
 		"--label", fmt.Sprintf("%s=%s", nodeRoleLabelKey, role),
 	}
 
+	// attach the node to the selected network, if any
+	if network != "" {
+		runArgs = append(runArgs, "--network", network)
+	}
+
 	// pass proxy environment variables to be used by node's docker daemon
-	proxyDetails, err := getProxyDetails()
+	proxyDetails, err := getProxyDetails(network)
 	if err != nil || proxyDetails == nil {
 		return nil, errors.Wrap(err, "proxy setup error")
 	}
@@ -201,8 +210,8 @@ func getSubnets(networkName string) ([]string, error) {
 }
 
 // getProxyDetails returns a struct with the host environment proxy settings
-// that should be passed to the nodes
-func getProxyDetails() (*proxyDetails, error) {
+// that should be passed to the nodes attached to the given network
+func getProxyDetails(network string) (*proxyDetails, error) {
 	var val string
 	details := proxyDetails{Envs: make(map[string]string)}
 	proxyEnvs := []string{httpProxy, httpsProxy, noProxy}
@@ -226,7 +235,10 @@ func getProxyDetails() (*proxyDetails, error) {
 
 	// Specifically add the docker network subnets to NO_PROXY if we are using proxies
 	if proxySupport {
-		subnets, err := getSubnets(defaultNetwork)
+		if network == "" {
+			network = defaultNetwork
+		}
+		subnets, err := getSubnets(network)
 		if err != nil {
 			return nil, err
 		}
@@ -40,8 +40,9 @@ type LoadBalancer struct {
 	lbCreator lbCreator
 }
 
-// NewLoadBalancer returns a new helper for managing a docker loadbalancer with a given name.
-func NewLoadBalancer(name string, logger logr.Logger) (*LoadBalancer, error) {
+// NewLoadBalancer returns a new helper for managing a docker loadbalancer with a given name,
+// attached to the given docker network or to the default one if empty.
+func NewLoadBalancer(name, network string, logger logr.Logger) (*LoadBalancer, error) {
 	if name == "" {
 		return nil, errors.New("name is required when creating a docker.LoadBalancer")
 	}
@@ -61,7 +62,7 @@ func NewLoadBalancer(name string, logger logr.Logger) (*LoadBalancer, error) {
 		name:      name,
 		container: container,
 		log:       logger,
-		lbCreator: &Manager{},
+		lbCreator: &Manager{Network: network},
 	}, nil
 }
 
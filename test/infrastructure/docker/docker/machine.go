@@ -59,8 +59,9 @@ type Machine struct {
 	nodeCreator nodeCreator
 }
 
-// NewMachine returns a new Machine service for the given Cluster/DockerCluster pair.
-func NewMachine(cluster, machine, image string, logger logr.Logger) (*Machine, error) {
+// NewMachine returns a new Machine service for the given Cluster/DockerCluster pair,
+// attached to the given docker network or to the default one if empty.
+func NewMachine(cluster, machine, image, network string, logger logr.Logger) (*Machine, error) {
 	if cluster == "" {
 		return nil, errors.New("cluster is required when creating a docker.Machine")
 	}
@@ -85,7 +86,7 @@ func NewMachine(cluster, machine, image string, logger logr.Logger) (*Machine, e
 		image:       image,
 		container:   container,
 		log:         logger,
-		nodeCreator: &Manager{},
+		nodeCreator: &Manager{Network: network},
 	}, nil
 }
 
@@ -215,10 +215,11 @@ func TestAggregate(t *testing.T) {
 	bar := FalseCondition("bar", "reason falseError1", clusterv1.ConditionSeverityError, "message falseError1") //NB. bar has higher priority than other conditions
 
 	tests := []struct {
-		name string
-		from []Getter
-		t    clusterv1.ConditionType
-		want *clusterv1.Condition
+		name    string
+		from    []Getter
+		t       clusterv1.ConditionType
+		options []MergeOption
+		want    *clusterv1.Condition
 	}{
 		{
 			name: "Returns nil when there are no conditions to aggregate",
@@ -237,13 +238,24 @@ func TestAggregate(t *testing.T) {
 			t:    "foo",
 			want: FalseCondition("foo", "reason falseInfo1", clusterv1.ConditionSeverityInfo, "2 of 5 completed"),
 		},
+		{
+			name: "Returns foo condition with a custom step counter message",
+			from: []Getter{
+				getterWithConditions(ready1),
+				getterWithConditions(ready1),
+				getterWithConditions(ready2),
+			},
+			t:       "foo",
+			options: []MergeOption{WithStepCounterMessage("%d of %d bars available")},
+			want:    FalseCondition("foo", "reason falseInfo1", clusterv1.ConditionSeverityInfo, "2 of 3 bars available"),
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			g := NewWithT(t)
 
-			got := aggregate(tt.from, tt.t)
+			got := aggregate(tt.from, tt.t, tt.options...)
 			if tt.want == nil {
 				g.Expect(got).To(BeNil())
 				return
@@ -31,6 +31,7 @@ type mergeOptions struct {
 	addStepCounter                     bool
 	addStepCounterIfOnlyConditionTypes []clusterv1.ConditionType
 	stepCounter                        int
+	stepCounterMessageFormat           string
 }
 
 // MergeOption defines an option for computing a summary of conditions.
@@ -58,6 +59,16 @@ func WithStepCounter() MergeOption {
 	}
 }
 
+// WithStepCounterMessage instructs merge to use the given format for the step counter message instead
+// of "x of y completed"; the format must contain two %d verbs, respectively for x and y.
+// E.g. WithStepCounterMessage("%d of %d MachineDeployments available").
+func WithStepCounterMessage(format string) MergeOption {
+	return func(c *mergeOptions) {
+		c.addStepCounter = true
+		c.stepCounterMessageFormat = format
+	}
+}
+
 // If it is required to add a step counter only if a subset of condition exists, check if the conditions
 // in scope are included in this subset. This applies for example on Machines, where we want to use
 // the step counter notation while provisioning the machine, but then we want to move away from this notation
@@ -109,20 +120,23 @@ func localizeReason(reason string, from Getter) string {
 // summary of existing errors is automatically added.
 func getMessage(groups conditionGroups, options *mergeOptions) string {
 	if options.addStepCounter {
-		return getStepCounterMessage(groups, options.stepCounter)
+		return getStepCounterMessage(groups, options.stepCounter, options.stepCounterMessageFormat)
 	}
 
 	return getFirstMessage(groups, options.conditionTypes)
 }
 
-// getStepCounterMessage returns a message "x of y completed", where x is the number of conditions
-// with Status=true and y is the number passed to this method.
-func getStepCounterMessage(groups conditionGroups, to int) string {
+// getStepCounterMessage returns a message "x of y completed", or formatted with the given format if any,
+// where x is the number of conditions with Status=true and y is the number passed to this method.
+func getStepCounterMessage(groups conditionGroups, to int, format string) string {
 	ct := 0
 	if trueGroup := groups.TrueGroup(); trueGroup != nil {
 		ct = len(trueGroup.conditions)
 	}
-	return fmt.Sprintf("%d of %d completed", ct, to)
+	if format == "" {
+		format = "%d of %d completed"
+	}
+	return fmt.Sprintf(format, ct, to)
 }
 
 // getFirstMessage returns the message from the ordered list of conditions in the top group.
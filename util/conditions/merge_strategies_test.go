@@ -36,10 +36,15 @@ func TestGetStepCounterMessage(t *testing.T) {
 		unknown1,
 	))
 
-	got := getStepCounterMessage(groups, 8)
+	got := getStepCounterMessage(groups, 8, "")
 
 	// step count message should report n° if true conditions over to number
 	g.Expect(got).To(Equal("2 of 8 completed"))
+
+	got = getStepCounterMessage(groups, 8, "%d of %d MachineDeployments available")
+
+	// step count message should use the given format
+	g.Expect(got).To(Equal("2 of 8 MachineDeployments available"))
 }
 
 func TestLocalizeReason(t *testing.T) {
@@ -19,6 +19,7 @@ package conditions
 import (
 	"fmt"
 	"sort"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -33,6 +34,49 @@ type Setter interface {
 	SetConditions(clusterv1.Conditions)
 }
 
+// ChangeHandler is a function invoked by NotifyChanges when a condition is added to an object, or when
+// the Status, Reason or Severity of an existing condition change; previous is nil for new conditions.
+type ChangeHandler func(obj Setter, previous, current *clusterv1.Condition)
+
+var (
+	changeHandlersLock sync.RWMutex
+	changeHandlers     []ChangeHandler
+)
+
+// AddChangeHandler registers a handler invoked for each condition whose state changed once the object
+// is persisted, e.g. for emitting an Event for each condition transition.
+func AddChangeHandler(handler ChangeHandler) {
+	changeHandlersLock.Lock()
+	defer changeHandlersLock.Unlock()
+
+	changeHandlers = append(changeHandlers, handler)
+}
+
+// NotifyChanges invokes the registered change handlers for each condition added to after, or whose Status,
+// Reason or Severity changed compared to before; changes of the message only, e.g. of a step counter, are
+// not considered a state change.
+//
+// NOTE: This func is expected to be called only after the conditions of after have been persisted, e.g. by
+// the patch helper, so handlers are not invoked for transitions which are never observed by users.
+func NotifyChanges(obj Setter, before, after Getter) {
+	changeHandlersLock.RLock()
+	defer changeHandlersLock.RUnlock()
+
+	if len(changeHandlers) == 0 {
+		return
+	}
+
+	for _, current := range after.GetConditions() {
+		previous := Get(before, current.Type)
+		if previous != nil && previous.Status == current.Status && previous.Reason == current.Reason && previous.Severity == current.Severity {
+			continue
+		}
+		for _, handler := range changeHandlers {
+			handler(obj, previous, current.DeepCopy())
+		}
+	}
+}
+
 // Set sets the given condition.
 //
 // NOTE: If a condition already exists, the LastTransitionTime is updated only if a change is detected
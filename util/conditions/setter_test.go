@@ -254,6 +254,43 @@ func TestSetAggregate(t *testing.T) {
 	g.Expect(Has(target, "foo")).To(BeTrue())
 }
 
+func TestNotifyChanges(t *testing.T) {
+	g := NewWithT(t)
+
+	defer func(handlers []ChangeHandler) { changeHandlers = handlers }(changeHandlers)
+	changeHandlers = nil
+
+	var notified []*clusterv1.Condition
+	AddChangeHandler(func(_ Setter, _, current *clusterv1.Condition) {
+		notified = append(notified, current)
+	})
+
+	before := setterWithConditions()
+	after := setterWithConditions()
+
+	// setting a condition does not notify until the changes are persisted
+	MarkFalse(after, "foo", "reason", clusterv1.ConditionSeverityInfo, "step %d of %d", 1, 2)
+	g.Expect(notified).To(BeEmpty())
+
+	// a new condition is notified
+	NotifyChanges(after, before, after)
+	g.Expect(notified).To(HaveLen(1))
+	g.Expect(notified[0].Reason).To(Equal("reason"))
+
+	// a change of the message only is not notified
+	before = after.DeepCopyObject().(Setter)
+	MarkFalse(after, "foo", "reason", clusterv1.ConditionSeverityInfo, "step %d of %d", 2, 2)
+	NotifyChanges(after, before, after)
+	g.Expect(notified).To(HaveLen(1))
+
+	// a change of the status is notified
+	before = after.DeepCopyObject().(Setter)
+	MarkTrue(after, "foo")
+	NotifyChanges(after, before, after)
+	g.Expect(notified).To(HaveLen(2))
+	g.Expect(notified[1].Status).To(Equal(corev1.ConditionTrue))
+}
+
 func setterWithConditions(conditions ...*clusterv1.Condition) Setter {
 	obj := &clusterv1.Cluster{}
 	obj.SetConditions(conditionList(conditions...))
@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package identity implements helpers for resolving the infrastructure provider identities referenced
+// by infrastructure clusters, and for checking if an infrastructure cluster is allowed to use them.
+package identity
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// ErrNamespaceNotAllowed is returned by Resolve when the namespace of the infrastructure cluster
+	// is not allowed to use the referenced identity.
+	ErrNamespaceNotAllowed = errors.New("namespace is not allowed to use the identity")
+)
+
+// Get uses the client and reference to get a cluster-scoped identity, as an unstructured object.
+func Get(ctx context.Context, c client.Client, ref *clusterv1.ClusterIdentityReference) (*unstructured.Unstructured, error) {
+	if ref == nil {
+		return nil, errors.New("identity reference is required")
+	}
+	if ref.APIVersion == "" || ref.Kind == "" || ref.Name == "" {
+		return nil, errors.Errorf("invalid identity reference %s/%s %q, apiVersion, kind and name are required", ref.APIVersion, ref.Kind, ref.Name)
+	}
+
+	obj := new(unstructured.Unstructured)
+	obj.SetAPIVersion(ref.APIVersion)
+	obj.SetKind(ref.Kind)
+	if err := c.Get(ctx, client.ObjectKey{Name: ref.Name}, obj); err != nil {
+		return nil, errors.Wrapf(err, "failed to retrieve %s identity %q", ref.Kind, ref.Name)
+	}
+	return obj, nil
+}
+
+// GetAllowedNamespaces returns the namespaces allowed to use an identity, as defined in the `spec.allowedNamespaces`
+// field of the identity; nil is returned if the field is not set.
+func GetAllowedNamespaces(identity *unstructured.Unstructured) (*clusterv1.AllowedNamespaces, error) {
+	allowedNamespaces := &clusterv1.AllowedNamespaces{}
+	if err := util.UnstructuredUnmarshalField(identity, allowedNamespaces, "spec", "allowedNamespaces"); err != nil {
+		if err == util.ErrUnstructuredFieldNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return allowedNamespaces, nil
+}
+
+// IsNamespaceAllowed returns true if the namespace is allowed by allowedNamespaces, either because it is included
+// in the list of namespaces or because its labels match the selector.
+//
+// A nil allowedNamespaces does not allow any namespace, while an empty allowedNamespaces allows all the namespaces.
+func IsNamespaceAllowed(ctx context.Context, c client.Client, allowedNamespaces *clusterv1.AllowedNamespaces, namespace string) (bool, error) {
+	if allowedNamespaces == nil {
+		return false, nil
+	}
+	if len(allowedNamespaces.NamespaceList) == 0 && allowedNamespaces.Selector == nil {
+		return true, nil
+	}
+
+	for _, n := range allowedNamespaces.NamespaceList {
+		if n == namespace {
+			return true, nil
+		}
+	}
+
+	if allowedNamespaces.Selector == nil {
+		return false, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(allowedNamespaces.Selector)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to parse the selector of the allowed namespaces")
+	}
+
+	ns := &corev1.Namespace{}
+	if err := c.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		return false, errors.Wrapf(err, "failed to retrieve namespace %q", namespace)
+	}
+	return selector.Matches(labels.Set(ns.GetLabels())), nil
+}
+
+// Resolve gets the identity referenced by an infrastructure cluster, and checks that the namespace of the
+// infrastructure cluster is allowed to use it; if not, an error wrapping ErrNamespaceNotAllowed is returned.
+func Resolve(ctx context.Context, c client.Client, ref *clusterv1.ClusterIdentityReference, namespace string) (*unstructured.Unstructured, error) {
+	identity, err := Get(ctx, c, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	allowedNamespaces, err := GetAllowedNamespaces(identity)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := IsNamespaceAllowed(ctx, c, allowedNamespaces, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errors.Wrapf(ErrNamespaceNotAllowed, "failed to use %s identity %q in namespace %q", ref.Kind, ref.Name, namespace)
+	}
+	return identity, nil
+}
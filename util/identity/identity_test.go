@@ -0,0 +1,238 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package identity
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+)
+
+var (
+	ctx = context.Background()
+
+	tenantNamespace = &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "tenant-a",
+			Labels: map[string]string{"tenant": "a"},
+		},
+	}
+	otherNamespace = &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "tenant-b",
+		},
+	}
+)
+
+func newIdentity(name string, allowedNamespaces interface{}) *unstructured.Unstructured {
+	identity := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha3",
+			"kind":       "GenericClusterIdentity",
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+			"spec": map[string]interface{}{},
+		},
+	}
+	if allowedNamespaces != nil {
+		identity.Object["spec"].(map[string]interface{})["allowedNamespaces"] = allowedNamespaces
+	}
+	return identity
+}
+
+func newIdentityRef(name string) *clusterv1.ClusterIdentityReference {
+	return &clusterv1.ClusterIdentityReference{
+		APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha3",
+		Kind:       "GenericClusterIdentity",
+		Name:       name,
+	}
+}
+
+func TestIsNamespaceAllowed(t *testing.T) {
+	tests := []struct {
+		name              string
+		allowedNamespaces *clusterv1.AllowedNamespaces
+		namespace         string
+		want              bool
+		wantErr           bool
+	}{
+		{
+			name:              "nil allowed namespaces do not allow any namespace",
+			allowedNamespaces: nil,
+			namespace:         "tenant-a",
+			want:              false,
+		},
+		{
+			name:              "empty allowed namespaces allow all the namespaces",
+			allowedNamespaces: &clusterv1.AllowedNamespaces{},
+			namespace:         "tenant-b",
+			want:              true,
+		},
+		{
+			name: "namespace in the list",
+			allowedNamespaces: &clusterv1.AllowedNamespaces{
+				NamespaceList: []string{"tenant-a"},
+			},
+			namespace: "tenant-a",
+			want:      true,
+		},
+		{
+			name: "namespace not in the list",
+			allowedNamespaces: &clusterv1.AllowedNamespaces{
+				NamespaceList: []string{"tenant-a"},
+			},
+			namespace: "tenant-b",
+			want:      false,
+		},
+		{
+			name: "namespace matching the selector",
+			allowedNamespaces: &clusterv1.AllowedNamespaces{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}},
+			},
+			namespace: "tenant-a",
+			want:      true,
+		},
+		{
+			name: "namespace not matching the selector",
+			allowedNamespaces: &clusterv1.AllowedNamespaces{
+				NamespaceList: []string{"tenant-c"},
+				Selector:      &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}},
+			},
+			namespace: "tenant-b",
+			want:      false,
+		},
+		{
+			name: "fails if the namespace does not exist",
+			allowedNamespaces: &clusterv1.AllowedNamespaces{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}},
+			},
+			namespace: "does-not-exist",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c := fake.NewFakeClientWithScheme(setupScheme(), tenantNamespace.DeepCopy(), otherNamespace.DeepCopy())
+
+			got, err := IsNamespaceAllowed(ctx, c, tt.allowedNamespaces, tt.namespace)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name             string
+		identity         *unstructured.Unstructured
+		ref              *clusterv1.ClusterIdentityReference
+		namespace        string
+		wantErr          bool
+		wantNotFound     bool
+		wantNotAllowed   bool
+		wantIdentityName string
+	}{
+		{
+			name:             "identity allowing the namespace",
+			identity:         newIdentity("allowed", map[string]interface{}{"list": []interface{}{"tenant-a"}}),
+			ref:              newIdentityRef("allowed"),
+			namespace:        "tenant-a",
+			wantIdentityName: "allowed",
+		},
+		{
+			name:             "identity allowing all the namespaces",
+			identity:         newIdentity("all", map[string]interface{}{}),
+			ref:              newIdentityRef("all"),
+			namespace:        "tenant-b",
+			wantIdentityName: "all",
+		},
+		{
+			name:           "identity without allowed namespaces",
+			identity:       newIdentity("none", nil),
+			ref:            newIdentityRef("none"),
+			namespace:      "tenant-a",
+			wantErr:        true,
+			wantNotAllowed: true,
+		},
+		{
+			name:           "identity not allowing the namespace",
+			identity:       newIdentity("selector", map[string]interface{}{"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"tenant": "a"}}}),
+			ref:            newIdentityRef("selector"),
+			namespace:      "tenant-b",
+			wantErr:        true,
+			wantNotAllowed: true,
+		},
+		{
+			name:         "identity does not exist",
+			identity:     newIdentity("allowed", map[string]interface{}{}),
+			ref:          newIdentityRef("does-not-exist"),
+			namespace:    "tenant-a",
+			wantErr:      true,
+			wantNotFound: true,
+		},
+		{
+			name:      "invalid reference",
+			identity:  newIdentity("allowed", map[string]interface{}{}),
+			ref:       &clusterv1.ClusterIdentityReference{Name: "allowed"},
+			namespace: "tenant-a",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c := fake.NewFakeClientWithScheme(setupScheme(), tenantNamespace.DeepCopy(), otherNamespace.DeepCopy(), tt.identity)
+
+			got, err := Resolve(ctx, c, tt.ref, tt.namespace)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(errors.Cause(err) == ErrNamespaceNotAllowed).To(Equal(tt.wantNotAllowed))
+				g.Expect(apierrors.IsNotFound(errors.Cause(err))).To(Equal(tt.wantNotFound))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got.GetName()).To(Equal(tt.wantIdentityName))
+		})
+	}
+}
+
+func setupScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		panic(err)
+	}
+	return scheme
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package kubeconfig
 
 import (
+	"bytes"
 	"context"
 	"crypto/rsa"
 	"crypto/x509"
@@ -33,6 +34,7 @@ import (
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/certs"
 	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/cluster-api/util/signer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -49,32 +51,74 @@ func FromSecret(ctx context.Context, c client.Reader, cluster client.ObjectKey)
 	return toKubeconfigBytes(out)
 }
 
+// ControllerFromSecret fetches the Kubeconfig of the ServiceAccount used by the Cluster API controllers in a Cluster.
+func ControllerFromSecret(ctx context.Context, c client.Reader, cluster client.ObjectKey) ([]byte, error) {
+	out, err := secret.Get(ctx, c, cluster, secret.ControllerKubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	return toKubeconfigBytes(out)
+}
+
 // New creates a new Kubeconfig using the cluster name and specified endpoint.
 func New(clusterName, endpoint string, caCert *x509.Certificate, caKey *rsa.PrivateKey) (*api.Config, error) {
-	cfg := &certs.Config{
-		CommonName:   "kubernetes-admin",
-		Organization: []string{"system:masters"},
-		Usages:       []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	return NewWithSigner(context.Background(), clusterName, endpoint, adminUserName(clusterName), adminCertConfig(), signer.NewKeyPairSigner(caCert, caKey), certs.EncodeCertPEM(caCert))
+}
+
+// NewForUser creates a new Kubeconfig using the cluster name and specified endpoint, authenticating
+// as the given user and groups with a client certificate valid for the given duration.
+func NewForUser(clusterName, endpoint, userName string, groups []string, duration time.Duration, caCert *x509.Certificate, caKey *rsa.PrivateKey) (*api.Config, error) {
+	return NewWithSigner(context.Background(), clusterName, endpoint, userName, userCertConfig(userName, groups, duration), signer.NewKeyPairSigner(caCert, caKey), certs.EncodeCertPEM(caCert))
+}
+
+// NewForToken creates a new Kubeconfig using the cluster name and specified endpoint, authenticating as the
+// given user with a bearer token, e.g. the token of a ServiceAccount.
+func NewForToken(clusterName, endpoint, userName, token string, caData []byte) *api.Config {
+	contextName := fmt.Sprintf("%s@%s", userName, clusterName)
+
+	return &api.Config{
+		Clusters: map[string]*api.Cluster{
+			clusterName: {
+				Server:                   endpoint,
+				CertificateAuthorityData: caData,
+			},
+		},
+		Contexts: map[string]*api.Context{
+			contextName: {
+				Cluster:  clusterName,
+				AuthInfo: userName,
+			},
+		},
+		AuthInfos: map[string]*api.AuthInfo{
+			userName: {
+				Token: token,
+			},
+		},
+		CurrentContext: contextName,
 	}
+}
 
+// NewWithSigner creates a new Kubeconfig using the cluster name and specified endpoint, authenticating as the given
+// user with a client certificate described by the given config and issued by the given Signer, and trusting the
+// given PEM encoded CA certificates.
+func NewWithSigner(ctx context.Context, clusterName, endpoint, userName string, cfg *certs.Config, s signer.Signer, caData []byte) (*api.Config, error) {
 	clientKey, err := certs.NewPrivateKey()
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to create private key")
 	}
 
-	clientCert, err := cfg.NewSignedCert(clientKey, caCert, caKey)
+	clientCert, err := s.Sign(ctx, clientKey, cfg)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to sign certificate")
 	}
 
-	userName := fmt.Sprintf("%s-admin", clusterName)
 	contextName := fmt.Sprintf("%s@%s", userName, clusterName)
 
 	return &api.Config{
 		Clusters: map[string]*api.Cluster{
 			clusterName: {
 				Server:                   endpoint,
-				CertificateAuthorityData: certs.EncodeCertPEM(caCert),
+				CertificateAuthorityData: caData,
 			},
 		},
 		Contexts: map[string]*api.Context{
@@ -172,6 +216,28 @@ func NeedsClientCertRotation(configSecret *corev1.Secret, threshold time.Duratio
 	return false, nil
 }
 
+// NeedsCertificateAuthoritiesUpdate returns whether any of the clusters of the Kubeconfig secret doesn't trust the
+// given PEM encoded CA certificates, e.g. after the certificate authorities of the cluster were rotated.
+func NeedsCertificateAuthoritiesUpdate(configSecret *corev1.Secret, caData []byte) (bool, error) {
+	data, err := toKubeconfigBytes(configSecret)
+	if err != nil {
+		return false, err
+	}
+
+	config, err := clientcmd.Load(data)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to convert kubeconfig Secret into a clientcmdapi.Config")
+	}
+
+	for _, cluster := range config.Clusters {
+		if !bytes.Equal(cluster.CertificateAuthorityData, caData) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 // RegenerateSecret creates and stores a new Kubeconfig in the given secret.
 func RegenerateSecret(ctx context.Context, c client.Client, configSecret *corev1.Secret) error {
 	clusterName, _, err := secret.ParseSecretName(configSecret.Name)
@@ -197,30 +263,33 @@ func RegenerateSecret(ctx context.Context, c client.Client, configSecret *corev1
 	return c.Update(ctx, configSecret)
 }
 
-func generateKubeconfig(ctx context.Context, c client.Client, clusterName client.ObjectKey, endpoint string) ([]byte, error) {
-	clusterCA, err := secret.GetFromNamespacedName(ctx, c, clusterName, secret.ClusterCA)
+// GenerateForUser returns the serialized Kubeconfig of the given user and groups for the given cluster,
+// with a client certificate signed by the cluster CA and valid for the given duration.
+func GenerateForUser(ctx context.Context, c client.Reader, clusterName client.ObjectKey, endpoint, userName string, groups []string, duration time.Duration) ([]byte, error) {
+	s, caData, err := getClusterSigner(ctx, c, clusterName)
 	if err != nil {
-		if apierrors.IsNotFound(err) {
-			return nil, ErrDependentCertificateNotFound
-		}
 		return nil, err
 	}
 
-	cert, err := certs.DecodeCertPEM(clusterCA.Data[secret.TLSCrtDataName])
+	cfg, err := NewWithSigner(ctx, clusterName.Name, endpoint, userName, userCertConfig(userName, groups, duration), s, caData)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to decode CA Cert")
-	} else if cert == nil {
-		return nil, errors.New("certificate not found in config")
+		return nil, errors.Wrap(err, "failed to generate a kubeconfig")
 	}
 
-	key, err := certs.DecodePrivateKeyPEM(clusterCA.Data[secret.TLSKeyDataName])
+	out, err := clientcmd.Write(*cfg)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to decode private key")
-	} else if key == nil {
-		return nil, errors.New("CA private key not found")
+		return nil, errors.Wrap(err, "failed to serialize config to yaml")
 	}
+	return out, nil
+}
 
-	cfg, err := New(clusterName.Name, endpoint, cert, key)
+func generateKubeconfig(ctx context.Context, c client.Client, clusterName client.ObjectKey, endpoint string) ([]byte, error) {
+	s, caData, err := getClusterSigner(ctx, c, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := NewWithSigner(ctx, clusterName.Name, endpoint, adminUserName(clusterName.Name), adminCertConfig(), s, caData)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to generate a kubeconfig")
 	}
@@ -232,6 +301,48 @@ func generateKubeconfig(ctx context.Context, c client.Client, clusterName client
 	return out, nil
 }
 
+// adminUserName returns the name of the admin user in the kubeconfig of the given cluster.
+func adminUserName(clusterName string) string {
+	return fmt.Sprintf("%s-admin", clusterName)
+}
+
+// adminCertConfig returns the config of the client certificate of the admin kubeconfig.
+func adminCertConfig() *certs.Config {
+	return &certs.Config{
+		CommonName:   "kubernetes-admin",
+		Organization: []string{"system:masters"},
+		Usages:       []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+}
+
+// userCertConfig returns the config of a client certificate of the given user and groups, valid for the given duration.
+func userCertConfig(userName string, groups []string, duration time.Duration) *certs.Config {
+	return &certs.Config{
+		CommonName:   userName,
+		Organization: groups,
+		Usages:       []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		Duration:     duration,
+	}
+}
+
+// getClusterSigner returns the Signer of the cluster CA, and the CA certificates to be trusted by the clients, which
+// include the previous cluster CA while it is being rotated.
+func getClusterSigner(ctx context.Context, c client.Reader, clusterName client.ObjectKey) (signer.Signer, []byte, error) {
+	clusterCA, err := secret.GetFromNamespacedName(ctx, c, clusterName, secret.ClusterCA)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil, ErrDependentCertificateNotFound
+		}
+		return nil, nil, err
+	}
+
+	s, err := signer.FromSecret(clusterCA)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, clusterCA.Data[secret.TLSCrtDataName], nil
+}
+
 func toKubeconfigBytes(out *corev1.Secret) ([]byte, error) {
 	data, ok := out.Data[secret.KubeconfigDataName]
 	if !ok {
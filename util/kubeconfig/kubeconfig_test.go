@@ -40,6 +40,7 @@ import (
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/certs"
 	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/cluster-api/util/signer"
 )
 
 var (
@@ -183,6 +184,68 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNewForUser(t *testing.T) {
+	g := NewWithT(t)
+
+	caKey, err := certs.NewPrivateKey()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	caCert, err := getTestCACert(caKey)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	config, err := NewForUser("foo", "https://127.0.0.1:4003", "jane", []string{"developers", "oncall"}, time.Hour, caCert, caKey)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(config.CurrentContext).To(Equal("jane@foo"))
+	g.Expect(config.Contexts).To(Equal(map[string]*api.Context{
+		"jane@foo": {
+			Cluster:  "foo",
+			AuthInfo: "jane",
+		},
+	}))
+
+	clientCert, err := certs.DecodeCertPEM(config.AuthInfos["jane"].ClientCertificateData)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(clientCert.Subject.CommonName).To(Equal("jane"))
+	g.Expect(clientCert.Subject.Organization).To(ConsistOf("developers", "oncall"))
+	g.Expect(clientCert.NotAfter).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+}
+
+type recordingSigner struct {
+	signer.Signer
+	configs []*certs.Config
+}
+
+func (s *recordingSigner) Sign(ctx context.Context, key *rsa.PrivateKey, cfg *certs.Config) (*x509.Certificate, error) {
+	s.configs = append(s.configs, cfg)
+	return s.Signer.Sign(ctx, key, cfg)
+}
+
+func TestNewWithSigner(t *testing.T) {
+	g := NewWithT(t)
+
+	caKey, err := certs.NewPrivateKey()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	caCert, err := getTestCACert(caKey)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	s := &recordingSigner{Signer: signer.NewKeyPairSigner(caCert, caKey)}
+	cfg := &certs.Config{
+		CommonName: "system:kube-scheduler",
+		Usages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	config, err := NewWithSigner(context.Background(), "foo", "https://127.0.0.1:4003", "scheduler", cfg, s, []byte("ca-data"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(s.configs).To(ConsistOf(cfg))
+	g.Expect(config.CurrentContext).To(Equal("scheduler@foo"))
+	g.Expect(config.Clusters["foo"].CertificateAuthorityData).To(Equal([]byte("ca-data")))
+
+	clientCert, err := certs.DecodeCertPEM(config.AuthInfos["scheduler"].ClientCertificateData)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(clientCert.Subject.CommonName).To(Equal("system:kube-scheduler"))
+	g.Expect(clientCert.CheckSignatureFrom(caCert)).To(Succeed())
+}
+
 func TestGenerateSecretWithOwner(t *testing.T) {
 	g := NewWithT(t)
 
@@ -384,6 +447,38 @@ func TestNeedsClientCertRotation(t *testing.T) {
 	g.Expect(NeedsClientCertRotation(kubeconfigSecret, certs.DefaultCertDuration-time.Hour)).To(BeFalse())
 }
 
+func TestNeedsCertificateAuthoritiesUpdate(t *testing.T) {
+	g := NewWithT(t)
+	caKey, err := certs.NewPrivateKey()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	caCert, err := getTestCACert(caKey)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	config, err := New("foo", "https://127:0.0.1:4003", caCert, caKey)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	out, err := clientcmd.Write(*config)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	kubeconfigSecret := GenerateSecretWithOwner(
+		client.ObjectKey{
+			Name:      "test1",
+			Namespace: "test",
+		},
+		out,
+		metav1.OwnerReference{},
+	)
+
+	g.Expect(NeedsCertificateAuthoritiesUpdate(kubeconfigSecret, certs.EncodeCertPEM(caCert))).To(BeFalse())
+
+	newCAKey, err := certs.NewPrivateKey()
+	g.Expect(err).NotTo(HaveOccurred())
+	newCACert, err := getTestCACert(newCAKey)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(NeedsCertificateAuthoritiesUpdate(kubeconfigSecret, append(certs.EncodeCertPEM(caCert), certs.EncodeCertPEM(newCACert)...))).To(BeTrue())
+}
+
 func TestRegenerateClientCerts(t *testing.T) {
 	g := NewWithT(t)
 	caKey, err := certs.NewPrivateKey()
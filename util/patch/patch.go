@@ -19,16 +19,19 @@ package patch
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"reflect"
 	"time"
 
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/tracing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -80,7 +83,10 @@ func NewHelper(obj runtime.Object, crClient client.Client, opts ...Option) (*Hel
 }
 
 // Patch will attempt to patch the given object, including its status.
-func (h *Helper) Patch(ctx context.Context, obj runtime.Object) error {
+func (h *Helper) Patch(ctx context.Context, obj runtime.Object) (reterr error) {
+	ctx, span := tracing.StartSpan(ctx, "patch.Helper.Patch", attribute.String("type", fmt.Sprintf("%T", obj)))
+	defer func() { tracing.EndSpan(span, reterr) }()
+
 	if obj == nil {
 		return errors.Errorf("expected non-nil object")
 	}
@@ -114,11 +120,34 @@ func (h *Helper) Patch(ctx context.Context, obj runtime.Object) error {
 	}
 
 	// Issue patches and return errors in an aggregate.
-	return kerrors.NewAggregate([]error{
+	if err := kerrors.NewAggregate([]error{
 		h.patch(ctx, obj),
 		h.patchStatus(ctx, obj),
 		h.patchStatusConditions(ctx, obj),
-	})
+	}); err != nil {
+		return err
+	}
+
+	// Notify condition changes only once they have been persisted.
+	h.notifyConditionChanges(obj)
+	return nil
+}
+
+// notifyConditionChanges invokes the condition change handlers for the conditions whose state changed
+// between the before object and the object being patched.
+func (h *Helper) notifyConditionChanges(obj runtime.Object) {
+	if !h.isConditionsSetter || !h.shouldPatch("status") {
+		return
+	}
+	before, ok := h.beforeObject.(conditions.Getter)
+	if !ok {
+		return
+	}
+	after, ok := obj.(conditions.Setter)
+	if !ok {
+		return
+	}
+	conditions.NotifyChanges(after, before, after)
 }
 
 // patch issues a patch for metadata and spec.
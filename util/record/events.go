@@ -0,0 +1,175 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package record
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+// EventDefinition defines an Event emitted by the Cluster API controllers.
+type EventDefinition struct {
+	// Reason is the machine readable reason of the Event; reasons use the Failed<Action> and
+	// Successful<Action> form, or the reason of the Condition the Event is related to, if any.
+	Reason string
+
+	// Type is the severity of the Event, either corev1.EventTypeNormal or corev1.EventTypeWarning.
+	Type string
+
+	// Message is the template of the human readable message of the Event, expanded with fmt.Sprintf.
+	Message string
+}
+
+// Emit records an Event for the given object, expanding the message template with the given arguments.
+func (e EventDefinition) Emit(recorder record.EventRecorder, object runtime.Object, args ...interface{}) {
+	recorder.Eventf(object, e.Type, e.Reason, e.Message, args...)
+}
+
+// Events common to all the controllers.
+var (
+	// ReconcileError is emitted when the reconciliation of an object fails.
+	ReconcileError = EventDefinition{"ReconcileError", corev1.EventTypeWarning, "%v"}
+
+	// FailedAdopt is emitted when an object can't be adopted by its owner; args: kind, name, error.
+	FailedAdopt = EventDefinition{"FailedAdopt", corev1.EventTypeWarning, "Failed to adopt %s %q: %v"}
+
+	// SuccessfulAdopt is emitted when an object is adopted by its owner; args: kind, name.
+	SuccessfulAdopt = EventDefinition{"SuccessfulAdopt", corev1.EventTypeNormal, "Adopted %s %q"}
+
+	// FailedCreate is emitted when an owned object can't be created; args: kind, name, error.
+	FailedCreate = EventDefinition{"FailedCreate", corev1.EventTypeWarning, "Failed to create %s %q: %v"}
+
+	// SuccessfulCreate is emitted when an owned object is created; args: kind, name.
+	SuccessfulCreate = EventDefinition{"SuccessfulCreate", corev1.EventTypeNormal, "Created %s %q"}
+
+	// FailedDelete is emitted when an owned object can't be deleted; args: kind, name, error.
+	FailedDelete = EventDefinition{"FailedDelete", corev1.EventTypeWarning, "Failed to delete %s %q: %v"}
+
+	// SuccessfulDelete is emitted when an owned object is deleted; args: kind, name.
+	SuccessfulDelete = EventDefinition{"SuccessfulDelete", corev1.EventTypeNormal, "Deleted %s %q"}
+
+	// VersionSkewNotSupported is emitted when the version of an object is newer than the one of the control plane;
+	// args: version, control plane version.
+	VersionSkewNotSupported = EventDefinition{clusterv1.VersionSkewNotSupportedReason, corev1.EventTypeWarning, "Version %s is newer than the control plane version v%s"}
+)
+
+// Events emitted by the Cluster controller.
+var (
+	// UpgradingControlPlane is emitted when the upgrade of the control plane to the Cluster's target version starts;
+	// args: control plane kind, control plane name, current version, target version.
+	UpgradingControlPlane = EventDefinition{"UpgradingControlPlane", corev1.EventTypeNormal, "Upgrading %s %s from %s to %s"}
+
+	// UpgradingMachineDeployment is emitted when the upgrade of a MachineDeployment to the Cluster's target version starts;
+	// args: MachineDeployment name, target version.
+	UpgradingMachineDeployment = EventDefinition{"UpgradingMachineDeployment", corev1.EventTypeNormal, "Upgrading MachineDeployment %s to %s"}
+
+	// UpgradingMachinePool is emitted when the upgrade of a MachinePool to the Cluster's target version starts;
+	// args: MachinePool name, target version.
+	UpgradingMachinePool = EventDefinition{"UpgradingMachinePool", corev1.EventTypeNormal, "Upgrading MachinePool %s to %s"}
+)
+
+// Events emitted by the Machine and MachinePool controllers.
+var (
+	// FailedDrainNode is emitted when the Node of a Machine being deleted can't be drained; args: node name, error.
+	FailedDrainNode = EventDefinition{"FailedDrainNode", corev1.EventTypeWarning, "Error draining Machine's node %q: %v"}
+
+	// SuccessfulDrainNode is emitted when the Node of a Machine being deleted is drained; args: node name.
+	SuccessfulDrainNode = EventDefinition{"SuccessfulDrainNode", corev1.EventTypeNormal, "Success draining Machine's node %q"}
+
+	// FailedDeleteNode is emitted when the Node of a deleted Machine can't be deleted; args: error.
+	FailedDeleteNode = EventDefinition{"FailedDeleteNode", corev1.EventTypeWarning, "Error deleting Machine's node: %v"}
+
+	// FailedSetNodeRef is emitted when the NodeRef can't be set; args: error.
+	FailedSetNodeRef = EventDefinition{"FailedSetNodeRef", corev1.EventTypeWarning, "%v"}
+
+	// SuccessfulSetNodeRef is emitted when the NodeRef is set; args: node name(s).
+	SuccessfulSetNodeRef = EventDefinition{"SuccessfulSetNodeRef", corev1.EventTypeNormal, "%v"}
+)
+
+// Events emitted by the MachineDeployment controller.
+var (
+	// FailedScale is emitted when a MachineSet can't be scaled; args: MachineSet name, error.
+	FailedScale = EventDefinition{"FailedScale", corev1.EventTypeWarning, "Failed to scale MachineSet %q: %v"}
+
+	// SuccessfulScale is emitted when a MachineSet is scaled; args: "up" or "down", MachineSet name, replicas.
+	SuccessfulScale = EventDefinition{"SuccessfulScale", corev1.EventTypeNormal, "Scaled %s MachineSet %q to %d"}
+)
+
+// Events emitted by the MachineHealthCheck controller.
+var (
+	// RemediationRestricted is emitted when remediation is not allowed because too many targets are unhealthy;
+	// args: total targets, healthy targets, max unhealthy.
+	RemediationRestricted = EventDefinition{"RemediationRestricted", corev1.EventTypeWarning, "Remediation restricted due to exceeded number of unhealthy machines (total: %v, healthy: %v, maxUnhealthy: %v)"}
+
+	// MachineMarkedUnhealthy is emitted when a Machine is marked for remediation; args: target.
+	MachineMarkedUnhealthy = EventDefinition{"MachineMarkedUnhealthy", corev1.EventTypeNormal, "Machine %v has been marked as unhealthy"}
+
+	// DetectedUnhealthy is emitted when a Machine has an unhealthy Node not yet exceeding the timeout; args: target, node name.
+	DetectedUnhealthy = EventDefinition{"DetectedUnhealthy", corev1.EventTypeNormal, "Machine %v has unhealthy node %v"}
+)
+
+// Events emitted by the KubeadmControlPlane controllers.
+var (
+	// ControlPlaneUnhealthy is emitted when a control plane operation is blocked by a failed health check;
+	// args: health check ("control plane" or "etcd"), blocked operation, error.
+	ControlPlaneUnhealthy = EventDefinition{"ControlPlaneUnhealthy", corev1.EventTypeWarning, "Waiting for control plane to pass %s health check before %s: %v"}
+
+	// FailedInitialization is emitted when the first control plane Machine can't be created; args: cluster namespace, cluster name, error.
+	FailedInitialization = EventDefinition{"FailedInitialization", corev1.EventTypeWarning, "Failed to create initial control plane Machine for cluster %s/%s control plane: %v"}
+
+	// FailedScaleUp is emitted when a control plane Machine can't be added; args: cluster namespace, cluster name, error.
+	FailedScaleUp = EventDefinition{"FailedScaleUp", corev1.EventTypeWarning, "Failed to create additional control plane Machine for cluster %s/%s control plane: %v"}
+
+	// FailedScaleDown is emitted when a control plane Machine can't be removed; args: Machine name, cluster namespace, cluster name, error.
+	FailedScaleDown = EventDefinition{"FailedScaleDown", corev1.EventTypeWarning, "Failed to delete control plane Machine %s for cluster %s/%s control plane: %v"}
+
+	// FailedSnapshot is emitted when an etcd snapshot can't be taken; args: cluster namespace, cluster name, error.
+	FailedSnapshot = EventDefinition{"FailedSnapshot", corev1.EventTypeWarning, "Failed to take etcd snapshot for cluster %s/%s: %v"}
+
+	// SuccessfulSnapshot is emitted when an etcd snapshot is taken; args: cluster namespace, cluster name, size in bytes.
+	SuccessfulSnapshot = EventDefinition{"SuccessfulSnapshot", corev1.EventTypeNormal, "Took etcd snapshot for cluster %s/%s (%d bytes)"}
+)
+
+// ConditionChangeHandler returns a conditions.ChangeHandler emitting an Event for each condition transition.
+// The Event reason is the reason of the condition, or the condition type if the condition has no reason, so
+// Events can be correlated with the condition they represent; the Event is a warning only if the condition
+// is False with severity Warning or Error.
+func ConditionChangeHandler(recorder record.EventRecorder) conditions.ChangeHandler {
+	return func(obj conditions.Setter, _, current *clusterv1.Condition) {
+		reason := current.Reason
+		if reason == "" {
+			reason = string(current.Type)
+		}
+
+		eventType := corev1.EventTypeNormal
+		if current.Status == corev1.ConditionFalse &&
+			(current.Severity == clusterv1.ConditionSeverityWarning || current.Severity == clusterv1.ConditionSeverityError) {
+			eventType = corev1.EventTypeWarning
+		}
+
+		message := fmt.Sprintf("Condition %s is %s", current.Type, current.Status)
+		if current.Message != "" {
+			message = fmt.Sprintf("%s: %s", message, current.Message)
+		}
+		recorder.Event(obj, eventType, reason, message)
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package record
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func TestEventDefinitionEmit(t *testing.T) {
+	g := NewWithT(t)
+
+	recorder := record.NewFakeRecorder(1)
+	FailedCreate.Emit(recorder, &clusterv1.MachineSet{}, "Machine", "foo", errors.New("boom"))
+
+	g.Expect(recorder.Events).To(Receive(Equal(`Warning FailedCreate Failed to create Machine "foo": boom`)))
+}
+
+func TestConditionChangeHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition *clusterv1.Condition
+		want      string
+	}{
+		{
+			name:      "True condition",
+			condition: conditions.TrueCondition(clusterv1.ReadyCondition),
+			want:      "Normal Ready Condition Ready is True",
+		},
+		{
+			name:      "False condition with info severity",
+			condition: conditions.FalseCondition(clusterv1.ReadyCondition, "Provisioning", clusterv1.ConditionSeverityInfo, "1 of 2 completed"),
+			want:      "Normal Provisioning Condition Ready is False: 1 of 2 completed",
+		},
+		{
+			name:      "False condition with error severity",
+			condition: conditions.FalseCondition(clusterv1.ReadyCondition, "Failed", clusterv1.ConditionSeverityError, "boom"),
+			want:      "Warning Failed Condition Ready is False: boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			recorder := record.NewFakeRecorder(1)
+			ConditionChangeHandler(recorder)(&clusterv1.Cluster{}, nil, tt.condition)
+
+			g.Expect(recorder.Events).To(Receive(Equal(tt.want)))
+		})
+	}
+}
+
+func TestRateLimitedRecorder(t *testing.T) {
+	g := NewWithT(t)
+
+	fake := record.NewFakeRecorder(10)
+	recorder := NewRateLimitedRecorder(fake, time.Minute)
+
+	foo := &clusterv1.Machine{}
+	foo.Name = "foo"
+	bar := &clusterv1.Machine{}
+	bar.Name = "bar"
+
+	recorder.Eventf(foo, "Normal", "Reason", "message %d", 1)
+	recorder.Eventf(foo, "Normal", "Reason", "message %d", 1)
+	g.Expect(fake.Events).To(HaveLen(1))
+
+	// a different message, reason or object is not a duplicate
+	recorder.Eventf(foo, "Normal", "Reason", "message %d", 2)
+	recorder.Event(foo, "Normal", "AnotherReason", "message 2")
+	recorder.Event(bar, "Normal", "Reason", "message 2")
+	g.Expect(fake.Events).To(HaveLen(4))
+
+	// duplicates are emitted again after the interval expired
+	recorder = NewRateLimitedRecorder(fake, time.Millisecond)
+	recorder.Event(foo, "Normal", "Reason", "message")
+	time.Sleep(10 * time.Millisecond)
+	recorder.Event(foo, "Normal", "Reason", "message")
+	g.Expect(fake.Events).To(HaveLen(6))
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package record
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// DefaultDuplicateEventsInterval is the interval during which an Event identical to one already
+	// emitted for the same object is dropped.
+	DefaultDuplicateEventsInterval = 10 * time.Minute
+
+	// duplicateEventsCacheSize is the maximum number of recent events tracked for detecting duplicates.
+	duplicateEventsCacheSize = 4096
+)
+
+// NewRateLimitedRecorder returns an EventRecorder dropping the Events identical (same object, type, reason
+// and message) to one emitted within the given interval, so the same Event is not emitted on every requeue.
+func NewRateLimitedRecorder(recorder record.EventRecorder, interval time.Duration) record.EventRecorder {
+	return &rateLimitedRecorder{
+		EventRecorder: recorder,
+		interval:      interval,
+		recent:        cache.NewLRUExpireCache(duplicateEventsCacheSize),
+	}
+}
+
+type rateLimitedRecorder struct {
+	record.EventRecorder
+	interval time.Duration
+	recent   *cache.LRUExpireCache
+}
+
+func (r *rateLimitedRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	if r.isDuplicate(object, eventtype, reason, message) {
+		return
+	}
+	r.EventRecorder.Event(object, eventtype, reason, message)
+}
+
+func (r *rateLimitedRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *rateLimitedRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	if r.isDuplicate(object, eventtype, reason, fmt.Sprintf(messageFmt, args...)) {
+		return
+	}
+	r.EventRecorder.AnnotatedEventf(object, annotations, eventtype, reason, messageFmt, args...)
+}
+
+// isDuplicate returns true if the same event was emitted for the object within the interval,
+// otherwise it records the event as recently emitted.
+func (r *rateLimitedRecorder) isDuplicate(object runtime.Object, eventtype, reason, message string) bool {
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return false
+	}
+
+	key := fmt.Sprintf("%s/%s/%s/%s/%s", accessor.GetUID(), accessor.GetNamespace(), accessor.GetName(), eventtype, reason)
+	if previous, ok := r.recent.Get(key); ok && previous.(string) == message {
+		return true
+	}
+	r.recent.Add(key, message, r.interval)
+	return false
+}
@@ -186,6 +186,7 @@ func (c Certificates) Lookup(ctx context.Context, ctrlclient client.Client, clus
 			return err
 		}
 		certificate.KeyPair = kp
+		certificate.ExternalSigner = len(kp.Key) == 0 && s.GetAnnotations()[ExternalSignerURLAnnotation] != ""
 	}
 	return nil
 }
@@ -199,7 +200,7 @@ func (c Certificates) EnsureAllExist() error {
 		if len(certificate.KeyPair.Cert) == 0 {
 			return errors.Wrapf(ErrMissingCrt, "for certificate: %s", certificate.Purpose)
 		}
-		if len(certificate.KeyPair.Key) == 0 {
+		if len(certificate.KeyPair.Key) == 0 && !certificate.ExternalSigner {
 			return errors.Wrapf(ErrMissingKey, "for certificate: %s", certificate.Purpose)
 		}
 	}
@@ -270,8 +271,10 @@ func (c Certificates) LookupOrGenerate(ctx context.Context, ctrlclient client.Cl
 
 // Certificate represents a single certificate CA.
 type Certificate struct {
-	Generated         bool
-	External          bool
+	Generated bool
+	External  bool
+	// ExternalSigner is true when the private key of the CA is kept by an external signer, see ExternalSignerURLAnnotation.
+	ExternalSigner    bool
 	Purpose           Purpose
 	KeyPair           *certs.KeyPair
 	CertFile, KeyFile string
@@ -29,6 +29,21 @@ const (
 	// TLSCrtDataName is the key used to store a TLS certificate in the secret's data field.
 	TLSCrtDataName = "tls.crt"
 
+	// TokenDataName is the key used to store a bearer token in the secret's data field.
+	TokenDataName = "token"
+
+	// ExternalSignerURLAnnotation is the annotation set on a certificate authority Secret without a private key,
+	// to issue its certificates with the external signing endpoint at the given URL.
+	ExternalSignerURLAnnotation = "cluster.x-k8s.io/external-signer-url"
+
+	// ExternalSignerTokenDataName is the key used to store the token authenticating to the external signer
+	// in the certificate authority secret's data field.
+	ExternalSignerTokenDataName = "signer-token"
+
+	// ExternalSignerCADataName is the key used to store the CA certificates used to verify the external signer
+	// endpoint in the certificate authority secret's data field. The system roots are used when it is not set.
+	ExternalSignerCADataName = "signer-ca.crt"
+
 	// Kubeconfig is the secret name suffix storing the Cluster Kubeconfig.
 	Kubeconfig = Purpose("kubeconfig")
 
@@ -46,9 +61,16 @@ const (
 
 	// APIServerEtcdClient is the secret name of user-supplied secret containing the apiserver-etcd-client key/cert
 	APIServerEtcdClient Purpose = "apiserver-etcd-client"
+
+	// Tunnel is the secret name suffix for the token authenticating the tunnel agent of a Cluster.
+	Tunnel Purpose = "tunnel"
+
+	// ControllerKubeconfig is the secret name suffix storing the Kubeconfig of the ServiceAccount used by the
+	// Cluster API controllers in the Cluster.
+	ControllerKubeconfig Purpose = "controller-kubeconfig"
 )
 
 var (
 	// allSecretPurposes defines a lists with all the secret suffix used by Cluster API
-	allSecretPurposes = []Purpose{Kubeconfig, ClusterCA, EtcdCA, ServiceAccount, FrontProxyCA, APIServerEtcdClient}
+	allSecretPurposes = []Purpose{Kubeconfig, ClusterCA, EtcdCA, ServiceAccount, FrontProxyCA, APIServerEtcdClient, Tunnel, ControllerKubeconfig}
 )
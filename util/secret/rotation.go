@@ -0,0 +1,145 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"bytes"
+	"encoding/pem"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// RotationPhase is a phase of the rotation of a certificate authority.
+//
+// The certificate authority Secrets store the trust bundle in their TLSCrtDataName key, starting with the certificate
+// of the signing key pair stored in the TLSKeyDataName key, so they can be written as-is to the kubeadm
+// certificate files: kubeadm signs with the first certificate of a CA file, while the Kubernetes components
+// trust all of them.
+type RotationPhase string
+
+const (
+	// RotationPhaseAnnotation is the annotation storing the phase of the rotation of a certificate authority
+	// Secret. It is removed once the rotation is completed.
+	RotationPhaseAnnotation = "cluster.x-k8s.io/ca-rotation-phase"
+
+	// NextTLSKeyDataName is the key used to store the private key of the new certificate authority in the
+	// secret's data field, while the current one is still signing.
+	NextTLSKeyDataName = "next-tls.key"
+
+	// RotationTrustNew is the first phase of a rotation: a new certificate authority is added to the trust
+	// bundle, while the current one keeps signing.
+	RotationTrustNew = RotationPhase("TrustNew")
+
+	// RotationSignWithNew is the second phase of a rotation: the new certificate authority signs, while the
+	// previous one is still trusted.
+	RotationSignWithNew = RotationPhase("SignWithNew")
+
+	// RotationRemoveOld is the last phase of a rotation: only the new certificate authority is trusted.
+	RotationRemoveOld = RotationPhase("RemoveOld")
+)
+
+// Next returns the phase following the given one, where the empty phase stands for no rotation in progress.
+func (p RotationPhase) Next() RotationPhase {
+	switch p {
+	case "":
+		return RotationTrustNew
+	case RotationTrustNew:
+		return RotationSignWithNew
+	case RotationSignWithNew:
+		return RotationRemoveOld
+	default:
+		return ""
+	}
+}
+
+// GetRotationPhase returns the phase of the rotation of a certificate authority Secret, or an empty phase
+// if it is not being rotated.
+func GetRotationPhase(s *corev1.Secret) RotationPhase {
+	return RotationPhase(s.GetAnnotations()[RotationPhaseAnnotation])
+}
+
+// AdvanceRotation moves a certificate authority Secret to the next phase of its rotation, starting a rotation if
+// none is in progress, and completing it after the RotationRemoveOld phase. The Secret is not saved.
+func AdvanceRotation(s *corev1.Secret, purpose Purpose) error {
+	if s.Data == nil {
+		s.Data = map[string][]byte{}
+	}
+	current, err := pemBlocks(s.Data[TLSCrtDataName])
+	if err != nil {
+		return errors.Wrapf(err, "invalid %s certificate authority", purpose)
+	}
+
+	phase := GetRotationPhase(s)
+	switch phase {
+	case "":
+		generator := generateCACert
+		if purpose == ServiceAccount {
+			generator = generateServiceAccountKeys
+		}
+		kp, err := generator()
+		if err != nil {
+			return errors.Wrapf(err, "failed to generate the new %s certificate authority", purpose)
+		}
+		s.Data[TLSCrtDataName] = append(pem.EncodeToMemory(current[0]), kp.Cert...)
+		s.Data[NextTLSKeyDataName] = kp.Key
+	case RotationTrustNew:
+		if len(current) != 2 || len(s.Data[NextTLSKeyDataName]) == 0 {
+			return errors.Errorf("%s certificate authority is not in a valid %s state", purpose, phase)
+		}
+		s.Data[TLSCrtDataName] = append(pem.EncodeToMemory(current[1]), pem.EncodeToMemory(current[0])...)
+		s.Data[TLSKeyDataName] = s.Data[NextTLSKeyDataName]
+		delete(s.Data, NextTLSKeyDataName)
+	case RotationSignWithNew:
+		s.Data[TLSCrtDataName] = pem.EncodeToMemory(current[0])
+	case RotationRemoveOld:
+		// Nothing to do, only the new certificate authority is left.
+	default:
+		return errors.Errorf("unknown %s certificate authority rotation phase %q", purpose, phase)
+	}
+
+	annotations := s.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if next := phase.Next(); next == "" {
+		delete(annotations, RotationPhaseAnnotation)
+	} else {
+		annotations[RotationPhaseAnnotation] = string(next)
+	}
+	s.SetAnnotations(annotations)
+	return nil
+}
+
+// pemBlocks returns the PEM blocks of a certificate authority trust bundle.
+func pemBlocks(data []byte) ([]*pem.Block, error) {
+	var blocks []*pem.Block
+	rest := bytes.TrimSpace(data)
+	for len(rest) > 0 {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return nil, errors.New("failed to decode PEM data")
+		}
+		blocks = append(blocks, block)
+		rest = bytes.TrimSpace(rest)
+	}
+	if len(blocks) == 0 {
+		return nil, errors.New("no PEM data found")
+	}
+	return blocks, nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret_test
+
+import (
+	"crypto/tls"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newCASecret(g *WithT, purpose secret.Purpose) *corev1.Secret {
+	certificates := secret.Certificates{&secret.Certificate{Purpose: purpose}}
+	g.Expect(certificates.Generate()).To(Succeed())
+	return certificates[0].AsSecret(client.ObjectKey{Namespace: "default", Name: "test"}, metav1.OwnerReference{})
+}
+
+// expectSigner checks the first certificate of the trust bundle is the one of the signing key.
+func expectSigner(g *WithT, s *corev1.Secret) {
+	_, err := tls.X509KeyPair(s.Data[secret.TLSCrtDataName], s.Data[secret.TLSKeyDataName])
+	g.Expect(err).NotTo(HaveOccurred())
+}
+
+func TestAdvanceRotation(t *testing.T) {
+	g := NewWithT(t)
+
+	s := newCASecret(g, secret.ClusterCA)
+	g.Expect(secret.GetRotationPhase(s)).To(BeEmpty())
+	original, err := cert.ParseCertsPEM(s.Data[secret.TLSCrtDataName])
+	g.Expect(err).NotTo(HaveOccurred())
+	originalKey := s.Data[secret.TLSKeyDataName]
+
+	// The new CA is trusted, the original one keeps signing.
+	g.Expect(secret.AdvanceRotation(s, secret.ClusterCA)).To(Succeed())
+	g.Expect(secret.GetRotationPhase(s)).To(Equal(secret.RotationTrustNew))
+	bundle, err := cert.ParseCertsPEM(s.Data[secret.TLSCrtDataName])
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(bundle).To(HaveLen(2))
+	g.Expect(bundle[0].Equal(original[0])).To(BeTrue())
+	g.Expect(s.Data[secret.TLSKeyDataName]).To(Equal(originalKey))
+	g.Expect(s.Data[secret.NextTLSKeyDataName]).NotTo(BeEmpty())
+	expectSigner(g, s)
+	next := bundle[1]
+
+	// The new CA signs, the original one is still trusted.
+	g.Expect(secret.AdvanceRotation(s, secret.ClusterCA)).To(Succeed())
+	g.Expect(secret.GetRotationPhase(s)).To(Equal(secret.RotationSignWithNew))
+	bundle, err = cert.ParseCertsPEM(s.Data[secret.TLSCrtDataName])
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(bundle).To(HaveLen(2))
+	g.Expect(bundle[0].Equal(next)).To(BeTrue())
+	g.Expect(bundle[1].Equal(original[0])).To(BeTrue())
+	g.Expect(s.Data).NotTo(HaveKey(secret.NextTLSKeyDataName))
+	expectSigner(g, s)
+
+	// Only the new CA is trusted.
+	g.Expect(secret.AdvanceRotation(s, secret.ClusterCA)).To(Succeed())
+	g.Expect(secret.GetRotationPhase(s)).To(Equal(secret.RotationRemoveOld))
+	bundle, err = cert.ParseCertsPEM(s.Data[secret.TLSCrtDataName])
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(bundle).To(HaveLen(1))
+	g.Expect(bundle[0].Equal(next)).To(BeTrue())
+	expectSigner(g, s)
+
+	// The rotation is completed.
+	g.Expect(secret.AdvanceRotation(s, secret.ClusterCA)).To(Succeed())
+	g.Expect(secret.GetRotationPhase(s)).To(BeEmpty())
+	g.Expect(s.Annotations).NotTo(HaveKey(secret.RotationPhaseAnnotation))
+	expectSigner(g, s)
+}
+
+func TestAdvanceRotationServiceAccount(t *testing.T) {
+	g := NewWithT(t)
+
+	s := newCASecret(g, secret.ServiceAccount)
+	g.Expect(secret.AdvanceRotation(s, secret.ServiceAccount)).To(Succeed())
+	keys, err := keyutil.ParsePublicKeysPEM(s.Data[secret.TLSCrtDataName])
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(keys).To(HaveLen(2))
+	_, err = keyutil.ParsePrivateKeyPEM(s.Data[secret.NextTLSKeyDataName])
+	g.Expect(err).NotTo(HaveOccurred())
+}
+
+func TestAdvanceRotationInvalidState(t *testing.T) {
+	g := NewWithT(t)
+
+	s := newCASecret(g, secret.ClusterCA)
+	s.Annotations = map[string]string{secret.RotationPhaseAnnotation: string(secret.RotationTrustNew)}
+	g.Expect(secret.AdvanceRotation(s, secret.ClusterCA)).NotTo(Succeed())
+
+	s.Annotations[secret.RotationPhaseAnnotation] = "Unknown"
+	g.Expect(secret.AdvanceRotation(s, secret.ClusterCA)).NotTo(Succeed())
+}
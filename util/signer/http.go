@@ -0,0 +1,176 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package signer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api/util/certs"
+)
+
+// NewHTTPSigner returns a Signer issuing certificates with an external signing endpoint implementing the
+// Vault PKI sign API, e.g. https://vault.example.com:8200/v1/pki/sign-verbatim/cluster-api.
+//
+// The certificate signing request carries the subject and the SANs of the certificates, so the endpoint must
+// keep them, as the sign-verbatim endpoint does. The token is sent with the X-Vault-Token header, and the
+// endpoint is verified with the given PEM encoded CA certificates, or the system roots if empty.
+func NewHTTPSigner(url, token string, caData []byte) (Signer, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if len(caData) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caData) {
+			return nil, errors.New("failed to parse the CA certificates of the external signer")
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	return &httpSigner{
+		url:    url,
+		token:  token,
+		client: &http.Client{Transport: transport, Timeout: 30 * time.Second},
+	}, nil
+}
+
+type httpSigner struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// signRequest is the subset of the Vault PKI sign request used by the signer.
+type signRequest struct {
+	CSR         string   `json:"csr"`
+	CommonName  string   `json:"common_name"`
+	AltNames    string   `json:"alt_names,omitempty"`
+	IPSANs      string   `json:"ip_sans,omitempty"`
+	TTL         string   `json:"ttl"`
+	ExtKeyUsage []string `json:"ext_key_usage,omitempty"`
+	Format      string   `json:"format"`
+}
+
+// signResponse is the subset of the Vault PKI sign response used by the signer.
+type signResponse struct {
+	Data struct {
+		Certificate string `json:"certificate"`
+	} `json:"data"`
+	Errors []string `json:"errors"`
+}
+
+// extKeyUsages are the names of the extended key usages in the Vault PKI API.
+var extKeyUsages = map[x509.ExtKeyUsage]string{
+	x509.ExtKeyUsageServerAuth: "ServerAuth",
+	x509.ExtKeyUsageClientAuth: "ClientAuth",
+}
+
+// Sign implements Signer.
+func (s *httpSigner) Sign(ctx context.Context, key *rsa.PrivateKey, cfg *certs.Config) (*x509.Certificate, error) {
+	if len(cfg.CommonName) == 0 {
+		return nil, errors.New("must specify a CommonName")
+	}
+	if len(cfg.Usages) == 0 {
+		return nil, errors.New("must specify at least one ExtKeyUsage")
+	}
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{
+			CommonName:   cfg.CommonName,
+			Organization: cfg.Organization,
+		},
+		DNSNames:    cfg.AltNames.DNSNames,
+		IPAddresses: cfg.AltNames.IPs,
+	}, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create certificate signing request")
+	}
+
+	duration := cfg.Duration
+	if duration == 0 {
+		duration = certs.DefaultCertDuration
+	}
+	request := signRequest{
+		CSR:        string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr})),
+		CommonName: cfg.CommonName,
+		AltNames:   strings.Join(cfg.AltNames.DNSNames, ","),
+		TTL:        fmt.Sprintf("%ds", int64(duration.Seconds())),
+		Format:     "pem",
+	}
+	ips := make([]string, 0, len(cfg.AltNames.IPs))
+	for _, ip := range cfg.AltNames.IPs {
+		ips = append(ips, ip.String())
+	}
+	request.IPSANs = strings.Join(ips, ",")
+	for _, usage := range cfg.Usages {
+		name, ok := extKeyUsages[usage]
+		if !ok {
+			return nil, errors.Errorf("unsupported extended key usage %d", usage)
+		}
+		request.ExtKeyUsage = append(request.ExtKeyUsage, name)
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode sign request")
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create sign request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("X-Vault-Token", s.token)
+	}
+
+	resp, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to send sign request to %s", s.url)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read sign response from %s", s.url)
+	}
+
+	response := signResponse{}
+	if err := json.Unmarshal(data, &response); err != nil && resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil, errors.Wrapf(err, "failed to decode sign response from %s", s.url)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, errors.Errorf("failed to sign certificate with %s: %s %s", s.url, resp.Status, strings.Join(response.Errors, ", "))
+	}
+
+	cert, err := certs.DecodeCertPEM([]byte(response.Data.Certificate))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode certificate signed by %s", s.url)
+	} else if cert == nil {
+		return nil, errors.Errorf("no certificate in sign response from %s", s.url)
+	}
+	return cert, nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package signer implements the issuance of certificates signed by the certificate authorities of a cluster,
+// either with a private key stored in the certificate authority Secret, or with an external signing service
+// keeping the private key out of the management cluster.
+package signer
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// ErrNoSigner is returned when a certificate authority has neither a private key nor an external signer,
+	// e.g. the CA of an external etcd.
+	ErrNoSigner = errors.New("certificate authority has neither a private key nor an external signer")
+)
+
+// Signer issues certificates signed by a certificate authority.
+type Signer interface {
+	// Sign issues a certificate for the public key of the given private key, as described by the given config.
+	Sign(ctx context.Context, key *rsa.PrivateKey, cfg *certs.Config) (*x509.Certificate, error)
+}
+
+// Get returns the Signer of the certificate authority with the given purpose of a cluster.
+func Get(ctx context.Context, c client.Reader, cluster client.ObjectKey, purpose secret.Purpose) (Signer, error) {
+	s, err := secret.Get(ctx, c, cluster, purpose)
+	if err != nil {
+		return nil, err
+	}
+	return FromSecret(s)
+}
+
+// FromSecret returns the Signer of a certificate authority Secret: the private key stored in the Secret is used if
+// any, otherwise the external signer configured with the secret.ExternalSignerURLAnnotation.
+func FromSecret(s *corev1.Secret) (Signer, error) {
+	caCert, err := certs.DecodeCertPEM(s.Data[secret.TLSCrtDataName])
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode the certificate of Secret %s/%s", s.Namespace, s.Name)
+	} else if caCert == nil {
+		return nil, errors.Errorf("certificate not found in Secret %s/%s", s.Namespace, s.Name)
+	}
+
+	if len(s.Data[secret.TLSKeyDataName]) > 0 {
+		caKey, err := certs.DecodePrivateKeyPEM(s.Data[secret.TLSKeyDataName])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode the private key of Secret %s/%s", s.Namespace, s.Name)
+		} else if caKey == nil {
+			return nil, errors.Errorf("private key not found in Secret %s/%s", s.Namespace, s.Name)
+		}
+		return NewKeyPairSigner(caCert, caKey), nil
+	}
+
+	if url := s.GetAnnotations()[secret.ExternalSignerURLAnnotation]; url != "" {
+		return NewHTTPSigner(url, string(s.Data[secret.ExternalSignerTokenDataName]), s.Data[secret.ExternalSignerCADataName])
+	}
+
+	return nil, ErrNoSigner
+}
+
+// NewKeyPairSigner returns a Signer signing certificates with the given certificate authority key pair.
+func NewKeyPairSigner(caCert *x509.Certificate, caKey *rsa.PrivateKey) Signer {
+	return &keyPairSigner{caCert: caCert, caKey: caKey}
+}
+
+type keyPairSigner struct {
+	caCert *x509.Certificate
+	caKey  *rsa.PrivateKey
+}
+
+// Sign implements Signer.
+func (s *keyPairSigner) Sign(_ context.Context, key *rsa.PrivateKey, cfg *certs.Config) (*x509.Certificate, error) {
+	return cfg.NewSignedCert(key, s.caCert, s.caKey)
+}
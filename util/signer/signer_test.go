@@ -0,0 +1,143 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package signer
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newCA(g *WithT) (*x509.Certificate, *rsa.PrivateKey, *corev1.Secret) {
+	certificates := secret.Certificates{&secret.Certificate{Purpose: secret.ClusterCA}}
+	g.Expect(certificates.Generate()).To(Succeed())
+	s := certificates[0].AsSecret(client.ObjectKey{Namespace: "default", Name: "test"}, metav1.OwnerReference{})
+
+	caCert, err := certs.DecodeCertPEM(s.Data[secret.TLSCrtDataName])
+	g.Expect(err).NotTo(HaveOccurred())
+	caKey, err := certs.DecodePrivateKeyPEM(s.Data[secret.TLSKeyDataName])
+	g.Expect(err).NotTo(HaveOccurred())
+	return caCert, caKey, s
+}
+
+func serverCA(server *httptest.Server) []byte {
+	return certs.EncodeCertPEM(server.Certificate())
+}
+
+func TestFromSecretKeyPair(t *testing.T) {
+	g := NewWithT(t)
+
+	caCert, _, s := newCA(g)
+	signer, err := FromSecret(s)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	key, err := certs.NewPrivateKey()
+	g.Expect(err).NotTo(HaveOccurred())
+	cert, err := signer.Sign(context.Background(), key, &certs.Config{
+		CommonName: "user",
+		Usages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cert.CheckSignatureFrom(caCert)).To(Succeed())
+}
+
+func TestFromSecretExternalSigner(t *testing.T) {
+	g := NewWithT(t)
+
+	caCert, caKey, s := newCA(g)
+	server := NewFakeServer(caCert, caKey, "token")
+	defer server.Close()
+
+	delete(s.Data, secret.TLSKeyDataName)
+	s.Annotations = map[string]string{secret.ExternalSignerURLAnnotation: server.URL + "/v1/pki/sign-verbatim/cluster-api"}
+	s.Data[secret.ExternalSignerTokenDataName] = []byte("token")
+	s.Data[secret.ExternalSignerCADataName] = serverCA(server)
+	signer, err := FromSecret(s)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	key, err := certs.NewPrivateKey()
+	g.Expect(err).NotTo(HaveOccurred())
+	cert, err := signer.Sign(context.Background(), key, &certs.Config{
+		CommonName:   "kube-apiserver",
+		Organization: []string{"system:masters"},
+		AltNames: certs.AltNames{
+			DNSNames: []string{"kubernetes", "kubernetes.default"},
+			IPs:      []net.IP{net.ParseIP("10.96.0.1")},
+		},
+		Usages:   []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		Duration: time.Hour,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cert.CheckSignatureFrom(caCert)).To(Succeed())
+	g.Expect(cert.PublicKey).To(Equal(key.Public()))
+	g.Expect(cert.Subject.CommonName).To(Equal("kube-apiserver"))
+	g.Expect(cert.Subject.Organization).To(ConsistOf("system:masters"))
+	g.Expect(cert.DNSNames).To(ConsistOf("kubernetes", "kubernetes.default"))
+	g.Expect(cert.IPAddresses[0].Equal(net.ParseIP("10.96.0.1"))).To(BeTrue())
+	g.Expect(cert.ExtKeyUsage).To(ConsistOf(x509.ExtKeyUsageServerAuth))
+	g.Expect(cert.NotAfter).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+}
+
+func TestExternalSignerErrors(t *testing.T) {
+	g := NewWithT(t)
+
+	caCert, caKey, _ := newCA(g)
+	server := NewFakeServer(caCert, caKey, "token")
+	defer server.Close()
+
+	key, err := certs.NewPrivateKey()
+	g.Expect(err).NotTo(HaveOccurred())
+	cfg := &certs.Config{
+		CommonName: "user",
+		Usages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	// The signer errors are reported.
+	signer, err := NewHTTPSigner(server.URL, "invalid", serverCA(server))
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = signer.Sign(context.Background(), key, cfg)
+	g.Expect(err).To(MatchError(ContainSubstring("permission denied")))
+
+	// The signer endpoint is verified.
+	signer, err = NewHTTPSigner(server.URL, "token", nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = signer.Sign(context.Background(), key, cfg)
+	g.Expect(err).To(HaveOccurred())
+
+	_, err = NewHTTPSigner(server.URL, "token", []byte("invalid"))
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestFromSecretNoSigner(t *testing.T) {
+	g := NewWithT(t)
+
+	_, _, s := newCA(g)
+	delete(s.Data, secret.TLSKeyDataName)
+	_, err := FromSecret(s)
+	g.Expect(err).To(Equal(ErrNoSigner))
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package signer
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"math"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"sigs.k8s.io/cluster-api/util/certs"
+)
+
+// NewFakeServer returns a TLS server signing the certificate signing requests verbatim with the given CA, in the
+// style of the Vault PKI sign-verbatim endpoint, for the requests authenticated with the given token.
+func NewFakeServer(caCert *x509.Certificate, caKey *rsa.PrivateKey, token string) *httptest.Server {
+	writeErrors := func(w http.ResponseWriter, status int, errs ...string) {
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(signResponse{Errors: errs})
+	}
+
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != token {
+			writeErrors(w, http.StatusForbidden, "permission denied")
+			return
+		}
+
+		request := signRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeErrors(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		block, _ := pem.Decode([]byte(request.CSR))
+		if block == nil {
+			writeErrors(w, http.StatusBadRequest, "invalid csr")
+			return
+		}
+		csr, err := x509.ParseCertificateRequest(block.Bytes)
+		if err == nil {
+			err = csr.CheckSignature()
+		}
+		if err != nil {
+			writeErrors(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		ttl, err := time.ParseDuration(request.TTL)
+		if err != nil {
+			writeErrors(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var usages []x509.ExtKeyUsage
+		for _, name := range request.ExtKeyUsage {
+			for usage, n := range extKeyUsages {
+				if n == name {
+					usages = append(usages, usage)
+				}
+			}
+		}
+		serial, err := rand.Int(rand.Reader, new(big.Int).SetInt64(math.MaxInt64))
+		if err != nil {
+			writeErrors(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		tmpl := &x509.Certificate{
+			SerialNumber: serial,
+			Subject:      csr.Subject,
+			DNSNames:     csr.DNSNames,
+			IPAddresses:  csr.IPAddresses,
+			NotBefore:    time.Now(),
+			NotAfter:     time.Now().Add(ttl),
+			KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  usages,
+		}
+		b, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, csr.PublicKey, caKey)
+		if err != nil {
+			writeErrors(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		response := signResponse{}
+		response.Data.Certificate = string(certs.EncodeCertPEM(&x509.Certificate{Raw: b}))
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WrapClient returns a client creating a span for each call to the given client,
+// with the given attributes; e.g. the workload cluster the client is connected to.
+func WrapClient(c client.Client, attrs ...attribute.KeyValue) client.Client {
+	return &tracingClient{Client: c, attrs: attrs}
+}
+
+type tracingClient struct {
+	client.Client
+	attrs []attribute.KeyValue
+}
+
+func (c *tracingClient) start(ctx context.Context, verb string, obj runtime.Object) (context.Context, func(error)) {
+	ctx, span := StartSpan(ctx, fmt.Sprintf("client.%s %T", verb, obj), c.attrs...)
+	return ctx, func(err error) { EndSpan(span, err) }
+}
+
+func (c *tracingClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) (err error) {
+	ctx, end := c.start(ctx, "Get", obj)
+	defer func() { end(err) }()
+	return c.Client.Get(ctx, key, obj)
+}
+
+func (c *tracingClient) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) (err error) {
+	ctx, end := c.start(ctx, "List", list)
+	defer func() { end(err) }()
+	return c.Client.List(ctx, list, opts...)
+}
+
+func (c *tracingClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) (err error) {
+	ctx, end := c.start(ctx, "Create", obj)
+	defer func() { end(err) }()
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *tracingClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOption) (err error) {
+	ctx, end := c.start(ctx, "Delete", obj)
+	defer func() { end(err) }()
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *tracingClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) (err error) {
+	ctx, end := c.start(ctx, "Update", obj)
+	defer func() { end(err) }()
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *tracingClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) (err error) {
+	ctx, end := c.start(ctx, "Patch", obj)
+	defer func() { end(err) }()
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *tracingClient) DeleteAllOf(ctx context.Context, obj runtime.Object, opts ...client.DeleteAllOfOption) (err error) {
+	ctx, end := c.start(ctx, "DeleteAllOf", obj)
+	defer func() { end(err) }()
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// This file is a stopgap, internal to the tracing package and only reachable through Setup with ExporterOTLP:
+// the upstream OTLP exporters (go.opentelemetry.io/otel/exporters/otlp/otlptrace) require google.golang.org/grpc v1.40.0,
+// which removed the balancer and resolver APIs used by the etcd v3.4 client KCP depends on, so we implement the (small)
+// JSON flavor of the protocol instead.
+//
+// TODO: replace this file with the upstream otlptracehttp exporter once the etcd client works with a recent
+// google.golang.org/grpc version.
+
+// newOTLPExporter returns a span exporter sending spans to the given OTLP/HTTP endpoint
+// using the JSON encoding.
+func newOTLPExporter(endpoint string) sdktrace.SpanExporter {
+	return &otlpExporter{
+		url:    strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type otlpExporter struct {
+	url    string
+	client *http.Client
+}
+
+// ExportSpans implements sdktrace.SpanExporter.
+func (e *otlpExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(toOTLP(spans))
+	if err != nil {
+		return errors.Wrap(err, "failed to encode spans")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to create OTLP request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrapf(err, "failed to send spans to %s", e.url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("failed to send spans to %s: %s", e.url, resp.Status)
+	}
+	return nil
+}
+
+// Shutdown implements sdktrace.SpanExporter.
+func (e *otlpExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// The following types are the subset of the OTLP JSON encoding used by the exporter.
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// toOTLP converts spans to an OTLP export request; all the spans produced by a manager
+// share the same resource, so they are grouped by instrumentation scope only.
+func toOTLP(spans []sdktrace.ReadOnlySpan) *otlpRequest {
+	resourceSpans := otlpResourceSpans{}
+	if r := spans[0].Resource(); r != nil {
+		resourceSpans.Resource.Attributes = toOTLPAttributes(r.Attributes())
+	}
+
+	scopes := map[string]int{}
+	for _, s := range spans {
+		scope := s.InstrumentationLibrary()
+		i, ok := scopes[scope.Name]
+		if !ok {
+			i = len(resourceSpans.ScopeSpans)
+			scopes[scope.Name] = i
+			resourceSpans.ScopeSpans = append(resourceSpans.ScopeSpans, otlpScopeSpans{
+				Scope: otlpScope{Name: scope.Name, Version: scope.Version},
+			})
+		}
+
+		span := otlpSpan{
+			TraceID:           s.SpanContext().TraceID().String(),
+			SpanID:            s.SpanContext().SpanID().String(),
+			Name:              s.Name(),
+			Kind:              int(s.SpanKind()),
+			StartTimeUnixNano: strconv.FormatInt(s.StartTime().UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.EndTime().UnixNano(), 10),
+			Attributes:        toOTLPAttributes(s.Attributes()),
+			Status:            otlpStatus{Message: s.Status().Description},
+		}
+		if s.Parent().IsValid() {
+			span.ParentSpanID = s.Parent().SpanID().String()
+		}
+		// NB. OTLP status codes are ordered differently than the OpenTelemetry API ones.
+		switch s.Status().Code {
+		case codes.Ok:
+			span.Status.Code = 1
+		case codes.Error:
+			span.Status.Code = 2
+		}
+		resourceSpans.ScopeSpans[i].Spans = append(resourceSpans.ScopeSpans[i].Spans, span)
+	}
+
+	return &otlpRequest{ResourceSpans: []otlpResourceSpans{resourceSpans}}
+}
+
+func toOTLPAttributes(attrs []attribute.KeyValue) []otlpKeyValue {
+	ret := make([]otlpKeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		kv := otlpKeyValue{Key: string(a.Key)}
+		switch a.Value.Type() {
+		case attribute.BOOL:
+			v := a.Value.AsBool()
+			kv.Value.BoolValue = &v
+		case attribute.INT64:
+			v := strconv.FormatInt(a.Value.AsInt64(), 10)
+			kv.Value.IntValue = &v
+		case attribute.FLOAT64:
+			v := a.Value.AsFloat64()
+			kv.Value.DoubleValue = &v
+		default:
+			v := a.Value.Emit()
+			kv.Value.StringValue = &v
+		}
+		ret = append(ret, kv)
+	}
+	return ret
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing implements opt-in OpenTelemetry tracing for the Cluster API managers.
+package tracing
+
+import (
+	"context"
+	"os"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// ExporterNone disables tracing.
+	ExporterNone = ""
+
+	// ExporterStdout writes spans as JSON to the standard output.
+	ExporterStdout = "stdout"
+
+	// ExporterOTLP sends spans to an OTLP/HTTP endpoint, e.g. an OpenTelemetry collector.
+	ExporterOTLP = "otlp"
+
+	// DefaultOTLPEndpoint is the default OTLP/HTTP endpoint of an OpenTelemetry collector.
+	DefaultOTLPEndpoint = "http://localhost:4318"
+
+	tracerName = "sigs.k8s.io/cluster-api"
+)
+
+// Options are the options for setting up tracing in a manager.
+type Options struct {
+	// ServiceName is the name of the manager reported in the spans.
+	ServiceName string
+
+	// Exporter is one of ExporterNone, ExporterStdout or ExporterOTLP.
+	Exporter string
+
+	// OTLPEndpoint is the base URL of the OTLP/HTTP endpoint used by ExporterOTLP.
+	OTLPEndpoint string
+}
+
+// Setup installs a global tracer provider exporting spans as defined by the given options,
+// and returns a function flushing pending spans and stopping the provider.
+// If tracing is disabled, spans are not recorded and the returned function is a noop.
+func Setup(opts Options) (func(context.Context) error, error) {
+	var exporter sdktrace.SpanExporter
+	switch opts.Exporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		e, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create stdout trace exporter")
+		}
+		exporter = e
+	case ExporterOTLP:
+		endpoint := opts.OTLPEndpoint
+		if endpoint == "" {
+			endpoint = DefaultOTLPEndpoint
+		}
+		exporter = newOTLPExporter(endpoint)
+	default:
+		return nil, errors.Errorf("unknown tracing exporter %q, must be one of %q or %q", opts.Exporter, ExporterStdout, ExporterOTLP)
+	}
+
+	provider := NewTracerProvider(opts.ServiceName, sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(provider)
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		ctrl.Log.WithName("tracing").Error(err, "Failed to export spans")
+	}))
+	return provider.Shutdown, nil
+}
+
+// NewTracerProvider returns a tracer provider for the given service; this is exposed
+// so tests can install a provider backed by an in-memory exporter.
+func NewTracerProvider(serviceName string, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	opts = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(serviceName))),
+	}, opts...)
+	return sdktrace.NewTracerProvider(opts...)
+}
+
+// StartSpan starts a span with the given name as a child of the span in ctx, if any.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan ends the span, recording the error if not nil.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// ObjectAttributes returns the span attributes identifying an object.
+func ObjectAttributes(kind, namespace, name string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("kind", kind),
+		attribute.String("namespace", namespace),
+		attribute.String("name", name),
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func setupInMemoryTracing(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := NewTracerProvider("test", sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return exporter
+}
+
+func TestStartAndEndSpan(t *testing.T) {
+	g := NewWithT(t)
+	exporter := setupInMemoryTracing(t)
+
+	ctx, root := StartSpan(context.Background(), "root", ObjectAttributes("Cluster", "default", "test")...)
+	_, child := StartSpan(ctx, "child")
+	EndSpan(child, errors.New("boom"))
+	EndSpan(root, nil)
+
+	spans := exporter.GetSpans()
+	g.Expect(spans).To(HaveLen(2))
+
+	g.Expect(spans[0].Name).To(Equal("child"))
+	g.Expect(spans[0].Parent.SpanID()).To(Equal(spans[1].SpanContext.SpanID()))
+	g.Expect(spans[0].Status.Code).To(Equal(codes.Error))
+	g.Expect(spans[0].Status.Description).To(Equal("boom"))
+
+	g.Expect(spans[1].Name).To(Equal("root"))
+	g.Expect(spans[1].Parent.IsValid()).To(BeFalse())
+	g.Expect(spans[1].Status.Code).To(Equal(codes.Unset))
+	g.Expect(spans[1].Attributes).To(ContainElement(attribute.String("kind", "Cluster")))
+}
+
+func TestWrapClient(t *testing.T) {
+	g := NewWithT(t)
+	exporter := setupInMemoryTracing(t)
+
+	c := WrapClient(fake.NewFakeClientWithScheme(scheme.Scheme), attribute.String("cluster", "default/test"))
+
+	ctx, root := StartSpan(context.Background(), "root")
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	g.Expect(c.Create(ctx, node)).To(Succeed())
+	g.Expect(c.Get(ctx, client.ObjectKey{Name: "missing"}, &corev1.Node{})).ToNot(Succeed())
+	EndSpan(root, nil)
+
+	spans := exporter.GetSpans()
+	g.Expect(spans).To(HaveLen(3))
+
+	g.Expect(spans[0].Name).To(Equal("client.Create *v1.Node"))
+	g.Expect(spans[0].Parent.SpanID()).To(Equal(spans[2].SpanContext.SpanID()))
+	g.Expect(spans[0].Attributes).To(ContainElement(attribute.String("cluster", "default/test")))
+
+	g.Expect(spans[1].Name).To(Equal("client.Get *v1.Node"))
+	g.Expect(spans[1].Status.Code).To(Equal(codes.Error))
+}
+
+func TestOTLPExporter(t *testing.T) {
+	g := NewWithT(t)
+
+	var got otlpRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.Expect(r.URL.Path).To(Equal("/v1/traces"))
+		g.Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
+		g.Expect(json.NewDecoder(r.Body).Decode(&got)).To(Succeed())
+	}))
+	defer server.Close()
+
+	provider := NewTracerProvider("test", sdktrace.WithSyncer(newOTLPExporter(server.URL)))
+	ctx, root := provider.Tracer(tracerName).Start(context.Background(), "root")
+	_, child := provider.Tracer(tracerName).Start(ctx, "child")
+	EndSpan(child, errors.New("boom"))
+
+	g.Expect(got.ResourceSpans).To(HaveLen(1))
+	g.Expect(got.ResourceSpans[0].Resource.Attributes).To(ContainElement(otlpKeyValue{Key: "service.name", Value: otlpAnyValue{StringValue: stringPtr("test")}}))
+	g.Expect(got.ResourceSpans[0].ScopeSpans).To(HaveLen(1))
+	g.Expect(got.ResourceSpans[0].ScopeSpans[0].Scope.Name).To(Equal(tracerName))
+
+	span := got.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	g.Expect(span.Name).To(Equal("child"))
+	g.Expect(span.TraceID).To(Equal(root.SpanContext().TraceID().String()))
+	g.Expect(span.ParentSpanID).To(Equal(root.SpanContext().SpanID().String()))
+	g.Expect(span.Status).To(Equal(otlpStatus{Code: 2, Message: "boom"}))
+}
+
+func TestSetup(t *testing.T) {
+	g := NewWithT(t)
+
+	shutdown, err := Setup(Options{Exporter: ExporterNone})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(shutdown(context.Background())).To(Succeed())
+
+	_, err = Setup(Options{Exporter: "jaeger"})
+	g.Expect(err).To(HaveOccurred())
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"bufio"
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/httpstream/spdy"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultRetryPeriod = 10 * time.Second
+	defaultDialTimeout = 10 * time.Second
+)
+
+// Agent connects out to a tunnel Server and proxies the streams opened by the Server to the API server
+// of the Cluster the agent runs in. The connections are proxied at the TCP level, so TLS is terminated
+// by the API server and the agent doesn't need any credential for it.
+type Agent struct {
+	Log logr.Logger
+
+	// ServerURL is the URL of the tunnel server, e.g. https://tunnel.example.com:8443.
+	ServerURL string
+
+	// Cluster is the Cluster the agent runs in.
+	Cluster client.ObjectKey
+
+	// Token is the bearer token authenticating the agent, stored in the tunnel Secret of the Cluster.
+	Token string
+
+	// TLSConfig is used when connecting to a https ServerURL, e.g. for trusting the server's CA.
+	TLSConfig *tls.Config
+
+	// Target is the address of the API server the streams are proxied to, e.g. 10.96.0.1:443.
+	Target string
+
+	// RetryPeriod is how long the agent waits before reconnecting after losing the connection to
+	// the server. Defaults to 10 seconds.
+	RetryPeriod time.Duration
+}
+
+// Run connects to the server, reconnecting when the connection is lost, until the stop channel is closed.
+func (a *Agent) Run(stop <-chan struct{}) {
+	retryPeriod := a.RetryPeriod
+	if retryPeriod == 0 {
+		retryPeriod = defaultRetryPeriod
+	}
+
+	wait.Until(func() {
+		if err := a.connectAndServe(stop); err != nil {
+			a.Log.Error(err, "Tunnel connection failed, retrying", "server", a.ServerURL, "after", retryPeriod)
+		}
+	}, retryPeriod, stop)
+}
+
+// connectAndServe connects to the server and serves the streams it opens until the connection
+// is lost or the stop channel is closed.
+func (a *Agent) connectAndServe(stop <-chan struct{}) error {
+	c, err := a.connect()
+	if err != nil {
+		return err
+	}
+
+	// The agent accepts the streams, so it is the server side of the SPDY connection even though
+	// it initiated the underlying connection.
+	session, err := spdy.NewServerConnection(c, a.handleStream)
+	if err != nil {
+		return errors.Wrap(err, "failed to create tunnel session")
+	}
+	defer session.Close()
+	a.Log.Info("Tunnel connected", "server", a.ServerURL)
+
+	select {
+	case <-session.CloseChan():
+		return errors.New("tunnel connection closed by the server")
+	case <-stop:
+		return nil
+	}
+}
+
+// connect dials the server and upgrades the connection to the tunnel protocol.
+func (a *Agent) connect() (net.Conn, error) {
+	serverURL, err := url.Parse(a.ServerURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid tunnel server URL %q", a.ServerURL)
+	}
+
+	host := serverURL.Host
+	if serverURL.Port() == "" {
+		port := "80"
+		if serverURL.Scheme == "https" {
+			port = "443"
+		}
+		host = net.JoinHostPort(serverURL.Hostname(), port)
+	}
+
+	dialer := &net.Dialer{Timeout: defaultDialTimeout}
+	var c net.Conn
+	switch serverURL.Scheme {
+	case "https":
+		tlsConfig := &tls.Config{}
+		if a.TLSConfig != nil {
+			tlsConfig = a.TLSConfig.Clone()
+		}
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = serverURL.Hostname()
+		}
+		c, err = tls.DialWithDialer(dialer, "tcp", host, tlsConfig)
+	case "http":
+		c, err = dialer.Dial("tcp", host)
+	default:
+		return nil, errors.Errorf("unsupported tunnel server URL scheme %q", serverURL.Scheme)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to tunnel server %s", a.ServerURL)
+	}
+
+	serverURL.Path = strings.TrimSuffix(serverURL.Path, "/") + Path(a.Cluster)
+	req, err := http.NewRequest(http.MethodGet, serverURL.String(), nil)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+a.Token)
+	req.Header.Set(httpstream.HeaderConnection, httpstream.HeaderUpgrade)
+	req.Header.Set(httpstream.HeaderUpgrade, Protocol)
+
+	if err := req.Write(c); err != nil {
+		c.Close()
+		return nil, errors.Wrap(err, "failed to send tunnel upgrade request")
+	}
+
+	reader := bufio.NewReader(c)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		c.Close()
+		return nil, errors.Wrap(err, "failed to read tunnel upgrade response")
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		c.Close()
+		return nil, errors.Errorf("tunnel server refused the connection: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return &bufferedConn{Conn: c, reader: reader}, nil
+}
+
+// handleStream accepts a stream opened by the server and proxies it to the target.
+func (a *Agent) handleStream(stream httpstream.Stream, replySent <-chan struct{}) error {
+	go func() {
+		<-replySent
+		defer stream.Reset()
+
+		target, err := net.DialTimeout("tcp", a.Target, defaultDialTimeout)
+		if err != nil {
+			a.Log.Error(err, "Failed to dial tunnel target", "target", a.Target)
+			return
+		}
+		defer target.Close()
+
+		pipe(stream, target)
+	}()
+	return nil
+}
+
+// pipe copies data in both directions between a and b, until either direction is done.
+func pipe(a, b io.ReadWriter) {
+	var once sync.Once
+	done := make(chan struct{})
+	copyData := func(dst io.Writer, src io.Reader) {
+		_, _ = io.Copy(dst, src)
+		once.Do(func() { close(done) })
+	}
+
+	go copyData(a, b)
+	go copyData(b, a)
+	<-done
+}
@@ -0,0 +1,250 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/httpstream/spdy"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// serverShutdownTimeout is how long the server waits for the pending requests on shutdown.
+	serverShutdownTimeout = 10 * time.Second
+
+	// directDialTimeout and directDialKeepAlive match the defaults of client-go for connections
+	// to the clusters which don't have a tunnel.
+	directDialTimeout   = 30 * time.Second
+	directDialKeepAlive = 30 * time.Second
+)
+
+// Server accepts the connections of the tunnel agents and dials the API server of their Cluster
+// through them.
+type Server struct {
+	Log    logr.Logger
+	Client client.Reader
+
+	// BindAddress is the address the server listens on for the connections of the agents.
+	BindAddress string
+
+	// CertFile and KeyFile are the paths of the TLS certificate and key the server presents to the agents.
+	// If unset, the server does not use TLS; this should only be used when testing locally.
+	CertFile string
+	KeyFile  string
+
+	sessionsLock sync.RWMutex
+	sessions     map[client.ObjectKey]httpstream.Connection
+}
+
+// Start listens for the connections of the agents until the stop channel is closed.
+func (s *Server) Start(stop <-chan struct{}) error {
+	server := &http.Server{
+		Addr:    s.BindAddress,
+		Handler: s,
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		s.Log.Info("Starting tunnel server", "address", s.BindAddress)
+		if s.CertFile != "" || s.KeyFile != "" {
+			errChan <- server.ListenAndServeTLS(s.CertFile, s.KeyFile)
+			return
+		}
+		errChan <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errChan:
+		return errors.Wrap(err, "failed to run tunnel server")
+	case <-stop:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+	defer cancel()
+	err := server.Shutdown(ctx)
+
+	// Hijacked connections are not closed by Shutdown.
+	s.sessionsLock.Lock()
+	defer s.sessionsLock.Unlock()
+	for cluster, session := range s.sessions {
+		session.Close()
+		delete(s.sessions, cluster)
+	}
+	return err
+}
+
+// ServeHTTP authenticates the agent connecting to PathPrefix/<namespace>/<name> with the token stored
+// in the tunnel Secret of the Cluster, then upgrades the connection and registers it as the tunnel of
+// the Cluster, replacing any previous one.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	cluster, err := clusterFromPath(req.URL.Path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	log := s.Log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "remote", req.RemoteAddr)
+
+	if err := s.authenticate(req.Context(), cluster, req); err != nil {
+		log.Info("Rejecting tunnel agent", "reason", err.Error())
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if !strings.EqualFold(req.Header.Get(httpstream.HeaderUpgrade), Protocol) {
+		http.Error(w, fmt.Sprintf("Expected upgrade to %s", Protocol), http.StatusBadRequest)
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "Connection upgrade not supported", http.StatusInternalServerError)
+		return
+	}
+	c, rw, err := hijacker.Hijack()
+	if err != nil {
+		log.Error(err, "Failed to hijack tunnel agent connection")
+		return
+	}
+	fmt.Fprintf(rw, "HTTP/1.1 %d %s\r\n%s: %s\r\n%s: %s\r\n\r\n", http.StatusSwitchingProtocols, http.StatusText(http.StatusSwitchingProtocols),
+		httpstream.HeaderConnection, httpstream.HeaderUpgrade, httpstream.HeaderUpgrade, Protocol)
+	if err := rw.Flush(); err != nil {
+		log.Error(err, "Failed to upgrade tunnel agent connection")
+		c.Close()
+		return
+	}
+
+	// The server opens the streams, so it is the client side of the SPDY connection even though
+	// the agent initiated the underlying connection.
+	session, err := spdy.NewClientConnection(&bufferedConn{Conn: c, reader: rw.Reader})
+	if err != nil {
+		log.Error(err, "Failed to create tunnel session")
+		return
+	}
+
+	s.setSession(cluster, session)
+	log.Info("Tunnel agent connected")
+
+	go func() {
+		<-session.CloseChan()
+		s.deleteSession(cluster, session)
+		log.Info("Tunnel agent disconnected")
+	}()
+}
+
+// authenticate checks the request carries the bearer token stored in the tunnel Secret of the Cluster.
+func (s *Server) authenticate(ctx context.Context, cluster client.ObjectKey, req *http.Request) error {
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		return errors.New("missing bearer token")
+	}
+
+	tokenSecret, err := secret.Get(ctx, s.Client, cluster, secret.Tunnel)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get tunnel token secret")
+	}
+	expected := tokenSecret.Data[secret.TokenDataName]
+	if len(expected) == 0 {
+		return errors.Errorf("tunnel token secret has no %q key", secret.TokenDataName)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), expected) != 1 {
+		return errors.New("invalid bearer token")
+	}
+	return nil
+}
+
+// Connected returns true if the agent of the given Cluster is connected.
+func (s *Server) Connected(cluster client.ObjectKey) bool {
+	return s.getSession(cluster) != nil
+}
+
+// DialContextFor returns a function dialing the API server of the given Cluster through its tunnel. The
+// tunnel is looked up on each dial, so clients built before the agent connected, or reconnected, use the
+// current tunnel. The returned function can be used as rest.Config.Dial.
+//
+// The API server is dialed directly only if the Cluster has no tunnel Secret; if it does, dialing fails
+// until its agent is connected to this server, instead of trying to reach an API server behind NAT.
+func (s *Server) DialContextFor(cluster client.ObjectKey) func(ctx context.Context, network, address string) (net.Conn, error) {
+	direct := &net.Dialer{
+		Timeout:   directDialTimeout,
+		KeepAlive: directDialKeepAlive,
+	}
+
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		session := s.getSession(cluster)
+		if session == nil {
+			_, err := secret.Get(ctx, s.Client, cluster, secret.Tunnel)
+			switch {
+			case apierrors.IsNotFound(err):
+				return direct.DialContext(ctx, network, address)
+			case err != nil:
+				return nil, errors.Wrapf(err, "failed to get tunnel token secret of Cluster %s", cluster)
+			}
+			return nil, errors.Errorf("tunnel agent of Cluster %s is not connected", cluster)
+		}
+
+		// The agent always proxies to the API server of its Cluster, so the address is irrelevant.
+		stream, err := session.CreateStream(http.Header{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to open tunnel to Cluster %s", cluster)
+		}
+		return &conn{Stream: stream, addr: addr{cluster: cluster}}, nil
+	}
+}
+
+func (s *Server) getSession(cluster client.ObjectKey) httpstream.Connection {
+	s.sessionsLock.RLock()
+	defer s.sessionsLock.RUnlock()
+
+	return s.sessions[cluster]
+}
+
+func (s *Server) setSession(cluster client.ObjectKey, session httpstream.Connection) {
+	s.sessionsLock.Lock()
+	defer s.sessionsLock.Unlock()
+
+	if s.sessions == nil {
+		s.sessions = make(map[client.ObjectKey]httpstream.Connection)
+	}
+	if previous, ok := s.sessions[cluster]; ok {
+		previous.Close()
+	}
+	s.sessions[cluster] = session
+}
+
+// deleteSession removes the session of the Cluster, if it was not already replaced by a new one.
+func (s *Server) deleteSession(cluster client.ObjectKey, session httpstream.Connection) {
+	s.sessionsLock.Lock()
+	defer s.sessionsLock.Unlock()
+
+	if s.sessions[cluster] == session {
+		delete(s.sessions, cluster)
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tunnel implements a reverse tunnel allowing the management cluster to reach the API server
+// of workload clusters that can only dial out, e.g. because they are behind NAT or a firewall.
+//
+// An Agent running in the workload cluster connects to the Server running in the management cluster
+// and upgrades the connection to SPDY; the Server then opens a stream over the connection for each
+// connection to the workload cluster's API server, and the Agent proxies the stream to the API server.
+//
+// Each Cluster API manager runs its own Server, so the workload cluster must run an Agent connected to
+// each of them; the managers do not fall back to dialing the API server of a Cluster with a tunnel Secret
+// directly when its Agent is not connected.
+package tunnel
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Protocol is the protocol the connections of the agents are upgraded to.
+	Protocol = "cluster-api-tunnel/spdy"
+
+	// PathPrefix is the prefix of the path the agents connect to; the path is completed with
+	// the namespace and the name of the Cluster, e.g. /tunnel/default/my-cluster.
+	PathPrefix = "/tunnel/"
+
+	// network is the name of the network of the tunneled connections.
+	network = "tunnel"
+)
+
+// Path returns the path the agent of the given Cluster connects to.
+func Path(cluster client.ObjectKey) string {
+	return PathPrefix + cluster.Namespace + "/" + cluster.Name
+}
+
+// clusterFromPath returns the Cluster the given path refers to.
+func clusterFromPath(path string) (client.ObjectKey, error) {
+	parts := strings.Split(strings.TrimPrefix(path, PathPrefix), "/")
+	if !strings.HasPrefix(path, PathPrefix) || len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return client.ObjectKey{}, errors.Errorf("invalid tunnel path %q, expected %s<namespace>/<name>", path, PathPrefix)
+	}
+	return client.ObjectKey{Namespace: parts[0], Name: parts[1]}, nil
+}
+
+// addr is the address of one end of a tunneled connection.
+type addr struct {
+	cluster client.ObjectKey
+}
+
+// Network returns the network of the tunneled connections.
+func (a addr) Network() string {
+	return network
+}
+
+// String returns the Cluster the connection is tunneled to.
+func (a addr) String() string {
+	return fmt.Sprintf("%s:%s", network, a.cluster)
+}
+
+// conn is a net.Conn over a stream of a tunnel.
+type conn struct {
+	httpstream.Stream
+	addr addr
+}
+
+// Close closes both directions of the stream.
+func (c *conn) Close() error {
+	return c.Stream.Reset()
+}
+
+// LocalAddr returns the address of the tunnel.
+func (c *conn) LocalAddr() net.Addr {
+	return c.addr
+}
+
+// RemoteAddr returns the address of the tunnel.
+func (c *conn) RemoteAddr() net.Addr {
+	return c.addr
+}
+
+// SetDeadline is a no-op, streams do not support deadlines; callers rely on contexts and request
+// timeouts instead.
+func (c *conn) SetDeadline(_ time.Time) error {
+	return nil
+}
+
+// SetReadDeadline is a no-op, see SetDeadline.
+func (c *conn) SetReadDeadline(_ time.Time) error {
+	return nil
+}
+
+// SetWriteDeadline is a no-op, see SetDeadline.
+func (c *conn) SetWriteDeadline(_ time.Time) error {
+	return nil
+}
+
+// bufferedConn is a net.Conn reading first the data already buffered while reading the upgrade
+// request or response.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// Read reads from the buffer, then from the connection.
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var (
+	ctx         = context.Background()
+	testCluster = client.ObjectKey{Namespace: "default", Name: "edge"}
+)
+
+// startEnvironment starts an API server, returning its config and a function stopping it.
+func startEnvironment(g *WithT) (*rest.Config, func()) {
+	env := &envtest.Environment{}
+	cfg, err := env.Start()
+	g.Expect(err).NotTo(HaveOccurred())
+	return cfg, func() { g.Expect(env.Stop()).To(Succeed()) }
+}
+
+// freeAddress returns a local address nothing listens on.
+func freeAddress(g *WithT) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer l.Close()
+	return l.Addr().String()
+}
+
+// hostAddress returns the host:port of the API server of the given config.
+func hostAddress(g *WithT, cfg *rest.Config) string {
+	u, err := url.Parse(cfg.Host)
+	g.Expect(err).NotTo(HaveOccurred())
+	return u.Host
+}
+
+// tunneledClient returns a client for the given workload cluster config which dials the API server through
+// the server, using an address only the agent could resolve.
+func tunneledClient(g *WithT, cfg *rest.Config, server *Server, cluster client.ObjectKey) client.Client {
+	cfg = rest.CopyConfig(cfg)
+	cfg.Host = strings.Replace(cfg.Host, hostAddress(g, cfg), "workload.invalid:6443", 1)
+	cfg.Dial = server.DialContextFor(cluster)
+	cfg.Timeout = 10 * time.Second
+
+	c, err := client.New(cfg, client.Options{Scheme: scheme.Scheme})
+	g.Expect(err).NotTo(HaveOccurred())
+	return c
+}
+
+func TestTunnel(t *testing.T) {
+	g := NewWithT(t)
+
+	// The management cluster stores the tunnel Secret, while the API server of the workload cluster is only
+	// reachable through the agent.
+	managementConfig, stopManagement := startEnvironment(g)
+	defer stopManagement()
+	workloadConfig, stopWorkload := startEnvironment(g)
+	defer stopWorkload()
+
+	managementClient, err := client.New(managementConfig, client.Options{Scheme: scheme.Scheme})
+	g.Expect(err).NotTo(HaveOccurred())
+	workloadClient, err := client.New(workloadConfig, client.Options{Scheme: scheme.Scheme})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(managementClient.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testCluster.Namespace,
+			Name:      secret.Name(testCluster.Name, secret.Tunnel),
+		},
+		Data: map[string][]byte{
+			secret.TokenDataName: []byte("secret-token"),
+		},
+	})).To(Succeed())
+
+	stop := make(chan struct{})
+	defer close(stop)
+
+	server := &Server{
+		Log:         log.Log,
+		Client:      managementClient,
+		BindAddress: freeAddress(g),
+	}
+	go func() { _ = server.Start(stop) }()
+	serverURL := "http://" + server.BindAddress
+
+	c := tunneledClient(g, workloadConfig, server, testCluster)
+
+	// The API server of a Cluster with a tunnel Secret is not dialed directly while its agent is not connected.
+	err = c.List(ctx, &corev1.NamespaceList{})
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("not connected"))
+
+	// Agents with an invalid token, or of Clusters without a tunnel Secret, are rejected.
+	invalidAgent := &Agent{
+		Log:       log.Log,
+		ServerURL: serverURL,
+		Cluster:   testCluster,
+		Token:     "another-token",
+	}
+	g.Eventually(func() error {
+		_, err := invalidAgent.connect()
+		return err
+	}, 10*time.Second).Should(MatchError(ContainSubstring("401")))
+	invalidAgent.Cluster = client.ObjectKey{Namespace: "default", Name: "another"}
+	_, err = invalidAgent.connect()
+	g.Expect(err).To(MatchError(ContainSubstring("401")))
+	g.Expect(server.Connected(invalidAgent.Cluster)).To(BeFalse())
+
+	agent := &Agent{
+		Log:         log.Log,
+		ServerURL:   serverURL,
+		Cluster:     testCluster,
+		Token:       "secret-token",
+		Target:      hostAddress(g, workloadConfig),
+		RetryPeriod: 100 * time.Millisecond,
+	}
+	go agent.Run(stop)
+	g.Eventually(func() bool { return server.Connected(testCluster) }, 10*time.Second).Should(BeTrue())
+
+	// Objects created through the tunnel are stored by the API server of the workload cluster.
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: metav1.NamespaceDefault,
+			Name:      "through-the-tunnel",
+		},
+	}
+	g.Expect(c.Create(ctx, configMap)).To(Succeed())
+	g.Expect(workloadClient.Get(ctx, client.ObjectKey{Namespace: configMap.Namespace, Name: configMap.Name}, &corev1.ConfigMap{})).To(Succeed())
+	g.Expect(managementClient.Get(ctx, client.ObjectKey{Namespace: configMap.Namespace, Name: configMap.Name}, &corev1.ConfigMap{})).NotTo(Succeed())
+
+	// The agent reconnects when the session is lost, and the existing clients use the new session.
+	session := server.getSession(testCluster)
+	session.Close()
+	g.Eventually(func() bool {
+		current := server.getSession(testCluster)
+		return current != nil && current != session
+	}, 10*time.Second).Should(BeTrue())
+	g.Eventually(func() error {
+		return c.Get(ctx, client.ObjectKey{Namespace: configMap.Namespace, Name: configMap.Name}, &corev1.ConfigMap{})
+	}, 10*time.Second).Should(Succeed())
+
+	// The API server of a Cluster without a tunnel Secret is dialed directly.
+	directConfig := rest.CopyConfig(workloadConfig)
+	directConfig.Dial = server.DialContextFor(client.ObjectKey{Namespace: "default", Name: "reachable"})
+	direct, err := client.New(directConfig, client.Options{Scheme: scheme.Scheme})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(direct.List(ctx, &corev1.NamespaceList{})).To(Succeed())
+}
+
+func TestClusterFromPath(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster, err := clusterFromPath(Path(testCluster))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cluster).To(Equal(testCluster))
+
+	for _, path := range []string{"/", "/tunnel/default", "/tunnel/default/", "/tunnel//edge", "/tunnel/default/edge/extra", "/other/default/edge"} {
+		_, err := clusterFromPath(path)
+		g.Expect(err).To(HaveOccurred(), path)
+		g.Expect(strings.Contains(err.Error(), PathPrefix)).To(BeTrue())
+	}
+}
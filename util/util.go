@@ -626,6 +626,52 @@ func IsSupportedVersionSkew(a, b semver.Version) bool {
 	return b.Minor-a.Minor <= 1
 }
 
+// GetControlPlaneVersion returns the Kubernetes version the control plane of the Cluster has actually been rolled
+// out to, as reported by the status.version field of the object referenced by Cluster.Spec.ControlPlaneRef; it
+// returns nil if the Cluster has no control plane reference or if the control plane object does not report a version.
+func GetControlPlaneVersion(ctx context.Context, c client.Client, cluster *clusterv1.Cluster) (*semver.Version, error) {
+	if cluster.Spec.ControlPlaneRef == nil {
+		return nil, nil
+	}
+
+	controlPlane := ObjectReferenceToUnstructured(*cluster.Spec.ControlPlaneRef)
+	if controlPlane.GetNamespace() == "" {
+		controlPlane.SetNamespace(cluster.Namespace)
+	}
+	key := client.ObjectKey{Namespace: controlPlane.GetNamespace(), Name: controlPlane.GetName()}
+	if err := c.Get(ctx, key, controlPlane); err != nil {
+		return nil, errors.Wrapf(err, "failed to get control plane %s %s", controlPlane.GetKind(), key)
+	}
+
+	version, found, err := unstructured.NestedString(controlPlane.Object, "status", "version")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get status.version from control plane %s %s", controlPlane.GetKind(), key)
+	}
+	if !found {
+		return nil, nil
+	}
+
+	v, err := ParseMajorMinorPatch(version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the version of control plane %s %s", controlPlane.GetKind(), key)
+	}
+	return &v, nil
+}
+
+// IsVersionNewer returns true if a is newer than b, looking only at major.minor.patch.
+func IsVersionNewer(a, b semver.Version) bool {
+	a = semver.Version{Major: a.Major, Minor: a.Minor, Patch: a.Patch}
+	b = semver.Version{Major: b.Major, Minor: b.Minor, Patch: b.Patch}
+	return a.GT(b)
+}
+
+// IsMinorVersionNewer returns true if a is newer than b, looking only at major.minor.
+func IsMinorVersionNewer(a, b semver.Version) bool {
+	a = semver.Version{Major: a.Major, Minor: a.Minor}
+	b = semver.Version{Major: b.Major, Minor: b.Minor}
+	return a.GT(b)
+}
+
 // NewDelegatingClientFunc returns a manager.NewClientFunc to be used when creating
 // a new controller runtime manager.
 //
@@ -25,6 +25,7 @@ import (
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
@@ -823,3 +824,102 @@ func TestIsSupportedVersionSkew(t *testing.T) {
 		})
 	}
 }
+
+func TestIsVersionNewer(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(IsVersionNewer(semver.MustParse("1.17.1"), semver.MustParse("1.17.0"))).To(BeTrue())
+	g.Expect(IsVersionNewer(semver.MustParse("1.18.0"), semver.MustParse("1.17.5"))).To(BeTrue())
+	g.Expect(IsVersionNewer(semver.MustParse("1.17.0"), semver.MustParse("1.17.0"))).To(BeFalse())
+	g.Expect(IsVersionNewer(semver.MustParse("1.17.0"), semver.MustParse("1.17.1"))).To(BeFalse())
+	// Pre-release and build metadata are ignored.
+	g.Expect(IsVersionNewer(semver.MustParse("1.17.0"), semver.MustParse("1.17.0-rc.1"))).To(BeFalse())
+}
+
+func TestIsMinorVersionNewer(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(IsMinorVersionNewer(semver.MustParse("1.18.0"), semver.MustParse("1.17.5"))).To(BeTrue())
+	g.Expect(IsMinorVersionNewer(semver.MustParse("2.0.0"), semver.MustParse("1.17.5"))).To(BeTrue())
+	g.Expect(IsMinorVersionNewer(semver.MustParse("1.17.0"), semver.MustParse("1.17.0"))).To(BeFalse())
+	g.Expect(IsMinorVersionNewer(semver.MustParse("1.17.0"), semver.MustParse("1.18.0"))).To(BeFalse())
+	// Patch versions are ignored.
+	g.Expect(IsMinorVersionNewer(semver.MustParse("1.17.3"), semver.MustParse("1.17.1"))).To(BeFalse())
+}
+
+func TestGetControlPlaneVersion(t *testing.T) {
+	controlPlane := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"kind":       "GenericControlPlane",
+			"apiVersion": "controlplane.cluster.x-k8s.io/v1alpha3",
+			"metadata": map[string]interface{}{
+				"name":      "cp",
+				"namespace": "default",
+			},
+			"spec": map[string]interface{}{
+				"version": "v1.18.0",
+			},
+			"status": map[string]interface{}{
+				"version": "v1.17.3",
+			},
+		},
+	}
+	controlPlaneNoVersion := controlPlane.DeepCopy()
+	controlPlaneNoVersion.SetName("cp-no-version")
+	unstructured.RemoveNestedField(controlPlaneNoVersion.Object, "status", "version")
+
+	controlPlaneRef := func(name string) *corev1.ObjectReference {
+		return &corev1.ObjectReference{
+			Kind:       "GenericControlPlane",
+			APIVersion: "controlplane.cluster.x-k8s.io/v1alpha3",
+			Name:       name,
+		}
+	}
+
+	tests := []struct {
+		name            string
+		controlPlaneRef *corev1.ObjectReference
+		want            *semver.Version
+		wantErr         bool
+	}{
+		{
+			name:            "returns nil without a control plane reference",
+			controlPlaneRef: nil,
+			want:            nil,
+		},
+		{
+			name:            "returns the version the control plane has been rolled out to",
+			controlPlaneRef: controlPlaneRef("cp"),
+			want:            &semver.Version{Major: 1, Minor: 17, Patch: 3},
+		},
+		{
+			name:            "returns nil if the control plane does not report a version",
+			controlPlaneRef: controlPlaneRef("cp-no-version"),
+			want:            nil,
+		},
+		{
+			name:            "returns error if the control plane does not exist",
+			controlPlaneRef: controlPlaneRef("does-not-exist"),
+			wantErr:         true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec:       clusterv1.ClusterSpec{ControlPlaneRef: tt.controlPlaneRef},
+			}
+			c := fake.NewFakeClientWithScheme(runtime.NewScheme(), controlPlane.DeepCopy(), controlPlaneNoVersion.DeepCopy())
+
+			got, err := GetControlPlaneVersion(context.Background(), c, cluster)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}